@@ -0,0 +1,353 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/manifoldco/promptui"
+	"github.com/spf13/cobra"
+
+	"github.com/pascal71/hrbcli/pkg/api"
+	"github.com/pascal71/hrbcli/pkg/apply"
+	"github.com/pascal71/hrbcli/pkg/compare"
+	"github.com/pascal71/hrbcli/pkg/config"
+	"github.com/pascal71/hrbcli/pkg/output"
+)
+
+// errDrift is returned by diff and apply --dry-run when Harbor differs
+// from the manifests, so CI jobs can fail on drift
+var errDrift = &exitError{code: 2, msg: "Harbor differs from the manifests"}
+
+// manifestFlags are shared by apply and diff
+type manifestFlags struct {
+	files      []string
+	prune      bool
+	pruneKinds []string
+}
+
+func (f *manifestFlags) register(cmd *cobra.Command) {
+	cmd.Flags().StringSliceVarP(&f.files, "filename", "f", nil, "Manifest file or directory (repeatable)")
+	cmd.Flags().BoolVar(&f.prune, "prune", false, "Delete objects of the declared kinds, except projects, that are not in the manifests")
+	cmd.Flags().StringSliceVar(&f.pruneKinds, "prune-kind", nil, "Only prune this kind; required to prune projects (repeatable, implies --prune)")
+}
+
+// plan loads the manifests and compares them with Harbor
+func (f *manifestFlags) plan(cmd *cobra.Command) (*apply.Engine, *apply.Plan, error) {
+	if len(f.files) == 0 {
+		return nil, nil, fmt.Errorf("at least one manifest is required (-f)")
+	}
+	resources, err := apply.LoadManifests(f.files)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	client, err := api.NewClient()
+	if err != nil {
+		return nil, nil, err
+	}
+	engine := apply.NewEngine(client)
+	plan, err := engine.Plan(cmd.Context(), resources, apply.Options{
+		Prune:      f.prune || len(f.pruneKinds) > 0,
+		PruneKinds: f.pruneKinds,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return engine, plan, nil
+}
+
+// NewApplyCmd creates the apply command
+func NewApplyCmd() *cobra.Command {
+	var (
+		flags     manifestFlags
+		dryRun    bool
+		force     bool
+		secretDir string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "apply",
+		Short: "Create or update Harbor objects from manifests",
+		Long: `Converge Harbor to the objects declared in YAML manifests.
+
+Each document has a kind, a name and a spec:
+
+  kind: Project
+  name: team-a
+  spec:
+    public: false
+    auto_scan: true
+
+Supported kinds are Registry, Project, Label, ReplicationPolicy, Robot and
+Webhook. Only the fields present in a spec are managed. The plan is shown
+before anything changes; with --prune, objects of the declared kinds that
+are missing from the manifests are deleted. Projects are only pruned when
+named with --prune-kind Project.
+
+The secrets of created robots are never printed. They are written to
+<name>.secret files with 0600 permissions in --secret-dir, which is
+required when the plan creates robots.`,
+		Example: `  # Show what would change and exit 2 if anything would
+  hrbcli apply -f harbor/ --dry-run
+
+  # Apply without confirmation and store new robot secrets
+  hrbcli apply -f harbor/ --force --secret-dir ./secrets
+
+  # Also delete undeclared objects
+  hrbcli apply -f harbor/ --prune
+
+  # Also delete undeclared projects
+  hrbcli apply -f harbor/ --prune-kind Project`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			engine, plan, err := flags.plan(cmd)
+			if err != nil {
+				return err
+			}
+
+			if !plan.HasChanges() {
+				return printPlan(plan)
+			}
+
+			if dryRun {
+				if err := printPlan(plan); err != nil {
+					return err
+				}
+				return errDrift
+			}
+
+			if secretDir == "" {
+				for _, c := range plan.Changes {
+					if c.GeneratesSecret() {
+						return fmt.Errorf("--secret-dir is required to store the secret of %s %s", c.Kind, c.Name)
+					}
+				}
+			}
+
+			if output.GetFormat() == "table" {
+				printPlanText(plan)
+			}
+			if !force {
+				prompt := promptui.Prompt{
+					Label:     fmt.Sprintf("Apply %d changes", len(plan.Changes)),
+					IsConfirm: true,
+				}
+				if _, err := prompt.Run(); err != nil {
+					output.Info("Apply cancelled")
+					return nil
+				}
+			}
+
+			for _, c := range plan.Changes {
+				if err := engine.ApplyChange(cmd.Context(), c); err != nil {
+					return err
+				}
+				var secretFile string
+				if c.Secret() != "" {
+					secretFile = filepath.Join(secretDir, c.Name+".secret")
+					if err := writeSecretFile(secretFile, c.Secret()); err != nil {
+						return fmt.Errorf("failed to write secret of %s %s: %w", c.Kind, c.Name, err)
+					}
+				}
+				if output.GetFormat() == "table" {
+					output.Success("%s %s %sd", c.Kind, c.Name, c.Action)
+					if secretFile != "" {
+						output.Info("Secret written to %s", secretFile)
+					}
+				}
+			}
+
+			switch output.GetFormat() {
+			case "json":
+				return output.JSON(plan)
+			case "yaml":
+				return output.YAML(plan)
+			}
+			return nil
+		},
+	}
+
+	flags.register(cmd)
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Only show the plan; exit 2 if there are changes")
+	cmd.Flags().BoolVar(&force, "force", false, "Apply without confirmation")
+	cmd.Flags().StringVar(&secretDir, "secret-dir", "", "Write the secrets of created robots to <name>.secret files in this directory (mode 0600)")
+	return cmd
+}
+
+// NewDiffCmd creates the diff command
+func NewDiffCmd() *cobra.Command {
+	var (
+		flags    manifestFlags
+		from, to string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "diff",
+		Short: "Show differences between manifests and Harbor, or two Harbor instances",
+		Long: `Compare YAML manifests with Harbor without changing anything.
+
+With --from and --to, compare two Harbor instances configured as contexts
+instead: projects and their metadata, registries, replication policies,
+labels, users and system configuration. Objects are matched by name and
+references between them by the name of the referenced object, so IDs may
+differ.
+
+The exit code is 0 when there are no differences and 2 when there are,
+so the command can guard against drift in CI.`,
+		Example: `  hrbcli diff -f harbor/
+  hrbcli diff -f harbor/ --prune -o json
+
+  # Compare two instances
+  hrbcli diff --from prod --to staging`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if from != "" || to != "" {
+				if len(flags.files) > 0 {
+					return fmt.Errorf("--from/--to cannot be combined with -f")
+				}
+				return diffInstances(cmd, from, to)
+			}
+
+			_, plan, err := flags.plan(cmd)
+			if err != nil {
+				return err
+			}
+			if err := printPlan(plan); err != nil {
+				return err
+			}
+			if plan.HasChanges() {
+				return errDrift
+			}
+			return nil
+		},
+	}
+
+	flags.register(cmd)
+	cmd.Flags().StringVar(&from, "from", "", "Context of the reference instance (default the active context)")
+	cmd.Flags().StringVar(&to, "to", "", "Context of the instance to compare with")
+	return cmd
+}
+
+// diffInstances compares the Harbor instances of two contexts
+func diffInstances(cmd *cobra.Command, from, to string) error {
+	if to == "" {
+		return fmt.Errorf("--to is required when comparing instances")
+	}
+	if from == "" {
+		from = config.ActiveContext()
+		if from == "" {
+			return fmt.Errorf("--from is required when no context is active")
+		}
+	}
+
+	var inventories []compare.Inventory
+	for _, name := range []string{from, to} {
+		client, err := api.NewClientForContext(name)
+		if err != nil {
+			return err
+		}
+		inv, err := compare.Collect(cmd.Context(), client)
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		inventories = append(inventories, inv)
+	}
+	diffs := compare.Compare(inventories[0], inventories[1])
+
+	switch output.GetFormat() {
+	case "json":
+		if err := output.JSON(diffs); err != nil {
+			return err
+		}
+	case "yaml":
+		if err := output.YAML(diffs); err != nil {
+			return err
+		}
+	default:
+		if len(diffs) == 0 {
+			output.Success("No differences between %s and %s", from, to)
+			return nil
+		}
+		table := output.Table()
+		table.Append([]string{"SECTION", "NAME", "FIELD", strings.ToUpper(from), strings.ToUpper(to)})
+		for _, d := range diffs {
+			row := []string{d.Section, d.Name, d.Field, "", ""}
+			switch d.Type {
+			case compare.OnlyInFrom:
+				row[3], row[4] = output.Green("present"), output.Red("missing")
+			case compare.OnlyInTo:
+				row[3], row[4] = output.Red("missing"), output.Green("present")
+			default:
+				row[3], row[4] = diffValue(d.From), diffValue(d.To)
+			}
+			table.Append(row)
+		}
+		table.Render()
+	}
+
+	if len(diffs) > 0 {
+		return &exitError{code: 2, msg: fmt.Sprintf("%d differences between %s and %s", len(diffs), from, to)}
+	}
+	return nil
+}
+
+func diffValue(v interface{}) string {
+	if v == nil {
+		return "(unset)"
+	}
+	if s, ok := v.(string); ok {
+		return output.Truncate(s, 60)
+	}
+	return output.Truncate(planValue(v), 60)
+}
+
+func printPlan(plan *apply.Plan) error {
+	switch output.GetFormat() {
+	case "json":
+		return output.JSON(plan)
+	case "yaml":
+		return output.YAML(plan)
+	default:
+		printPlanText(plan)
+		return nil
+	}
+}
+
+// printPlanText prints the plan in a terraform like layout
+func printPlanText(plan *apply.Plan) {
+	if !plan.HasChanges() {
+		output.Success("No changes, %d objects up to date", plan.Unchanged)
+		return
+	}
+
+	for _, c := range plan.Changes {
+		switch c.Action {
+		case apply.ActionCreate:
+			fmt.Printf("%s %s %s\n", output.Green("+"), c.Kind, c.Name)
+			for _, f := range c.Fields {
+				fmt.Printf("      %s: %s\n", f.Field, planValue(f.New))
+			}
+		case apply.ActionUpdate:
+			fmt.Printf("%s %s %s\n", output.Yellow("~"), c.Kind, c.Name)
+			for _, f := range c.Fields {
+				fmt.Printf("      %s: %s -> %s\n", f.Field, planValue(f.Old), planValue(f.New))
+			}
+		case apply.ActionDelete:
+			fmt.Printf("%s %s %s\n", output.Red("-"), c.Kind, c.Name)
+		}
+	}
+
+	create, update, del := plan.Counts()
+	fmt.Printf("\nPlan: %d to create, %d to update, %d to delete (%d unchanged).\n", create, update, del, plan.Unchanged)
+}
+
+func planValue(v interface{}) string {
+	if v == nil {
+		return "(unset)"
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
@@ -2,6 +2,8 @@ package cmd
 
 import (
 	"fmt"
+	"io"
+	"os"
 	"strings"
 	"time"
 
@@ -10,6 +12,8 @@ import (
 	"github.com/pascal71/hrbcli/pkg/api"
 	"github.com/pascal71/hrbcli/pkg/harbor"
 	"github.com/pascal71/hrbcli/pkg/output"
+	"github.com/pascal71/hrbcli/pkg/sbom"
+	"github.com/pascal71/hrbcli/pkg/vuln"
 )
 
 // NewArtifactCmd creates the artifact command
@@ -24,24 +28,41 @@ func NewArtifactCmd() *cobra.Command {
 	cmd.AddCommand(newArtifactGetCmd())
 	cmd.AddCommand(newArtifactScanCmd())
 	cmd.AddCommand(newArtifactVulnCmd())
+	cmd.AddCommand(newArtifactVulnDiffCmd())
+	cmd.AddCommand(newArtifactCheckCmd())
 	cmd.AddCommand(newArtifactSbomCmd())
+	cmd.AddCommand(newArtifactPullCmd())
+	cmd.AddCommand(newArtifactPushCmd())
+	cmd.AddCommand(newArtifactCopyCmd())
+	cmd.AddCommand(newArtifactTagCmd())
 
 	return cmd
 }
 
 func newArtifactScanCmd() *cobra.Command {
 	var (
-		scanType string
-		wait     bool
-		all      bool
+		scanType    string
+		wait        bool
+		all         bool
+		concurrency int
 	)
 
 	cmd := &cobra.Command{
 		Use:   "scan <project>/<repository>[:tag|@digest]",
 		Short: "Scan an image",
-		Long:  `Trigger vulnerability scan for a specific image in Harbor.`,
-		Args:  requireArgs(1, "requires <project>/<repository>[:tag|@digest]"),
+		Long: `Trigger vulnerability scan for a specific image in Harbor.
+
+With --all every artifact of the repository is scanned. --wait polls until
+the scans succeeded, failed or were stopped and exits non-zero when a scan
+failed or did not finish within --timeout.`,
+		Example: `  hrbcli artifact scan myproject/myapp:latest --wait
+  hrbcli artifact scan myproject/myapp --all --wait --timeout 15m`,
+		Args: requireArgs(1, "requires <project>/<repository>[:tag|@digest]"),
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := checkScanWait(wait, scanType); err != nil {
+				return err
+			}
+
 			client, err := api.NewClient()
 			if err != nil {
 				return err
@@ -58,18 +79,21 @@ func newArtifactScanCmd() *cobra.Command {
 					return fmt.Errorf("repository is required when using --all")
 				}
 
-				arts, err := artSvc.List(project, repo, nil)
+				arts, err := artSvc.ListAll(cmd.Context(), project, repo, nil, 0)
 				if err != nil {
 					return fmt.Errorf("failed to list artifacts: %w", err)
 				}
-				for _, a := range arts {
-					if err := artSvc.Scan(project, repo, a.Digest, scanType); err != nil {
-						output.Warning("Failed to scan %s/%s@%s: %v", project, repo, output.Truncate(a.Digest, 13), err)
-					} else {
-						output.Success("Scan triggered for %s/%s@%s", project, repo, output.Truncate(a.Digest, 13))
+				targets := make([]repoArtifact, len(arts))
+				for i, a := range arts {
+					targets[i] = repoArtifact{Repository: repo, Artifact: a}
+				}
+				triggered, errs := scanArtifacts(cmd.Context(), artSvc, project, targets, scanType, concurrency)
+				if wait && len(triggered) > 0 {
+					if err := printScanOutcomes(waitForScans(cmd.Context(), artSvc, project, triggered, concurrency)); err != nil {
+						errs = append(errs, err)
 					}
 				}
-				return nil
+				return errs.Err()
 			}
 
 			project, repo, ref, err := parseArtifactRef(args[0])
@@ -77,41 +101,28 @@ func newArtifactScanCmd() *cobra.Command {
 				return err
 			}
 
-			if err := artSvc.Scan(project, repo, ref, scanType); err != nil {
+			if err := artSvc.Scan(cmd.Context(), project, repo, ref, scanType); err != nil {
 				return fmt.Errorf("failed to scan artifact: %w", err)
 			}
 
 			output.Success("Scan triggered for %s/%s:%s", project, repo, ref)
 
 			if wait {
-				ctx := cmd.Context()
-				for {
-					select {
-					case <-ctx.Done():
-						return ctx.Err()
-					default:
-					}
-
-					art, err := artSvc.GetWithOptions(project, repo, ref, &api.ArtifactGetOptions{WithScanOverview: true})
-					if err != nil {
-						return fmt.Errorf("failed to get scan status: %w", err)
-					}
-
-					done := true
-					for _, ov := range art.ScanOverview {
-						status := strings.ToLower(ov.ScanStatus)
-						if status != "success" && status != "finished" {
-							done = false
-							break
-						}
-					}
-
-					if done {
-						output.Success("Scan completed for %s/%s:%s", project, repo, ref)
-						return nil
+				// The reference may be a tag, which works for polling too
+				target := repoArtifact{Repository: repo, Artifact: &api.Artifact{Digest: ref}}
+				outcome := waitForScans(cmd.Context(), artSvc, project, []repoArtifact{target}, 1)[0]
+				switch outcome.Status {
+				case scanSuccess:
+					output.Success("Scan completed for %s/%s:%s", project, repo, ref)
+				case scanStopped:
+					output.Warning("Scan stopped for %s/%s:%s", project, repo, ref)
+				case scanTimeout:
+					return fmt.Errorf("scan did not finish for %s/%s:%s: %s", project, repo, ref, outcome.Message)
+				default:
+					if outcome.Message != "" {
+						return fmt.Errorf("scan failed for %s/%s:%s: %s", project, repo, ref, outcome.Message)
 					}
-
-					time.Sleep(2 * time.Second)
+					return fmt.Errorf("scan failed for %s/%s:%s", project, repo, ref)
 				}
 			}
 
@@ -121,8 +132,9 @@ func newArtifactScanCmd() *cobra.Command {
 
 	cmd.Flags().StringVar(&scanType, "scan-type", "", "Scan type (vulnerability|sbom)")
 
-	cmd.Flags().BoolVar(&wait, "wait", false, "Wait for scan to complete")
+	cmd.Flags().BoolVar(&wait, "wait", false, "Wait for the scan to finish; with --all summarize the outcome of every scan")
 	cmd.Flags().BoolVar(&all, "all", false, "Scan all artifacts in the repository")
+	addConcurrencyFlag(cmd, &concurrency)
 
 	return cmd
 }
@@ -176,6 +188,7 @@ func newArtifactListCmd() *cobra.Command {
 		withLabel        bool
 		withScanOverview bool
 		detail           bool
+		pages            pageFlags
 	)
 
 	cmd := &cobra.Command{
@@ -203,6 +216,13 @@ func newArtifactListCmd() *cobra.Command {
 				WithSignature:    true,
 				WithScanOverview: withScanOverview,
 			}
+			listArtifacts := func(repoName string) ([]*api.Artifact, error) {
+				if pages.enabled() {
+					opts.Page, opts.PageSize = 0, 0
+					return artSvc.ListAll(cmd.Context(), project, repoName, opts, pages.limit)
+				}
+				return artSvc.List(cmd.Context(), project, repoName, opts)
+			}
 
 			printArtifacts := func(repoName string, arts []*api.Artifact) error {
 				if len(arts) == 0 {
@@ -223,7 +243,7 @@ func newArtifactListCmd() *cobra.Command {
 					table.Append(headers)
 					for _, a := range arts {
 						if detail && (a.ExtraAttrs == nil || a.ExtraAttrs.Architecture == "") {
-							if art, err := artSvc.Get(project, repoName, a.Digest); err == nil && art.ExtraAttrs != nil {
+							if art, err := artSvc.Get(cmd.Context(), project, repoName, a.Digest); err == nil && art.ExtraAttrs != nil {
 								a.ExtraAttrs = art.ExtraAttrs
 							}
 						}
@@ -255,7 +275,7 @@ func newArtifactListCmd() *cobra.Command {
 			}
 
 			if repo != "" {
-				arts, err := artSvc.List(project, repo, opts)
+				arts, err := listArtifacts(repo)
 				if err != nil {
 					return fmt.Errorf("failed to list artifacts: %w", err)
 				}
@@ -263,7 +283,7 @@ func newArtifactListCmd() *cobra.Command {
 			}
 
 			repoSvc := harbor.NewRepositoryService(client)
-			repos, err := repoSvc.List(project, nil)
+			repos, err := repoSvc.ListAll(cmd.Context(), project, nil, 0)
 			if err != nil {
 				return fmt.Errorf("failed to list repositories: %w", err)
 			}
@@ -271,7 +291,7 @@ func newArtifactListCmd() *cobra.Command {
 			for _, r := range repos {
 
 				repoName := strings.TrimPrefix(r.Name, project+"/")
-				arts, err := artSvc.List(project, repoName, opts)
+				arts, err := listArtifacts(repoName)
 
 				if err != nil {
 					return fmt.Errorf("failed to list artifacts for %s: %w", r.Name, err)
@@ -290,6 +310,7 @@ func newArtifactListCmd() *cobra.Command {
 	cmd.Flags().BoolVar(&withLabel, "with-label", false, "Include labels")
 	cmd.Flags().BoolVar(&withScanOverview, "with-scan-overview", false, "Include scan overview")
 	cmd.Flags().BoolVar(&detail, "detail", false, "Show detailed information")
+	pages.register(cmd)
 	return cmd
 }
 
@@ -318,7 +339,7 @@ func newArtifactVulnCmd() *cobra.Command {
 			artSvc := harbor.NewArtifactService(client)
 
 			if summary {
-				art, err := artSvc.GetWithOptions(project, repo, ref, &api.ArtifactGetOptions{WithScanOverview: true})
+				art, err := artSvc.GetWithOptions(cmd.Context(), project, repo, ref, &api.ArtifactGetOptions{WithScanOverview: true})
 				if err != nil {
 					return fmt.Errorf("failed to get summary: %w", err)
 				}
@@ -365,11 +386,30 @@ func newArtifactVulnCmd() *cobra.Command {
 				}
 			}
 
-			report, err := artSvc.Vulnerabilities(project, repo, ref)
+			report, err := artSvc.Vulnerabilities(cmd.Context(), project, repo, ref)
 			if err != nil {
 				return fmt.Errorf("failed to get vulnerabilities: %w", err)
 			}
 
+			// CI formats are written even without findings
+			if format := output.GetFormat(); ciFormat(format) {
+				var vulns []api.VulnerabilityItem
+				if report != nil {
+					for _, v := range report.Vulnerabilities {
+						if severity == "" || vuln.AtLeast(v.Severity, severity) {
+							vulns = append(vulns, v)
+						}
+					}
+				}
+				if err := writeScanResults(outFile, format, []output.ScanResult{scanResult(args[0], vulns)}); err != nil {
+					return err
+				}
+				if severity != "" && len(vulns) > 0 {
+					return fmt.Errorf("vulnerabilities with severity >= %s found", severity)
+				}
+				return nil
+			}
+
 			if report == nil {
 				output.Info("No vulnerabilities found")
 				return nil
@@ -395,14 +435,11 @@ func newArtifactVulnCmd() *cobra.Command {
 				output.Success("Saved report to %s", outFile)
 			}
 
-			sevOrder := map[string]int{"none": 0, "negligible": 1, "low": 2, "medium": 3, "high": 4, "critical": 5}
 			var vulns []api.VulnerabilityItem
 			normalized := strings.ToLower(severity)
 			for _, v := range report.Vulnerabilities {
-				if normalized != "" {
-					if sevOrder[strings.ToLower(v.Severity)] < sevOrder[normalized] {
-						continue
-					}
+				if normalized != "" && !vuln.AtLeast(v.Severity, normalized) {
+					continue
 				}
 				vulns = append(vulns, v)
 			}
@@ -441,14 +478,364 @@ func newArtifactVulnCmd() *cobra.Command {
 	return cmd
 }
 
+func newArtifactVulnDiffCmd() *cobra.Command {
+	var (
+		severity  string
+		unchanged bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "vuln-diff <project>/<repository>[:tag|@digest] <project>/<repository>[:tag|@digest]",
+		Short: "Compare the vulnerabilities of two artifacts",
+		Long: `Compare the vulnerability reports of two artifacts, e.g. before and after
+a base image bump. Each CVE and package pair is classified as added,
+removed, severity-changed or unchanged. Unchanged findings are only listed
+in the table with --unchanged.
+
+With --severity the command fails when the second artifact has findings at
+or above that severity that the first did not have. Both artifacts must
+have been scanned.`,
+		Example: `  hrbcli artifact vuln-diff myproject/app:1.0 myproject/app:1.1
+  hrbcli artifact vuln-diff myproject/app:1.0 myproject/app:1.1 --severity high`,
+		Args: requireArgs(2, "requires two <project>/<repository>[:tag|@digest] references"),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if severity != "" && !vuln.ValidSeverity(severity) {
+				return fmt.Errorf("invalid severity %q", severity)
+			}
+
+			client, err := api.NewClient()
+			if err != nil {
+				return err
+			}
+			artSvc := harbor.NewArtifactService(client)
+
+			reports := make([]*api.VulnerabilityReport, len(args))
+			for i, arg := range args {
+				project, repo, ref, err := parseArtifactRef(arg)
+				if err != nil {
+					return err
+				}
+				if reports[i], err = artSvc.Vulnerabilities(cmd.Context(), project, repo, ref); err != nil {
+					return fmt.Errorf("failed to get vulnerabilities of %s: %w", arg, err)
+				}
+				if reports[i] == nil {
+					return fmt.Errorf("artifact %s has not been scanned", arg)
+				}
+			}
+
+			changes := vuln.Diff(reports[0], reports[1])
+
+			switch output.GetFormat() {
+			case "json":
+				if err := output.JSON(changes); err != nil {
+					return err
+				}
+			case "yaml":
+				if err := output.YAML(changes); err != nil {
+					return err
+				}
+			default:
+				printVulnDiff(changes, unchanged)
+			}
+
+			if severity == "" {
+				return nil
+			}
+			var found int
+			for _, c := range changes {
+				if c.IsNew(severity) {
+					found++
+				}
+			}
+			if found > 0 {
+				return fmt.Errorf("%d new vulnerabilities with severity >= %s found", found, severity)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&severity, "severity", "", "Fail if new vulnerabilities of this severity or higher appear")
+	cmd.Flags().BoolVar(&unchanged, "unchanged", false, "Also list unchanged vulnerabilities")
+
+	return cmd
+}
+
+func printVulnDiff(changes []*vuln.Change, unchanged bool) {
+	counts := vuln.Counts(changes)
+	if len(changes) == counts[vuln.Unchanged] && !unchanged {
+		output.Info("No differences (%d unchanged vulnerabilities)", counts[vuln.Unchanged])
+		return
+	}
+
+	table := output.Table()
+	table.Append([]string{"STATUS", "SEVERITY", "CVE", "PACKAGE", "VERSION", "FIXED VERSION"})
+	for _, c := range changes {
+		if c.Status == vuln.Unchanged && !unchanged {
+			continue
+		}
+		status := c.Status
+		switch c.Status {
+		case vuln.Added:
+			status = output.Red(status)
+		case vuln.Removed:
+			status = output.Green(status)
+		case vuln.SeverityChanged:
+			status = output.Yellow(status)
+		}
+		table.Append([]string{
+			status,
+			transition(c.FromSeverity, c.ToSeverity),
+			c.CVEID,
+			c.Package,
+			transition(c.FromVersion, c.ToVersion),
+			c.FixedVersion,
+		})
+	}
+	table.Render()
+
+	fmt.Printf("\n%d added, %d removed, %d severity changed, %d unchanged\n",
+		counts[vuln.Added], counts[vuln.Removed], counts[vuln.SeverityChanged], counts[vuln.Unchanged])
+}
+
+// checkReport is the auditable outcome of artifact check
+type checkReport struct {
+	Artifact     string    `json:"artifact" yaml:"artifact"`
+	Policy       string    `json:"policy" yaml:"policy"`
+	CheckedAt    time.Time `json:"checked_at" yaml:"checked_at"`
+	*vuln.Result `yaml:",inline"`
+}
+
+func newArtifactCheckCmd() *cobra.Command {
+	var (
+		policyFile string
+		outFile    string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "check <project>/<repository>[:tag|@digest]",
+		Short: "Check an artifact against a vulnerability policy",
+		Long: `Evaluate the vulnerability report of an artifact against a policy file
+and report pass or fail with reasons. The command exits with code 2 when
+the policy fails; an artifact that has not been scanned is an error.
+
+A policy file supports these rules:
+
+  max_counts:            # maximum findings per severity
+    critical: 0
+    high: 5
+  allowlist:             # accepted CVEs, optionally per package
+    - id: CVE-2024-1234
+      package: openssl
+      expires: 2026-12-31
+      justification: Not reachable from the service
+  ignore_unfixed: true   # drop findings without a fixed version
+  fixable_only: true     # only gate findings with a fixed version
+  exclude_packages:      # package names or glob patterns
+    - linux-libc-dev
+
+Expired allowlist entries no longer apply and are reported as warnings.`,
+		Example: `  hrbcli artifact check myproject/app:1.0 --policy policy.yaml
+  hrbcli artifact check myproject/app:1.0 --policy policy.yaml --file audit.json`,
+		Args: requireArgs(1, "requires <project>/<repository>[:tag|@digest]"),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if policyFile == "" {
+				return fmt.Errorf("--policy is required")
+			}
+			policy, err := vuln.LoadPolicy(policyFile)
+			if err != nil {
+				return err
+			}
+			project, repo, ref, err := parseArtifactRef(args[0])
+			if err != nil {
+				return err
+			}
+
+			client, err := api.NewClient()
+			if err != nil {
+				return err
+			}
+			report, err := harbor.NewArtifactService(client).Vulnerabilities(cmd.Context(), project, repo, ref)
+			if err != nil {
+				return fmt.Errorf("failed to get vulnerabilities: %w", err)
+			}
+			if report == nil {
+				return fmt.Errorf("artifact %s has not been scanned", args[0])
+			}
+
+			now := time.Now()
+			result := &checkReport{
+				Artifact:  args[0],
+				Policy:    policyFile,
+				CheckedAt: now.UTC(),
+				Result:    policy.Evaluate(report, now),
+			}
+
+			if outFile != "" {
+				format := output.GetFormat()
+				if format != "yaml" {
+					format = "json"
+				}
+				if err := output.WriteFile(outFile, format, result); err != nil {
+					return fmt.Errorf("failed to write report: %w", err)
+				}
+				output.Success("Saved report to %s", outFile)
+			}
+
+			switch output.GetFormat() {
+			case "json":
+				if err := output.JSON(result); err != nil {
+					return err
+				}
+			case "yaml":
+				if err := output.YAML(result); err != nil {
+					return err
+				}
+			default:
+				printCheckResult(result)
+			}
+
+			if !result.Passed {
+				return &exitError{code: 2, msg: fmt.Sprintf("%s does not comply with %s", args[0], policyFile)}
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&policyFile, "policy", "", "Policy file (YAML)")
+	cmd.Flags().StringVarP(&outFile, "file", "f", "", "Save the check report to file")
+
+	return cmd
+}
+
+func printCheckResult(result *checkReport) {
+	if len(result.Findings) > 0 {
+		table := output.Table()
+		table.Append([]string{"STATUS", "SEVERITY", "CVE", "PACKAGE", "VERSION", "FIXED VERSION", "REASON"})
+		for _, f := range result.Findings {
+			table.Append([]string{f.Status, f.Severity, f.CVEID, f.Package, f.Version, f.FixedVersion, output.Truncate(f.Reason, 50)})
+		}
+		table.Render()
+		fmt.Println()
+	}
+
+	for _, w := range result.Warnings {
+		output.Warning("%s", w)
+	}
+	if result.Passed {
+		output.Success("%s passes %s", result.Artifact, result.Policy)
+		return
+	}
+	output.Error("%s fails %s:", result.Artifact, result.Policy)
+	for _, r := range result.Reasons {
+		fmt.Printf("  - %s\n", r)
+	}
+}
+
+// ciFormat reports whether format is a report format for CI systems
+func ciFormat(format string) bool {
+	return format == "sarif" || format == "junit"
+}
+
+// scanResult converts the findings for an artifact for SARIF and JUnit
+// output
+func scanResult(artifact string, items []api.VulnerabilityItem) output.ScanResult {
+	res := output.ScanResult{Artifact: artifact}
+	for _, v := range items {
+		res.Findings = append(res.Findings, output.Finding{
+			ID:           v.CVEID,
+			Package:      v.Package,
+			Version:      v.Version,
+			FixedVersion: v.FixedVersion,
+			Severity:     v.Severity,
+			Description:  v.Description,
+			Links:        v.Links,
+		})
+	}
+	return res
+}
+
+// artifactName formats an artifact reference as project/repository:tag
+// or project/repository@digest
+func artifactName(project, repo, ref string) string {
+	if strings.HasPrefix(ref, "sha256:") {
+		return fmt.Sprintf("%s/%s@%s", project, repo, ref)
+	}
+	return fmt.Sprintf("%s/%s:%s", project, repo, ref)
+}
+
+// writeScanResults writes results as SARIF or JUnit to path, or to
+// stdout when path is empty
+func writeScanResults(path, format string, results []output.ScanResult) error {
+	var w io.Writer = os.Stdout
+	if path != "" {
+		f, err := os.Create(path)
+		if err != nil {
+			return fmt.Errorf("failed to write report: %w", err)
+		}
+		defer f.Close()
+		w = f
+	}
+
+	var err error
+	if format == "junit" {
+		err = output.JUnit(w, results)
+	} else {
+		err = output.SARIF(w, results)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+
+	if path != "" {
+		output.Success("Saved report to %s", path)
+	}
+	return nil
+}
+
+// transition shows a value that may differ between two reports
+func transition(from, to string) string {
+	switch {
+	case from == "" || from == to:
+		return to
+	case to == "":
+		return from
+	default:
+		return from + " -> " + to
+	}
+}
+
 func newArtifactSbomCmd() *cobra.Command {
-	var outFile string
+	var (
+		outFile string
+		format  string
+		diffRef string
+	)
 
 	cmd := &cobra.Command{
 		Use:   "sbom <project>/<repository>[:tag|@digest]",
 		Short: "Show SBOM report",
-		Args:  requireArgs(1, "requires <project>/<repository>[:tag|@digest]"),
+		Long: `Show the SBOM of an artifact. Harbor stores SBOMs in CycloneDX or SPDX
+format. --format selects the output:
+
+  raw         the document as generated by the scanner (default)
+  cyclonedx   a CycloneDX 1.5 JSON document
+  spdx        an SPDX 2.3 JSON document
+  components  a flat component list (name, version, purl, license)
+
+With --diff the components of the artifact are compared with those of
+another artifact. The other reference is a tag or digest in the same
+repository, or a full <project>/<repository>[:tag|@digest].`,
+		Example: `  hrbcli artifact sbom myproject/app:1.0 --format components
+  hrbcli artifact sbom myproject/app:1.0 --format cyclonedx -f app.cdx.json
+  hrbcli artifact sbom myproject/app:1.0 --diff 1.1`,
+		Args: requireArgs(1, "requires <project>/<repository>[:tag|@digest]"),
 		RunE: func(cmd *cobra.Command, args []string) error {
+			switch format {
+			case "raw", sbom.FormatCycloneDX, sbom.FormatSPDX, "components":
+			default:
+				return fmt.Errorf("invalid format %q: expected raw, cyclonedx, spdx or components", format)
+			}
+
 			project, repo, ref, err := parseArtifactRef(args[0])
 			if err != nil {
 				return err
@@ -460,22 +847,62 @@ func newArtifactSbomCmd() *cobra.Command {
 			}
 
 			artSvc := harbor.NewArtifactService(client)
-			report, err := artSvc.SBOM(project, repo, ref)
+			report, err := artSvc.SBOM(cmd.Context(), project, repo, ref)
 			if err != nil {
 				return fmt.Errorf("failed to get SBOM: %w", err)
 			}
 
-			if len(report) == 0 {
+			if report == nil {
 				output.Info("No SBOM data found")
 				return nil
 			}
 
+			if diffRef != "" {
+				other := diffRef
+				if !strings.Contains(other, "/") {
+					other = artifactName(project, repo, diffRef)
+				}
+				oProject, oRepo, oRef, err := parseArtifactRef(other)
+				if err != nil {
+					return err
+				}
+				otherReport, err := artSvc.SBOM(cmd.Context(), oProject, oRepo, oRef)
+				if err != nil {
+					return fmt.Errorf("failed to get SBOM of %s: %w", other, err)
+				}
+				if otherReport == nil {
+					return fmt.Errorf("no SBOM data found for %s", other)
+				}
+
+				changes := sbom.Diff(report, otherReport)
+				switch output.GetFormat() {
+				case "json":
+					return output.JSON(changes)
+				case "yaml":
+					return output.YAML(changes)
+				default:
+					printSBOMDiff(changes)
+					return nil
+				}
+			}
+
+			var data interface{} = report.Components
+			if format != "components" {
+				docFormat := format
+				if format == "raw" {
+					docFormat = report.Format
+				}
+				if data, err = report.Document(docFormat); err != nil {
+					return err
+				}
+			}
+
 			if outFile != "" {
-				format := output.GetFormat()
-				if format != "yaml" {
-					format = "json"
+				fileFormat := output.GetFormat()
+				if fileFormat != "yaml" {
+					fileFormat = "json"
 				}
-				if err := output.WriteFile(outFile, format, report); err != nil {
+				if err := output.WriteFile(outFile, fileFormat, data); err != nil {
 					return fmt.Errorf("failed to write report: %w", err)
 				}
 				output.Success("Saved report to %s", outFile)
@@ -484,18 +911,77 @@ func newArtifactSbomCmd() *cobra.Command {
 
 			switch output.GetFormat() {
 			case "yaml":
-				return output.YAML(report)
+				return output.YAML(data)
+			case "json":
+				return output.JSON(data)
 			default:
-				return output.JSON(report)
+				if format != "components" {
+					return output.JSON(data)
+				}
+				printComponents(report.Components)
+				return nil
 			}
 		},
 	}
 
 	cmd.Flags().StringVarP(&outFile, "file", "f", "", "Save report to file")
+	cmd.Flags().StringVar(&format, "format", "raw", "SBOM format (raw|cyclonedx|spdx|components)")
+	cmd.Flags().StringVar(&diffRef, "diff", "", "Compare components with another artifact")
 
 	return cmd
 }
 
+func printComponents(components []*sbom.Component) {
+	if len(components) == 0 {
+		output.Info("No components found")
+		return
+	}
+
+	table := output.Table()
+	table.Append([]string{"NAME", "VERSION", "PURL", "LICENSE"})
+	for _, c := range components {
+		table.Append([]string{
+			c.Name,
+			c.Version,
+			c.PURL,
+			strings.Join(c.Licenses, ", "),
+		})
+	}
+	table.Render()
+
+	fmt.Printf("\n%d components\n", len(components))
+}
+
+func printSBOMDiff(changes []*sbom.Change) {
+	counts := sbom.Counts(changes)
+	if len(changes) == counts[sbom.Unchanged] {
+		output.Info("No differences (%d unchanged components)", counts[sbom.Unchanged])
+		return
+	}
+
+	table := output.Table()
+	table.Append([]string{"STATUS", "NAME", "VERSION", "KEY"})
+	for _, c := range changes {
+		if c.Status == sbom.Unchanged {
+			continue
+		}
+		status := c.Status
+		switch c.Status {
+		case sbom.Added:
+			status = output.Green(status)
+		case sbom.Removed:
+			status = output.Red(status)
+		case sbom.Changed:
+			status = output.Yellow(status)
+		}
+		table.Append([]string{status, c.Name, transition(c.FromVersion, c.ToVersion), c.Key})
+	}
+	table.Render()
+
+	fmt.Printf("\n%d added, %d removed, %d changed, %d unchanged\n",
+		counts[sbom.Added], counts[sbom.Removed], counts[sbom.Changed], counts[sbom.Unchanged])
+}
+
 func newArtifactGetCmd() *cobra.Command {
 	var showTags bool
 	cmd := &cobra.Command{
@@ -512,7 +998,7 @@ func newArtifactGetCmd() *cobra.Command {
 				return err
 			}
 			artSvc := harbor.NewArtifactService(client)
-			artifact, err := artSvc.Get(project, repo, ref)
+			artifact, err := artSvc.Get(cmd.Context(), project, repo, ref)
 			if err != nil {
 				return fmt.Errorf("failed to get artifact: %w", err)
 			}
@@ -1,6 +1,14 @@
 package cmd
 
-import "testing"
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
 
 func TestParseArtifactRef(t *testing.T) {
 	tests := []struct {
@@ -35,3 +43,110 @@ func TestParseArtifactRef(t *testing.T) {
 		}
 	}
 }
+
+func TestArtifactCopyPromote(t *testing.T) {
+	var reqs []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reqs = append(reqs, r.Method+" "+r.URL.Path)
+		switch r.Method + " " + r.URL.Path {
+		case "POST /api/v2.0/projects/prod/repositories/app/artifacts":
+			if r.URL.Query().Get("from") != "dev/app:1.2" {
+				t.Errorf("unexpected source %q", r.URL.Query().Get("from"))
+			}
+			w.Header().Set("Location", "/api/v2.0/projects/prod/repositories/app/artifacts/sha256:abc")
+			w.WriteHeader(http.StatusCreated)
+		case "GET /api/v2.0/projects/prod/repositories/app/artifacts/sha256:abc":
+			w.Write([]byte(`{"digest":"sha256:abc","tags":[{"name":"1.2"}]}`))
+		case "POST /api/v2.0/projects/prod/repositories/app/artifacts/sha256:abc/tags":
+			w.WriteHeader(http.StatusCreated)
+		case "GET /api/v2.0/projects/prod":
+			w.Write([]byte(`{"project_id":3,"name":"prod"}`))
+		case "GET /api/v2.0/labels":
+			if r.URL.Query().Get("scope") == "p" {
+				w.Write([]byte(`[{"id":7,"name":"approved-by-qa"}]`))
+			} else {
+				w.Write([]byte(`[{"id":9,"name":"approved"}]`))
+			}
+		case "POST /api/v2.0/projects/prod/repositories/app/artifacts/sha256:abc/labels":
+			w.WriteHeader(http.StatusOK)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	setupEnv(server.URL)
+	defer os.Unsetenv("HARBOR_URL")
+
+	cmd := newArtifactCopyCmd()
+	cmd.SetContext(context.Background())
+	cmd.Flags().Set("promote", "approved")
+	if err := cmd.RunE(cmd, []string{"dev/app:1.2", "prod/app:stable"}); err != nil {
+		t.Fatalf("run error: %v", err)
+	}
+
+	// The copy only carries the source tag, so the new tag is added
+	var tagged, labelled bool
+	for _, r := range reqs {
+		tagged = tagged || r == "POST /api/v2.0/projects/prod/repositories/app/artifacts/sha256:abc/tags"
+		labelled = labelled || r == "POST /api/v2.0/projects/prod/repositories/app/artifacts/sha256:abc/labels"
+	}
+	if !tagged || !labelled {
+		t.Fatalf("expected tag and label requests, got %v", reqs)
+	}
+}
+
+func TestArtifactCheckRejectsUnscannedArtifact(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v2.0/projects/dev/repositories/app/artifacts/1.0/additions/vulnerabilities" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	setupEnv(server.URL)
+	defer os.Unsetenv("HARBOR_URL")
+
+	policy := filepath.Join(t.TempDir(), "policy.yaml")
+	if err := os.WriteFile(policy, []byte("max_counts:\n  critical: 0\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := newArtifactCheckCmd()
+	cmd.SetContext(context.Background())
+	cmd.Flags().Set("policy", policy)
+	err := cmd.RunE(cmd, []string{"dev/app:1.0"})
+	if err == nil || !strings.Contains(err.Error(), "has not been scanned") {
+		t.Fatalf("expected an unscanned artifact error, got %v", err)
+	}
+}
+
+func TestArtifactVulnDiffRejectsUnscannedArtifact(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v2.0/projects/dev/repositories/app/artifacts/1.0/additions/vulnerabilities":
+			w.Write([]byte(`{}`))
+		case "/api/v2.0/projects/dev/repositories/app/artifacts/1.1/additions/vulnerabilities":
+			w.Write([]byte(`{"severity":"High","vulnerabilities":[{"id":"CVE-1","package":"openssl","severity":"High"}]}`))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	setupEnv(server.URL)
+	defer os.Unsetenv("HARBOR_URL")
+
+	cmd := newArtifactVulnDiffCmd()
+	cmd.SetContext(context.Background())
+	cmd.Flags().Set("severity", "critical")
+	err := cmd.RunE(cmd, []string{"dev/app:1.0", "dev/app:1.1"})
+	if err == nil || !strings.Contains(err.Error(), "dev/app:1.0 has not been scanned") {
+		t.Fatalf("expected an unscanned artifact error, got %v", err)
+	}
+}
@@ -0,0 +1,330 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/manifoldco/promptui"
+	"github.com/spf13/cobra"
+
+	"github.com/pascal71/hrbcli/pkg/api"
+	"github.com/pascal71/hrbcli/pkg/harbor"
+	"github.com/pascal71/hrbcli/pkg/output"
+	"github.com/pascal71/hrbcli/pkg/registry"
+)
+
+func newArtifactPullCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "pull <project>/<repository>[:tag|@digest] <dir|file.tar[.gz]>",
+		Short: "Download an image to an OCI image layout",
+		Long: `Download an image, including all platforms of a multi-platform image,
+into an OCI image layout directory or tarball. No Docker daemon is needed.`,
+		Args: requireArgs(2, "requires <project>/<repository>[:tag|@digest] and a destination"),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			project, repo, ref, err := parseArtifactRef(args[0])
+			if err != nil {
+				return err
+			}
+			dest := args[1]
+
+			client, err := api.NewClient()
+			if err != nil {
+				return err
+			}
+			src := registry.NewClientFromAPI(client).Repository(project+"/"+repo, false)
+
+			dir := dest
+			if registry.IsArchive(dest) {
+				if dir, err = os.MkdirTemp("", "hrbcli-pull-"); err != nil {
+					return err
+				}
+				defer os.RemoveAll(dir)
+			}
+			layout, err := registry.NewLayout(dir)
+			if err != nil {
+				return err
+			}
+
+			desc, err := registry.Copy(cmd.Context(), src, ref, layout, ref, registry.CopyOptions{Progress: transferProgress})
+			if err != nil {
+				return fmt.Errorf("failed to pull %s: %w", args[0], err)
+			}
+			if registry.IsArchive(dest) {
+				if err := registry.WriteArchive(dest, dir); err != nil {
+					return fmt.Errorf("failed to write %s: %w", dest, err)
+				}
+			}
+
+			output.Success("Pulled %s/%s:%s (%s) to %s", project, repo, ref, desc.Digest, dest)
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+func newArtifactPushCmd() *cobra.Command {
+	var image string
+
+	cmd := &cobra.Command{
+		Use:   "push <dir|file.tar[.gz]> <project>/<repository>[:tag]",
+		Short: "Upload an image from an OCI image layout or docker archive",
+		Long: `Upload an image from an OCI image layout, as written by 'artifact pull',
+or from a 'docker save' archive. Directories and tarballs are accepted.
+Use --image to pick an image when the source holds several.`,
+		Args: requireArgs(2, "requires a source and <project>/<repository>[:tag]"),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			project, repo, ref, err := parseArtifactRef(args[1])
+			if err != nil {
+				return err
+			}
+
+			src, cleanup, err := registry.OpenSource(args[0])
+			if err != nil {
+				return err
+			}
+			defer cleanup()
+
+			client, err := api.NewClient()
+			if err != nil {
+				return err
+			}
+			dst := registry.NewClientFromAPI(client).Repository(project+"/"+repo, true)
+
+			desc, err := registry.Copy(cmd.Context(), src, image, dst, ref, registry.CopyOptions{Progress: transferProgress})
+			if err != nil {
+				return fmt.Errorf("failed to push %s: %w", args[0], err)
+			}
+
+			output.Success("Pushed %s/%s:%s (%s)", project, repo, ref, desc.Digest)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&image, "image", "", "Image to push when the source holds several (ref name or repository tag)")
+
+	return cmd
+}
+
+func newArtifactCopyCmd() *cobra.Command {
+	var (
+		toContext string
+		promote   []string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "copy <project>/<repository>[:tag|@digest] <project>/<repository>[:tag]",
+		Short: "Copy an image between repositories",
+		Long: `Copy an image to another repository. The source tag is kept unless the
+destination names one. Within one Harbor instance the copy is made on the
+server. With --to-context the image, including all platforms of a
+multi-platform image, is transferred through the registry API to the Harbor
+instance of another configuration context; blobs the destination already
+has are not transferred again.
+
+Use --promote to label the copy in the same step.`,
+		Example: `  hrbcli artifact copy dev/app:1.2 prod/app
+  hrbcli artifact copy dev/app:1.2 prod/app --promote approved
+  hrbcli artifact copy dev/app:1.2 prod/app:stable --to-context prod`,
+		Args: requireArgs(2, "requires source and destination references"),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			srcProject, srcRepo, srcRef, err := parseArtifactRef(args[0])
+			if err != nil {
+				return err
+			}
+			dstProject, dstRepo, dstRef, err := parseArtifactRef(args[1])
+			if err != nil {
+				return err
+			}
+			// Keep the source tag or digest unless the destination names one
+			if !strings.ContainsAny(strings.SplitN(args[1], "/", 2)[1], ":@") {
+				dstRef = srcRef
+			}
+
+			client, err := api.NewClient()
+			if err != nil {
+				return err
+			}
+			ctx := cmd.Context()
+
+			dstClient := client
+			var digest string
+			if toContext == "" {
+				if digest, err = copyArtifact(ctx, client, srcProject, srcRepo, srcRef, dstProject, dstRepo, dstRef); err != nil {
+					return err
+				}
+			} else {
+				if dstClient, err = api.NewClientForContext(toContext); err != nil {
+					return err
+				}
+				src := registry.NewClientFromAPI(client).Repository(srcProject+"/"+srcRepo, false)
+				dst := registry.NewClientFromAPI(dstClient).Repository(dstProject+"/"+dstRepo, true)
+				desc, err := registry.Copy(ctx, src, srcRef, dst, dstRef, registry.CopyOptions{Progress: transferProgress})
+				if err != nil {
+					return fmt.Errorf("failed to copy %s: %w", args[0], err)
+				}
+				digest = desc.Digest
+			}
+			output.Success("Copied %s to %s/%s:%s (%s)", args[0], dstProject, dstRepo, dstRef, digest)
+
+			if len(promote) == 0 {
+				return nil
+			}
+			project, err := harbor.NewProjectService(dstClient).Get(ctx, dstProject)
+			if err != nil {
+				return fmt.Errorf("failed to get project: %w", err)
+			}
+			labelSvc := harbor.NewLabelService(dstClient)
+			for _, name := range promote {
+				label, err := labelSvc.FindByName(ctx, name, project.ProjectID)
+				if err != nil {
+					return err
+				}
+				if err := labelSvc.AddToArtifact(ctx, dstProject, dstRepo, digest, label.ID); err != nil {
+					return fmt.Errorf("failed to add label %s: %w", name, err)
+				}
+				output.Success("Added label %s to %s/%s@%s", name, dstProject, dstRepo, output.Truncate(digest, 19))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&toContext, "to-context", "", "Copy to the Harbor instance of this context")
+	cmd.Flags().StringSliceVar(&promote, "promote", nil, "Add these labels to the copy")
+
+	return cmd
+}
+
+// copyArtifact copies an artifact on the server and makes sure the copy
+// carries dstRef when it is a tag. It returns the digest of the copy.
+func copyArtifact(ctx context.Context, client *api.Client, srcProject, srcRepo, srcRef, dstProject, dstRepo, dstRef string) (string, error) {
+	artSvc := harbor.NewArtifactService(client)
+
+	digest, err := artSvc.Copy(ctx, dstProject, dstRepo, artifactName(srcProject, srcRepo, srcRef))
+	if err != nil {
+		return "", fmt.Errorf("failed to copy artifact: %w", err)
+	}
+	if digest == "" {
+		src, err := artSvc.Get(ctx, srcProject, srcRepo, srcRef)
+		if err != nil {
+			return "", fmt.Errorf("failed to get artifact: %w", err)
+		}
+		digest = src.Digest
+	}
+	if strings.HasPrefix(dstRef, "sha256:") {
+		return digest, nil
+	}
+
+	art, err := artSvc.GetWithOptions(ctx, dstProject, dstRepo, digest, &api.ArtifactGetOptions{WithTag: true})
+	if err != nil {
+		return "", fmt.Errorf("failed to get copied artifact: %w", err)
+	}
+	for _, t := range art.Tags {
+		if t.Name == dstRef {
+			return digest, nil
+		}
+	}
+	if err := artSvc.CreateTag(ctx, dstProject, dstRepo, digest, dstRef); err != nil {
+		return "", fmt.Errorf("failed to tag copied artifact: %w", err)
+	}
+	return digest, nil
+}
+
+func newArtifactTagCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "tag",
+		Short: "Manage artifact tags",
+	}
+
+	cmd.AddCommand(newArtifactTagAddCmd())
+	cmd.AddCommand(newArtifactTagDeleteCmd())
+
+	return cmd
+}
+
+func newArtifactTagAddCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "add <project>/<repository>[:tag|@digest] <tag>",
+		Short: "Add a tag to an artifact",
+		Args:  requireArgs(2, "requires <project>/<repository>[:tag|@digest] and a tag"),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			project, repo, ref, err := parseArtifactRef(args[0])
+			if err != nil {
+				return err
+			}
+
+			client, err := api.NewClient()
+			if err != nil {
+				return err
+			}
+
+			artSvc := harbor.NewArtifactService(client)
+			if err := artSvc.CreateTag(cmd.Context(), project, repo, ref, args[1]); err != nil {
+				return fmt.Errorf("failed to add tag: %w", err)
+			}
+
+			output.Success("Tagged %s/%s:%s as %s", project, repo, ref, args[1])
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+func newArtifactTagDeleteCmd() *cobra.Command {
+	var force bool
+
+	cmd := &cobra.Command{
+		Use:   "delete <project>/<repository>:<tag>",
+		Short: "Remove a tag from an artifact",
+		Long:  `Remove a tag from an artifact. The artifact itself and its other tags are kept.`,
+		Args:  requireArgs(1, "requires <project>/<repository>:<tag>"),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			project, repo, tag, err := parseArtifactRef(args[0])
+			if err != nil {
+				return err
+			}
+			if strings.HasPrefix(tag, "sha256:") {
+				return fmt.Errorf("requires a tag, not a digest")
+			}
+
+			client, err := api.NewClient()
+			if err != nil {
+				return err
+			}
+
+			if !force {
+				prompt := promptui.Prompt{Label: fmt.Sprintf("Delete tag '%s' of %s/%s", tag, project, repo), IsConfirm: true}
+				result, err := prompt.Run()
+				if err != nil || strings.ToLower(result) != "y" {
+					output.Info("Deletion cancelled")
+					return nil
+				}
+			}
+
+			artSvc := harbor.NewArtifactService(client)
+			if err := artSvc.DeleteTag(cmd.Context(), project, repo, tag, tag); err != nil {
+				return fmt.Errorf("failed to delete tag: %w", err)
+			}
+
+			output.Success("Deleted tag %s of %s/%s", tag, project, repo)
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&force, "force", false, "Delete without confirmation")
+
+	return cmd
+}
+
+// transferProgress reports blob transfers on stderr so that stdout stays
+// clean for scripts
+func transferProgress(desc registry.Descriptor, skipped bool) {
+	status := "transferring"
+	if skipped {
+		status = "exists"
+	}
+	fmt.Fprintf(os.Stderr, "%s: %s (%s)\n", output.Truncate(desc.Digest, 19), status, output.FormatSize(desc.Size))
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/http"
 	"os"
+	"strconv"
 	"strings"
 	"syscall"
 	"time"
@@ -30,6 +31,11 @@ func NewConfigCmd() *cobra.Command {
 	cmd.AddCommand(newConfigSetCmd())
 	cmd.AddCommand(newConfigGetCmd())
 	cmd.AddCommand(newConfigListCmd())
+	cmd.AddCommand(newConfigUseContextCmd())
+	cmd.AddCommand(newConfigGetContextsCmd())
+	cmd.AddCommand(newConfigRenameContextCmd())
+	cmd.AddCommand(newConfigDeleteContextCmd())
+	cmd.AddCommand(newConfigFetchCACmd())
 
 	return cmd
 }
@@ -38,7 +44,11 @@ func newConfigInitCmd() *cobra.Command {
 	return &cobra.Command{
 		Use:   "init",
 		Short: "Initialize configuration interactively",
-		Long:  `Initialize Harbor CLI configuration interactively. This will prompt for Harbor URL, credentials, and other settings.`,
+		Long: `Initialize Harbor CLI configuration interactively. This will prompt for Harbor URL, credentials, and other settings.
+
+When a context is selected with --context, or a current context is set,
+the connection settings are saved to that context and it becomes the
+current context.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			output.Info("Welcome to Harbor CLI configuration!")
 			output.Info("")
@@ -151,7 +161,7 @@ func newConfigInitCmd() *cobra.Command {
 					HTTPClient: httpClient,
 				}
 
-				if err := client.CheckHealth(); err != nil {
+				if err := client.CheckHealth(cmd.Context()); err != nil {
 					output.Error("Connection failed: %v", err)
 				} else {
 					output.Success("Successfully connected to Harbor!")
@@ -159,20 +169,64 @@ func newConfigInitCmd() *cobra.Command {
 			}
 
 			// Save configuration
-			if err := config.Save(cfg); err != nil {
+			if name := config.ActiveContext(); name != "" {
+				err = config.SaveContext(name, &config.Context{
+					HarborURL:  harborURL,
+					Username:   username,
+					APIVersion: apiVersion,
+					Insecure:   insecure,
+				})
+				if err == nil {
+					err = config.UseContext(name)
+				}
+				if err == nil {
+					err = config.Set("output_format", outputFormat)
+				}
+			} else {
+				err = config.Save(cfg)
+			}
+			if err != nil {
 				return fmt.Errorf("failed to save configuration: %w", err)
 			}
 
+			// Keep the password in the credential store rather than the
+			// config file
+			stored := false
+			if password != "" {
+				storePrompt := promptui.Select{
+					Label: "Save password in the credential store",
+					Items: []string{"yes", "no"},
+				}
+				_, storeAnswer, err := storePrompt.Run()
+				if err != nil {
+					return err
+				}
+				if storeAnswer == "yes" {
+					storeCfg, err := config.Load()
+					if err != nil {
+						return err
+					}
+					store, err := loginCredentialStore(storeCfg)
+					if err != nil {
+						return err
+					}
+					if err := store.Store(strings.TrimRight(harborURL, "/"), username, password); err != nil {
+						return fmt.Errorf("failed to store credentials: %w", err)
+					}
+					stored = true
+				}
+			}
+
 			output.Success("Configuration saved to %s", config.GetConfigPath())
 			output.Info("")
 			output.Info("You can now use Harbor CLI!")
 			output.Info("Try 'hrbcli project list' to list your projects.")
 
 			// Save password to environment variable hint
-			if password != "" {
+			if password != "" && !stored {
 				output.Info("")
 				output.Warning(
-					"For security, consider using HARBOR_PASSWORD environment variable instead of storing the password.",
+					"The password was not saved. Use 'hrbcli login' or the HARBOR_PASSWORD environment variable.",
 				)
 			}
 
@@ -191,9 +245,22 @@ func newConfigSetCmd() *cobra.Command {
   - api_version: Harbor API version (v1.0, v2.0)
   - output_format: Default output format (table, json, yaml)
   - insecure: Skip TLS verification (true, false)
+  - ca_cert: PEM file with CA certificates to trust
+  - client_cert: PEM client certificate for mutual TLS
+  - client_key: PEM private key of client_cert
   - default_project: Default project name
   - no_color: Disable colored output (true, false)
-  - debug: Enable debug output (true, false)`,
+  - debug: Enable debug output (true, false)
+  - max_attempts: Maximum attempts per API request (1 disables retries)
+  - retry_wait_min: Initial backoff between retries (e.g. 500ms)
+  - retry_wait_max: Maximum backoff between retries (e.g. 30s)
+  - credentials_store: Where 'hrbcli login' keeps passwords: "file" or a
+    Docker credential helper name (osxkeychain, wincred, secretservice, pass)
+  - credentials_file: Path of the encrypted credentials file
+
+harbor_url, username, api_version, insecure, ca_cert, client_cert,
+client_key and default_project are connection settings. When a context is active they are stored in that
+context, which is created if it does not exist yet.`,
 		Args: requireArgs(2, "requires <key> and <value>"),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			key := args[0]
@@ -210,6 +277,18 @@ func newConfigSetCmd() *cobra.Command {
 				} else {
 					return fmt.Errorf("boolean value must be 'true' or 'false'")
 				}
+			case "max_attempts":
+				n, err := strconv.Atoi(value)
+				if err != nil || n < 1 {
+					return fmt.Errorf("max_attempts must be a positive integer")
+				}
+				typedValue = n
+			case "retry_wait_min", "retry_wait_max":
+				d, err := time.ParseDuration(value)
+				if err != nil || d <= 0 {
+					return fmt.Errorf("%s must be a positive duration (e.g. 500ms, 10s)", key)
+				}
+				typedValue = d
 			default:
 				typedValue = value
 			}
@@ -282,15 +361,24 @@ func listConfig() error {
 	default:
 		output.Info("Harbor CLI Configuration:")
 		output.Info("")
+		if cfg.Context != "" {
+			fmt.Printf("Context:         %s\n", cfg.Context)
+		}
 		fmt.Printf("Harbor URL:      %s\n", cfg.HarborURL)
 		fmt.Printf("Username:        %s\n", cfg.Username)
 		fmt.Printf("Password:        %s\n", cfg.Password)
 		fmt.Printf("API Version:     %s\n", cfg.APIVersion)
 		fmt.Printf("Insecure:        %v\n", cfg.Insecure)
+		fmt.Printf("CA Cert:         %s\n", cfg.CACert)
+		fmt.Printf("Client Cert:     %s\n", cfg.ClientCert)
+		fmt.Printf("Client Key:      %s\n", cfg.ClientKey)
 		fmt.Printf("Output Format:   %s\n", cfg.OutputFormat)
 		fmt.Printf("Default Project: %s\n", cfg.DefaultProject)
 		fmt.Printf("No Color:        %v\n", cfg.NoColor)
 		fmt.Printf("Debug:           %v\n", cfg.Debug)
+		fmt.Printf("Max Attempts:    %d\n", cfg.MaxAttempts)
+		fmt.Printf("Retry Wait:      %s - %s\n", cfg.RetryWaitMin, cfg.RetryWaitMax)
+		fmt.Printf("Credentials:     %s\n", cfg.CredentialsStore)
 		output.Info("")
 		output.Info("Config file: %s", config.GetConfigPath())
 
@@ -0,0 +1,153 @@
+package cmd
+
+import (
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/manifoldco/promptui"
+	"github.com/spf13/cobra"
+
+	"github.com/pascal71/hrbcli/pkg/api"
+	"github.com/pascal71/hrbcli/pkg/config"
+	"github.com/pascal71/hrbcli/pkg/harbor"
+	"github.com/pascal71/hrbcli/pkg/output"
+)
+
+func newConfigFetchCACmd() *cobra.Command {
+	var (
+		file  string
+		force bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "fetch-ca",
+		Short: "Download and trust Harbor's root certificate",
+		Long: `Download the root certificate Harbor was installed with from
+/systeminfo/getcert, save it and set ca_cert so later commands verify the
+server against it instead of using --insecure.
+
+The certificate is fetched without verification, so compare the printed
+fingerprint with the one your Harbor administrator publishes before
+trusting it.`,
+		Example: `  # Trust the CA of the configured Harbor
+  hrbcli config fetch-ca
+
+  # Trust the CA of the staging context and store it in a chosen place
+  hrbcli config fetch-ca --context staging --file ~/certs/staging-ca.crt`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := config.Load()
+			if err != nil {
+				return err
+			}
+			if cfg.HarborURL == "" {
+				return fmt.Errorf("Harbor URL not configured. Run 'hrbcli config init' or set HARBOR_URL")
+			}
+
+			// The CA is not trusted yet, so this request cannot verify it.
+			// getcert needs no login; never send credentials to a server
+			// that has not been verified. The client certificate is kept
+			// for ingresses that require mutual TLS.
+			fetchCfg := *cfg
+			fetchCfg.Insecure = true
+			fetchCfg.CACert = ""
+			fetchCfg.Username = ""
+			fetchCfg.Password = ""
+			client, err := api.NewClientFromConfig(&fetchCfg)
+			if err != nil {
+				return err
+			}
+			data, err := harbor.NewSystemService(client).GetCert(cmd.Context())
+			if err != nil {
+				if apiErr, ok := err.(*api.APIError); ok && apiErr.IsNotFound() {
+					return fmt.Errorf("Harbor has no root certificate to download; ask your administrator for the CA bundle")
+				}
+				return fmt.Errorf("failed to download certificate: %w", err)
+			}
+
+			certs, err := parsePEMCertificates(data)
+			if err != nil {
+				return err
+			}
+			for _, c := range certs {
+				printCertificate(c)
+			}
+
+			if !force {
+				prompt := promptui.Prompt{
+					Label:     "Trust this certificate",
+					IsConfirm: true,
+				}
+				if _, err := prompt.Run(); err != nil {
+					output.Info("Certificate not saved")
+					return nil
+				}
+			}
+
+			if file == "" {
+				file = config.DefaultCACertFile(cfg.HarborURL)
+			}
+			if err := os.MkdirAll(filepath.Dir(file), 0700); err != nil {
+				return fmt.Errorf("failed to create certificate directory: %w", err)
+			}
+			if err := os.WriteFile(file, data, 0644); err != nil {
+				return fmt.Errorf("failed to write certificate: %w", err)
+			}
+			if err := config.Set("ca_cert", file); err != nil {
+				return err
+			}
+
+			output.Success("Saved CA certificate to %s", file)
+			if cfg.Insecure {
+				output.Info("TLS verification can now be enabled with 'hrbcli config set insecure false'")
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&file, "file", "", "Where to save the certificate (default next to the config file)")
+	cmd.Flags().BoolVar(&force, "force", false, "Trust the certificate without confirmation")
+	return cmd
+}
+
+// parsePEMCertificates decodes every certificate in a PEM bundle
+func parsePEMCertificates(data []byte) ([]*x509.Certificate, error) {
+	var certs []*x509.Certificate
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse certificate: %w", err)
+		}
+		certs = append(certs, cert)
+	}
+	if len(certs) == 0 {
+		return nil, fmt.Errorf("response does not contain a PEM certificate")
+	}
+	return certs, nil
+}
+
+func printCertificate(cert *x509.Certificate) {
+	sum := sha256.Sum256(cert.Raw)
+	hexes := make([]string, len(sum))
+	for i, b := range sum {
+		hexes[i] = fmt.Sprintf("%02X", b)
+	}
+
+	fmt.Printf("Subject:     %s\n", cert.Subject)
+	fmt.Printf("Issuer:      %s\n", cert.Issuer)
+	fmt.Printf("Valid until: %s\n", cert.NotAfter.Format("2006-01-02"))
+	fmt.Printf("SHA-256:     %s\n", strings.Join(hexes, ":"))
+	fmt.Println()
+}
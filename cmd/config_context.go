@@ -0,0 +1,133 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/manifoldco/promptui"
+	"github.com/spf13/cobra"
+
+	"github.com/pascal71/hrbcli/pkg/config"
+	"github.com/pascal71/hrbcli/pkg/output"
+)
+
+// contextInfo is the listing form of a context
+type contextInfo struct {
+	Name    string `json:"name" yaml:"name"`
+	Current bool   `json:"current" yaml:"current"`
+
+	config.Context `yaml:",inline"`
+}
+
+func newConfigUseContextCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "use-context <name>",
+		Short: "Set the current context",
+		Long: `Set the context used when --context is not given. Create contexts with
+'hrbcli config set' or 'hrbcli config init' together with --context.`,
+		Example: `  # Create a staging context and switch to it
+  hrbcli config set harbor_url https://harbor.staging.example.com --context staging
+  hrbcli config use-context staging`,
+		Args: requireArgs(1, "requires <name>"),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := config.UseContext(args[0]); err != nil {
+				return err
+			}
+			output.Success("Switched to context '%s'", args[0])
+			return nil
+		},
+	}
+}
+
+func newConfigGetContextsCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "get-contexts",
+		Short: "List contexts",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			f, err := config.LoadFile()
+			if err != nil {
+				return err
+			}
+			current := config.ActiveContext()
+
+			contexts := make([]*contextInfo, 0, len(f.Contexts))
+			for _, name := range f.ContextNames() {
+				contexts = append(contexts, &contextInfo{
+					Name:    name,
+					Current: name == current,
+					Context: *f.Contexts[name],
+				})
+			}
+
+			switch output.GetFormat() {
+			case "json":
+				return output.JSON(contexts)
+			case "yaml":
+				return output.YAML(contexts)
+			default:
+				if len(contexts) == 0 {
+					output.Info("No contexts configured")
+					return nil
+				}
+				table := output.Table()
+				table.Append([]string{"CURRENT", "NAME", "HARBOR URL", "USERNAME", "DEFAULT PROJECT"})
+				for _, c := range contexts {
+					marker := ""
+					if c.Current {
+						marker = "*"
+					}
+					table.Append([]string{marker, c.Name, c.HarborURL, c.Username, c.DefaultProject})
+				}
+				table.Render()
+				return nil
+			}
+		},
+	}
+}
+
+func newConfigRenameContextCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "rename-context <old-name> <new-name>",
+		Short: "Rename a context",
+		Args:  requireArgs(2, "requires <old-name> and <new-name>"),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := config.RenameContext(args[0], args[1]); err != nil {
+				return err
+			}
+			output.Success("Renamed context '%s' to '%s'", args[0], args[1])
+			return nil
+		},
+	}
+}
+
+func newConfigDeleteContextCmd() *cobra.Command {
+	var force bool
+
+	cmd := &cobra.Command{
+		Use:   "delete-context <name>",
+		Short: "Delete a context",
+		Args:  requireArgs(1, "requires <name>"),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := args[0]
+
+			if !force {
+				prompt := promptui.Prompt{
+					Label:     fmt.Sprintf("Delete context '%s'", name),
+					IsConfirm: true,
+				}
+				if _, err := prompt.Run(); err != nil {
+					output.Info("Deletion cancelled")
+					return nil
+				}
+			}
+
+			if err := config.DeleteContext(name); err != nil {
+				return err
+			}
+			output.Success("Deleted context '%s'", name)
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&force, "force", false, "Skip confirmation")
+	return cmd
+}
@@ -36,7 +36,7 @@ func newDistributionProvidersCmd() *cobra.Command {
 				return err
 			}
 			svc := harbor.NewPreheatService(client)
-			providers, err := svc.ListProviders(project)
+			providers, err := svc.ListProviders(cmd.Context(), project)
 			if err != nil {
 				return err
 			}
@@ -67,6 +67,7 @@ func newDistributionProvidersCmd() *cobra.Command {
 func newDistributionPoliciesCmd() *cobra.Command {
 	var page, pageSize int
 	var query, sort string
+	var pages pageFlags
 
 	cmd := &cobra.Command{
 		Use:   "policies <project>",
@@ -80,7 +81,13 @@ func newDistributionPoliciesCmd() *cobra.Command {
 			}
 			svc := harbor.NewPreheatService(client)
 			opts := &api.ListOptions{Page: page, PageSize: pageSize, Query: query, Sort: sort}
-			policies, err := svc.ListPolicies(project, opts)
+			var policies []*api.PreheatPolicy
+			if pages.enabled() {
+				opts.Page, opts.PageSize = 0, 0
+				policies, err = svc.ListAllPolicies(cmd.Context(), project, opts, pages.limit)
+			} else {
+				policies, err = svc.ListPolicies(cmd.Context(), project, opts)
+			}
 			if err != nil {
 				return err
 			}
@@ -109,6 +116,7 @@ func newDistributionPoliciesCmd() *cobra.Command {
 	cmd.Flags().IntVar(&pageSize, "page-size", 0, "Page size")
 	cmd.Flags().StringVar(&query, "query", "", "Query filter")
 	cmd.Flags().StringVar(&sort, "sort", "", "Sort order")
+	pages.register(cmd)
 	return cmd
 }
 
@@ -125,7 +133,7 @@ func newDistributionPolicyGetCmd() *cobra.Command {
 				return err
 			}
 			svc := harbor.NewPreheatService(client)
-			policy, err := svc.GetPolicy(project, name)
+			policy, err := svc.GetPolicy(cmd.Context(), project, name)
 			if err != nil {
 				return err
 			}
@@ -32,15 +32,15 @@ func newJobServiceDashboardCmd() *cobra.Command {
 			}
 			svc := harbor.NewJobService(client)
 
-			pools, err := svc.GetWorkerPools()
+			pools, err := svc.GetWorkerPools(cmd.Context())
 			if err != nil {
 				return err
 			}
-			workers, err := svc.GetWorkers("all")
+			workers, err := svc.GetWorkers(cmd.Context(), "all")
 			if err != nil {
 				return err
 			}
-			queues, err := svc.ListJobQueues()
+			queues, err := svc.ListJobQueues(cmd.Context())
 			if err != nil {
 				return err
 			}
@@ -36,6 +36,7 @@ func newLabelListCmd() *cobra.Command {
 		name      string
 		scope     string
 		projectID int64
+		pages     pageFlags
 	)
 
 	cmd := &cobra.Command{
@@ -57,7 +58,13 @@ func newLabelListCmd() *cobra.Command {
 				ProjectID: projectID,
 			}
 
-			labels, err := labelSvc.List(opts)
+			var labels []*api.Label
+			if pages.enabled() {
+				opts.Page, opts.PageSize = 0, 0
+				labels, err = labelSvc.ListAll(cmd.Context(), opts, pages.limit)
+			} else {
+				labels, err = labelSvc.List(cmd.Context(), opts)
+			}
 			if err != nil {
 				return fmt.Errorf("failed to list labels: %w", err)
 			}
@@ -98,6 +105,7 @@ func newLabelListCmd() *cobra.Command {
 	cmd.Flags().StringVar(&name, "name", "", "Filter by name")
 	cmd.Flags().StringVar(&scope, "scope", "", "Label scope (g or p)")
 	cmd.Flags().Int64Var(&projectID, "project-id", 0, "Project ID for project labels")
+	pages.register(cmd)
 	return cmd
 }
 
@@ -132,7 +140,7 @@ func newLabelCreateCmd() *cobra.Command {
 				label.ProjectID = projectID
 			}
 
-			created, err := labelSvc.Create(label)
+			created, err := labelSvc.Create(cmd.Context(), label)
 			if err != nil {
 				if apiErr, ok := err.(*api.APIError); ok && apiErr.IsConflict() {
 					return fmt.Errorf("label '%s' already exists", name)
@@ -169,7 +177,7 @@ func newLabelGetCmd() *cobra.Command {
 			}
 			labelSvc := harbor.NewLabelService(client)
 
-			label, err := labelSvc.Get(id)
+			label, err := labelSvc.Get(cmd.Context(), id)
 			if err != nil {
 				return fmt.Errorf("failed to get label: %w", err)
 			}
@@ -232,7 +240,7 @@ func newLabelUpdateCmd() *cobra.Command {
 				label.Color = color
 			}
 
-			if err := labelSvc.Update(id, label); err != nil {
+			if err := labelSvc.Update(cmd.Context(), id, label); err != nil {
 				return fmt.Errorf("failed to update label: %w", err)
 			}
 			output.Success("Label %d updated", id)
@@ -276,7 +284,7 @@ func newLabelDeleteCmd() *cobra.Command {
 				}
 			}
 
-			if err := labelSvc.Delete(id); err != nil {
+			if err := labelSvc.Delete(cmd.Context(), id); err != nil {
 				return fmt.Errorf("failed to delete label: %w", err)
 			}
 			output.Success("Label %d deleted", id)
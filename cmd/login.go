@@ -0,0 +1,211 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/manifoldco/promptui"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+
+	"github.com/pascal71/hrbcli/pkg/api"
+	"github.com/pascal71/hrbcli/pkg/config"
+	"github.com/pascal71/hrbcli/pkg/harbor"
+	"github.com/pascal71/hrbcli/pkg/output"
+)
+
+// NewLoginCmd creates the login command
+func NewLoginCmd() *cobra.Command {
+	var passwordStdin bool
+
+	cmd := &cobra.Command{
+		Use:   "login [server]",
+		Short: "Log in to Harbor and store the credentials",
+		Long: `Verify credentials against Harbor and save them in the credential store,
+so that later commands no longer need --password or HARBOR_PASSWORD.
+
+The store is selected with the credentials_store setting: "file" keeps
+credentials in a local file encrypted with a passphrase (asked for, or
+taken from HARBOR_CREDENTIALS_PASSPHRASE), any other value names a Docker
+credential helper such as "osxkeychain", "wincred", "secretservice" or
+"pass". When no store is configured the encrypted file is used.
+
+The server defaults to the Harbor URL of the active context.`,
+		Example: `  # Log in to the configured Harbor
+  hrbcli login --username admin
+
+  # Log in with a robot token from a pipe
+  echo "$TOKEN" | hrbcli login https://harbor.example.com --username 'robot$ci' --password-stdin
+
+  # Use the macOS keychain
+  hrbcli config set credentials_store osxkeychain
+  hrbcli login`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := config.Load()
+			if err != nil {
+				return err
+			}
+			if len(args) == 1 {
+				cfg.HarborURL = args[0]
+			}
+			if cfg.HarborURL == "" {
+				return fmt.Errorf("Harbor URL not configured. Pass a server or run 'hrbcli config init'")
+			}
+			server := strings.TrimRight(cfg.HarborURL, "/")
+
+			if cfg.Username == "" {
+				prompt := promptui.Prompt{Label: "Username"}
+				if cfg.Username, err = prompt.Run(); err != nil {
+					return err
+				}
+			}
+
+			switch {
+			case passwordStdin:
+				if cfg.Password, err = readPasswordStdin(); err != nil {
+					return err
+				}
+			case cfg.Password == "":
+				if cfg.Password, err = readPassword("Password: "); err != nil {
+					return err
+				}
+			}
+			if cfg.Password == "" {
+				return fmt.Errorf("password is required")
+			}
+
+			client, err := api.NewClientFromConfig(cfg)
+			if err != nil {
+				return err
+			}
+			if err := verifyLogin(cmd, client, cfg.Username); err != nil {
+				return fmt.Errorf("login failed: %w", err)
+			}
+
+			store, err := loginCredentialStore(cfg)
+			if err != nil {
+				return err
+			}
+			if err := store.Store(server, cfg.Username, cfg.Password); err != nil {
+				return fmt.Errorf("failed to store credentials: %w", err)
+			}
+
+			output.Success("Login succeeded for %s as %s", server, cfg.Username)
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&passwordStdin, "password-stdin", false, "Read the password or token from stdin")
+	return cmd
+}
+
+// NewLogoutCmd creates the logout command
+func NewLogoutCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "logout [server]",
+		Short: "Remove stored credentials",
+		Long:  `Remove the credentials stored by 'hrbcli login' for a server. The server defaults to the Harbor URL of the active context.`,
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := config.Load()
+			if err != nil {
+				return err
+			}
+			if len(args) == 1 {
+				cfg.HarborURL = args[0]
+			}
+			if cfg.HarborURL == "" {
+				return fmt.Errorf("Harbor URL not configured. Pass a server to log out from")
+			}
+			server := strings.TrimRight(cfg.HarborURL, "/")
+
+			store := config.NewCredentialStore(cfg)
+			if store == nil {
+				output.Info("No credential store configured")
+				return nil
+			}
+			if err := store.Erase(server); err != nil {
+				if errors.Is(err, config.ErrCredentialsNotFound) {
+					output.Info("Not logged in to %s", server)
+					return nil
+				}
+				return fmt.Errorf("failed to remove credentials: %w", err)
+			}
+
+			output.Success("Removed credentials for %s", server)
+			return nil
+		},
+	}
+	return cmd
+}
+
+// verifyLogin checks the credentials with an authenticated request.
+// Robot accounts cannot read /users/current, so they list projects.
+func verifyLogin(cmd *cobra.Command, client *api.Client, username string) error {
+	if strings.Contains(username, "$") {
+		_, err := harbor.NewProjectService(client).List(cmd.Context(), &api.ListOptions{PageSize: 1})
+		return err
+	}
+	_, err := harbor.NewUserService(client).Current(cmd.Context())
+	return err
+}
+
+// loginCredentialStore returns the configured credential store, switching
+// to the encrypted file when none is configured yet
+func loginCredentialStore(cfg *config.Config) (config.CredentialStore, error) {
+	if cfg.CredentialsStore == "" {
+		if err := config.Set("credentials_store", config.CredentialsStoreFile); err != nil {
+			return nil, err
+		}
+		cfg.CredentialsStore = config.CredentialsStoreFile
+		output.Info("Storing credentials in encrypted file %s", config.DefaultCredentialsFile())
+	}
+	return config.NewCredentialStore(cfg), nil
+}
+
+// readPassword reads a secret from the terminal without echoing it
+func readPassword(label string) (string, error) {
+	fmt.Fprint(os.Stderr, label)
+	b, err := term.ReadPassword(int(os.Stdin.Fd()))
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// readPasswordStdin reads a secret piped on stdin
+func readPasswordStdin() (string, error) {
+	b, err := io.ReadAll(bufio.NewReader(os.Stdin))
+	if err != nil {
+		return "", fmt.Errorf("failed to read secret from stdin: %w", err)
+	}
+	return strings.TrimRight(string(b), "\r\n"), nil
+}
+
+// promptPassphrase asks for the credentials file passphrase on the
+// terminal, twice when a new file is created
+func promptPassphrase(confirm bool) (string, error) {
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return "", fmt.Errorf("set %s to unlock the credentials file", config.PassphraseEnv)
+	}
+	passphrase, err := readPassword("Credentials passphrase: ")
+	if err != nil {
+		return "", err
+	}
+	if confirm {
+		again, err := readPassword("Repeat passphrase: ")
+		if err != nil {
+			return "", err
+		}
+		if again != passphrase {
+			return "", fmt.Errorf("passphrases do not match")
+		}
+	}
+	return passphrase, nil
+}
@@ -0,0 +1,21 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// pageFlags holds the --all and --limit flags shared by list commands
+type pageFlags struct {
+	all   bool
+	limit int
+}
+
+func (f *pageFlags) register(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&f.all, "all", false, "Fetch all pages (ignores --page and --page-size)")
+	cmd.Flags().IntVar(&f.limit, "limit", 0, "Maximum number of results to fetch across pages (implies --all)")
+}
+
+// enabled reports whether results should be collected across all pages
+func (f *pageFlags) enabled() bool {
+	return f.all || f.limit > 0
+}
@@ -29,6 +29,10 @@ func NewProjectCmd() *cobra.Command {
 	cmd.AddCommand(newProjectUpdateCmd())
 	cmd.AddCommand(newProjectDeleteCmd())
 	cmd.AddCommand(newProjectExistsCmd())
+	cmd.AddCommand(newProjectMemberCmd())
+	cmd.AddCommand(newProjectRetentionCmd())
+	cmd.AddCommand(newProjectImmutableCmd())
+	cmd.AddCommand(newProjectScannerCmd())
 
 	return cmd
 }
@@ -40,6 +44,7 @@ func newProjectListCmd() *cobra.Command {
 		query    string
 		sort     string
 		detail   bool
+		pages    pageFlags
 	)
 
 	cmd := &cobra.Command{
@@ -56,7 +61,10 @@ func newProjectListCmd() *cobra.Command {
   hrbcli project list --query "name=~prod"
 
   # List with pagination
-  hrbcli project list --page 2 --page-size 20`,
+  hrbcli project list --page 2 --page-size 20
+
+  # List every project
+  hrbcli project list --all`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			client, err := api.NewClient()
 			if err != nil {
@@ -72,7 +80,13 @@ func newProjectListCmd() *cobra.Command {
 				Sort:     sort,
 			}
 
-			projects, err := projectSvc.List(opts)
+			var projects []*api.Project
+			if pages.enabled() {
+				opts.Page, opts.PageSize = 0, 0
+				projects, err = projectSvc.ListAll(cmd.Context(), opts, pages.limit)
+			} else {
+				projects, err = projectSvc.List(cmd.Context(), opts)
+			}
 			if err != nil {
 				return fmt.Errorf("failed to list projects: %w", err)
 			}
@@ -107,7 +121,7 @@ func newProjectListCmd() *cobra.Command {
 
 					if detail {
 						// Get project summary for quota info
-						summary, err := projectSvc.GetSummary(p.Name)
+						summary, err := projectSvc.GetSummary(cmd.Context(), p.Name)
 						if err == nil && summary.Quota != nil {
 							storageLimit := harbor.FormatStorageSize(summary.Quota.Hard.Storage)
 							storageUsed := harbor.FormatStorageSize(summary.Quota.Used.Storage)
@@ -132,6 +146,7 @@ func newProjectListCmd() *cobra.Command {
 	cmd.Flags().StringVar(&query, "query", "", "Query string (e.g., 'name=~prod')")
 	cmd.Flags().StringVar(&sort, "sort", "", "Sort by field")
 	cmd.Flags().BoolVar(&detail, "detail", false, "Show detailed information")
+	pages.register(cmd)
 
 	return cmd
 }
@@ -184,7 +199,7 @@ func newProjectCreateCmd() *cobra.Command {
 			projectSvc := harbor.NewProjectService(client)
 
 			// Check if project already exists
-			exists, err := projectSvc.Exists(projectName)
+			exists, err := projectSvc.Exists(cmd.Context(), projectName)
 			if err != nil {
 				return fmt.Errorf("failed to check project existence: %w", err)
 			}
@@ -227,7 +242,7 @@ func newProjectCreateCmd() *cobra.Command {
 				// Look up registry by name if provided
 				if registryName != "" && registryID == 0 {
 					registrySvc := harbor.NewRegistryService(client)
-					registries, err := registrySvc.List(nil)
+					registries, err := registrySvc.ListAll(cmd.Context(), nil, 0)
 					if err != nil {
 						return fmt.Errorf("failed to list registries: %w", err)
 					}
@@ -259,7 +274,7 @@ func newProjectCreateCmd() *cobra.Command {
 			}
 
 			// Create project
-			if err := projectSvc.Create(req); err != nil {
+			if err := projectSvc.Create(cmd.Context(), req); err != nil {
 				return fmt.Errorf("failed to create project: %w", err)
 			}
 
@@ -326,13 +341,13 @@ func newProjectGetCmd() *cobra.Command {
 
 			projectSvc := harbor.NewProjectService(client)
 
-			project, err := projectSvc.Get(projectName)
+			project, err := projectSvc.Get(cmd.Context(), projectName)
 			if err != nil {
 				return fmt.Errorf("failed to get project: %w", err)
 			}
 
 			// Get project summary for additional details
-			summary, err := projectSvc.GetSummary(projectName)
+			summary, err := projectSvc.GetSummary(cmd.Context(), projectName)
 			if err != nil {
 				output.Warning("Failed to get project summary: %v", err)
 			}
@@ -451,7 +466,7 @@ func newProjectUpdateCmd() *cobra.Command {
 			projectSvc := harbor.NewProjectService(client)
 
 			// Get current project to preserve existing settings
-			project, err := projectSvc.Get(projectName)
+			project, err := projectSvc.Get(cmd.Context(), projectName)
 			if err != nil {
 				return fmt.Errorf("failed to get project: %w", err)
 			}
@@ -522,7 +537,7 @@ func newProjectUpdateCmd() *cobra.Command {
 			}
 
 			// Update project
-			if err := projectSvc.Update(project.Name, req); err != nil {
+			if err := projectSvc.Update(cmd.Context(), project.Name, req); err != nil {
 				return fmt.Errorf("failed to update project: %w", err)
 			}
 
@@ -593,7 +608,7 @@ func newProjectDeleteCmd() *cobra.Command {
 			projectSvc := harbor.NewProjectService(client)
 
 			// Get project details first
-			project, err := projectSvc.Get(projectName)
+			project, err := projectSvc.Get(cmd.Context(), projectName)
 			if err != nil {
 				return fmt.Errorf("failed to get project: %w", err)
 			}
@@ -621,7 +636,7 @@ func newProjectDeleteCmd() *cobra.Command {
 			}
 
 			// Delete project
-			if err := projectSvc.Delete(projectName); err != nil {
+			if err := projectSvc.Delete(cmd.Context(), projectName); err != nil {
 				return fmt.Errorf("failed to delete project: %w", err)
 			}
 
@@ -651,7 +666,7 @@ func newProjectExistsCmd() *cobra.Command {
 
 			projectSvc := harbor.NewProjectService(client)
 
-			exists, err := projectSvc.Exists(projectName)
+			exists, err := projectSvc.Exists(cmd.Context(), projectName)
 			if err != nil {
 				return fmt.Errorf("failed to check project existence: %w", err)
 			}
@@ -0,0 +1,295 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/manifoldco/promptui"
+	"github.com/spf13/cobra"
+
+	"github.com/pascal71/hrbcli/pkg/api"
+	"github.com/pascal71/hrbcli/pkg/harbor"
+	"github.com/pascal71/hrbcli/pkg/output"
+)
+
+func newProjectImmutableCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "immutable",
+		Short: "Manage project immutable tag rules",
+		Long: `Manage rules that make tags immutable. An immutable tag cannot be
+overwritten or deleted.
+
+Repository and tag patterns use doublestar syntax, for example "**",
+"release-*", "{v1,v2}.*" or "team-a/**".`,
+	}
+
+	cmd.AddCommand(newProjectImmutableListCmd())
+	cmd.AddCommand(newProjectImmutableCreateCmd())
+	cmd.AddCommand(newProjectImmutableUpdateCmd())
+	cmd.AddCommand(newProjectImmutableToggleCmd("enable", "Enable immutable tag rule"))
+	cmd.AddCommand(newProjectImmutableToggleCmd("disable", "Disable immutable tag rule"))
+	cmd.AddCommand(newProjectImmutableDeleteCmd())
+
+	return cmd
+}
+
+func parseImmutableRuleID(s string) (int64, error) {
+	id, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid rule ID: %s", s)
+	}
+	return id, nil
+}
+
+// immutableFlags holds the selector flags shared by create and update
+type immutableFlags struct {
+	repos        string
+	excludeRepos bool
+	tags         string
+	excludeTags  bool
+}
+
+func (f *immutableFlags) register(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&f.repos, "repos", "**", "Repository pattern (doublestar)")
+	cmd.Flags().BoolVar(&f.excludeRepos, "exclude-repos", false, "Apply the rule to repositories NOT matching --repos")
+	cmd.Flags().StringVar(&f.tags, "tags", "**", "Tag pattern (doublestar)")
+	cmd.Flags().BoolVar(&f.excludeTags, "exclude-tags", false, "Apply the rule to tags NOT matching --tags")
+}
+
+func newProjectImmutableListCmd() *cobra.Command {
+	var (
+		page     int
+		pageSize int
+		pages    pageFlags
+	)
+
+	cmd := &cobra.Command{
+		Use:   "list <project>",
+		Short: "List immutable tag rules",
+		Args:  requireArgs(1, "requires <project>"),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := api.NewClient()
+			if err != nil {
+				return err
+			}
+			svc := harbor.NewImmutableRuleService(client)
+			opts := &api.ListOptions{Page: page, PageSize: pageSize}
+			var rules []*api.ImmutableRule
+			if pages.enabled() {
+				opts.Page, opts.PageSize = 0, 0
+				rules, err = svc.ListAll(cmd.Context(), args[0], opts, pages.limit)
+			} else {
+				rules, err = svc.List(cmd.Context(), args[0], opts)
+			}
+			if err != nil {
+				return fmt.Errorf("failed to list immutable rules: %w", err)
+			}
+
+			if len(rules) == 0 {
+				output.Info("No immutable tag rules found")
+				return nil
+			}
+
+			switch output.GetFormat() {
+			case "json":
+				return output.JSON(rules)
+			case "yaml":
+				return output.YAML(rules)
+			default:
+				table := output.Table()
+				table.Append([]string{"ID", "ENABLED", "REPOSITORIES", "TAGS"})
+				for _, r := range rules {
+					repos, tags := harbor.DescribeImmutableSelectors(r)
+					table.Append([]string{
+						strconv.FormatInt(r.ID, 10),
+						strconv.FormatBool(!r.Disabled),
+						repos,
+						tags,
+					})
+				}
+				table.Render()
+				return nil
+			}
+		},
+	}
+	cmd.Flags().IntVar(&page, "page", 1, "Page number")
+	cmd.Flags().IntVar(&pageSize, "page-size", 100, "Page size")
+	pages.register(cmd)
+	return cmd
+}
+
+func newProjectImmutableCreateCmd() *cobra.Command {
+	flags := &immutableFlags{}
+	var disabled bool
+
+	cmd := &cobra.Command{
+		Use:   "create <project>",
+		Short: "Create immutable tag rule",
+		Example: `  # Make all release tags immutable in every repository
+  hrbcli project immutable create myproject --tags "release-*"
+
+  # Protect everything except dev tags in the team-a repositories
+  hrbcli project immutable create myproject --repos "team-a/**" --tags "dev-*" --exclude-tags`,
+		Args: requireArgs(1, "requires <project>"),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rule, err := harbor.NewImmutableRule(flags.repos, flags.excludeRepos, flags.tags, flags.excludeTags)
+			if err != nil {
+				return err
+			}
+			rule.Disabled = disabled
+
+			client, err := api.NewClient()
+			if err != nil {
+				return err
+			}
+			id, err := harbor.NewImmutableRuleService(client).Create(cmd.Context(), args[0], rule)
+			if err != nil {
+				if apiErr, ok := err.(*api.APIError); ok && apiErr.IsConflict() {
+					return fmt.Errorf("an identical immutable rule already exists")
+				}
+				return fmt.Errorf("failed to create immutable rule: %w", err)
+			}
+			output.Success("Created immutable tag rule %d", id)
+			return nil
+		},
+	}
+
+	flags.register(cmd)
+	cmd.Flags().BoolVar(&disabled, "disabled", false, "Create the rule disabled")
+	return cmd
+}
+
+func newProjectImmutableUpdateCmd() *cobra.Command {
+	flags := &immutableFlags{}
+
+	cmd := &cobra.Command{
+		Use:   "update <project> <rule-id>",
+		Short: "Update immutable tag rule",
+		Args:  requireArgs(2, "requires <project> and <rule-id>"),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			project := args[0]
+			id, err := parseImmutableRuleID(args[1])
+			if err != nil {
+				return err
+			}
+
+			client, err := api.NewClient()
+			if err != nil {
+				return err
+			}
+			svc := harbor.NewImmutableRuleService(client)
+			existing, err := svc.Get(cmd.Context(), project, id)
+			if err != nil {
+				return err
+			}
+
+			// Start from the current selectors so unset flags are kept
+			repos, excludeRepos := flags.repos, flags.excludeRepos
+			if sel := existing.ScopeSelectors["repository"]; len(sel) > 0 {
+				if !cmd.Flags().Changed("repos") {
+					repos = sel[0].Pattern
+				}
+				if !cmd.Flags().Changed("exclude-repos") {
+					excludeRepos = sel[0].Decoration == "repoExcludes"
+				}
+			}
+			tags, excludeTags := flags.tags, flags.excludeTags
+			if len(existing.TagSelectors) > 0 {
+				if !cmd.Flags().Changed("tags") {
+					tags = existing.TagSelectors[0].Pattern
+				}
+				if !cmd.Flags().Changed("exclude-tags") {
+					excludeTags = existing.TagSelectors[0].Decoration == "excludes"
+				}
+			}
+
+			rule, err := harbor.NewImmutableRule(repos, excludeRepos, tags, excludeTags)
+			if err != nil {
+				return err
+			}
+			rule.ID = id
+			rule.Priority = existing.Priority
+			rule.Disabled = existing.Disabled
+
+			if err := svc.Update(cmd.Context(), project, id, rule); err != nil {
+				return fmt.Errorf("failed to update immutable rule: %w", err)
+			}
+			output.Success("Updated immutable tag rule %d", id)
+			return nil
+		},
+	}
+
+	flags.register(cmd)
+	return cmd
+}
+
+func newProjectImmutableToggleCmd(action, short string) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   action + " <project> <rule-id>",
+		Short: short,
+		Args:  requireArgs(2, "requires <project> and <rule-id>"),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id, err := parseImmutableRuleID(args[1])
+			if err != nil {
+				return err
+			}
+
+			client, err := api.NewClient()
+			if err != nil {
+				return err
+			}
+			svc := harbor.NewImmutableRuleService(client)
+			if action == "enable" {
+				err = svc.Enable(cmd.Context(), args[0], id)
+			} else {
+				err = svc.Disable(cmd.Context(), args[0], id)
+			}
+			if err != nil {
+				return fmt.Errorf("failed to %s immutable rule: %w", action, err)
+			}
+			output.Success("Immutable tag rule %d %sd", id, action)
+			return nil
+		},
+	}
+	return cmd
+}
+
+func newProjectImmutableDeleteCmd() *cobra.Command {
+	var force bool
+
+	cmd := &cobra.Command{
+		Use:   "delete <project> <rule-id>",
+		Short: "Delete immutable tag rule",
+		Args:  requireArgs(2, "requires <project> and <rule-id>"),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id, err := parseImmutableRuleID(args[1])
+			if err != nil {
+				return err
+			}
+
+			if !force {
+				prompt := promptui.Prompt{
+					Label:     fmt.Sprintf("Delete immutable tag rule %d of project '%s'", id, args[0]),
+					IsConfirm: true,
+				}
+				if _, err := prompt.Run(); err != nil {
+					output.Info("Deletion cancelled")
+					return nil
+				}
+			}
+
+			client, err := api.NewClient()
+			if err != nil {
+				return err
+			}
+			if err := harbor.NewImmutableRuleService(client).Delete(cmd.Context(), args[0], id); err != nil {
+				return fmt.Errorf("failed to delete immutable rule: %w", err)
+			}
+			output.Success("Deleted immutable tag rule %d", id)
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&force, "force", false, "Skip confirmation")
+	return cmd
+}
@@ -0,0 +1,286 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/manifoldco/promptui"
+	"github.com/spf13/cobra"
+
+	"github.com/pascal71/hrbcli/pkg/api"
+	"github.com/pascal71/hrbcli/pkg/harbor"
+	"github.com/pascal71/hrbcli/pkg/output"
+)
+
+func newProjectMemberCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "member",
+		Aliases: []string{"members"},
+		Short:   "Manage project members",
+		Long: `Manage users and groups that have access to a project.
+
+Roles: projectAdmin, maintainer, developer, guest, limitedGuest.`,
+	}
+
+	cmd.AddCommand(newProjectMemberListCmd())
+	cmd.AddCommand(newProjectMemberAddCmd())
+	cmd.AddCommand(newProjectMemberUpdateCmd())
+	cmd.AddCommand(newProjectMemberRemoveCmd())
+
+	return cmd
+}
+
+func newProjectMemberListCmd() *cobra.Command {
+	var (
+		page     int
+		pageSize int
+		name     string
+		pages    pageFlags
+	)
+
+	cmd := &cobra.Command{
+		Use:   "list <project>",
+		Short: "List project members",
+		Args:  requireArgs(1, "requires <project>"),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			project := args[0]
+
+			client, err := api.NewClient()
+			if err != nil {
+				return err
+			}
+			memberSvc := harbor.NewMemberService(client)
+
+			opts := &api.MemberListOptions{
+				Page:       page,
+				PageSize:   pageSize,
+				EntityName: name,
+			}
+			var members []*api.ProjectMemberEntity
+			if pages.enabled() {
+				opts.Page, opts.PageSize = 0, 0
+				members, err = memberSvc.ListAll(cmd.Context(), project, opts, pages.limit)
+			} else {
+				members, err = memberSvc.List(cmd.Context(), project, opts)
+			}
+			if err != nil {
+				return fmt.Errorf("failed to list members: %w", err)
+			}
+
+			if len(members) == 0 {
+				output.Info("No members found")
+				return nil
+			}
+
+			switch output.GetFormat() {
+			case "json":
+				return output.JSON(members)
+			case "yaml":
+				return output.YAML(members)
+			default:
+				table := output.Table()
+				table.Append([]string{"ID", "NAME", "TYPE", "ROLE"})
+				for _, m := range members {
+					entityType := "user"
+					if m.EntityType == api.MemberEntityGroup {
+						entityType = "group"
+					}
+					table.Append([]string{
+						strconv.FormatInt(m.ID, 10),
+						m.EntityName,
+						entityType,
+						harbor.MemberRoleName(m.RoleID),
+					})
+				}
+				table.Render()
+				return nil
+			}
+		},
+	}
+
+	cmd.Flags().IntVar(&page, "page", 1, "Page number")
+	cmd.Flags().IntVar(&pageSize, "page-size", 20, "Page size")
+	cmd.Flags().StringVar(&name, "name", "", "Filter by user or group name")
+	pages.register(cmd)
+	return cmd
+}
+
+func newProjectMemberAddCmd() *cobra.Command {
+	var (
+		role      string
+		group     bool
+		groupType string
+		ldapDN    string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "add <project> <username|group>",
+		Short: "Add a user or group to a project",
+		Example: `  # Add a user as developer
+  hrbcli project member add myproject john --role developer
+
+  # Add an OIDC group as guest
+  hrbcli project member add myproject qa-team --group --group-type oidc --role guest
+
+  # Add an LDAP group as maintainer
+  hrbcli project member add myproject admins --group --ldap-dn "cn=admins,ou=groups,dc=example,dc=com" --role maintainer`,
+		Args: requireArgs(2, "requires <project> and <username|group>"),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			project, name := args[0], args[1]
+
+			roleID, err := harbor.ParseMemberRole(role)
+			if err != nil {
+				return err
+			}
+
+			client, err := api.NewClient()
+			if err != nil {
+				return err
+			}
+			memberSvc := harbor.NewMemberService(client)
+
+			req := &api.ProjectMember{RoleID: roleID}
+			if group {
+				ug, err := memberGroup(name, groupType, ldapDN)
+				if err != nil {
+					return err
+				}
+				req.MemberGroup = ug
+			} else {
+				userSvc := harbor.NewUserService(client)
+				user, err := userSvc.GetByUsername(cmd.Context(), name)
+				if err != nil {
+					return fmt.Errorf("failed to find user: %w", err)
+				}
+				req.MemberUser = &api.UserEntity{UserID: user.UserID, Username: user.Username}
+			}
+
+			if _, err := memberSvc.Add(cmd.Context(), project, req); err != nil {
+				if apiErr, ok := err.(*api.APIError); ok && apiErr.IsConflict() {
+					return fmt.Errorf("'%s' is already a member of project '%s'", name, project)
+				}
+				return fmt.Errorf("failed to add member: %w", err)
+			}
+
+			output.Success("Added '%s' to project '%s' as %s", name, project, harbor.MemberRoleName(roleID))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&role, "role", "developer", "Member role (projectAdmin|maintainer|developer|guest|limitedGuest)")
+	cmd.Flags().BoolVar(&group, "group", false, "Add a group instead of a user")
+	cmd.Flags().StringVar(&groupType, "group-type", "ldap", "Group type (ldap|http|oidc)")
+	cmd.Flags().StringVar(&ldapDN, "ldap-dn", "", "LDAP group DN (required for LDAP groups)")
+	return cmd
+}
+
+func newProjectMemberUpdateCmd() *cobra.Command {
+	var role string
+
+	cmd := &cobra.Command{
+		Use:   "update-role <project> <name>",
+		Short: "Change the role of a project member",
+		Example: `  # Promote a user to maintainer
+  hrbcli project member update-role myproject john --role maintainer`,
+		Args: requireArgs(2, "requires <project> and <name>"),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			project, name := args[0], args[1]
+
+			if role == "" {
+				return fmt.Errorf("--role is required")
+			}
+			roleID, err := harbor.ParseMemberRole(role)
+			if err != nil {
+				return err
+			}
+
+			client, err := api.NewClient()
+			if err != nil {
+				return err
+			}
+			memberSvc := harbor.NewMemberService(client)
+
+			member, err := memberSvc.FindByName(cmd.Context(), project, name)
+			if err != nil {
+				return err
+			}
+
+			if err := memberSvc.UpdateRole(cmd.Context(), project, member.ID, roleID); err != nil {
+				return fmt.Errorf("failed to update member role: %w", err)
+			}
+
+			output.Success("Role of '%s' in project '%s' set to %s", name, project, harbor.MemberRoleName(roleID))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&role, "role", "", "New role (projectAdmin|maintainer|developer|guest|limitedGuest)")
+	return cmd
+}
+
+func newProjectMemberRemoveCmd() *cobra.Command {
+	var force bool
+
+	cmd := &cobra.Command{
+		Use:   "remove <project> <name>",
+		Short: "Remove a user or group from a project",
+		Args:  requireArgs(2, "requires <project> and <name>"),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			project, name := args[0], args[1]
+
+			client, err := api.NewClient()
+			if err != nil {
+				return err
+			}
+			memberSvc := harbor.NewMemberService(client)
+
+			member, err := memberSvc.FindByName(cmd.Context(), project, name)
+			if err != nil {
+				return err
+			}
+
+			if !force {
+				prompt := promptui.Prompt{
+					Label:     fmt.Sprintf("Remove '%s' from project '%s'", name, project),
+					IsConfirm: true,
+				}
+				result, err := prompt.Run()
+				if err != nil || strings.ToLower(result) != "y" {
+					output.Info("Removal cancelled")
+					return nil
+				}
+			}
+
+			if err := memberSvc.Remove(cmd.Context(), project, member.ID); err != nil {
+				return fmt.Errorf("failed to remove member: %w", err)
+			}
+
+			output.Success("Removed '%s' from project '%s'", name, project)
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&force, "force", false, "Remove without confirmation")
+	return cmd
+}
+
+// memberGroup builds the group reference used when adding a group member
+func memberGroup(name, groupType, ldapDN string) (*api.UserGroup, error) {
+	ug := &api.UserGroup{GroupName: name}
+	switch strings.ToLower(groupType) {
+	case "ldap":
+		if ldapDN == "" {
+			return nil, fmt.Errorf("--ldap-dn is required for LDAP groups")
+		}
+		ug.GroupType = api.GroupTypeLDAP
+		ug.LdapGroupDN = ldapDN
+	case "http":
+		ug.GroupType = api.GroupTypeHTTP
+	case "oidc":
+		ug.GroupType = api.GroupTypeOIDC
+	default:
+		return nil, fmt.Errorf("invalid group type: %s (valid: ldap, http, oidc)", groupType)
+	}
+	return ug, nil
+}
@@ -0,0 +1,27 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/pascal71/hrbcli/pkg/api"
+)
+
+func TestMemberGroup(t *testing.T) {
+	dn := "cn=admins,ou=groups,dc=example,dc=com"
+	ug, err := memberGroup("admins", "ldap", dn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ug.GroupType != api.GroupTypeLDAP || ug.LdapGroupDN != dn || ug.GroupName != "admins" {
+		t.Fatalf("unexpected group %+v", ug)
+	}
+
+	if _, err := memberGroup("admins", "LDAP", ""); err == nil {
+		t.Fatal("expected an error for an LDAP group without DN")
+	}
+
+	ug, err = memberGroup("qa-team", "oidc", "")
+	if err != nil || ug.GroupType != api.GroupTypeOIDC || ug.LdapGroupDN != "" {
+		t.Fatalf("unexpected OIDC group %+v, %v", ug, err)
+	}
+}
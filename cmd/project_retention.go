@@ -0,0 +1,667 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/manifoldco/promptui"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"github.com/pascal71/hrbcli/pkg/api"
+	"github.com/pascal71/hrbcli/pkg/harbor"
+	"github.com/pascal71/hrbcli/pkg/output"
+)
+
+func newProjectRetentionCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "retention",
+		Short: "Manage project tag retention policy",
+		Long: `Manage the tag retention policy of a project.
+
+Rules are combined with "or": an artifact is kept when any rule retains it
+and removed otherwise. Templates: latestPushedK, latestPulledN,
+nDaysSinceLastPush, nDaysSinceLastPull, always.
+
+Rules can be given as flags (single rule) or as a YAML file:
+
+  schedule: "0 0 0 * * *"
+  rules:
+    - template: latestPushedK
+      count: 10
+      repositories: "**"
+      tags: "release-*"
+    - template: nDaysSinceLastPull
+      count: 30
+      untagged: true`,
+	}
+
+	cmd.AddCommand(newProjectRetentionGetCmd())
+	cmd.AddCommand(newProjectRetentionCreateCmd())
+	cmd.AddCommand(newProjectRetentionUpdateCmd())
+	cmd.AddCommand(newProjectRetentionDeleteCmd())
+	cmd.AddCommand(newProjectRetentionRunCmd())
+	cmd.AddCommand(newProjectRetentionExecutionsCmd())
+	cmd.AddCommand(newProjectRetentionTasksCmd())
+	cmd.AddCommand(newProjectRetentionLogCmd())
+	cmd.AddCommand(newProjectRetentionSimulateCmd())
+
+	return cmd
+}
+
+// projectRetentionID returns the project and the ID of its retention
+// policy, or 0 when the project has none
+func projectRetentionID(ctx context.Context, client *api.Client, projectName string) (*api.Project, int64, error) {
+	project, err := harbor.NewProjectService(client).Get(ctx, projectName)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get project: %w", err)
+	}
+	if project.Metadata == nil || project.Metadata.RetentionID == "" {
+		return project, 0, nil
+	}
+	id, err := strconv.ParseInt(project.Metadata.RetentionID, 10, 64)
+	if err != nil {
+		return nil, 0, fmt.Errorf("invalid retention ID %q: %w", project.Metadata.RetentionID, err)
+	}
+	return project, id, nil
+}
+
+// requireRetentionID is like projectRetentionID but fails when the
+// project has no retention policy
+func requireRetentionID(ctx context.Context, client *api.Client, projectName string) (int64, error) {
+	_, id, err := projectRetentionID(ctx, client, projectName)
+	if err != nil {
+		return 0, err
+	}
+	if id == 0 {
+		return 0, fmt.Errorf("project '%s' has no retention policy", projectName)
+	}
+	return id, nil
+}
+
+// retentionFlags holds the flags used to build a retention spec
+type retentionFlags struct {
+	file         string
+	template     string
+	count        int
+	repos        string
+	excludeRepos bool
+	tags         string
+	excludeTags  bool
+	untagged     bool
+	schedule     string
+}
+
+func (f *retentionFlags) register(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&f.file, "file", "f", "", "YAML file with retention rules")
+	cmd.Flags().StringVar(&f.template, "template", "", "Rule template (latestPushedK|latestPulledN|nDaysSinceLastPush|nDaysSinceLastPull|always)")
+	cmd.Flags().IntVar(&f.count, "count", 0, "Number of artifacts or days for the template")
+	cmd.Flags().StringVar(&f.repos, "repos", "**", "Repository pattern (doublestar)")
+	cmd.Flags().BoolVar(&f.excludeRepos, "exclude-repos", false, "Apply the rule to repositories NOT matching --repos")
+	cmd.Flags().StringVar(&f.tags, "tags", "**", "Tag pattern (doublestar)")
+	cmd.Flags().BoolVar(&f.excludeTags, "exclude-tags", false, "Apply the rule to tags NOT matching --tags")
+	cmd.Flags().BoolVar(&f.untagged, "untagged", false, "Include untagged artifacts")
+	cmd.Flags().StringVar(&f.schedule, "schedule", "", "Cron schedule (e.g. \"0 0 0 * * *\"), empty for manual runs only")
+}
+
+// spec builds a retention spec from the rule file or the single rule
+// flags. The file takes precedence; --schedule overrides its schedule.
+func (f *retentionFlags) spec(cmd *cobra.Command) (*harbor.RetentionSpec, error) {
+	var spec *harbor.RetentionSpec
+	if f.file != "" {
+		var err error
+		spec, err = loadRetentionSpec(f.file)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		if f.template == "" {
+			return nil, fmt.Errorf("either --file or --template is required")
+		}
+		spec = &harbor.RetentionSpec{
+			Rules: []*harbor.RetentionRuleSpec{{
+				Template:            f.template,
+				Count:               f.count,
+				Repositories:        f.repos,
+				ExcludeRepositories: f.excludeRepos,
+				Tags:                f.tags,
+				ExcludeTags:         f.excludeTags,
+				Untagged:            f.untagged,
+			}},
+		}
+	}
+	if f.file == "" || cmd.Flags().Changed("schedule") {
+		spec.Schedule = f.schedule
+	}
+	return spec, nil
+}
+
+func loadRetentionSpec(file string) (*harbor.RetentionSpec, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rules file: %w", err)
+	}
+	var spec harbor.RetentionSpec
+	if err := yaml.Unmarshal(data, &spec); err != nil {
+		return nil, fmt.Errorf("failed to parse rules file: %w", err)
+	}
+	return &spec, nil
+}
+
+func newProjectRetentionGetCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "get <project>",
+		Short: "Show retention policy",
+		Args:  requireArgs(1, "requires <project>"),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := api.NewClient()
+			if err != nil {
+				return err
+			}
+			id, err := requireRetentionID(cmd.Context(), client, args[0])
+			if err != nil {
+				return err
+			}
+			policy, err := harbor.NewRetentionService(client).GetPolicy(cmd.Context(), id)
+			if err != nil {
+				return fmt.Errorf("failed to get retention policy: %w", err)
+			}
+
+			switch output.GetFormat() {
+			case "json":
+				return output.JSON(policy)
+			case "yaml":
+				return output.YAML(policy)
+			default:
+				output.Info("Retention policy for %s", output.Bold(args[0]))
+				fmt.Printf("ID:        %d\n", policy.ID)
+				fmt.Printf("Algorithm: %s\n", policy.Algorithm)
+				schedule := "manual"
+				if policy.Trigger != nil {
+					if cron, ok := policy.Trigger.Settings["cron"].(string); ok && cron != "" {
+						schedule = cron
+					}
+				}
+				fmt.Printf("Schedule:  %s\n", schedule)
+
+				if len(policy.Rules) == 0 {
+					return nil
+				}
+				fmt.Println()
+				table := output.Table()
+				table.Append([]string{"#", "ENABLED", "RULE"})
+				for i, r := range policy.Rules {
+					table.Append([]string{
+						strconv.Itoa(i + 1),
+						strconv.FormatBool(!r.Disabled),
+						harbor.DescribeRetentionRule(r),
+					})
+				}
+				table.Render()
+				return nil
+			}
+		},
+	}
+	return cmd
+}
+
+func newProjectRetentionCreateCmd() *cobra.Command {
+	flags := &retentionFlags{}
+
+	cmd := &cobra.Command{
+		Use:   "create <project>",
+		Short: "Create retention policy",
+		Example: `  # Keep the 10 most recently pushed artifacts of every repository
+  hrbcli project retention create myproject --template latestPushedK --count 10
+
+  # Create a policy from a rules file, run daily at midnight
+  hrbcli project retention create myproject -f retention.yaml --schedule "0 0 0 * * *"`,
+		Args: requireArgs(1, "requires <project>"),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			spec, err := flags.spec(cmd)
+			if err != nil {
+				return err
+			}
+
+			client, err := api.NewClient()
+			if err != nil {
+				return err
+			}
+			project, existing, err := projectRetentionID(cmd.Context(), client, args[0])
+			if err != nil {
+				return err
+			}
+			if existing != 0 {
+				return fmt.Errorf("project '%s' already has retention policy %d, use update instead", args[0], existing)
+			}
+
+			policy, err := spec.Policy(project.ProjectID)
+			if err != nil {
+				return err
+			}
+			id, err := harbor.NewRetentionService(client).CreatePolicy(cmd.Context(), policy)
+			if err != nil {
+				return fmt.Errorf("failed to create retention policy: %w", err)
+			}
+			output.Success("Created retention policy %d for project %s", id, args[0])
+			return nil
+		},
+	}
+
+	flags.register(cmd)
+	return cmd
+}
+
+func newProjectRetentionUpdateCmd() *cobra.Command {
+	flags := &retentionFlags{}
+
+	cmd := &cobra.Command{
+		Use:   "update <project>",
+		Short: "Replace retention policy rules",
+		Args:  requireArgs(1, "requires <project>"),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			spec, err := flags.spec(cmd)
+			if err != nil {
+				return err
+			}
+
+			client, err := api.NewClient()
+			if err != nil {
+				return err
+			}
+			project, id, err := projectRetentionID(cmd.Context(), client, args[0])
+			if err != nil {
+				return err
+			}
+			if id == 0 {
+				return fmt.Errorf("project '%s' has no retention policy", args[0])
+			}
+
+			policy, err := spec.Policy(project.ProjectID)
+			if err != nil {
+				return err
+			}
+			policy.ID = id
+			if err := harbor.NewRetentionService(client).UpdatePolicy(cmd.Context(), id, policy); err != nil {
+				return fmt.Errorf("failed to update retention policy: %w", err)
+			}
+			output.Success("Updated retention policy %d for project %s", id, args[0])
+			return nil
+		},
+	}
+
+	flags.register(cmd)
+	return cmd
+}
+
+func newProjectRetentionDeleteCmd() *cobra.Command {
+	var force bool
+
+	cmd := &cobra.Command{
+		Use:   "delete <project>",
+		Short: "Delete retention policy",
+		Args:  requireArgs(1, "requires <project>"),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := api.NewClient()
+			if err != nil {
+				return err
+			}
+			id, err := requireRetentionID(cmd.Context(), client, args[0])
+			if err != nil {
+				return err
+			}
+
+			if !force {
+				prompt := promptui.Prompt{
+					Label:     fmt.Sprintf("Delete retention policy of project '%s'", args[0]),
+					IsConfirm: true,
+				}
+				if _, err := prompt.Run(); err != nil {
+					output.Info("Deletion cancelled")
+					return nil
+				}
+			}
+
+			if err := harbor.NewRetentionService(client).DeletePolicy(cmd.Context(), id); err != nil {
+				return fmt.Errorf("failed to delete retention policy: %w", err)
+			}
+			output.Success("Deleted retention policy of project %s", args[0])
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&force, "force", false, "Skip confirmation")
+	return cmd
+}
+
+func newProjectRetentionRunCmd() *cobra.Command {
+	var dryRun bool
+
+	cmd := &cobra.Command{
+		Use:   "run <project>",
+		Short: "Run retention policy now",
+		Long: `Trigger the project's retention policy on the server.
+
+With --dry-run Harbor evaluates the policy and records the result in the
+execution history without deleting anything.`,
+		Args: requireArgs(1, "requires <project>"),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := api.NewClient()
+			if err != nil {
+				return err
+			}
+			id, err := requireRetentionID(cmd.Context(), client, args[0])
+			if err != nil {
+				return err
+			}
+			if err := harbor.NewRetentionService(client).Run(cmd.Context(), id, dryRun); err != nil {
+				return fmt.Errorf("failed to run retention policy: %w", err)
+			}
+			if dryRun {
+				output.Success("Triggered retention dry run for project %s", args[0])
+			} else {
+				output.Success("Triggered retention run for project %s", args[0])
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Evaluate the policy without deleting artifacts")
+	return cmd
+}
+
+func newProjectRetentionExecutionsCmd() *cobra.Command {
+	var (
+		page     int
+		pageSize int
+		pages    pageFlags
+	)
+
+	cmd := &cobra.Command{
+		Use:   "executions <project>",
+		Short: "List retention executions",
+		Args:  requireArgs(1, "requires <project>"),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := api.NewClient()
+			if err != nil {
+				return err
+			}
+			id, err := requireRetentionID(cmd.Context(), client, args[0])
+			if err != nil {
+				return err
+			}
+			svc := harbor.NewRetentionService(client)
+			opts := &api.ListOptions{Page: page, PageSize: pageSize}
+			var execs []*api.RetentionExecution
+			if pages.enabled() {
+				opts.Page, opts.PageSize = 0, 0
+				execs, err = svc.ListAllExecutions(cmd.Context(), id, opts, pages.limit)
+			} else {
+				execs, err = svc.ListExecutions(cmd.Context(), id, opts)
+			}
+			if err != nil {
+				return fmt.Errorf("failed to list executions: %w", err)
+			}
+
+			if len(execs) == 0 {
+				output.Info("No executions found")
+				return nil
+			}
+
+			switch output.GetFormat() {
+			case "json":
+				return output.JSON(execs)
+			case "yaml":
+				return output.YAML(execs)
+			default:
+				table := output.Table()
+				table.Append([]string{"ID", "STATUS", "TRIGGER", "DRY RUN", "START TIME"})
+				for _, e := range execs {
+					table.Append([]string{
+						strconv.FormatInt(e.ID, 10),
+						e.Status,
+						e.Trigger,
+						strconv.FormatBool(e.DryRun),
+						e.StartTime.Format("2006-01-02 15:04:05"),
+					})
+				}
+				table.Render()
+				return nil
+			}
+		},
+	}
+	cmd.Flags().IntVar(&page, "page", 1, "Page number")
+	cmd.Flags().IntVar(&pageSize, "page-size", 20, "Page size")
+	pages.register(cmd)
+	return cmd
+}
+
+func newProjectRetentionTasksCmd() *cobra.Command {
+	var (
+		page     int
+		pageSize int
+		pages    pageFlags
+	)
+
+	cmd := &cobra.Command{
+		Use:   "tasks <project> <execution-id>",
+		Short: "List tasks of a retention execution",
+		Args:  requireArgs(2, "requires <project> and <execution-id>"),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			execID, err := strconv.ParseInt(args[1], 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid execution ID: %s", args[1])
+			}
+
+			client, err := api.NewClient()
+			if err != nil {
+				return err
+			}
+			id, err := requireRetentionID(cmd.Context(), client, args[0])
+			if err != nil {
+				return err
+			}
+			svc := harbor.NewRetentionService(client)
+			opts := &api.ListOptions{Page: page, PageSize: pageSize}
+			var tasks []*api.RetentionExecutionTask
+			if pages.enabled() {
+				opts.Page, opts.PageSize = 0, 0
+				tasks, err = svc.ListAllTasks(cmd.Context(), id, execID, opts, pages.limit)
+			} else {
+				tasks, err = svc.ListTasks(cmd.Context(), id, execID, opts)
+			}
+			if err != nil {
+				return fmt.Errorf("failed to list tasks: %w", err)
+			}
+
+			if len(tasks) == 0 {
+				output.Info("No tasks found")
+				return nil
+			}
+
+			switch output.GetFormat() {
+			case "json":
+				return output.JSON(tasks)
+			case "yaml":
+				return output.YAML(tasks)
+			default:
+				table := output.Table()
+				table.Append([]string{"ID", "REPOSITORY", "STATUS", "RETAINED", "TOTAL", "START TIME"})
+				for _, t := range tasks {
+					table.Append([]string{
+						strconv.FormatInt(t.ID, 10),
+						t.Repository,
+						t.Status,
+						strconv.Itoa(t.Retained),
+						strconv.Itoa(t.Total),
+						t.StartTime.Format("2006-01-02 15:04:05"),
+					})
+				}
+				table.Render()
+				return nil
+			}
+		},
+	}
+	cmd.Flags().IntVar(&page, "page", 1, "Page number")
+	cmd.Flags().IntVar(&pageSize, "page-size", 20, "Page size")
+	pages.register(cmd)
+	return cmd
+}
+
+func newProjectRetentionLogCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "log <project> <execution-id> <task-id>",
+		Short: "Show log of a retention task",
+		Args:  requireArgs(3, "requires <project>, <execution-id> and <task-id>"),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			execID, err := strconv.ParseInt(args[1], 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid execution ID: %s", args[1])
+			}
+			taskID, err := strconv.ParseInt(args[2], 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid task ID: %s", args[2])
+			}
+
+			client, err := api.NewClient()
+			if err != nil {
+				return err
+			}
+			id, err := requireRetentionID(cmd.Context(), client, args[0])
+			if err != nil {
+				return err
+			}
+			log, err := harbor.NewRetentionService(client).GetTaskLog(cmd.Context(), id, execID, taskID)
+			if err != nil {
+				return fmt.Errorf("failed to get task log: %w", err)
+			}
+			fmt.Print(log)
+			return nil
+		},
+	}
+	return cmd
+}
+
+func newProjectRetentionSimulateCmd() *cobra.Command {
+	var (
+		file        string
+		deletedOnly bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "simulate <project>[/<repository>]",
+		Short: "Show what a retention policy would delete",
+		Long: `Evaluate retention rules locally against the artifacts of a project and
+print which artifacts would be retained or removed. Nothing is changed on
+the server.
+
+By default the project's current policy is evaluated. Use --file to try a
+rules file before applying it.`,
+		Example: `  # Evaluate the current policy
+  hrbcli project retention simulate myproject
+
+  # Try a rules file against a single repository
+  hrbcli project retention simulate myproject/nginx -f retention.yaml --deleted-only`,
+		Args: requireArgs(1, "requires <project>[/<repository>]"),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			projectName, repoName, err := parseProjectRepo(args[0])
+			if err != nil {
+				return err
+			}
+
+			client, err := api.NewClient()
+			if err != nil {
+				return err
+			}
+
+			var policy *api.RetentionPolicy
+			if file != "" {
+				spec, err := loadRetentionSpec(file)
+				if err != nil {
+					return err
+				}
+				if policy, err = spec.Policy(0); err != nil {
+					return err
+				}
+			} else {
+				id, err := requireRetentionID(cmd.Context(), client, projectName)
+				if err != nil {
+					return err
+				}
+				if policy, err = harbor.NewRetentionService(client).GetPolicy(cmd.Context(), id); err != nil {
+					return fmt.Errorf("failed to get retention policy: %w", err)
+				}
+			}
+
+			repos, err := resolveRepositories(cmd.Context(), client, projectName, repoName)
+			if err != nil {
+				return err
+			}
+
+			artifactSvc := harbor.NewArtifactService(client)
+			now := time.Now()
+			var decisions []*harbor.RetentionDecision
+			for _, repo := range repos {
+				artifacts, err := artifactSvc.ListAll(cmd.Context(), projectName, repo, &api.ArtifactListOptions{WithTag: true}, 0)
+				if err != nil {
+					return fmt.Errorf("failed to list artifacts of %s: %w", repo, err)
+				}
+				result, err := harbor.SimulateRetention(policy, repo, artifacts, now)
+				if err != nil {
+					return err
+				}
+				decisions = append(decisions, result...)
+			}
+
+			retained := 0
+			shown := decisions[:0:0]
+			for _, d := range decisions {
+				if d.Retained {
+					retained++
+				}
+				if !deletedOnly || !d.Retained {
+					shown = append(shown, d)
+				}
+			}
+
+			switch output.GetFormat() {
+			case "json":
+				return output.JSON(shown)
+			case "yaml":
+				return output.YAML(shown)
+			default:
+				if len(shown) > 0 {
+					table := output.Table()
+					table.Append([]string{"ACTION", "REPOSITORY", "DIGEST", "TAGS", "PUSHED", "RULE"})
+					for _, d := range shown {
+						action := output.Red("DELETE")
+						rule := ""
+						if d.Retained {
+							action = output.Green("RETAIN")
+							rule = strconv.Itoa(d.Rule)
+						}
+						table.Append([]string{
+							action,
+							d.Repository,
+							output.Truncate(d.Digest, 19),
+							strings.Join(d.Tags, ","),
+							d.PushTime.Format("2006-01-02 15:04"),
+							rule,
+						})
+					}
+					table.Render()
+				}
+				output.Info("%d artifacts: %d retained, %d to delete", len(decisions), retained, len(decisions)-retained)
+				return nil
+			}
+		},
+	}
+
+	cmd.Flags().StringVarP(&file, "file", "f", "", "YAML file with retention rules (default: the project's current policy)")
+	cmd.Flags().BoolVar(&deletedOnly, "deleted-only", false, "Only show artifacts that would be deleted")
+	return cmd
+}
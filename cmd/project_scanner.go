@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/pascal71/hrbcli/pkg/api"
+	"github.com/pascal71/hrbcli/pkg/harbor"
+	"github.com/pascal71/hrbcli/pkg/output"
+)
+
+func newProjectScannerCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "scanner",
+		Short: "Manage the scanner of a project",
+		Long: `Show or choose the scanner used for a project. Projects without a
+scanner of their own use the system default scanner.`,
+	}
+
+	cmd.AddCommand(newProjectScannerGetCmd())
+	cmd.AddCommand(newProjectScannerSetCmd())
+
+	return cmd
+}
+
+func newProjectScannerGetCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "get <project>",
+		Short: "Show the scanner of a project",
+		Args:  requireArgs(1, "requires <project>"),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := api.NewClient()
+			if err != nil {
+				return err
+			}
+			scanner, err := harbor.NewScannerRegistrationService(client).GetProjectScanner(cmd.Context(), args[0])
+			if err != nil {
+				return fmt.Errorf("failed to get project scanner: %w", err)
+			}
+			switch output.GetFormat() {
+			case "json", "yaml":
+			default:
+				if scanner.UUID == "" {
+					output.Info("No scanner configured for project %s", args[0])
+					return nil
+				}
+			}
+			return printScannerRegistration(scanner)
+		},
+	}
+}
+
+func newProjectScannerSetCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "set <project> <scanner>",
+		Short: "Choose the scanner of a project",
+		Long:  `Choose the scanner used for a project by scanner name or UUID.`,
+		Example: `  # Scan the images of myproject with Trivy
+  hrbcli project scanner set myproject trivy`,
+		Args: requireArgs(2, "requires <project> <scanner>"),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := api.NewClient()
+			if err != nil {
+				return err
+			}
+			svc := harbor.NewScannerRegistrationService(client)
+
+			scanner, err := svc.Find(cmd.Context(), args[1])
+			if err != nil {
+				return err
+			}
+			if scanner.Disabled {
+				output.Warning("Scanner '%s' is disabled", scanner.Name)
+			}
+			if err := svc.SetProjectScanner(cmd.Context(), args[0], scanner.UUID); err != nil {
+				return fmt.Errorf("failed to set project scanner: %w", err)
+			}
+			output.Success("Project %s now uses scanner '%s'", args[0], scanner.Name)
+			return nil
+		},
+	}
+}
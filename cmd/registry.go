@@ -36,7 +36,12 @@ func NewRegistryCmd() *cobra.Command {
 }
 
 func newRegistryListCmd() *cobra.Command {
-	var query string
+	var (
+		query    string
+		page     int
+		pageSize int
+		pages    pageFlags
+	)
 
 	cmd := &cobra.Command{
 		Use:   "list",
@@ -56,10 +61,18 @@ func newRegistryListCmd() *cobra.Command {
 			registrySvc := harbor.NewRegistryService(client)
 
 			opts := &api.ListOptions{
-				Query: query,
+				Page:     page,
+				PageSize: pageSize,
+				Query:    query,
 			}
 
-			registries, err := registrySvc.List(opts)
+			var registries []*api.Registry
+			if pages.enabled() {
+				opts.Page, opts.PageSize = 0, 0
+				registries, err = registrySvc.ListAll(cmd.Context(), opts, pages.limit)
+			} else {
+				registries, err = registrySvc.List(cmd.Context(), opts)
+			}
 			if err != nil {
 				return fmt.Errorf("failed to list registries: %w", err)
 			}
@@ -100,6 +113,9 @@ func newRegistryListCmd() *cobra.Command {
 	}
 
 	cmd.Flags().StringVar(&query, "query", "", "Search query")
+	cmd.Flags().IntVar(&page, "page", 1, "Page number")
+	cmd.Flags().IntVar(&pageSize, "page-size", 20, "Page size")
+	pages.register(cmd)
 
 	return cmd
 }
@@ -245,14 +261,14 @@ func newRegistryCreateCmd() *cobra.Command {
 
 			// Test connectivity first
 			output.Info("Testing connectivity to registry...")
-			if err := registrySvc.Ping(req); err != nil {
+			if err := registrySvc.Ping(cmd.Context(), req); err != nil {
 				output.Warning("Registry ping failed: %v", err)
 			} else {
 				output.Success("Registry is reachable")
 			}
 
 			// Create registry
-			registry, err := registrySvc.Create(req)
+			registry, err := registrySvc.Create(cmd.Context(), req)
 			if err != nil {
 				if apiErr, ok := err.(*api.APIError); ok && apiErr.IsConflict() {
 					return fmt.Errorf("registry '%s' already exists", name)
@@ -300,7 +316,7 @@ func newRegistryGetCmd() *cobra.Command {
 
 			registrySvc := harbor.NewRegistryService(client)
 
-			registry, err := registrySvc.Get(id)
+			registry, err := registrySvc.Get(cmd.Context(), id)
 			if err != nil {
 				return fmt.Errorf("failed to get registry: %w", err)
 			}
@@ -366,7 +382,7 @@ func newRegistryUpdateCmd() *cobra.Command {
 			registrySvc := harbor.NewRegistryService(client)
 
 			// Get current registry so we can preserve existing values
-			current, err := registrySvc.Get(id)
+			current, err := registrySvc.Get(cmd.Context(), id)
 			if err != nil {
 				return fmt.Errorf("failed to get registry: %w", err)
 			}
@@ -396,7 +412,7 @@ func newRegistryUpdateCmd() *cobra.Command {
 				}
 			}
 
-			if err := registrySvc.Update(id, req); err != nil {
+			if err := registrySvc.Update(cmd.Context(), id, req); err != nil {
 				return fmt.Errorf("failed to update registry: %w", err)
 			}
 
@@ -444,7 +460,7 @@ func newRegistryDeleteCmd() *cobra.Command {
 			registrySvc := harbor.NewRegistryService(client)
 
 			// Get registry details first
-			registry, err := registrySvc.Get(id)
+			registry, err := registrySvc.Get(cmd.Context(), id)
 			if err != nil {
 				return fmt.Errorf("failed to get registry: %w", err)
 			}
@@ -463,7 +479,7 @@ func newRegistryDeleteCmd() *cobra.Command {
 			}
 
 			// Delete registry
-			if err := registrySvc.Delete(id); err != nil {
+			if err := registrySvc.Delete(cmd.Context(), id); err != nil {
 				return fmt.Errorf("failed to delete registry: %w", err)
 			}
 
@@ -526,7 +542,7 @@ func newRegistryPingCmd() *cobra.Command {
 
 			// Test connectivity
 			output.Info("Testing connectivity to %s...", url)
-			if err := registrySvc.Ping(req); err != nil {
+			if err := registrySvc.Ping(cmd.Context(), req); err != nil {
 				output.Error("Ping failed: %v", err)
 				return err
 			}
@@ -560,7 +576,7 @@ func newRegistryAdaptersCmd() *cobra.Command {
 
 			registrySvc := harbor.NewRegistryService(client)
 
-			adapters, err := registrySvc.ListAdapters()
+			adapters, err := registrySvc.ListAdapters(cmd.Context())
 			if err != nil {
 				return fmt.Errorf("failed to list adapters: %w", err)
 			}
@@ -661,7 +677,7 @@ func newRegistryAdapterInfoCmd() *cobra.Command {
 			}
 
 			registrySvc := harbor.NewRegistryService(client)
-			adapters, err := registrySvc.ListAdapters()
+			adapters, err := registrySvc.ListAdapters(cmd.Context())
 			if err != nil {
 				return fmt.Errorf("failed to list adapters: %w", err)
 			}
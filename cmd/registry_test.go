@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"context"
 	"encoding/json"
 	"io"
 	"net/http"
@@ -49,6 +50,7 @@ func TestNewRegistryCreateCmd(t *testing.T) {
 	defer os.Unsetenv("HARBOR_URL")
 
 	cmd := newRegistryCreateCmd()
+	cmd.SetContext(context.Background())
 	cmd.Flags().Set("url", "https://example.com")
 	cmd.Flags().Set("type", "docker-hub")
 	if err := cmd.RunE(cmd, []string{"myreg"}); err != nil {
@@ -89,6 +91,7 @@ func TestNewRegistryUpdateCmd(t *testing.T) {
 	defer os.Unsetenv("HARBOR_URL")
 
 	cmd := newRegistryUpdateCmd()
+	cmd.SetContext(context.Background())
 	cmd.Flags().Set("description", "newdesc")
 	if err := cmd.RunE(cmd, []string{"1"}); err != nil {
 		t.Fatalf("run error: %v", err)
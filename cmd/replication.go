@@ -32,6 +32,12 @@ func NewReplicationCmd() *cobra.Command {
 }
 
 func newReplicationListCmd() *cobra.Command {
+	var (
+		page     int
+		pageSize int
+		pages    pageFlags
+	)
+
 	cmd := &cobra.Command{
 		Use:   "list",
 		Short: "List replication policies",
@@ -41,7 +47,14 @@ func newReplicationListCmd() *cobra.Command {
 				return err
 			}
 			svc := harbor.NewReplicationService(client)
-			policies, err := svc.ListPolicies(nil)
+			opts := &api.ListOptions{Page: page, PageSize: pageSize}
+			var policies []*api.ReplicationPolicy
+			if pages.enabled() {
+				opts.Page, opts.PageSize = 0, 0
+				policies, err = svc.ListAllPolicies(cmd.Context(), opts, pages.limit)
+			} else {
+				policies, err = svc.ListPolicies(cmd.Context(), opts)
+			}
 			if err != nil {
 				return err
 			}
@@ -66,6 +79,9 @@ func newReplicationListCmd() *cobra.Command {
 			return nil
 		},
 	}
+	cmd.Flags().IntVar(&page, "page", 1, "Page number")
+	cmd.Flags().IntVar(&pageSize, "page-size", 20, "Page size")
+	pages.register(cmd)
 	return cmd
 }
 
@@ -84,7 +100,7 @@ func newReplicationGetCmd() *cobra.Command {
 				return err
 			}
 			svc := harbor.NewReplicationService(client)
-			policy, err := svc.GetPolicy(id)
+			policy, err := svc.GetPolicy(cmd.Context(), id)
 			if err != nil {
 				return err
 			}
@@ -122,7 +138,7 @@ func newReplicationCreateCmd() *cobra.Command {
 			}
 			svc := harbor.NewReplicationService(client)
 			req := &api.ReplicationPolicy{Name: name, SrcRegistry: &api.Registry{Name: src}, DestRegistry: &api.Registry{Name: dst}, Enabled: true}
-			policy, err := svc.CreatePolicy(req)
+			policy, err := svc.CreatePolicy(cmd.Context(), req)
 			if err != nil {
 				return err
 			}
@@ -151,7 +167,7 @@ func newReplicationDeleteCmd() *cobra.Command {
 				return err
 			}
 			svc := harbor.NewReplicationService(client)
-			if err := svc.DeletePolicy(id); err != nil {
+			if err := svc.DeletePolicy(cmd.Context(), id); err != nil {
 				return err
 			}
 			output.Success("Deleted replication policy %d", id)
@@ -190,7 +206,7 @@ func newReplicationExecuteCmd() *cobra.Command {
 					return err
 				}
 				svc := harbor.NewReplicationService(client)
-				id, err = svc.ResolvePolicyID(policyName)
+				id, err = svc.ResolvePolicyID(cmd.Context(), policyName)
 				if err != nil {
 					return err
 				}
@@ -201,7 +217,7 @@ func newReplicationExecuteCmd() *cobra.Command {
 				return err
 			}
 			svc := harbor.NewReplicationService(client)
-			exec, err := svc.StartExecution(id)
+			exec, err := svc.StartExecution(cmd.Context(), id)
 			if err != nil {
 				return err
 			}
@@ -214,6 +230,12 @@ func newReplicationExecuteCmd() *cobra.Command {
 }
 
 func newReplicationExecutionsCmd() *cobra.Command {
+	var (
+		page     int
+		pageSize int
+		pages    pageFlags
+	)
+
 	cmd := &cobra.Command{
 		Use:   "executions [policy-id]",
 		Short: "List replication executions",
@@ -232,7 +254,14 @@ func newReplicationExecutionsCmd() *cobra.Command {
 				return err
 			}
 			svc := harbor.NewReplicationService(client)
-			execs, err := svc.ListExecutions(id)
+			opts := &api.ListOptions{Page: page, PageSize: pageSize}
+			var execs []*api.ReplicationExecution
+			if pages.enabled() {
+				opts.Page, opts.PageSize = 0, 0
+				execs, err = svc.ListAllExecutions(cmd.Context(), id, opts, pages.limit)
+			} else {
+				execs, err = svc.ListExecutions(cmd.Context(), id, opts)
+			}
 			if err != nil {
 				return err
 			}
@@ -258,6 +287,9 @@ func newReplicationExecutionsCmd() *cobra.Command {
 			return nil
 		},
 	}
+	cmd.Flags().IntVar(&page, "page", 1, "Page number")
+	cmd.Flags().IntVar(&pageSize, "page-size", 20, "Page size")
+	pages.register(cmd)
 	return cmd
 }
 
@@ -276,7 +308,7 @@ func newReplicationExecutionCmd() *cobra.Command {
 				return err
 			}
 			svc := harbor.NewReplicationService(client)
-			exec, err := svc.GetExecution(id)
+			exec, err := svc.GetExecution(cmd.Context(), id)
 			if err != nil {
 				return err
 			}
@@ -316,12 +348,12 @@ func newReplicationLogsCmd() *cobra.Command {
 				return err
 			}
 			svc := harbor.NewReplicationService(client)
-			tasks, err := svc.ListTasks(id)
+			tasks, err := svc.ListAllTasks(cmd.Context(), id, nil, 0)
 			if err != nil {
 				return err
 			}
 			for _, t := range tasks {
-				log, err := svc.GetTaskLog(id, t.ID)
+				log, err := svc.GetTaskLog(cmd.Context(), id, t.ID)
 				if err != nil {
 					return err
 				}
@@ -344,7 +376,7 @@ func newReplicationStatsCmd() *cobra.Command {
 				return err
 			}
 			svc := harbor.NewReplicationService(client)
-			execs, err := svc.ListExecutions(0)
+			execs, err := svc.ListAllExecutions(cmd.Context(), 0, nil, 0)
 			if err != nil {
 				return err
 			}
@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"strconv"
 	"strings"
@@ -37,6 +38,7 @@ func newRepoListCmd() *cobra.Command {
 		filter   string
 		sortBy   string
 		detail   bool
+		pages    pageFlags
 	)
 
 	cmd := &cobra.Command{
@@ -69,7 +71,13 @@ func newRepoListCmd() *cobra.Command {
 				Sort:     sortBy,
 			}
 
-			repos, err := repoSvc.List(project, opts)
+			var repos []*api.Repository
+			if pages.enabled() {
+				opts.Page, opts.PageSize = 0, 0
+				repos, err = repoSvc.ListAll(cmd.Context(), project, opts, pages.limit)
+			} else {
+				repos, err = repoSvc.List(cmd.Context(), project, opts)
+			}
 			if err != nil {
 				return fmt.Errorf("failed to list repositories: %w", err)
 			}
@@ -119,6 +127,7 @@ func newRepoListCmd() *cobra.Command {
 	cmd.Flags().StringVar(&filter, "filter", "", "Filter by name")
 	cmd.Flags().StringVar(&sortBy, "sort", "", "Sort by field")
 	cmd.Flags().BoolVar(&detail, "detail", false, "Show detailed information")
+	pages.register(cmd)
 
 	return cmd
 }
@@ -136,6 +145,24 @@ func parseRepoRef(input string) (string, string, error) {
 	return project, repo, nil
 }
 
+// resolveRepositories returns repo when set, otherwise the names of all
+// repositories in the project without the project prefix
+func resolveRepositories(ctx context.Context, client *api.Client, project, repo string) ([]string, error) {
+	if repo != "" {
+		return []string{repo}, nil
+	}
+
+	list, err := harbor.NewRepositoryService(client).ListAll(ctx, project, nil, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list repositories: %w", err)
+	}
+	repos := make([]string, 0, len(list))
+	for _, r := range list {
+		repos = append(repos, strings.TrimPrefix(r.Name, project+"/"))
+	}
+	return repos, nil
+}
+
 func newRepoGetCmd() *cobra.Command {
 	var detail bool
 
@@ -162,7 +189,7 @@ func newRepoGetCmd() *cobra.Command {
 
 			repoSvc := harbor.NewRepositoryService(client)
 
-			repository, err := repoSvc.Get(project, repo)
+			repository, err := repoSvc.Get(cmd.Context(), project, repo)
 			if err != nil {
 				return fmt.Errorf("failed to get repository: %w", err)
 			}
@@ -223,7 +250,7 @@ func newRepoDeleteCmd() *cobra.Command {
 			artSvc := harbor.NewArtifactService(client)
 
 			if ref != "" {
-				if err := artSvc.Delete(project, repo, ref); err != nil {
+				if err := artSvc.Delete(cmd.Context(), project, repo, ref); err != nil {
 					return fmt.Errorf("failed to delete artifact: %w", err)
 				}
 				output.Success("Deleted %s/%s:%s", project, repo, ref)
@@ -239,7 +266,7 @@ func newRepoDeleteCmd() *cobra.Command {
 				}
 			}
 
-			if err := repoSvc.Delete(project, repo); err != nil {
+			if err := repoSvc.Delete(cmd.Context(), project, repo); err != nil {
 				return fmt.Errorf("failed to delete repository: %w", err)
 			}
 			output.Success("Deleted repository %s/%s", project, repo)
@@ -254,6 +281,7 @@ func newRepoTagsCmd() *cobra.Command {
 	var detail bool
 	var filter string
 	var page, pageSize int
+	var pages pageFlags
 	cmd := &cobra.Command{
 		Use:   "tags <project>/<repository>",
 		Short: "List tags for repository",
@@ -271,7 +299,13 @@ func newRepoTagsCmd() *cobra.Command {
 
 			repoSvc := harbor.NewRepositoryService(client)
 			opts := &api.ListOptions{Query: filter, Page: page, PageSize: pageSize}
-			tags, err := repoSvc.ListTags(project, repo, opts)
+			var tags []*api.ArtifactTag
+			if pages.enabled() {
+				opts.Page, opts.PageSize = 0, 0
+				tags, err = repoSvc.ListAllTags(cmd.Context(), project, repo, opts, pages.limit)
+			} else {
+				tags, err = repoSvc.ListTags(cmd.Context(), project, repo, opts)
+			}
 			if err != nil {
 				return fmt.Errorf("failed to list tags: %w", err)
 			}
@@ -309,5 +343,6 @@ func newRepoTagsCmd() *cobra.Command {
 	cmd.Flags().StringVar(&filter, "filter", "", "Filter by tag name")
 	cmd.Flags().IntVar(&page, "page", 1, "Page number")
 	cmd.Flags().IntVar(&pageSize, "page-size", 20, "Page size")
+	pages.register(cmd)
 	return cmd
 }
@@ -0,0 +1,563 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/manifoldco/promptui"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"github.com/pascal71/hrbcli/pkg/api"
+	"github.com/pascal71/hrbcli/pkg/harbor"
+	"github.com/pascal71/hrbcli/pkg/output"
+)
+
+// NewRobotCmd creates the robot command
+func NewRobotCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "robot",
+		Short: "Manage robot accounts",
+		Long:  `Manage system and project level robot accounts.`,
+	}
+
+	cmd.AddCommand(newRobotListCmd())
+	cmd.AddCommand(newRobotGetCmd())
+	cmd.AddCommand(newRobotCreateCmd())
+	cmd.AddCommand(newRobotUpdateCmd())
+	cmd.AddCommand(newRobotDeleteCmd())
+	cmd.AddCommand(newRobotRefreshSecretCmd())
+
+	return cmd
+}
+
+func newRobotListCmd() *cobra.Command {
+	var (
+		page     int
+		pageSize int
+		project  string
+		level    string
+		query    string
+		pages    pageFlags
+	)
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List robot accounts",
+		Example: `  # List all robot accounts
+  hrbcli robot list
+
+  # List robot accounts of a project
+  hrbcli robot list --project myproject
+
+  # List system level robot accounts
+  hrbcli robot list --level system`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := api.NewClient()
+			if err != nil {
+				return err
+			}
+
+			var filters []string
+			if query != "" {
+				filters = append(filters, query)
+			}
+			if project != "" {
+				projectSvc := harbor.NewProjectService(client)
+				p, err := projectSvc.Get(cmd.Context(), project)
+				if err != nil {
+					return fmt.Errorf("failed to get project: %w", err)
+				}
+				filters = append(filters, "Level="+api.RobotLevelProject, fmt.Sprintf("ProjectID=%d", p.ProjectID))
+			} else if level != "" {
+				filters = append(filters, "Level="+level)
+			}
+
+			robotSvc := harbor.NewRobotService(client)
+			opts := &api.ListOptions{
+				Page:     page,
+				PageSize: pageSize,
+				Query:    strings.Join(filters, ","),
+			}
+			var robots []*api.Robot
+			if pages.enabled() {
+				opts.Page, opts.PageSize = 0, 0
+				robots, err = robotSvc.ListAll(cmd.Context(), opts, pages.limit)
+			} else {
+				robots, err = robotSvc.List(cmd.Context(), opts)
+			}
+			if err != nil {
+				return fmt.Errorf("failed to list robot accounts: %w", err)
+			}
+
+			if len(robots) == 0 {
+				output.Info("No robot accounts found")
+				return nil
+			}
+
+			switch output.GetFormat() {
+			case "json":
+				return output.JSON(robots)
+			case "yaml":
+				return output.YAML(robots)
+			default:
+				table := output.Table()
+				table.Append([]string{"ID", "NAME", "LEVEL", "DISABLED", "EXPIRES", "CREATED"})
+				for _, r := range robots {
+					table.Append([]string{
+						strconv.FormatInt(r.ID, 10),
+						r.Name,
+						r.Level,
+						strconv.FormatBool(r.Disable),
+						formatRobotExpiry(r.ExpiresAt),
+						r.CreationTime.Format("2006-01-02"),
+					})
+				}
+				table.Render()
+				return nil
+			}
+		},
+	}
+
+	cmd.Flags().IntVar(&page, "page", 1, "Page number")
+	cmd.Flags().IntVar(&pageSize, "page-size", 20, "Page size")
+	cmd.Flags().StringVar(&project, "project", "", "List robot accounts of this project")
+	cmd.Flags().StringVar(&level, "level", "", "Filter by level (system|project)")
+	cmd.Flags().StringVar(&query, "query", "", "Additional query string (e.g., 'name=~ci')")
+	pages.register(cmd)
+	return cmd
+}
+
+func newRobotGetCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "get <id>",
+		Short: "Get robot account details",
+		Args:  requireArgs(1, "requires <id>"),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id, err := strconv.ParseInt(args[0], 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid robot ID: %s", args[0])
+			}
+
+			client, err := api.NewClient()
+			if err != nil {
+				return err
+			}
+			robotSvc := harbor.NewRobotService(client)
+
+			robot, err := robotSvc.Get(cmd.Context(), id)
+			if err != nil {
+				return fmt.Errorf("failed to get robot account: %w", err)
+			}
+
+			switch output.GetFormat() {
+			case "json":
+				return output.JSON(robot)
+			case "yaml":
+				return output.YAML(robot)
+			default:
+				output.Info("Robot: %s", output.Bold(robot.Name))
+				fmt.Printf("ID:          %d\n", robot.ID)
+				fmt.Printf("Level:       %s\n", robot.Level)
+				fmt.Printf("Disabled:    %v\n", robot.Disable)
+				fmt.Printf("Expires:     %s\n", formatRobotExpiry(robot.ExpiresAt))
+				if robot.Description != "" {
+					fmt.Printf("Description: %s\n", robot.Description)
+				}
+				fmt.Printf("Created:     %s\n", robot.CreationTime.Format("2006-01-02 15:04:05"))
+
+				if len(robot.Permissions) > 0 {
+					output.Info("\nPermissions:")
+					table := output.Table()
+					table.Append([]string{"KIND", "NAMESPACE", "RESOURCE", "ACTION"})
+					for _, p := range robot.Permissions {
+						for _, a := range p.Access {
+							table.Append([]string{p.Kind, p.Namespace, a.Resource, a.Action})
+						}
+					}
+					table.Render()
+				}
+				return nil
+			}
+		},
+	}
+	return cmd
+}
+
+func newRobotCreateCmd() *cobra.Command {
+	var (
+		project     string
+		level       string
+		description string
+		duration    int64
+		access      []string
+		permissions []string
+		permFile    string
+		secretFile  string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "create <name>",
+		Short: "Create a robot account",
+		Long: `Create a system or project level robot account.
+
+Permissions can be given with --access (resource:action, applied to the
+robot's own project or to the system namespace), with --permission
+(kind:namespace:resource:action) or read from a YAML file:
+
+  permissions:
+    - kind: project
+      namespace: myproject
+      access:
+        - resource: repository
+          action: pull
+
+The generated secret is printed once. Use --secret-file to write it to a
+file with 0600 permissions instead.`,
+		Example: `  # Project robot that can push and pull
+  hrbcli robot create ci --project myproject --access repository:pull --access repository:push
+
+  # System robot with pull access to all projects, expiring in 90 days
+  hrbcli robot create puller --level system --permission project:*:repository:pull --duration 90
+
+  # Permissions from a file, secret written to disk
+  hrbcli robot create deployer --level system --permissions-file perms.yaml --secret-file ./robot.secret`,
+		Args: requireArgs(1, "requires <name>"),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := args[0]
+
+			if level == "" {
+				if project == "" {
+					return fmt.Errorf("--project or --level system is required")
+				}
+				level = api.RobotLevelProject
+			}
+			if level != api.RobotLevelSystem && level != api.RobotLevelProject {
+				return fmt.Errorf("invalid level: %s (valid: system, project)", level)
+			}
+			if level == api.RobotLevelProject && project == "" {
+				return fmt.Errorf("--project is required for project level robot accounts")
+			}
+
+			perms, err := buildRobotPermissions(level, project, access, permissions, permFile)
+			if err != nil {
+				return err
+			}
+			if len(perms) == 0 {
+				return fmt.Errorf("at least one permission is required (--access, --permission or --permissions-file)")
+			}
+
+			client, err := api.NewClient()
+			if err != nil {
+				return err
+			}
+			robotSvc := harbor.NewRobotService(client)
+
+			created, err := robotSvc.Create(cmd.Context(), &api.RobotCreate{
+				Name:        name,
+				Description: description,
+				Level:       level,
+				Duration:    duration,
+				Permissions: perms,
+			})
+			if err != nil {
+				if apiErr, ok := err.(*api.APIError); ok && apiErr.IsConflict() {
+					return fmt.Errorf("robot account '%s' already exists", name)
+				}
+				return fmt.Errorf("failed to create robot account: %w", err)
+			}
+
+			if secretFile != "" {
+				if err := writeSecretFile(secretFile, created.Secret); err != nil {
+					return fmt.Errorf("failed to write secret: %w", err)
+				}
+				created.Secret = ""
+			}
+
+			switch output.GetFormat() {
+			case "json":
+				return output.JSON(created)
+			case "yaml":
+				return output.YAML(created)
+			default:
+				output.Success("Robot account '%s' created (ID: %d)", created.Name, created.ID)
+				if secretFile != "" {
+					output.Success("Secret written to %s", secretFile)
+				} else {
+					fmt.Printf("Secret: %s\n", created.Secret)
+					output.Warning("Store the secret securely; it cannot be retrieved again.")
+				}
+				return nil
+			}
+		},
+	}
+
+	cmd.Flags().StringVar(&project, "project", "", "Project for a project level robot account")
+	cmd.Flags().StringVar(&level, "level", "", "Robot level (system|project)")
+	cmd.Flags().StringVar(&description, "description", "", "Robot account description")
+	cmd.Flags().Int64Var(&duration, "duration", -1, "Expiration in days (-1 never expires)")
+	cmd.Flags().StringArrayVar(&access, "access", nil, "Access as resource:action (repeatable)")
+	cmd.Flags().StringArrayVar(&permissions, "permission", nil, "Permission as kind:namespace:resource:action (repeatable)")
+	cmd.Flags().StringVar(&permFile, "permissions-file", "", "YAML file with permissions")
+	cmd.Flags().StringVar(&secretFile, "secret-file", "", "Write the generated secret to this file (mode 0600)")
+	return cmd
+}
+
+func newRobotUpdateCmd() *cobra.Command {
+	var (
+		description string
+		disable     bool
+		duration    int64
+		access      []string
+		permissions []string
+		permFile    string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "update <id>",
+		Short: "Update a robot account",
+		Long: `Update a robot account. Permission flags replace the existing
+permissions of the robot account.`,
+		Example: `  # Disable a robot account
+  hrbcli robot update 12 --disable
+
+  # Extend expiry and replace permissions
+  hrbcli robot update 12 --duration 30 --access repository:pull`,
+		Args: requireArgs(1, "requires <id>"),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id, err := strconv.ParseInt(args[0], 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid robot ID: %s", args[0])
+			}
+
+			client, err := api.NewClient()
+			if err != nil {
+				return err
+			}
+			robotSvc := harbor.NewRobotService(client)
+
+			robot, err := robotSvc.Get(cmd.Context(), id)
+			if err != nil {
+				return fmt.Errorf("failed to get robot account: %w", err)
+			}
+
+			if cmd.Flags().Changed("description") {
+				robot.Description = description
+			}
+			if cmd.Flags().Changed("disable") {
+				robot.Disable = disable
+			}
+			if cmd.Flags().Changed("duration") {
+				robot.Duration = duration
+			}
+
+			if len(access) > 0 || len(permissions) > 0 || permFile != "" {
+				project := ""
+				if robot.Level == api.RobotLevelProject && len(robot.Permissions) > 0 {
+					project = robot.Permissions[0].Namespace
+				}
+				perms, err := buildRobotPermissions(robot.Level, project, access, permissions, permFile)
+				if err != nil {
+					return err
+				}
+				robot.Permissions = perms
+			}
+
+			if err := robotSvc.Update(cmd.Context(), id, robot); err != nil {
+				return fmt.Errorf("failed to update robot account: %w", err)
+			}
+			output.Success("Robot account %d updated", id)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&description, "description", "", "New description")
+	cmd.Flags().BoolVar(&disable, "disable", false, "Disable (or --disable=false to enable) the robot account")
+	cmd.Flags().Int64Var(&duration, "duration", 0, "Expiration in days (-1 never expires)")
+	cmd.Flags().StringArrayVar(&access, "access", nil, "Access as resource:action (repeatable)")
+	cmd.Flags().StringArrayVar(&permissions, "permission", nil, "Permission as kind:namespace:resource:action (repeatable)")
+	cmd.Flags().StringVar(&permFile, "permissions-file", "", "YAML file with permissions")
+	return cmd
+}
+
+func newRobotDeleteCmd() *cobra.Command {
+	var force bool
+
+	cmd := &cobra.Command{
+		Use:   "delete <id>",
+		Short: "Delete a robot account",
+		Args:  requireArgs(1, "requires <id>"),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id, err := strconv.ParseInt(args[0], 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid robot ID: %s", args[0])
+			}
+			client, err := api.NewClient()
+			if err != nil {
+				return err
+			}
+			robotSvc := harbor.NewRobotService(client)
+
+			if !force {
+				prompt := promptui.Prompt{
+					Label:     fmt.Sprintf("Delete robot account %d", id),
+					IsConfirm: true,
+				}
+				result, err := prompt.Run()
+				if err != nil || strings.ToLower(result) != "y" {
+					output.Info("Deletion cancelled")
+					return nil
+				}
+			}
+
+			if err := robotSvc.Delete(cmd.Context(), id); err != nil {
+				return fmt.Errorf("failed to delete robot account: %w", err)
+			}
+			output.Success("Robot account %d deleted", id)
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&force, "force", false, "Force deletion without confirmation")
+	return cmd
+}
+
+func newRobotRefreshSecretCmd() *cobra.Command {
+	var (
+		secret     string
+		secretFile string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "refresh-secret <id>",
+		Short: "Refresh the secret of a robot account",
+		Long: `Refresh the secret of a robot account. Without --secret Harbor
+generates a new secret, which is printed once or written to --secret-file.`,
+		Args: requireArgs(1, "requires <id>"),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id, err := strconv.ParseInt(args[0], 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid robot ID: %s", args[0])
+			}
+			client, err := api.NewClient()
+			if err != nil {
+				return err
+			}
+			robotSvc := harbor.NewRobotService(client)
+
+			sec, err := robotSvc.RefreshSecret(cmd.Context(), id, secret)
+			if err != nil {
+				return fmt.Errorf("failed to refresh secret: %w", err)
+			}
+
+			output.Success("Secret of robot account %d refreshed", id)
+			if secret != "" || sec.Secret == "" {
+				return nil
+			}
+			if secretFile != "" {
+				if err := writeSecretFile(secretFile, sec.Secret); err != nil {
+					return fmt.Errorf("failed to write secret: %w", err)
+				}
+				output.Success("Secret written to %s", secretFile)
+				return nil
+			}
+			fmt.Printf("Secret: %s\n", sec.Secret)
+			output.Warning("Store the secret securely; it cannot be retrieved again.")
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&secret, "secret", "", "Set this secret instead of generating one")
+	cmd.Flags().StringVar(&secretFile, "secret-file", "", "Write the generated secret to this file (mode 0600)")
+	return cmd
+}
+
+// buildRobotPermissions assembles robot permissions from --access,
+// --permission and --permissions-file values. Accesses for the same
+// kind and namespace are merged into a single permission.
+func buildRobotPermissions(level, project string, access, permissions []string, file string) ([]*api.RobotPermission, error) {
+	var perms []*api.RobotPermission
+
+	add := func(kind, namespace string, accesses ...*api.RobotAccess) {
+		for _, p := range perms {
+			if p.Kind == kind && p.Namespace == namespace {
+				p.Access = append(p.Access, accesses...)
+				return
+			}
+		}
+		perms = append(perms, &api.RobotPermission{Kind: kind, Namespace: namespace, Access: accesses})
+	}
+
+	if file != "" {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read permissions file: %w", err)
+		}
+		var spec struct {
+			Permissions []*api.RobotPermission `yaml:"permissions"`
+		}
+		if err := yaml.Unmarshal(data, &spec); err != nil {
+			return nil, fmt.Errorf("failed to parse permissions file: %w", err)
+		}
+		for _, p := range spec.Permissions {
+			add(p.Kind, p.Namespace, p.Access...)
+		}
+	}
+
+	for _, p := range permissions {
+		parts := strings.SplitN(p, ":", 4)
+		if len(parts) != 4 || parts[0] == "" || parts[1] == "" || parts[2] == "" || parts[3] == "" {
+			return nil, fmt.Errorf("invalid permission %q (expected kind:namespace:resource:action)", p)
+		}
+		add(parts[0], parts[1], &api.RobotAccess{Resource: parts[2], Action: parts[3]})
+	}
+
+	if len(access) > 0 {
+		kind, namespace := api.RobotLevelSystem, "/"
+		if level == api.RobotLevelProject {
+			kind, namespace = api.RobotLevelProject, project
+		}
+		for _, a := range access {
+			parts := strings.SplitN(a, ":", 2)
+			if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+				return nil, fmt.Errorf("invalid access %q (expected resource:action)", a)
+			}
+			add(kind, namespace, &api.RobotAccess{Resource: parts[0], Action: parts[1]})
+		}
+	}
+
+	return perms, nil
+}
+
+// formatRobotExpiry renders a robot expiry timestamp for display
+func formatRobotExpiry(expiresAt int64) string {
+	if expiresAt <= 0 {
+		return "never"
+	}
+	return time.Unix(expiresAt, 0).Format("2006-01-02")
+}
+
+// writeSecretFile writes a secret to path, readable only by the owner
+func writeSecretFile(path, secret string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	// OpenFile keeps the mode of an existing file; restrict it before
+	// the secret is written
+	if err := f.Chmod(0600); err != nil {
+		return err
+	}
+	if _, err := f.WriteString(secret + "\n"); err != nil {
+		return err
+	}
+	return f.Close()
+}
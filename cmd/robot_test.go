@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestBuildRobotPermissions(t *testing.T) {
+	perms, err := buildRobotPermissions("project", "myproj",
+		[]string{"repository:pull", "repository:push"},
+		[]string{"project:other:artifact:read"}, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(perms) != 2 {
+		t.Fatalf("expected 2 permissions, got %d", len(perms))
+	}
+	if perms[0].Kind != "project" || perms[0].Namespace != "other" || len(perms[0].Access) != 1 {
+		t.Errorf("unexpected permission: %+v", perms[0])
+	}
+	if perms[1].Namespace != "myproj" || len(perms[1].Access) != 2 {
+		t.Errorf("accesses not merged: %+v", perms[1])
+	}
+
+	sys, err := buildRobotPermissions("system", "", []string{"project:create"}, nil, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sys[0].Kind != "system" || sys[0].Namespace != "/" {
+		t.Errorf("unexpected system permission: %+v", sys[0])
+	}
+
+	if _, err := buildRobotPermissions("project", "p", []string{"pull"}, nil, ""); err == nil {
+		t.Error("expected error for invalid access")
+	}
+	if _, err := buildRobotPermissions("project", "p", nil, []string{"project:p:pull"}, ""); err == nil {
+		t.Error("expected error for invalid permission")
+	}
+}
+
+func TestBuildRobotPermissionsFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "perms.yaml")
+	data := `permissions:
+  - kind: project
+    namespace: "*"
+    access:
+      - resource: repository
+        action: pull
+`
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	perms, err := buildRobotPermissions("system", "", nil, []string{"project:*:repository:push"}, path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(perms) != 1 || len(perms[0].Access) != 2 {
+		t.Fatalf("unexpected permissions: %+v", perms)
+	}
+}
+
+func TestWriteSecretFileMode(t *testing.T) {
+	dir := t.TempDir()
+	existing := filepath.Join(dir, "robot.secret")
+	if err := os.WriteFile(existing, []byte("old secret"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{existing, filepath.Join(dir, "new", "robot.secret")} {
+		if err := writeSecretFile(path, "s3cr3t"); err != nil {
+			t.Fatalf("writeSecretFile error: %v", err)
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Mode().Perm() != 0600 {
+			t.Errorf("%s: unexpected mode: %v", path, info.Mode().Perm())
+		}
+		if data, _ := os.ReadFile(path); string(data) != "s3cr3t\n" {
+			t.Errorf("%s: unexpected content %q", path, data)
+		}
+	}
+}
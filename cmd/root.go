@@ -1,13 +1,18 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
+	"os/signal"
 	"path/filepath"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 
+	"github.com/pascal71/hrbcli/pkg/api"
+	"github.com/pascal71/hrbcli/pkg/config"
 	"github.com/pascal71/hrbcli/pkg/output"
 )
 
@@ -16,6 +21,9 @@ var (
 	outputFormat string
 	debug        bool
 	noColor      bool
+
+	// cancelTimeout releases the --timeout deadline once the command ends
+	cancelTimeout context.CancelFunc = func() {}
 )
 
 var rootCmd = &cobra.Command{
@@ -41,13 +49,25 @@ Examples:
 	SilenceUsage:  true,
 	SilenceErrors: true,
 	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		// Bound the whole command, including retries, by --timeout
+		if timeout := viper.GetDuration("timeout"); timeout > 0 {
+			ctx, cancel := context.WithTimeout(cmd.Context(), timeout)
+			cmd.SetContext(ctx)
+			cancelTimeout = cancel
+		}
+
 		// Skip validation for config and completion commands
-		if cmd.Name() == "config" || cmd.Name() == "completion" || cmd.Parent().Name() == "config" {
+		if cmd.Name() == "config" || cmd.Name() == "completion" || cmd.Parent().Name() == "config" ||
+			cmd.Name() == "login" || cmd.Name() == "logout" {
 			return nil
 		}
 
-		// Validate required configuration
-		if viper.GetString("harbor_url") == "" {
+		// Validate required configuration, resolving the active context
+		cfg, err := config.Load()
+		if err != nil {
+			return err
+		}
+		if cfg.HarborURL == "" {
 			return fmt.Errorf(
 				"Harbor URL not configured. Run 'hrbcli config init' or set HARBOR_URL",
 			)
@@ -57,36 +77,81 @@ Examples:
 	},
 }
 
-// Execute runs the root command
+// Execute runs the root command. Interrupting the process cancels the
+// command context, which aborts any request in flight.
 func Execute() error {
-	return rootCmd.Execute()
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	defer func() { cancelTimeout() }()
+
+	return rootCmd.ExecuteContext(ctx)
+}
+
+// exitError is returned by commands that signal a result through a
+// specific exit code, such as drift detected by diff
+type exitError struct {
+	code int
+	msg  string
+}
+
+func (e *exitError) Error() string {
+	return e.msg
+}
+
+// ExitCode returns the process exit code for an error returned by
+// Execute
+func ExitCode(err error) int {
+	var exitErr *exitError
+	if errors.As(err, &exitErr) {
+		return exitErr.code
+	}
+	return 1
 }
 
 func init() {
 	cobra.OnInitialize(initConfig)
 
+	// Let the encrypted credentials file ask for its passphrase
+	config.PromptPassphrase = promptPassphrase
+
 	// Global flags
 	rootCmd.PersistentFlags().
 		StringVar(&cfgFile, "config", "", "config file (default is $HOME/.hrbcli.yaml)")
+	rootCmd.PersistentFlags().String("context", "", "Named context from the config file to use")
 	rootCmd.PersistentFlags().String("harbor-url", "", "Harbor server URL")
 	rootCmd.PersistentFlags().String("username", "", "Harbor username")
 	rootCmd.PersistentFlags().String("password", "", "Harbor password")
 	rootCmd.PersistentFlags().String("api-version", "v2.0", "Harbor API version")
 	rootCmd.PersistentFlags().Bool("insecure", false, "Skip TLS certificate verification")
+	rootCmd.PersistentFlags().String("ca-cert", "", "PEM file with CA certificates to trust")
+	rootCmd.PersistentFlags().String("client-cert", "", "PEM client certificate for mutual TLS")
+	rootCmd.PersistentFlags().String("client-key", "", "PEM private key of the client certificate")
 	rootCmd.PersistentFlags().
-		StringVarP(&outputFormat, "output", "o", "table", "Output format (table|json|yaml)")
+		StringVarP(&outputFormat, "output", "o", "table", "Output format (table|json|yaml, sarif|junit for vulnerability reports, csv for license reports)")
 	rootCmd.PersistentFlags().BoolVar(&debug, "debug", false, "Enable debug output")
 	rootCmd.PersistentFlags().BoolVar(&noColor, "no-color", false, "Disable colored output")
+	rootCmd.PersistentFlags().Int("max-attempts", api.DefaultMaxAttempts, "Maximum attempts per API request (1 disables retries)")
+	rootCmd.PersistentFlags().Duration("retry-wait-min", api.DefaultRetryWaitMin, "Initial backoff between retries")
+	rootCmd.PersistentFlags().Duration("retry-wait-max", api.DefaultRetryWaitMax, "Maximum backoff between retries, also caps Retry-After")
+	rootCmd.PersistentFlags().Duration("timeout", 0, "Abort the command after this duration, e.g. 30s or 2m (0 for no limit)")
 
 	// Bind flags to viper
+	viper.BindPFlag("context", rootCmd.PersistentFlags().Lookup("context"))
 	viper.BindPFlag("harbor_url", rootCmd.PersistentFlags().Lookup("harbor-url"))
 	viper.BindPFlag("username", rootCmd.PersistentFlags().Lookup("username"))
 	viper.BindPFlag("password", rootCmd.PersistentFlags().Lookup("password"))
 	viper.BindPFlag("api_version", rootCmd.PersistentFlags().Lookup("api-version"))
 	viper.BindPFlag("insecure", rootCmd.PersistentFlags().Lookup("insecure"))
+	viper.BindPFlag("ca_cert", rootCmd.PersistentFlags().Lookup("ca-cert"))
+	viper.BindPFlag("client_cert", rootCmd.PersistentFlags().Lookup("client-cert"))
+	viper.BindPFlag("client_key", rootCmd.PersistentFlags().Lookup("client-key"))
 	viper.BindPFlag("output_format", rootCmd.PersistentFlags().Lookup("output"))
 	viper.BindPFlag("debug", rootCmd.PersistentFlags().Lookup("debug"))
 	viper.BindPFlag("no_color", rootCmd.PersistentFlags().Lookup("no-color"))
+	viper.BindPFlag("max_attempts", rootCmd.PersistentFlags().Lookup("max-attempts"))
+	viper.BindPFlag("retry_wait_min", rootCmd.PersistentFlags().Lookup("retry-wait-min"))
+	viper.BindPFlag("retry_wait_max", rootCmd.PersistentFlags().Lookup("retry-wait-max"))
+	viper.BindPFlag("timeout", rootCmd.PersistentFlags().Lookup("timeout"))
 
 	// Add commands - we'll implement these next
 	rootCmd.AddCommand(NewProjectCmd())
@@ -94,14 +159,22 @@ func init() {
 	rootCmd.AddCommand(NewArtifactCmd())
 	rootCmd.AddCommand(NewLabelCmd())
 	rootCmd.AddCommand(NewUserCmd())
+	rootCmd.AddCommand(NewRobotCmd())
 	rootCmd.AddCommand(NewRepositoryCmd())
 	rootCmd.AddCommand(NewReplicationCmd())
+	rootCmd.AddCommand(NewWebhookCmd())
 	rootCmd.AddCommand(NewDistributionCmd())
 	rootCmd.AddCommand(NewScannerCmd())
 	rootCmd.AddCommand(NewJobServiceCmd())
 	// rootCmd.AddCommand(NewUserCmd())
 	rootCmd.AddCommand(NewSystemCmd())
+	rootCmd.AddCommand(NewApplyCmd())
+	rootCmd.AddCommand(NewDiffCmd())
+	rootCmd.AddCommand(NewExportCmd())
+	rootCmd.AddCommand(NewImportCmd())
 	rootCmd.AddCommand(NewConfigCmd())
+	rootCmd.AddCommand(NewLoginCmd())
+	rootCmd.AddCommand(NewLogoutCmd())
 	rootCmd.AddCommand(NewVersionCmd())
 	rootCmd.AddCommand(NewCompletionCmd())
 }
@@ -125,6 +198,7 @@ func initConfig() {
 	viper.BindEnv("harbor_url", "HARBOR_URL")
 	viper.BindEnv("username", "HARBOR_USERNAME")
 	viper.BindEnv("password", "HARBOR_PASSWORD")
+	viper.BindEnv("context", "HARBOR_CONTEXT")
 	viper.AutomaticEnv()
 
 	// Read config file if it exists
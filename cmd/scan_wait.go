@@ -0,0 +1,224 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pascal71/hrbcli/pkg/api"
+	"github.com/pascal71/hrbcli/pkg/harbor"
+	"github.com/pascal71/hrbcli/pkg/output"
+	"github.com/pascal71/hrbcli/pkg/parallel"
+	"github.com/pascal71/hrbcli/pkg/vuln"
+)
+
+// scanPollInterval is the time between scan status checks
+var scanPollInterval = 2 * time.Second
+
+// Final scan statuses. Harbor reports Success, Error and Stopped;
+// Timeout marks scans still running when the wait was aborted.
+const (
+	scanSuccess = "Success"
+	scanError   = "Error"
+	scanStopped = "Stopped"
+	scanTimeout = "Timeout"
+)
+
+// scanOutcome is the final state of a scan that was waited for
+type scanOutcome struct {
+	Repository string `json:"repository" yaml:"repository"`
+	Reference  string `json:"reference" yaml:"reference"`
+	Digest     string `json:"digest" yaml:"digest"`
+	Status     string `json:"status" yaml:"status"`
+	Message    string `json:"message,omitempty" yaml:"message,omitempty"`
+	Duration   string `json:"duration" yaml:"duration"`
+	Severity   string `json:"severity,omitempty" yaml:"severity,omitempty"`
+	Critical   int    `json:"critical" yaml:"critical"`
+	High       int    `json:"high" yaml:"high"`
+	Medium     int    `json:"medium" yaml:"medium"`
+	Low        int    `json:"low" yaml:"low"`
+	Total      int    `json:"total" yaml:"total"`
+
+	started time.Time
+}
+
+// scanState combines the scan overviews of an artifact into one status.
+// done is false while any scan is still pending or running.
+func scanState(overview map[string]api.NativeReportSummary) (status string, done bool) {
+	if len(overview) == 0 {
+		return "", false
+	}
+	status = scanSuccess
+	for _, ov := range overview {
+		switch strings.ToLower(ov.ScanStatus) {
+		case "success", "finished":
+		case "error":
+			status = scanError
+		case "stopped":
+			if status != scanError {
+				status = scanStopped
+			}
+		default:
+			return ov.ScanStatus, false
+		}
+	}
+	return status, true
+}
+
+// finish records the final status, duration and severities of a scan
+func (o *scanOutcome) finish(status string, overview map[string]api.NativeReportSummary) {
+	o.Status = status
+	var duration time.Duration
+	for _, ov := range overview {
+		d := time.Duration(ov.Duration) * time.Second
+		if d == 0 && !ov.StartTime.IsZero() && ov.EndTime.After(ov.StartTime) {
+			d = ov.EndTime.Sub(ov.StartTime)
+		}
+		if d > duration {
+			duration = d
+		}
+		if o.Severity == "" || vuln.SeverityRank(ov.Severity) > vuln.SeverityRank(o.Severity) {
+			o.Severity = ov.Severity
+		}
+		o.Critical += ov.Summary.Summary["Critical"]
+		o.High += ov.Summary.Summary["High"]
+		o.Medium += ov.Summary.Summary["Medium"]
+		o.Low += ov.Summary.Summary["Low"]
+		o.Total += ov.Summary.Total
+	}
+	if duration == 0 {
+		duration = time.Since(o.started)
+	}
+	o.Duration = duration.Round(time.Second).String()
+}
+
+// waitForScans polls the scan overview of every artifact until each
+// scan succeeded, failed or was stopped. Scans still running when ctx
+// ends, e.g. by --timeout, are reported with status Timeout.
+func waitForScans(ctx context.Context, artSvc *harbor.ArtifactService, project string, arts []repoArtifact, concurrency int) []*scanOutcome {
+	outcomes := make([]*scanOutcome, len(arts))
+	pending := make([]int, len(arts))
+	for i, a := range arts {
+		ref := output.Truncate(a.Digest, 19)
+		if len(a.Tags) > 0 {
+			ref = a.Tags[0].Name
+		}
+		outcomes[i] = &scanOutcome{Repository: a.Repository, Reference: ref, Digest: a.Digest, started: time.Now()}
+		pending[i] = i
+	}
+
+	progress := newProgress("Waiting for scans")
+	progress.Start(len(arts))
+	defer progress.Finish()
+
+	for len(pending) > 0 {
+		results := parallel.Map(ctx, pending, concurrency, nil, func(ctx context.Context, i int) (*api.Artifact, error) {
+			return artSvc.GetWithOptions(ctx, project, arts[i].Repository, arts[i].Digest, &api.ArtifactGetOptions{WithScanOverview: true})
+		})
+
+		var next []int
+		for j, res := range results {
+			i := pending[j]
+			o := outcomes[i]
+			if res.Err != nil {
+				if ctx.Err() != nil {
+					next = append(next, i)
+					continue
+				}
+				o.Status = scanError
+				o.Message = fmt.Sprintf("failed to get scan status: %v", res.Err)
+				o.Duration = time.Since(o.started).Round(time.Second).String()
+				progress.Step(true)
+				continue
+			}
+			status, done := scanState(res.Value.ScanOverview)
+			if !done {
+				next = append(next, i)
+				continue
+			}
+			o.finish(status, res.Value.ScanOverview)
+			progress.Step(status == scanError)
+		}
+
+		pending = next
+		if len(pending) == 0 {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			for _, i := range pending {
+				o := outcomes[i]
+				o.Status = scanTimeout
+				o.Message = ctx.Err().Error()
+				o.Duration = time.Since(o.started).Round(time.Second).String()
+			}
+			return outcomes
+		case <-time.After(scanPollInterval):
+		}
+	}
+	return outcomes
+}
+
+// printScanOutcomes shows the final state of waited scans and returns an
+// error when any scan failed or did not finish
+func printScanOutcomes(outcomes []*scanOutcome) error {
+	counts := map[string]int{}
+	for _, o := range outcomes {
+		counts[o.Status]++
+	}
+
+	var err error
+	switch output.GetFormat() {
+	case "json":
+		err = output.JSON(outcomes)
+	case "yaml":
+		err = output.YAML(outcomes)
+	default:
+		table := output.Table()
+		table.Append([]string{"REPOSITORY", "REFERENCE", "STATUS", "DURATION", "SEVERITY", "CRITICAL", "HIGH", "MEDIUM", "LOW", "TOTAL"})
+		for _, o := range outcomes {
+			status := o.Status
+			switch o.Status {
+			case scanSuccess:
+				status = output.Green(status)
+			case scanError:
+				status = output.Red(status)
+			default:
+				status = output.Yellow(status)
+			}
+			table.Append([]string{
+				o.Repository,
+				o.Reference,
+				status,
+				o.Duration,
+				o.Severity,
+				fmt.Sprintf("%d", o.Critical),
+				fmt.Sprintf("%d", o.High),
+				fmt.Sprintf("%d", o.Medium),
+				fmt.Sprintf("%d", o.Low),
+				fmt.Sprintf("%d", o.Total),
+			})
+		}
+		table.Render()
+
+		fmt.Printf("\n%d succeeded, %d failed, %d stopped, %d timed out\n",
+			counts[scanSuccess], counts[scanError], counts[scanStopped], counts[scanTimeout])
+		for _, o := range outcomes {
+			if o.Message != "" {
+				output.Warning("%s/%s: %s", o.Repository, o.Reference, o.Message)
+			}
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case counts[scanError] > 0:
+		return fmt.Errorf("%d of %d scans failed", counts[scanError], len(outcomes))
+	case counts[scanTimeout] > 0:
+		return fmt.Errorf("%d of %d scans did not finish", counts[scanTimeout], len(outcomes))
+	}
+	return nil
+}
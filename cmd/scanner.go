@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -8,10 +9,12 @@ import (
 	"strings"
 
 	"github.com/spf13/cobra"
+	"golang.org/x/term"
 
 	"github.com/pascal71/hrbcli/pkg/api"
 	"github.com/pascal71/hrbcli/pkg/harbor"
 	"github.com/pascal71/hrbcli/pkg/output"
+	"github.com/pascal71/hrbcli/pkg/parallel"
 )
 
 // NewScannerCmd creates the scanner command
@@ -19,6 +22,7 @@ func NewScannerCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "scanner",
 		Short: "Scanner related commands",
+		Long:  `Manage scanner registrations, trigger scans and read scan reports.`,
 	}
 
 	cmd.AddCommand(newScannerRunningCmd())
@@ -27,10 +31,25 @@ func NewScannerCmd() *cobra.Command {
 
 	cmd.AddCommand(newScannerReportsCmd())
 
+	cmd.AddCommand(newScannerLicensesCmd())
+
+	cmd.AddCommand(newScannerScanAllCmd())
+
+	cmd.AddCommand(newScannerListCmd())
+	cmd.AddCommand(newScannerGetCmd())
+	cmd.AddCommand(newScannerCreateCmd())
+	cmd.AddCommand(newScannerUpdateCmd())
+	cmd.AddCommand(newScannerDeleteCmd())
+	cmd.AddCommand(newScannerSetDefaultCmd())
+	cmd.AddCommand(newScannerPingCmd())
+	cmd.AddCommand(newScannerMetadataCmd())
+
 	return cmd
 }
 
 func newScannerRunningCmd() *cobra.Command {
+	var concurrency int
+
 	cmd := &cobra.Command{
 		Use:   "running <project>[/<repository>]",
 		Short: "Show running scans",
@@ -46,22 +65,15 @@ func newScannerRunningCmd() *cobra.Command {
 				return err
 			}
 
-			repoSvc := harbor.NewRepositoryService(client)
 			artSvc := harbor.NewArtifactService(client)
 
-			var repos []string
-			if repo != "" {
-				repos = []string{repo}
-			} else {
-				list, err := repoSvc.List(project, nil)
-				if err != nil {
-					return fmt.Errorf("failed to list repositories: %w", err)
-				}
-				for _, r := range list {
-					repos = append(repos, strings.TrimPrefix(r.Name, project+"/"))
-				}
+			repos, err := resolveRepositories(cmd.Context(), client, project, repo)
+			if err != nil {
+				return err
 			}
 
+			arts, errs := listRepoArtifacts(cmd.Context(), artSvc, project, repos, &api.ArtifactListOptions{WithTag: true, WithScanOverview: true}, concurrency)
+
 			type entry struct {
 				Repository string `json:"repository"`
 				Digest     string `json:"digest"`
@@ -70,42 +82,36 @@ func newScannerRunningCmd() *cobra.Command {
 			}
 			var running []entry
 
-			for _, r := range repos {
-				arts, err := artSvc.List(project, r, &api.ArtifactListOptions{WithTag: true, WithScanOverview: true})
-				if err != nil {
-					return fmt.Errorf("failed to list artifacts for %s: %w", r, err)
+			for _, a := range arts {
+				status := ""
+				for _, ov := range a.ScanOverview {
+					status = ov.ScanStatus
+					break
 				}
-				for _, a := range arts {
-					status := ""
-					for _, ov := range a.ScanOverview {
-						status = ov.ScanStatus
-						break
-					}
-					if status != "" && strings.ToLower(status) != "success" && strings.ToLower(status) != "finished" {
-						tags := make([]string, len(a.Tags))
-						for i, t := range a.Tags {
-							tags[i] = t.Name
-						}
-						running = append(running, entry{
-							Repository: r,
-							Digest:     output.Truncate(a.Digest, 13),
-							Tags:       strings.Join(tags, ","),
-							Status:     status,
-						})
+				if status != "" && strings.ToLower(status) != "success" && strings.ToLower(status) != "finished" {
+					tags := make([]string, len(a.Tags))
+					for i, t := range a.Tags {
+						tags[i] = t.Name
 					}
+					running = append(running, entry{
+						Repository: a.Repository,
+						Digest:     output.Truncate(a.Digest, 13),
+						Tags:       strings.Join(tags, ","),
+						Status:     status,
+					})
 				}
 			}
 
 			if len(running) == 0 {
 				output.Info("No running scans")
-				return nil
+				return errs.Err()
 			}
 
 			switch output.GetFormat() {
 			case "json":
-				return output.JSON(running)
+				err = output.JSON(running)
 			case "yaml":
-				return output.YAML(running)
+				err = output.YAML(running)
 			default:
 				table := output.Table()
 				table.Append([]string{"REPOSITORY", "DIGEST", "TAGS", "STATUS"})
@@ -113,66 +119,74 @@ func newScannerRunningCmd() *cobra.Command {
 					table.Append([]string{e.Repository, e.Digest, e.Tags, e.Status})
 				}
 				table.Render()
-				return nil
 			}
+			if err != nil {
+				return err
+			}
+			return errs.Err()
 		},
 	}
 
+	addConcurrencyFlag(cmd, &concurrency)
+
 	return cmd
 }
 
 func newScannerScanCmd() *cobra.Command {
-	var scanType string
+	var (
+		scanType    string
+		wait        bool
+		concurrency int
+	)
 
 	cmd := &cobra.Command{
 		Use:   "scan <project>[/<repository>]",
 		Short: "Trigger scan for artifacts",
-		Args:  requireArgs(1, "requires <project>[/<repository>]"),
+		Long: `Trigger vulnerability scans for all artifacts in a project or repository.
+
+With --wait the command polls every triggered scan until it succeeded,
+failed or was stopped, then prints the duration and severities of each
+scan. It exits non-zero when a scan failed or did not finish within
+--timeout.`,
+		Example: `  hrbcli scanner scan myproject
+  hrbcli scanner scan myproject/myrepo --wait --timeout 30m`,
+		Args: requireArgs(1, "requires <project>[/<repository>]"),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			project, repo, err := parseProjectRepo(args[0])
 			if err != nil {
 				return err
 			}
+			if err := checkScanWait(wait, scanType); err != nil {
+				return err
+			}
 
 			client, err := api.NewClient()
 			if err != nil {
 				return err
 			}
 
-			repoSvc := harbor.NewRepositoryService(client)
 			artSvc := harbor.NewArtifactService(client)
 
-			var repos []string
-			if repo != "" {
-				repos = []string{repo}
-			} else {
-				list, err := repoSvc.List(project, nil)
-				if err != nil {
-					return fmt.Errorf("failed to list repositories: %w", err)
-				}
-				for _, r := range list {
-					repos = append(repos, strings.TrimPrefix(r.Name, project+"/"))
-				}
+			repos, err := resolveRepositories(cmd.Context(), client, project, repo)
+			if err != nil {
+				return err
 			}
 
-			for _, r := range repos {
-				arts, err := artSvc.List(project, r, nil)
-				if err != nil {
-					return fmt.Errorf("failed to list artifacts for %s: %w", r, err)
-				}
-				for _, a := range arts {
-					if err := artSvc.Scan(project, r, a.Digest, scanType); err != nil {
-						output.Warning("Failed to scan %s/%s@%s: %v", project, r, output.Truncate(a.Digest, 13), err)
-					} else {
-						output.Success("Scan triggered for %s/%s@%s", project, r, output.Truncate(a.Digest, 13))
-					}
+			arts, errs := listRepoArtifacts(cmd.Context(), artSvc, project, repos, nil, concurrency)
+			triggered, scanErrs := scanArtifacts(cmd.Context(), artSvc, project, arts, scanType, concurrency)
+			errs = append(errs, scanErrs...)
+
+			if wait && len(triggered) > 0 {
+				if err := printScanOutcomes(waitForScans(cmd.Context(), artSvc, project, triggered, concurrency)); err != nil {
+					errs = append(errs, err)
 				}
 			}
-
-			return nil
+			return errs.Err()
 		},
 	}
 
+	cmd.Flags().BoolVar(&wait, "wait", false, "Wait for the scans to finish and summarize their outcome")
+	addConcurrencyFlag(cmd, &concurrency)
 	cmd.Flags().StringVar(&scanType, "scan-type", "", "Scan type (vulnerability|sbom)")
 
 	return cmd
@@ -184,6 +198,7 @@ func newScannerReportsCmd() *cobra.Command {
 	var outputDir string
 	var sortBy string
 	var reverse bool
+	var concurrency int
 
 	cmd := &cobra.Command{
 		Use:   "reports <project>[/<repository>]",
@@ -194,13 +209,16 @@ func newScannerReportsCmd() *cobra.Command {
 			if err != nil {
 				return err
 			}
+			format := output.GetFormat()
+			if ciFormat(format) && (summary || strings.ToLower(reportType) == "sbom") {
+				return fmt.Errorf("-o %s is only supported for vulnerability reports", format)
+			}
 
 			client, err := api.NewClient()
 			if err != nil {
 				return err
 			}
 
-			repoSvc := harbor.NewRepositoryService(client)
 			artSvc := harbor.NewArtifactService(client)
 
 			if outputDir != "" {
@@ -209,17 +227,9 @@ func newScannerReportsCmd() *cobra.Command {
 				}
 			}
 
-			var repos []string
-			if repo != "" {
-				repos = []string{repo}
-			} else {
-				list, err := repoSvc.List(project, nil)
-				if err != nil {
-					return fmt.Errorf("failed to list repositories: %w", err)
-				}
-				for _, r := range list {
-					repos = append(repos, strings.TrimPrefix(r.Name, project+"/"))
-				}
+			repos, err := resolveRepositories(cmd.Context(), client, project, repo)
+			if err != nil {
+				return err
 			}
 
 			type entry struct {
@@ -235,118 +245,152 @@ func newScannerReportsCmd() *cobra.Command {
 			}
 			var reports []entry
 
-			for _, r := range repos {
-				arts, err := artSvc.List(project, r, &api.ArtifactListOptions{WithTag: true, WithScanOverview: summary})
-				if err != nil {
-					return fmt.Errorf("failed to list artifacts for %s: %w", r, err)
+			arts, errs := listRepoArtifacts(cmd.Context(), artSvc, project, repos, &api.ArtifactListOptions{WithTag: true, WithScanOverview: summary}, concurrency)
+
+			// Fetch the reports concurrently, then handle them in order
+			fetched := make([]interface{}, len(arts))
+			if summary {
+				for i, a := range arts {
+					fetched[i] = a.ScanOverview
 				}
-				for _, a := range arts {
-					ref := a.Digest
-					if len(a.Tags) > 0 {
-						ref = a.Tags[0].Name
+			} else {
+				results := parallel.Map(cmd.Context(), arts, concurrency, newProgress("Fetching reports"), func(ctx context.Context, a repoArtifact) (interface{}, error) {
+					if strings.ToLower(reportType) == "sbom" {
+						report, err := artSvc.SBOM(ctx, project, a.Repository, a.Digest)
+						if err != nil {
+							return nil, fmt.Errorf("failed to get SBOM for %s/%s@%s: %w", project, a.Repository, output.Truncate(a.Digest, 13), err)
+						}
+						if report == nil {
+							return nil, nil
+						}
+						return report.Document(report.Format)
+					}
+					report, err := artSvc.Vulnerabilities(ctx, project, a.Repository, a.Digest)
+					if err != nil {
+						return nil, fmt.Errorf("failed to get vulnerabilities for %s/%s@%s: %w", project, a.Repository, output.Truncate(a.Digest, 13), err)
 					}
+					return report, nil
+				})
+				for i, res := range results {
+					fetched[i] = res.Value
+				}
+				errs = append(errs, parallel.Failures(results)...)
+			}
 
-					if summary {
-						if outputDir != "" {
-							ext := "json"
-							if output.GetFormat() == "yaml" {
-								ext = "yaml"
-							}
-							name := fmt.Sprintf("%s_%s_summary.%s", strings.ReplaceAll(r, "/", "_"), strings.ReplaceAll(strings.ReplaceAll(ref, ":", "_"), "/", "_"), ext)
-							path := filepath.Join(outputDir, name)
-							if err := output.WriteFile(path, ext, a.ScanOverview); err != nil {
-								return fmt.Errorf("failed to write report: %w", err)
-							}
-							output.Success("Saved report to %s", path)
-						} else {
-							c := 0
-							crit := 0
-							high := 0
-							med := 0
-							low := 0
-							for _, ov := range a.ScanOverview {
-								c += ov.Summary.Total
-								crit += ov.Summary.Summary["Critical"]
-								high += ov.Summary.Summary["High"]
-								med += ov.Summary.Summary["Medium"]
-								low += ov.Summary.Summary["Low"]
-							}
-							reports = append(reports, entry{Repository: r, Reference: ref, Report: a.ScanOverview, Count: c, Critical: crit, High: high, Medium: med, Low: low, Total: c})
+			for i, a := range arts {
+				if fetched[i] == nil {
+					continue
+				}
+				r := a.Repository
+				ref := a.Digest
+				if len(a.Tags) > 0 {
+					ref = a.Tags[0].Name
+				}
+
+				if summary {
+					if outputDir != "" {
+						ext := "json"
+						if output.GetFormat() == "yaml" {
+							ext = "yaml"
+						}
+						name := fmt.Sprintf("%s_%s_summary.%s", strings.ReplaceAll(r, "/", "_"), strings.ReplaceAll(strings.ReplaceAll(ref, ":", "_"), "/", "_"), ext)
+						path := filepath.Join(outputDir, name)
+						if err := output.WriteFile(path, ext, a.ScanOverview); err != nil {
+							return fmt.Errorf("failed to write report: %w", err)
 						}
-						continue
+						output.Success("Saved report to %s", path)
+					} else {
+						c := 0
+						crit := 0
+						high := 0
+						med := 0
+						low := 0
+						for _, ov := range a.ScanOverview {
+							c += ov.Summary.Total
+							crit += ov.Summary.Summary["Critical"]
+							high += ov.Summary.Summary["High"]
+							med += ov.Summary.Summary["Medium"]
+							low += ov.Summary.Summary["Low"]
+						}
+						reports = append(reports, entry{Repository: r, Reference: ref, Report: a.ScanOverview, Count: c, Critical: crit, High: high, Medium: med, Low: low, Total: c})
 					}
+					continue
+				}
 
-					if strings.ToLower(reportType) == "sbom" {
-						report, err := artSvc.SBOM(project, r, a.Digest)
-						if err != nil {
-							output.Warning("Failed to get SBOM for %s/%s@%s: %v", project, r, output.Truncate(a.Digest, 13), err)
-							continue
+				if report, ok := fetched[i].(*api.VulnerabilityReport); ok {
+					if outputDir != "" {
+						ext := "json"
+						switch format {
+						case "yaml":
+							ext = "yaml"
+						case "sarif":
+							ext = "sarif"
+						case "junit":
+							ext = "xml"
 						}
-						if outputDir != "" {
-							ext := "json"
-							if output.GetFormat() == "yaml" {
-								ext = "yaml"
+						name := fmt.Sprintf("%s_%s_vuln.%s", strings.ReplaceAll(r, "/", "_"), strings.ReplaceAll(strings.ReplaceAll(ref, ":", "_"), "/", "_"), ext)
+						path := filepath.Join(outputDir, name)
+						if ciFormat(format) {
+							results := []output.ScanResult{scanResult(artifactName(project, r, ref), report.Vulnerabilities)}
+							if err := writeScanResults(path, format, results); err != nil {
+								return err
 							}
-							name := fmt.Sprintf("%s_%s_sbom.%s", strings.ReplaceAll(r, "/", "_"), strings.ReplaceAll(strings.ReplaceAll(ref, ":", "_"), "/", "_"), ext)
-							path := filepath.Join(outputDir, name)
-							if err := output.WriteFile(path, ext, report); err != nil {
-								return fmt.Errorf("failed to write report: %w", err)
-							}
-							output.Success("Saved report to %s", path)
-						} else {
-							reports = append(reports, entry{Repository: r, Reference: ref, Report: report})
+							continue
+						}
+						if err := output.WriteFile(path, ext, report); err != nil {
+							return fmt.Errorf("failed to write report: %w", err)
 						}
+						output.Success("Saved report to %s", path)
 					} else {
-						report, err := artSvc.Vulnerabilities(project, r, a.Digest)
-						if err != nil {
-							output.Warning("Failed to get vulnerabilities for %s/%s@%s: %v", project, r, output.Truncate(a.Digest, 13), err)
-							continue
+						count := len(report.Vulnerabilities)
+						if count == 0 && report.Summary.Total > 0 {
+							count = report.Summary.Total
 						}
-						if outputDir != "" {
-							ext := "json"
-							if output.GetFormat() == "yaml" {
-								ext = "yaml"
-							}
-							name := fmt.Sprintf("%s_%s_vuln.%s", strings.ReplaceAll(r, "/", "_"), strings.ReplaceAll(strings.ReplaceAll(ref, ":", "_"), "/", "_"), ext)
-							path := filepath.Join(outputDir, name)
-							if err := output.WriteFile(path, ext, report); err != nil {
-								return fmt.Errorf("failed to write report: %w", err)
-							}
-							output.Success("Saved report to %s", path)
-						} else {
-							count := len(report.Vulnerabilities)
-							if count == 0 && report.Summary.Total > 0 {
-								count = report.Summary.Total
-							}
 
-							crit := report.Summary.Summary["Critical"]
-							high := report.Summary.Summary["High"]
-							med := report.Summary.Summary["Medium"]
-							low := report.Summary.Summary["Low"]
-
-							reports = append(reports, entry{
-								Repository: r,
-								Reference:  ref,
-								Report:     report,
-								Count:      count,
-								Critical:   crit,
-								High:       high,
-								Medium:     med,
-								Low:        low,
-								Total:      report.Summary.Total,
-							})
-						}
+						crit := report.Summary.Summary["Critical"]
+						high := report.Summary.Summary["High"]
+						med := report.Summary.Summary["Medium"]
+						low := report.Summary.Summary["Low"]
+
+						reports = append(reports, entry{
+							Repository: r,
+							Reference:  ref,
+							Report:     report,
+							Count:      count,
+							Critical:   crit,
+							High:       high,
+							Medium:     med,
+							Low:        low,
+							Total:      report.Summary.Total,
+						})
 					}
+					continue
+				}
+
+				doc := fetched[i]
+				if outputDir != "" {
+					ext := "json"
+					if output.GetFormat() == "yaml" {
+						ext = "yaml"
+					}
+					name := fmt.Sprintf("%s_%s_sbom.%s", strings.ReplaceAll(r, "/", "_"), strings.ReplaceAll(strings.ReplaceAll(ref, ":", "_"), "/", "_"), ext)
+					path := filepath.Join(outputDir, name)
+					if err := output.WriteFile(path, ext, doc); err != nil {
+						return fmt.Errorf("failed to write report: %w", err)
+					}
+					output.Success("Saved report to %s", path)
+				} else {
+					reports = append(reports, entry{Repository: r, Reference: ref, Report: doc})
 				}
 			}
 
 			if outputDir != "" {
-				return nil
+				return errs.Err()
 			}
 
-			if len(reports) == 0 {
+			if len(reports) == 0 && !ciFormat(format) {
 				output.Info("No reports found")
-				return nil
+				return errs.Err()
 			}
 
 			sort.SliceStable(reports, func(i, j int) bool {
@@ -431,11 +475,18 @@ func newScannerReportsCmd() *cobra.Command {
 				}
 			})
 
-			switch output.GetFormat() {
+			switch format {
 			case "json":
-				return output.JSON(reports)
+				err = output.JSON(reports)
 			case "yaml":
-				return output.YAML(reports)
+				err = output.YAML(reports)
+			case "sarif", "junit":
+				var results []output.ScanResult
+				for _, e := range reports {
+					report := e.Report.(*api.VulnerabilityReport)
+					results = append(results, scanResult(artifactName(project, e.Repository, e.Reference), report.Vulnerabilities))
+				}
+				err = writeScanResults("", format, results)
 			default:
 				table := output.Table()
 				if summary {
@@ -487,8 +538,11 @@ func newScannerReportsCmd() *cobra.Command {
 					}
 				}
 				table.Render()
-				return nil
 			}
+			if err != nil {
+				return err
+			}
+			return errs.Err()
 		},
 	}
 
@@ -497,6 +551,78 @@ func newScannerReportsCmd() *cobra.Command {
 	cmd.Flags().StringVar(&outputDir, "output-dir", "", "Directory to save reports")
 	cmd.Flags().StringVar(&sortBy, "sort", "severity", "Sort by field (severity|crit|high|medium|low|total|vuln|repo|ref)")
 	cmd.Flags().BoolVar(&reverse, "reverse", false, "Reverse sort order")
+	addConcurrencyFlag(cmd, &concurrency)
 
 	return cmd
 }
+
+// addConcurrencyFlag adds the flag limiting the number of parallel
+// requests of bulk commands
+func addConcurrencyFlag(cmd *cobra.Command, concurrency *int) {
+	cmd.Flags().IntVar(concurrency, "concurrency", parallel.DefaultConcurrency, "Number of parallel requests")
+}
+
+// newProgress shows the progress of a bulk operation on stderr when it
+// is a terminal
+func newProgress(label string) *parallel.Progress {
+	if !term.IsTerminal(int(os.Stderr.Fd())) {
+		return nil
+	}
+	return parallel.NewProgress(os.Stderr, label)
+}
+
+// repoArtifact is an artifact with the repository it belongs to
+type repoArtifact struct {
+	Repository string
+	*api.Artifact
+}
+
+// listRepoArtifacts lists the artifacts of several repositories
+// concurrently. Artifacts are returned in repository order; repositories
+// that fail to list are reported in the errors.
+func listRepoArtifacts(ctx context.Context, artSvc *harbor.ArtifactService, project string, repos []string, opts *api.ArtifactListOptions, concurrency int) ([]repoArtifact, parallel.Errors) {
+	results := parallel.Map(ctx, repos, concurrency, newProgress("Listing artifacts"), func(ctx context.Context, r string) ([]*api.Artifact, error) {
+		arts, err := artSvc.ListAll(ctx, project, r, opts, 0)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list artifacts for %s: %w", r, err)
+		}
+		return arts, nil
+	})
+
+	var arts []repoArtifact
+	for i, res := range results {
+		for _, a := range res.Value {
+			arts = append(arts, repoArtifact{Repository: repos[i], Artifact: a})
+		}
+	}
+	return arts, parallel.Failures(results)
+}
+
+// scanArtifacts triggers scans concurrently and returns the artifacts
+// whose scan was triggered
+func scanArtifacts(ctx context.Context, artSvc *harbor.ArtifactService, project string, arts []repoArtifact, scanType string, concurrency int) ([]repoArtifact, parallel.Errors) {
+	results := parallel.Map(ctx, arts, concurrency, newProgress("Triggering scans"), func(ctx context.Context, a repoArtifact) (struct{}, error) {
+		if err := artSvc.Scan(ctx, project, a.Repository, a.Digest, scanType); err != nil {
+			return struct{}{}, fmt.Errorf("failed to scan %s/%s@%s: %w", project, a.Repository, output.Truncate(a.Digest, 13), err)
+		}
+		return struct{}{}, nil
+	})
+
+	var triggered []repoArtifact
+	for i, res := range results {
+		if res.Err == nil {
+			output.Success("Scan triggered for %s/%s@%s", project, arts[i].Repository, output.Truncate(arts[i].Digest, 13))
+			triggered = append(triggered, arts[i])
+		}
+	}
+	return triggered, parallel.Failures(results)
+}
+
+// checkScanWait rejects waiting for SBOM generation, whose progress is
+// not part of the scan overview
+func checkScanWait(wait bool, scanType string) error {
+	if wait && strings.EqualFold(scanType, "sbom") {
+		return fmt.Errorf("--wait is only supported for vulnerability scans")
+	}
+	return nil
+}
@@ -0,0 +1,290 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/pascal71/hrbcli/pkg/api"
+	"github.com/pascal71/hrbcli/pkg/harbor"
+	"github.com/pascal71/hrbcli/pkg/output"
+	"github.com/pascal71/hrbcli/pkg/parallel"
+	"github.com/pascal71/hrbcli/pkg/sbom"
+)
+
+// licenseFinding is a component whose license is denied or unknown
+type licenseFinding struct {
+	Component          string `json:"component" yaml:"component"`
+	Version            string `json:"version,omitempty" yaml:"version,omitempty"`
+	PURL               string `json:"purl,omitempty" yaml:"purl,omitempty"`
+	License            string `json:"license,omitempty" yaml:"license,omitempty"`
+	*sbom.LicenseCheck `yaml:",inline"`
+}
+
+// licenseArtifact holds the license findings of one artifact
+type licenseArtifact struct {
+	Repository string           `json:"repository" yaml:"repository"`
+	Reference  string           `json:"reference" yaml:"reference"`
+	Components int              `json:"components" yaml:"components"`
+	Denied     int              `json:"denied" yaml:"denied"`
+	Unknown    int              `json:"unknown" yaml:"unknown"`
+	Findings   []licenseFinding `json:"findings" yaml:"findings"`
+}
+
+// licenseReport aggregates the license check of all artifacts
+type licenseReport struct {
+	Policy      *sbom.LicensePolicy `json:"policy" yaml:"policy"`
+	Scanned     int                 `json:"scanned" yaml:"scanned"`
+	WithoutSBOM int                 `json:"without_sbom" yaml:"without_sbom"`
+	Offending   int                 `json:"offending" yaml:"offending"`
+	// DeniedLicenses counts the denied components per license
+	DeniedLicenses map[string]int     `json:"denied_licenses" yaml:"denied_licenses"`
+	Artifacts      []*licenseArtifact `json:"artifacts" yaml:"artifacts"`
+}
+
+func newScannerLicensesCmd() *cobra.Command {
+	var (
+		allow       []string
+		deny        []string
+		policyFile  string
+		unknown     bool
+		concurrency int
+	)
+
+	cmd := &cobra.Command{
+		Use:   "licenses <project>[/<repository>]",
+		Short: "Check the licenses of artifact SBOMs",
+		Long: `Check the component licenses in the SBOMs of all artifacts of a project or
+repository against an allow and deny list. Entries are SPDX license IDs or
+glob patterns such as "GPL-*", matched case-insensitively. With an allow
+list every other license is denied.
+
+License expressions are evaluated: of "A OR B" one license must be
+acceptable, of "A AND B" both. Lists can be given with flags or in a
+policy file:
+
+  allow:
+    - MIT
+    - Apache-2.0
+    - BSD-*
+  deny:
+    - AGPL-*
+
+Only artifacts with denied licenses are listed, or also those with
+components without license information with --unknown. The command exits
+with code 2 when a denied license is found. Use -o csv for a spreadsheet.`,
+		Example: `  hrbcli scanner licenses myproject --deny 'GPL-*' --deny 'AGPL-*'
+  hrbcli scanner licenses myproject/myrepo --policy licenses.yaml -o csv`,
+		Args: requireArgs(1, "requires <project>[/<repository>]"),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			project, repo, err := parseProjectRepo(args[0])
+			if err != nil {
+				return err
+			}
+
+			policy := &sbom.LicensePolicy{}
+			if policyFile != "" {
+				if policy, err = sbom.LoadLicensePolicy(policyFile); err != nil {
+					return err
+				}
+			}
+			policy.Allow = append(policy.Allow, allow...)
+			policy.Deny = append(policy.Deny, deny...)
+			if len(policy.Allow) == 0 && len(policy.Deny) == 0 {
+				return fmt.Errorf("--allow, --deny or --policy is required")
+			}
+			if err := policy.Validate(); err != nil {
+				return err
+			}
+
+			client, err := api.NewClient()
+			if err != nil {
+				return err
+			}
+
+			artSvc := harbor.NewArtifactService(client)
+
+			repos, err := resolveRepositories(cmd.Context(), client, project, repo)
+			if err != nil {
+				return err
+			}
+
+			arts, errs := listRepoArtifacts(cmd.Context(), artSvc, project, repos, &api.ArtifactListOptions{WithTag: true}, concurrency)
+			results := parallel.Map(cmd.Context(), arts, concurrency, newProgress("Fetching SBOMs"), func(ctx context.Context, a repoArtifact) (*sbom.SBOM, error) {
+				doc, err := artSvc.SBOM(ctx, project, a.Repository, a.Digest)
+				if err != nil {
+					return nil, fmt.Errorf("failed to get SBOM for %s/%s@%s: %w", project, a.Repository, output.Truncate(a.Digest, 13), err)
+				}
+				return doc, nil
+			})
+			errs = append(errs, parallel.Failures(results)...)
+
+			report := &licenseReport{Policy: policy, DeniedLicenses: map[string]int{}, Artifacts: []*licenseArtifact{}}
+			for i, res := range results {
+				if res.Err != nil {
+					continue
+				}
+				if res.Value == nil {
+					report.WithoutSBOM++
+					continue
+				}
+				report.Scanned++
+
+				a := arts[i]
+				ref := a.Digest
+				if len(a.Tags) > 0 {
+					ref = a.Tags[0].Name
+				}
+				la := checkLicenses(policy, res.Value, unknown)
+				la.Repository = a.Repository
+				la.Reference = ref
+				for _, f := range la.Findings {
+					for _, l := range f.Denied {
+						report.DeniedLicenses[l]++
+					}
+				}
+				if la.Denied > 0 {
+					report.Offending++
+				}
+				if len(la.Findings) > 0 {
+					report.Artifacts = append(report.Artifacts, la)
+				}
+			}
+
+			sort.SliceStable(report.Artifacts, func(i, j int) bool {
+				a, b := report.Artifacts[i], report.Artifacts[j]
+				if a.Denied != b.Denied {
+					return a.Denied > b.Denied
+				}
+				if a.Repository != b.Repository {
+					return a.Repository < b.Repository
+				}
+				return a.Reference < b.Reference
+			})
+
+			switch output.GetFormat() {
+			case "json":
+				err = output.JSON(report)
+			case "yaml":
+				err = output.YAML(report)
+			case "csv":
+				err = output.CSV(licenseRows(project, report))
+			default:
+				printLicenseReport(project, report)
+			}
+			if err != nil {
+				return err
+			}
+
+			if report.Offending > 0 {
+				msg := fmt.Sprintf("%d artifacts use denied licenses", report.Offending)
+				// Failed artifacts are not in the report; say so
+				if err := errs.Err(); err != nil {
+					msg += "; the report is incomplete: " + err.Error()
+				}
+				return &exitError{code: 2, msg: msg}
+			}
+			return errs.Err()
+		},
+	}
+
+	cmd.Flags().StringSliceVar(&allow, "allow", nil, "Allowed license IDs or patterns")
+	cmd.Flags().StringSliceVar(&deny, "deny", nil, "Denied license IDs or patterns")
+	cmd.Flags().StringVar(&policyFile, "policy", "", "License policy file with allow and deny lists")
+	cmd.Flags().BoolVar(&unknown, "unknown", false, "Also list components without license information")
+	addConcurrencyFlag(cmd, &concurrency)
+
+	return cmd
+}
+
+// checkLicenses checks every component of an SBOM. Allowed components
+// and, unless unknown is set, components without license are omitted.
+func checkLicenses(policy *sbom.LicensePolicy, doc *sbom.SBOM, unknown bool) *licenseArtifact {
+	la := &licenseArtifact{Components: len(doc.Components), Findings: []licenseFinding{}}
+	for _, c := range doc.Components {
+		check := policy.CheckComponent(c)
+		switch check.Status {
+		case sbom.LicenseAllowed:
+			continue
+		case sbom.LicenseDenied:
+			la.Denied++
+		case sbom.LicenseUnknown:
+			la.Unknown++
+			if !unknown {
+				continue
+			}
+		}
+		la.Findings = append(la.Findings, licenseFinding{
+			Component:    c.Name,
+			Version:      c.Version,
+			PURL:         c.PURL,
+			License:      strings.Join(c.Licenses, ", "),
+			LicenseCheck: check,
+		})
+	}
+	return la
+}
+
+func licenseRows(project string, report *licenseReport) [][]string {
+	rows := [][]string{{"artifact", "component", "version", "purl", "license", "status", "denied"}}
+	for _, a := range report.Artifacts {
+		for _, f := range a.Findings {
+			rows = append(rows, []string{
+				artifactName(project, a.Repository, a.Reference),
+				f.Component,
+				f.Version,
+				f.PURL,
+				f.License,
+				f.Status,
+				strings.Join(f.Denied, " "),
+			})
+		}
+	}
+	return rows
+}
+
+func printLicenseReport(project string, report *licenseReport) {
+	if len(report.Artifacts) > 0 {
+		table := output.Table()
+		table.Append([]string{"ARTIFACT", "COMPONENT", "VERSION", "LICENSE", "STATUS"})
+		for _, a := range report.Artifacts {
+			for _, f := range a.Findings {
+				status := f.Status
+				if f.Status == sbom.LicenseDenied {
+					status = output.Red(status)
+				} else {
+					status = output.Yellow(status)
+				}
+				table.Append([]string{
+					artifactName(project, a.Repository, a.Reference),
+					f.Component,
+					f.Version,
+					output.Truncate(f.License, 40),
+					status,
+				})
+			}
+		}
+		table.Render()
+		fmt.Println()
+	}
+
+	if len(report.DeniedLicenses) > 0 {
+		licenses := make([]string, 0, len(report.DeniedLicenses))
+		for l := range report.DeniedLicenses {
+			licenses = append(licenses, l)
+		}
+		sort.Strings(licenses)
+		for i, l := range licenses {
+			licenses[i] = fmt.Sprintf("%s (%d)", l, report.DeniedLicenses[l])
+		}
+		fmt.Printf("Denied licenses: %s\n", strings.Join(licenses, ", "))
+	}
+	fmt.Printf("%d of %d artifacts use denied licenses", report.Offending, report.Scanned)
+	if report.WithoutSBOM > 0 {
+		fmt.Printf(", %d artifacts without SBOM", report.WithoutSBOM)
+	}
+	fmt.Println()
+}
@@ -0,0 +1,572 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/manifoldco/promptui"
+	"github.com/spf13/cobra"
+
+	"github.com/pascal71/hrbcli/pkg/api"
+	"github.com/pascal71/hrbcli/pkg/harbor"
+	"github.com/pascal71/hrbcli/pkg/output"
+)
+
+// scannerFlags holds the connection flags shared by create, update and ping
+type scannerFlags struct {
+	url             string
+	description     string
+	auth            string
+	credential      string
+	credentialStdin bool
+	skipCertVerify  bool
+	useInternalAddr bool
+	disabled        bool
+}
+
+func (f *scannerFlags) register(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&f.url, "url", "", "Scanner adapter URL")
+	cmd.Flags().StringVar(&f.auth, "auth", "none", "Authentication: none, basic, bearer or api-key")
+	cmd.Flags().StringVar(&f.credential, "credential", "", "Access credential: user:password for basic, otherwise the token or API key")
+	cmd.Flags().BoolVar(&f.credentialStdin, "credential-stdin", false, "Read the access credential from stdin")
+	cmd.Flags().BoolVar(&f.skipCertVerify, "skip-cert-verify", false, "Skip TLS verification of the adapter")
+	cmd.Flags().BoolVar(&f.useInternalAddr, "use-internal-addr", false, "Let the scanner pull artifacts from Harbor's internal address")
+}
+
+// apply copies the flags the user set onto req
+func (f *scannerFlags) apply(cmd *cobra.Command, req *api.ScannerRegistrationReq) error {
+	flags := cmd.Flags()
+	if flags.Changed("url") {
+		req.URL = f.url
+	}
+	if flags.Changed("description") {
+		req.Description = f.description
+	}
+	if flags.Changed("auth") {
+		auth, err := harbor.ParseScannerAuth(f.auth)
+		if err != nil {
+			return err
+		}
+		req.Auth = auth
+		if auth == harbor.ScannerAuthNone {
+			req.AccessCredential = ""
+		}
+	}
+	if flags.Changed("credential") {
+		req.AccessCredential = f.credential
+	}
+	if f.credentialStdin {
+		if flags.Changed("credential") {
+			return fmt.Errorf("--credential and --credential-stdin cannot be used together")
+		}
+		credential, err := readPasswordStdin()
+		if err != nil {
+			return err
+		}
+		req.AccessCredential = credential
+	}
+	if flags.Changed("skip-cert-verify") {
+		req.SkipCertVerify = f.skipCertVerify
+	}
+	if flags.Changed("use-internal-addr") {
+		req.UseInternalAddr = f.useInternalAddr
+	}
+	if flags.Changed("disabled") {
+		req.Disabled = f.disabled
+	}
+	if flags.Changed("auth") && req.Auth != harbor.ScannerAuthNone && req.AccessCredential == "" {
+		return fmt.Errorf("--credential or --credential-stdin is required with --auth %s", f.auth)
+	}
+	return nil
+}
+
+func newScannerListCmd() *cobra.Command {
+	var (
+		query    string
+		page     int
+		pageSize int
+		pages    pageFlags
+	)
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List registered scanners",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := api.NewClient()
+			if err != nil {
+				return err
+			}
+
+			svc := harbor.NewScannerRegistrationService(client)
+			opts := &api.ListOptions{Page: page, PageSize: pageSize, Query: query}
+
+			var scanners []*api.ScannerRegistration
+			if pages.enabled() {
+				opts.Page, opts.PageSize = 0, 0
+				scanners, err = svc.ListAll(cmd.Context(), opts, pages.limit)
+			} else {
+				scanners, err = svc.List(cmd.Context(), opts)
+			}
+			if err != nil {
+				return fmt.Errorf("failed to list scanners: %w", err)
+			}
+
+			if len(scanners) == 0 {
+				output.Info("No scanners found")
+				return nil
+			}
+
+			switch output.GetFormat() {
+			case "json", "yaml":
+				redacted := make([]*api.ScannerRegistration, len(scanners))
+				for i, sc := range scanners {
+					redacted[i] = redactScanner(sc)
+				}
+				if output.GetFormat() == "json" {
+					return output.JSON(redacted)
+				}
+				return output.YAML(redacted)
+			default:
+				table := output.Table()
+				table.Append([]string{"NAME", "UUID", "URL", "DEFAULT", "ENABLED", "ADAPTER", "VERSION"})
+				for _, sc := range scanners {
+					table.Append([]string{
+						sc.Name,
+						sc.UUID,
+						sc.URL,
+						strconv.FormatBool(sc.IsDefault),
+						strconv.FormatBool(!sc.Disabled),
+						sc.Adapter,
+						sc.Version,
+					})
+				}
+				table.Render()
+				return nil
+			}
+		},
+	}
+
+	cmd.Flags().StringVarP(&query, "query", "q", "", "Query filter, e.g. name=~trivy")
+	cmd.Flags().IntVar(&page, "page", 1, "Page number")
+	cmd.Flags().IntVar(&pageSize, "page-size", 20, "Page size")
+	pages.register(cmd)
+	return cmd
+}
+
+func newScannerGetCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "get <scanner>",
+		Short: "Get scanner registration details",
+		Long:  `Get a scanner registration by name or UUID.`,
+		Args:  requireArgs(1, "requires <scanner>"),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := api.NewClient()
+			if err != nil {
+				return err
+			}
+			svc := harbor.NewScannerRegistrationService(client)
+
+			found, err := svc.Find(cmd.Context(), args[0])
+			if err != nil {
+				return err
+			}
+			scanner, err := svc.Get(cmd.Context(), found.UUID)
+			if err != nil {
+				return fmt.Errorf("failed to get scanner: %w", err)
+			}
+			return printScannerRegistration(scanner)
+		},
+	}
+}
+
+// redactScanner returns a copy of sc with the access credential masked
+func redactScanner(sc *api.ScannerRegistration) *api.ScannerRegistration {
+	redacted := *sc
+	if redacted.AccessCredential != "" {
+		redacted.AccessCredential = "********"
+	}
+	return &redacted
+}
+
+// printScannerRegistration shows a scanner registration as FIELD/VALUE table
+func printScannerRegistration(sc *api.ScannerRegistration) error {
+	switch output.GetFormat() {
+	case "json":
+		return output.JSON(redactScanner(sc))
+	case "yaml":
+		return output.YAML(redactScanner(sc))
+	}
+
+	auth := sc.Auth
+	if auth == harbor.ScannerAuthNone {
+		auth = "none"
+	}
+	table := output.Table()
+	table.Append([]string{"FIELD", "VALUE"})
+	table.Append([]string{"NAME", sc.Name})
+	table.Append([]string{"UUID", sc.UUID})
+	table.Append([]string{"URL", sc.URL})
+	table.Append([]string{"DESCRIPTION", sc.Description})
+	table.Append([]string{"DEFAULT", strconv.FormatBool(sc.IsDefault)})
+	table.Append([]string{"ENABLED", strconv.FormatBool(!sc.Disabled)})
+	table.Append([]string{"HEALTH", sc.Health})
+	table.Append([]string{"AUTH", auth})
+	table.Append([]string{"SKIP CERT VERIFY", strconv.FormatBool(sc.SkipCertVerify)})
+	table.Append([]string{"USE INTERNAL ADDR", strconv.FormatBool(sc.UseInternalAddr)})
+	table.Append([]string{"ADAPTER", sc.Adapter})
+	table.Append([]string{"VENDOR", sc.Vendor})
+	table.Append([]string{"VERSION", sc.Version})
+	if len(sc.Capabilities) > 0 {
+		caps := make([]string, 0, len(sc.Capabilities))
+		for name, value := range sc.Capabilities {
+			if enabled, ok := value.(bool); !ok || enabled {
+				caps = append(caps, name)
+			}
+		}
+		sort.Strings(caps)
+		table.Append([]string{"CAPABILITIES", strings.Join(caps, ", ")})
+	}
+	if !sc.CreateTime.IsZero() {
+		table.Append([]string{"CREATED", sc.CreateTime.Local().Format("2006-01-02 15:04:05")})
+	}
+	if !sc.UpdateTime.IsZero() {
+		table.Append([]string{"UPDATED", sc.UpdateTime.Local().Format("2006-01-02 15:04:05")})
+	}
+	table.Render()
+	return nil
+}
+
+func newScannerCreateCmd() *cobra.Command {
+	flags := &scannerFlags{}
+	var makeDefault bool
+
+	cmd := &cobra.Command{
+		Use:   "create <name>",
+		Short: "Register a scanner",
+		Long: `Register a scanner adapter. The adapter is pinged first; a failed
+ping is reported but does not stop the registration.`,
+		Example: `  # Register a Trivy adapter
+  hrbcli scanner create trivy --url http://trivy-adapter:8080
+
+  # Register a commercial scanner with an API key read from a file and
+  # make it the default
+  hrbcli scanner create acme --url https://scanner.example.com \
+    --auth api-key --credential-stdin --default < acme.key`,
+		Args: requireArgs(1, "requires <name>"),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if flags.url == "" {
+				return fmt.Errorf("--url is required")
+			}
+			req := &api.ScannerRegistrationReq{Name: args[0]}
+			if err := flags.apply(cmd, req); err != nil {
+				return err
+			}
+
+			client, err := api.NewClient()
+			if err != nil {
+				return err
+			}
+			svc := harbor.NewScannerRegistrationService(client)
+
+			output.Info("Testing connectivity to scanner...")
+			if err := svc.Ping(cmd.Context(), req); err != nil {
+				output.Warning("Scanner ping failed: %v", err)
+			} else {
+				output.Success("Scanner is reachable")
+			}
+
+			scanner, err := svc.Create(cmd.Context(), req)
+			if err != nil {
+				if apiErr, ok := err.(*api.APIError); ok && apiErr.IsConflict() {
+					return fmt.Errorf("scanner '%s' already exists", req.Name)
+				}
+				return fmt.Errorf("failed to create scanner: %w", err)
+			}
+			output.Success("Scanner '%s' registered (UUID: %s)", scanner.Name, scanner.UUID)
+
+			if makeDefault {
+				if err := svc.SetDefault(cmd.Context(), scanner.UUID); err != nil {
+					return fmt.Errorf("failed to set default scanner: %w", err)
+				}
+				output.Success("Scanner '%s' is now the default", scanner.Name)
+			}
+			return nil
+		},
+	}
+
+	flags.register(cmd)
+	cmd.Flags().StringVar(&flags.description, "description", "", "Scanner description")
+	cmd.Flags().BoolVar(&flags.disabled, "disabled", false, "Register the scanner disabled")
+	cmd.Flags().BoolVar(&makeDefault, "default", false, "Make the scanner the system default")
+	return cmd
+}
+
+func newScannerUpdateCmd() *cobra.Command {
+	flags := &scannerFlags{}
+
+	cmd := &cobra.Command{
+		Use:   "update <scanner>",
+		Short: "Update a scanner registration",
+		Long:  `Update a scanner registration. Only the flags given are changed.`,
+		Example: `  # Rotate the API key of a scanner
+  hrbcli scanner update acme --credential-stdin < acme.key
+
+  # Disable a scanner
+  hrbcli scanner update acme --disabled`,
+		Args: requireArgs(1, "requires <scanner>"),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := api.NewClient()
+			if err != nil {
+				return err
+			}
+			svc := harbor.NewScannerRegistrationService(client)
+
+			found, err := svc.Find(cmd.Context(), args[0])
+			if err != nil {
+				return err
+			}
+			current, err := svc.Get(cmd.Context(), found.UUID)
+			if err != nil {
+				return fmt.Errorf("failed to get scanner: %w", err)
+			}
+
+			req := &api.ScannerRegistrationReq{
+				Name:             current.Name,
+				Description:      current.Description,
+				URL:              current.URL,
+				Auth:             current.Auth,
+				AccessCredential: current.AccessCredential,
+				SkipCertVerify:   current.SkipCertVerify,
+				UseInternalAddr:  current.UseInternalAddr,
+				Disabled:         current.Disabled,
+			}
+			if cmd.Flags().Changed("name") {
+				req.Name, _ = cmd.Flags().GetString("name")
+			}
+			if err := flags.apply(cmd, req); err != nil {
+				return err
+			}
+
+			if err := svc.Update(cmd.Context(), current.UUID, req); err != nil {
+				return fmt.Errorf("failed to update scanner: %w", err)
+			}
+			output.Success("Scanner '%s' updated", req.Name)
+			return nil
+		},
+	}
+
+	flags.register(cmd)
+	cmd.Flags().String("name", "", "New scanner name")
+	cmd.Flags().StringVar(&flags.description, "description", "", "Scanner description")
+	cmd.Flags().BoolVar(&flags.disabled, "disabled", false, "Disable (or with =false enable) the scanner")
+	return cmd
+}
+
+func newScannerDeleteCmd() *cobra.Command {
+	var force bool
+
+	cmd := &cobra.Command{
+		Use:   "delete <scanner>",
+		Short: "Delete a scanner registration",
+		Long:  `Delete a scanner registration. The default scanner cannot be deleted.`,
+		Args:  requireArgs(1, "requires <scanner>"),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := api.NewClient()
+			if err != nil {
+				return err
+			}
+			svc := harbor.NewScannerRegistrationService(client)
+
+			scanner, err := svc.Find(cmd.Context(), args[0])
+			if err != nil {
+				return err
+			}
+
+			if !force {
+				prompt := promptui.Prompt{
+					Label:     fmt.Sprintf("Delete scanner '%s'", scanner.Name),
+					IsConfirm: true,
+				}
+				result, err := prompt.Run()
+				if err != nil || strings.ToLower(result) != "y" {
+					output.Info("Deletion cancelled")
+					return nil
+				}
+			}
+
+			if err := svc.Delete(cmd.Context(), scanner.UUID); err != nil {
+				return fmt.Errorf("failed to delete scanner: %w", err)
+			}
+			output.Success("Scanner '%s' deleted", scanner.Name)
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&force, "force", false, "Force deletion without confirmation")
+	return cmd
+}
+
+func newScannerSetDefaultCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "set-default <scanner>",
+		Short: "Make a scanner the system default",
+		Long:  `Make a scanner the system default. Projects without a scanner of their own use the default scanner.`,
+		Args:  requireArgs(1, "requires <scanner>"),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := api.NewClient()
+			if err != nil {
+				return err
+			}
+			svc := harbor.NewScannerRegistrationService(client)
+
+			scanner, err := svc.Find(cmd.Context(), args[0])
+			if err != nil {
+				return err
+			}
+			if err := svc.SetDefault(cmd.Context(), scanner.UUID); err != nil {
+				return fmt.Errorf("failed to set default scanner: %w", err)
+			}
+			output.Success("Scanner '%s' is now the default", scanner.Name)
+			return nil
+		},
+	}
+}
+
+func newScannerPingCmd() *cobra.Command {
+	flags := &scannerFlags{}
+
+	cmd := &cobra.Command{
+		Use:   "ping [scanner]",
+		Short: "Test scanner connectivity",
+		Long: `Test the connection to a scanner adapter. Give the name or UUID of a
+registered scanner, or the adapter settings with --url.`,
+		Example: `  # Ping a registered scanner
+  hrbcli scanner ping trivy
+
+  # Ping an adapter before registering it
+  hrbcli scanner ping --url https://scanner.example.com --auth bearer --credential-stdin < token`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 && flags.url == "" {
+				return fmt.Errorf("requires <scanner> or --url")
+			}
+
+			client, err := api.NewClient()
+			if err != nil {
+				return err
+			}
+			svc := harbor.NewScannerRegistrationService(client)
+
+			req := &api.ScannerRegistrationReq{Name: "ping-test"}
+			if len(args) == 1 {
+				found, err := svc.Find(cmd.Context(), args[0])
+				if err != nil {
+					return err
+				}
+				scanner, err := svc.Get(cmd.Context(), found.UUID)
+				if err != nil {
+					return fmt.Errorf("failed to get scanner: %w", err)
+				}
+				req.Name = scanner.Name
+				req.URL = scanner.URL
+				req.Auth = scanner.Auth
+				req.AccessCredential = scanner.AccessCredential
+				req.SkipCertVerify = scanner.SkipCertVerify
+				req.UseInternalAddr = scanner.UseInternalAddr
+			}
+			if err := flags.apply(cmd, req); err != nil {
+				return err
+			}
+
+			output.Info("Testing connectivity to %s...", req.URL)
+			if err := svc.Ping(cmd.Context(), req); err != nil {
+				output.Error("Ping failed: %v", err)
+				return err
+			}
+			output.Success("Scanner is reachable!")
+			return nil
+		},
+	}
+
+	flags.register(cmd)
+	return cmd
+}
+
+func newScannerMetadataCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "metadata <scanner>",
+		Short: "Show scanner metadata and capabilities",
+		Long: `Show the scanner behind an adapter, the artifact types it can scan
+and the reports it produces.`,
+		Args: requireArgs(1, "requires <scanner>"),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := api.NewClient()
+			if err != nil {
+				return err
+			}
+			svc := harbor.NewScannerRegistrationService(client)
+
+			scanner, err := svc.Find(cmd.Context(), args[0])
+			if err != nil {
+				return err
+			}
+			metadata, err := svc.GetMetadata(cmd.Context(), scanner.UUID)
+			if err != nil {
+				return fmt.Errorf("failed to get scanner metadata: %w", err)
+			}
+
+			switch output.GetFormat() {
+			case "json":
+				return output.JSON(metadata)
+			case "yaml":
+				return output.YAML(metadata)
+			default:
+				if metadata.Scanner != nil {
+					table := output.Table()
+					table.Append([]string{"FIELD", "VALUE"})
+					table.Append([]string{"SCANNER", metadata.Scanner.Name})
+					table.Append([]string{"VENDOR", metadata.Scanner.Vendor})
+					table.Append([]string{"VERSION", metadata.Scanner.Version})
+					table.Render()
+				}
+
+				if len(metadata.Capabilities) > 0 {
+					fmt.Println()
+					table := output.Table()
+					table.Append([]string{"TYPE", "CONSUMES", "PRODUCES"})
+					for _, c := range metadata.Capabilities {
+						capType := c.Type
+						if capType == "" {
+							capType = "vulnerability"
+						}
+						table.Append([]string{
+							capType,
+							strings.Join(c.ConsumesMimeTypes, "\n"),
+							strings.Join(c.ProducesMimeTypes, "\n"),
+						})
+					}
+					table.Render()
+				}
+
+				if len(metadata.Properties) > 0 {
+					keys := make([]string, 0, len(metadata.Properties))
+					for k := range metadata.Properties {
+						keys = append(keys, k)
+					}
+					sort.Strings(keys)
+
+					fmt.Println()
+					table := output.Table()
+					table.Append([]string{"PROPERTY", "VALUE"})
+					for _, k := range keys {
+						table.Append([]string{k, metadata.Properties[k]})
+					}
+					table.Render()
+				}
+				return nil
+			}
+		},
+	}
+}
@@ -0,0 +1,281 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/pascal71/hrbcli/pkg/api"
+	"github.com/pascal71/hrbcli/pkg/harbor"
+	"github.com/pascal71/hrbcli/pkg/output"
+)
+
+func newScannerScanAllCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "scan-all",
+		Short: "Scan all artifacts of the system",
+		Long:  `Run, schedule, monitor and stop the scan of all artifacts in Harbor.`,
+	}
+	cmd.AddCommand(newScannerScanAllRunCmd())
+	cmd.AddCommand(newScannerScanAllScheduleCmd())
+	cmd.AddCommand(newScannerScanAllStatusCmd())
+	cmd.AddCommand(newScannerScanAllStopCmd())
+	return cmd
+}
+
+func newScannerScanAllRunCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "run",
+		Short: "Start a scan of all artifacts",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := api.NewClient()
+			if err != nil {
+				return err
+			}
+			svc := harbor.NewScanAllService(client)
+			if err := svc.Run(cmd.Context()); err != nil {
+				return fmt.Errorf("failed to start scan all: %w", err)
+			}
+			output.Success("Scan of all artifacts started")
+			return nil
+		},
+	}
+}
+
+func newScannerScanAllScheduleCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "schedule [hourly|daily|weekly|none|<cron>]",
+		Short: "Show or set the scan all schedule",
+		Long: `Show the schedule of the scan of all artifacts, or set it to hourly,
+daily, weekly, a cron expression, or none to disable it.
+
+Cron expressions have six fields, starting with seconds:
+"0 0 2 * * *" runs daily at 02:00. Five field expressions are run at
+second 0.`,
+		Example: `  hrbcli scanner scan-all schedule
+  hrbcli scanner scan-all schedule daily
+  hrbcli scanner scan-all schedule "0 30 1 * * 6"
+  hrbcli scanner scan-all schedule none`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := api.NewClient()
+			if err != nil {
+				return err
+			}
+			svc := harbor.NewScanAllService(client)
+
+			if len(args) == 1 {
+				schedule, err := parseSchedule(args[0])
+				if err != nil {
+					return err
+				}
+				if err := svc.SetSchedule(cmd.Context(), schedule); err != nil {
+					return fmt.Errorf("failed to set scan all schedule: %w", err)
+				}
+				if schedule.Type == harbor.ScheduleNone {
+					output.Success("Scan all schedule disabled")
+				} else {
+					output.Success("Scan all scheduled: %s", schedule.Cron)
+				}
+				return nil
+			}
+
+			current, err := svc.GetSchedule(cmd.Context())
+			if err != nil {
+				return fmt.Errorf("failed to get scan all schedule: %w", err)
+			}
+			switch output.GetFormat() {
+			case "json":
+				return output.JSON(current)
+			case "yaml":
+				return output.YAML(current)
+			default:
+				if current.Schedule == nil || current.Schedule.Type == "" || current.Schedule.Type == harbor.ScheduleNone {
+					output.Info("No scan all schedule")
+					return nil
+				}
+				next := ""
+				if current.Schedule.NextScheduledTime != nil {
+					next = current.Schedule.NextScheduledTime.Local().Format("2006-01-02 15:04:05")
+				}
+				table := output.Table()
+				table.Append([]string{"FIELD", "VALUE"})
+				table.Append([]string{"TYPE", current.Schedule.Type})
+				table.Append([]string{"CRON", current.Schedule.Cron})
+				table.Append([]string{"NEXT RUN", next})
+				table.Render()
+				return nil
+			}
+		},
+	}
+}
+
+// parseSchedule turns a preset name or cron expression into a schedule
+func parseSchedule(value string) (*api.Schedule, error) {
+	switch strings.ToLower(value) {
+	case "hourly":
+		return &api.Schedule{Type: harbor.ScheduleHourly, Cron: "0 0 * * * *"}, nil
+	case "daily":
+		return &api.Schedule{Type: harbor.ScheduleDaily, Cron: "0 0 0 * * *"}, nil
+	case "weekly":
+		return &api.Schedule{Type: harbor.ScheduleWeekly, Cron: "0 0 0 * * 0"}, nil
+	case "none":
+		return &api.Schedule{Type: harbor.ScheduleNone}, nil
+	}
+
+	fields := strings.Fields(value)
+	switch len(fields) {
+	case 5:
+		fields = append([]string{"0"}, fields...)
+	case 6:
+	default:
+		return nil, fmt.Errorf("invalid cron expression %q: expected 6 fields (seconds minutes hours day-of-month month day-of-week)", value)
+	}
+	for i, field := range fields {
+		if err := cronFields[i].validate(field); err != nil {
+			return nil, fmt.Errorf("invalid cron expression %q: %w", value, err)
+		}
+	}
+	return &api.Schedule{Type: harbor.ScheduleCustom, Cron: strings.Join(fields, " ")}, nil
+}
+
+// cronField is the range of one field of a cron expression. Months and
+// weekdays may also be given by their three letter names.
+type cronField struct {
+	name     string
+	min, max int
+	names    []string
+}
+
+var cronFields = []cronField{
+	{name: "seconds", min: 0, max: 59},
+	{name: "minutes", min: 0, max: 59},
+	{name: "hours", min: 0, max: 23},
+	{name: "day-of-month", min: 1, max: 31},
+	{name: "month", min: 1, max: 12, names: []string{"JAN", "FEB", "MAR", "APR", "MAY", "JUN", "JUL", "AUG", "SEP", "OCT", "NOV", "DEC"}},
+	{name: "day-of-week", min: 0, max: 6, names: []string{"SUN", "MON", "TUE", "WED", "THU", "FRI", "SAT"}},
+}
+
+// validate checks a comma separated list of *, ?, values and ranges, each
+// with an optional /step
+func (f cronField) validate(value string) error {
+	for _, part := range strings.Split(value, ",") {
+		rng, step, hasStep := strings.Cut(part, "/")
+		if hasStep {
+			if n, err := strconv.Atoi(step); err != nil || n < 1 {
+				return fmt.Errorf("invalid step %q in %s field", step, f.name)
+			}
+		}
+		if rng == "*" || rng == "?" {
+			continue
+		}
+		lo, hi, isRange := strings.Cut(rng, "-")
+		start, err := f.value(lo)
+		if err != nil {
+			return err
+		}
+		if isRange {
+			end, err := f.value(hi)
+			if err != nil {
+				return err
+			}
+			if end < start {
+				return fmt.Errorf("invalid range %q in %s field", rng, f.name)
+			}
+		}
+	}
+	return nil
+}
+
+// value parses a number or name within the range of the field
+func (f cronField) value(s string) (int, error) {
+	for i, name := range f.names {
+		if strings.EqualFold(s, name) {
+			return f.min + i, nil
+		}
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < f.min || n > f.max {
+		return 0, fmt.Errorf("invalid value %q in %s field: must be %d-%d", s, f.name, f.min, f.max)
+	}
+	return n, nil
+}
+
+func newScannerScanAllStatusCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "status",
+		Short: "Show the progress of the latest scan all run",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := api.NewClient()
+			if err != nil {
+				return err
+			}
+			svc := harbor.NewScanAllService(client)
+			metrics, err := svc.GetMetrics(cmd.Context())
+			if err != nil {
+				return fmt.Errorf("failed to get scan all status: %w", err)
+			}
+
+			switch output.GetFormat() {
+			case "json":
+				return output.JSON(metrics)
+			case "yaml":
+				return output.YAML(metrics)
+			default:
+				if metrics.Total == 0 && metrics.Trigger == "" {
+					output.Info("No scan all run found")
+					return nil
+				}
+				progress := fmt.Sprintf("%d/%d", metrics.Completed, metrics.Total)
+				if metrics.Total > 0 {
+					progress += fmt.Sprintf(" (%d%%)", metrics.Completed*100/metrics.Total)
+				}
+				table := output.Table()
+				table.Append([]string{"FIELD", "VALUE"})
+				table.Append([]string{"TRIGGER", metrics.Trigger})
+				table.Append([]string{"ONGOING", strconv.FormatBool(metrics.Ongoing)})
+				table.Append([]string{"PROGRESS", progress})
+				table.Render()
+
+				if len(metrics.Metrics) > 0 {
+					statuses := make([]string, 0, len(metrics.Metrics))
+					for status := range metrics.Metrics {
+						statuses = append(statuses, status)
+					}
+					sort.Strings(statuses)
+
+					fmt.Println()
+					table = output.Table()
+					table.Append([]string{"STATUS", "ARTIFACTS"})
+					for _, status := range statuses {
+						table.Append([]string{status, strconv.Itoa(metrics.Metrics[status])})
+					}
+					table.Render()
+				}
+				return nil
+			}
+		},
+	}
+}
+
+func newScannerScanAllStopCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "stop",
+		Short: "Stop the running scan all job",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := api.NewClient()
+			if err != nil {
+				return err
+			}
+			svc := harbor.NewScanAllService(client)
+			if err := svc.Stop(cmd.Context()); err != nil {
+				return fmt.Errorf("failed to stop scan all: %w", err)
+			}
+			output.Success("Scan all stop requested")
+			return nil
+		},
+	}
+}
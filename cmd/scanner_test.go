@@ -0,0 +1,322 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pascal71/hrbcli/pkg/api"
+	"github.com/pascal71/hrbcli/pkg/harbor"
+	"github.com/pascal71/hrbcli/pkg/parallel"
+)
+
+func TestScannerLicenses(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v2.0/projects/dev/repositories/app/artifacts":
+			w.Write([]byte(`[{"digest":"sha256:aaa","tags":[{"name":"1.0"}]},{"digest":"sha256:bbb","tags":[{"name":"1.1"}]},{"digest":"sha256:ccc"},{"digest":"sha256:ddd"}]`))
+		case "/api/v2.0/projects/dev/repositories/app/artifacts/sha256:aaa/additions/sbom":
+			w.Write([]byte(`{"bomFormat":"CycloneDX","components":[{"name":"musl","version":"1.2","licenses":[{"expression":"MIT"}]}]}`))
+		case "/api/v2.0/projects/dev/repositories/app/artifacts/sha256:bbb/additions/sbom":
+			w.Write([]byte(`{"bomFormat":"CycloneDX","components":[
+				{"name":"musl","version":"1.2","licenses":[{"expression":"MIT"}]},
+				{"name":"readline","version":"8.2","licenses":[{"license":{"id":"GPL-3.0-only"}}]},
+				{"name":"mystery","version":"0.1"}]}`))
+		case "/api/v2.0/projects/dev/repositories/app/artifacts/sha256:ccc/additions/sbom":
+			w.Write([]byte(`{}`))
+		case "/api/v2.0/projects/dev/repositories/app/artifacts/sha256:ddd/additions/sbom":
+			w.WriteHeader(http.StatusForbidden)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	setupEnv(server.URL)
+	defer os.Unsetenv("HARBOR_URL")
+
+	cmd := newScannerLicensesCmd()
+	cmd.SetContext(context.Background())
+	if err := cmd.RunE(cmd, []string{"dev/app"}); err == nil {
+		t.Fatal("expected an error without allow or deny list")
+	}
+
+	cmd.Flags().Set("deny", "GPL-*")
+	err := cmd.RunE(cmd, []string{"dev/app"})
+	if code := ExitCode(err); code != 2 {
+		t.Fatalf("expected exit code 2, got %d (%v)", code, err)
+	}
+	if !strings.Contains(err.Error(), "incomplete") || !strings.Contains(err.Error(), "sha256:ddd") {
+		t.Fatalf("expected the failed artifact to be reported, got %v", err)
+	}
+
+	cmd = newScannerLicensesCmd()
+	cmd.SetContext(context.Background())
+	cmd.Flags().Set("allow", "MIT")
+	cmd.Flags().Set("deny", "AGPL-*")
+	if err := cmd.RunE(cmd, []string{"dev/app"}); ExitCode(err) != 2 {
+		t.Fatalf("expected readline to be denied by the allow list, got %v", err)
+	}
+}
+
+func TestScannerScanAggregatesErrors(t *testing.T) {
+	var mu sync.Mutex
+	var scanned []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.URL.Path {
+		case "GET /api/v2.0/projects/dev/repositories":
+			w.Write([]byte(`[{"name":"dev/app"},{"name":"dev/web"},{"name":"dev/broken"}]`))
+		case "GET /api/v2.0/projects/dev/repositories/app/artifacts":
+			w.Write([]byte(`[{"digest":"sha256:a1"},{"digest":"sha256:a2"}]`))
+		case "GET /api/v2.0/projects/dev/repositories/web/artifacts":
+			w.Write([]byte(`[{"digest":"sha256:w1"}]`))
+		case "GET /api/v2.0/projects/dev/repositories/broken/artifacts":
+			w.WriteHeader(http.StatusInternalServerError)
+		case "POST /api/v2.0/projects/dev/repositories/app/artifacts/sha256:a1/scan",
+			"POST /api/v2.0/projects/dev/repositories/web/artifacts/sha256:w1/scan":
+			mu.Lock()
+			scanned = append(scanned, r.URL.Path)
+			mu.Unlock()
+			w.WriteHeader(http.StatusAccepted)
+		case "POST /api/v2.0/projects/dev/repositories/app/artifacts/sha256:a2/scan":
+			w.WriteHeader(http.StatusConflict)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	setupEnv(server.URL)
+	defer os.Unsetenv("HARBOR_URL")
+
+	cmd := newScannerScanCmd()
+	cmd.SetContext(context.Background())
+	cmd.Flags().Set("concurrency", "2")
+	err := cmd.RunE(cmd, []string{"dev"})
+
+	var errs parallel.Errors
+	if !errors.As(err, &errs) || len(errs) != 2 {
+		t.Fatalf("expected 2 aggregated errors, got %v", err)
+	}
+	if !strings.Contains(errs[0].Error(), "broken") || !strings.Contains(errs[1].Error(), "sha256:a2") {
+		t.Fatalf("unexpected errors %v", errs)
+	}
+	if len(scanned) != 2 {
+		t.Fatalf("expected the other scans to be triggered, got %v", scanned)
+	}
+}
+
+func TestScannerScanWait(t *testing.T) {
+	defer func(interval time.Duration) { scanPollInterval = interval }(scanPollInterval)
+	scanPollInterval = 10 * time.Millisecond
+
+	var mu sync.Mutex
+	polls := map[string]int{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v2.0/projects/dev/repositories/app/artifacts":
+			w.Write([]byte(`[{"digest":"sha256:ok","tags":[{"name":"1.0"}]},{"digest":"sha256:bad"},{"digest":"sha256:slow"}]`))
+		case r.Method == http.MethodPost:
+			w.WriteHeader(http.StatusAccepted)
+		case r.Method == http.MethodGet:
+			if r.URL.Query().Get("with_scan_overview") != "true" {
+				t.Errorf("expected the scan overview to be requested")
+			}
+			digest := strings.TrimPrefix(r.URL.Path, "/api/v2.0/projects/dev/repositories/app/artifacts/")
+			mu.Lock()
+			polls[digest]++
+			n := polls[digest]
+			mu.Unlock()
+			status := "Running"
+			switch {
+			case digest == "sha256:ok" && n > 2:
+				status = "Success"
+			case digest == "sha256:bad" && n > 1:
+				status = "Error"
+			}
+			w.Write([]byte(`{"digest":"` + digest + `","scan_overview":{"application/vnd.security.vulnerability.report; version=1.1":
+				{"scan_status":"` + status + `","severity":"High","duration":42,"summary":{"total":3,"summary":{"High":1,"Low":2}}}}}`))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	setupEnv(server.URL)
+	defer os.Unsetenv("HARBOR_URL")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+	cmd := newScannerScanCmd()
+	cmd.SetContext(ctx)
+	cmd.Flags().Set("wait", "true")
+	err := cmd.RunE(cmd, []string{"dev/app"})
+	if err == nil || !strings.Contains(err.Error(), "1 of 3 scans failed") {
+		t.Fatalf("expected the failed scan to be reported, got %v", err)
+	}
+
+	arts := []repoArtifact{{Repository: "app", Artifact: &api.Artifact{Digest: "sha256:ok"}}, {Repository: "app", Artifact: &api.Artifact{Digest: "sha256:slow"}}}
+	ctx, cancel = context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	client := &api.Client{BaseURL: server.URL, APIVersion: "v2.0", HTTPClient: server.Client()}
+	outcomes := waitForScans(ctx, harbor.NewArtifactService(client), "dev", arts, 2)
+	if outcomes[0].Status != scanSuccess || outcomes[0].Duration != "42s" || outcomes[0].High != 1 || outcomes[0].Total != 3 {
+		t.Fatalf("unexpected outcome %+v", outcomes[0])
+	}
+	if outcomes[1].Status != scanTimeout {
+		t.Fatalf("expected the running scan to time out, got %+v", outcomes[1])
+	}
+}
+
+func TestScanWaitRejectsSBOM(t *testing.T) {
+	cmd := newScannerScanCmd()
+	cmd.SetContext(context.Background())
+	cmd.Flags().Set("wait", "true")
+	cmd.Flags().Set("scan-type", "sbom")
+	if err := cmd.RunE(cmd, []string{"dev"}); err == nil {
+		t.Fatal("expected an error")
+	}
+}
+
+func TestParseSchedule(t *testing.T) {
+	tests := []struct {
+		input   string
+		typ     string
+		cron    string
+		wantErr bool
+	}{
+		{"daily", "Daily", "0 0 0 * * *", false},
+		{"Weekly", "Weekly", "0 0 0 * * 0", false},
+		{"none", "None", "", false},
+		{"0 30 1 * * 6", "Custom", "0 30 1 * * 6", false},
+		{"30 1 * * 6", "Custom", "0 30 1 * * 6", false},
+		{"*/15 1-5,8 * * MON-FRI", "Custom", "0 */15 1-5,8 * * MON-FRI", false},
+		{"0 0 12 ? * *", "Custom", "0 0 12 ? * *", false},
+		{"* *", "", "", true},
+		{"a b c d e f", "", "", true},
+		{"0 60 * * * *", "", "", true},
+		{"0 0 5-1 * * *", "", "", true},
+		{"0 */0 * * * *", "", "", true},
+		{"0 0 0 0 * *", "", "", true},
+	}
+	for _, tt := range tests {
+		schedule, err := parseSchedule(tt.input)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("expected error for %q", tt.input)
+			}
+			continue
+		}
+		if err != nil || schedule.Type != tt.typ || schedule.Cron != tt.cron {
+			t.Errorf("parseSchedule(%q) = %+v, %v", tt.input, schedule, err)
+		}
+	}
+}
+
+func TestScannerRegistrationCommands(t *testing.T) {
+	bodies := map[string]string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies[r.Method+" "+r.URL.Path] = string(body)
+		switch r.Method + " " + r.URL.Path {
+		case "GET /api/v2.0/scanners":
+			w.Write([]byte(`[{"uuid":"u-1","name":"trivy","is_default":true},{"uuid":"u-2","name":"acme"}]`))
+		case "GET /api/v2.0/scanners/u-2":
+			w.Write([]byte(`{"uuid":"u-2","name":"acme","url":"https://scanner.example.com","auth":"Bearer","access_credential":"old","skip_certVerify":true}`))
+		case "PUT /api/v2.0/scanners/u-2", "PUT /api/v2.0/projects/dev/scanner":
+			w.WriteHeader(http.StatusOK)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	setupEnv(server.URL)
+	defer os.Unsetenv("HARBOR_URL")
+
+	list := newScannerListCmd()
+	list.SetContext(context.Background())
+	list.Flags().Set("limit", "1")
+	if err := list.RunE(list, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	set := newProjectScannerSetCmd()
+	set.SetContext(context.Background())
+	if err := set.RunE(set, []string{"dev", "acme"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := bodies["PUT /api/v2.0/projects/dev/scanner"]; got != `{"uuid":"u-2"}` {
+		t.Errorf("unexpected project scanner body %s", got)
+	}
+
+	update := newScannerUpdateCmd()
+	update.SetContext(context.Background())
+	update.Flags().Set("credential", "new")
+	update.Flags().Set("disabled", "true")
+	if err := update.RunE(update, []string{"u-2"}); err != nil {
+		t.Fatal(err)
+	}
+	want := `{"name":"acme","url":"https://scanner.example.com","auth":"Bearer","access_credential":"new","skip_certVerify":true,"use_internal_addr":false,"disabled":true}`
+	if got := bodies["PUT /api/v2.0/scanners/u-2"]; got != want {
+		t.Errorf("unexpected update body\n got %s\nwant %s", got, want)
+	}
+
+	update = newScannerUpdateCmd()
+	update.SetContext(context.Background())
+	update.Flags().Set("auth", "basic")
+	if err := update.RunE(update, []string{"acme"}); err != nil {
+		t.Fatal(err)
+	}
+
+	stdin, err := os.CreateTemp(t.TempDir(), "credential")
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdin.WriteString("piped\n")
+	stdin.Seek(0, io.SeekStart)
+	defer func(orig *os.File) { os.Stdin = orig }(os.Stdin)
+	os.Stdin = stdin
+
+	update = newScannerUpdateCmd()
+	update.SetContext(context.Background())
+	update.Flags().Set("credential-stdin", "true")
+	if err := update.RunE(update, []string{"acme"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := bodies["PUT /api/v2.0/scanners/u-2"]; !strings.Contains(got, `"access_credential":"piped"`) {
+		t.Errorf("expected the credential from stdin, got %s", got)
+	}
+
+	bad := newScannerCreateCmd()
+	bad.SetContext(context.Background())
+	bad.Flags().Set("url", "https://scanner.example.com")
+	bad.Flags().Set("auth", "basic")
+	if err := bad.RunE(bad, []string{"other"}); err == nil || !strings.Contains(err.Error(), "--credential") {
+		t.Errorf("expected missing credential error, got %v", err)
+	}
+}
+
+func TestRedactScanner(t *testing.T) {
+	sc := &api.ScannerRegistration{Name: "acme", AccessCredential: "s3cret"}
+	if got := redactScanner(sc); got.AccessCredential != "********" || got.Name != "acme" {
+		t.Errorf("unexpected redacted scanner %+v", got)
+	}
+	if sc.AccessCredential != "s3cret" {
+		t.Errorf("redaction changed the original")
+	}
+	if got := redactScanner(&api.ScannerRegistration{}); got.AccessCredential != "" {
+		t.Errorf("expected an unset credential to stay empty, got %q", got.AccessCredential)
+	}
+}
@@ -0,0 +1,190 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/manifoldco/promptui"
+	"github.com/spf13/cobra"
+
+	"github.com/pascal71/hrbcli/pkg/api"
+	"github.com/pascal71/hrbcli/pkg/output"
+	"github.com/pascal71/hrbcli/pkg/snapshot"
+)
+
+// NewExportCmd creates the export command
+func NewExportCmd() *cobra.Command {
+	var force bool
+
+	cmd := &cobra.Command{
+		Use:   "export <dir|file.tar.gz>",
+		Short: "Export the Harbor configuration to a snapshot",
+		Long: `Export the configuration of the Harbor instance to a snapshot that
+'hrbcli import' can recreate on another instance.
+
+The snapshot holds projects with metadata, quotas and CVE allowlists,
+registries, replication policies, labels, users, the editable system
+configuration and preheat policies, one JSON file per section. Registry
+secrets and user passwords are not exported.
+
+A path ending in .tar.gz or .tgz is written as a gzipped tarball, any
+other path as a directory. With --force the files of an earlier snapshot in
+the directory are replaced; a non-empty directory that holds no snapshot is
+refused.`,
+		Example: `  # Export to a directory
+  hrbcli export ./harbor-backup
+
+  # Export the staging instance to a tarball
+  hrbcli export staging-$(date +%F).tar.gz --context staging`,
+		Args: requireArgs(1, "requires <dir|file.tar.gz>"),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path := args[0]
+			if _, err := os.Stat(path); err == nil && !force {
+				return fmt.Errorf("%s already exists; use --force to overwrite", path)
+			}
+
+			client, err := api.NewClient()
+			if err != nil {
+				return err
+			}
+			snap, err := snapshot.Export(cmd.Context(), client)
+			if err != nil {
+				return err
+			}
+			if err := snap.Write(path); err != nil {
+				return fmt.Errorf("failed to write snapshot: %w", err)
+			}
+
+			output.Success("Exported %s (Harbor %s) to %s", snap.Manifest.Source, snap.Manifest.HarborVersion, path)
+			fmt.Printf("  Projects:             %d\n", len(snap.Projects))
+			fmt.Printf("  Registries:           %d\n", len(snap.Registries))
+			fmt.Printf("  Replication policies: %d\n", len(snap.ReplicationPolicies))
+			fmt.Printf("  Labels:               %d\n", len(snap.Labels))
+			fmt.Printf("  Users:                %d\n", len(snap.Users))
+			fmt.Printf("  Preheat policies:     %d\n", len(snap.PreheatPolicies))
+			fmt.Printf("  Settings:             %d\n", len(snap.Configuration))
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&force, "force", false, "Overwrite an existing snapshot")
+	return cmd
+}
+
+// NewImportCmd creates the import command
+func NewImportCmd() *cobra.Command {
+	var (
+		include      []string
+		userPassword string
+		force        bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "import <dir|file.tar.gz>",
+		Short: "Recreate the objects of a snapshot on Harbor",
+		Long: `Create the objects of a snapshot written by 'hrbcli export' on the
+configured Harbor instance.
+
+Objects that already exist, matched by name, are skipped. References are
+remapped to the IDs of the target instance: replication policies and
+proxy cache projects point to the registry with the same name, labels and
+preheat policies to the project with the same name.
+
+Registries are created without secrets. Users are only created when
+--user-password is given, as passwords are not exported.`,
+		Example: `  # Recreate everything on the DR instance
+  hrbcli import ./harbor-backup --context dr
+
+  # Only projects and the registries they proxy
+  hrbcli import backup.tar.gz --include registries,projects
+
+  # Also create users with an initial password
+  hrbcli import backup.tar.gz --user-password 'Change-me-1'`,
+		Args: requireArgs(1, "requires <dir|file.tar.gz>"),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			for _, s := range include {
+				if !slices.Contains(snapshot.Sections, s) {
+					return fmt.Errorf("invalid section %q (valid: %s)", s, strings.Join(snapshot.Sections, ", "))
+				}
+			}
+
+			snap, err := snapshot.Read(args[0])
+			if err != nil {
+				return err
+			}
+
+			client, err := api.NewClient()
+			if err != nil {
+				return err
+			}
+
+			if !force {
+				prompt := promptui.Prompt{
+					Label:     fmt.Sprintf("Import snapshot of %s into %s", snap.Manifest.Source, client.BaseURL),
+					IsConfirm: true,
+				}
+				if _, err := prompt.Run(); err != nil {
+					output.Info("Import cancelled")
+					return nil
+				}
+			}
+
+			results, err := snapshot.Import(cmd.Context(), client, snap, snapshot.ImportOptions{
+				Sections:     include,
+				UserPassword: userPassword,
+			})
+			if printErr := printImportResults(results); printErr != nil {
+				return printErr
+			}
+			if err != nil {
+				return err
+			}
+
+			failed := 0
+			for _, r := range results {
+				if r.Status == snapshot.StatusFailed {
+					failed++
+				}
+			}
+			if failed > 0 {
+				return fmt.Errorf("%d of %d objects failed to import", failed, len(results))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringSliceVar(&include, "include", nil, "Sections to import (default all): "+strings.Join(snapshot.Sections, ", "))
+	cmd.Flags().StringVar(&userPassword, "user-password", "", "Initial password of imported users")
+	cmd.Flags().BoolVar(&force, "force", false, "Import without confirmation")
+	return cmd
+}
+
+func printImportResults(results []*snapshot.Result) error {
+	switch output.GetFormat() {
+	case "json":
+		return output.JSON(results)
+	case "yaml":
+		return output.YAML(results)
+	default:
+		if len(results) == 0 {
+			output.Info("Nothing to import")
+			return nil
+		}
+		table := output.Table()
+		table.Append([]string{"SECTION", "NAME", "STATUS", "MESSAGE"})
+		for _, r := range results {
+			status := r.Status
+			switch r.Status {
+			case snapshot.StatusCreated, snapshot.StatusUpdated:
+				status = output.Green(status)
+			case snapshot.StatusFailed:
+				status = output.Red(status)
+			}
+			table.Append([]string{r.Section, r.Name, status, r.Message})
+		}
+		table.Render()
+		return nil
+	}
+}
@@ -40,7 +40,7 @@ func newSystemStatisticsCmd() *cobra.Command {
 
 			sysSvc := harbor.NewSystemService(client)
 
-			stats, err := sysSvc.GetStatistics()
+			stats, err := sysSvc.GetStatistics(cmd.Context())
 			if err != nil {
 				return fmt.Errorf("failed to get statistics: %w", err)
 			}
@@ -93,7 +93,7 @@ func newSystemInfoCmd() *cobra.Command {
 			}
 
 			svc := harbor.NewSystemService(client)
-			info, err := svc.GetInfo(withStorage)
+			info, err := svc.GetInfo(cmd.Context(), withStorage)
 			if err != nil {
 				return fmt.Errorf("failed to get system info: %w", err)
 			}
@@ -127,7 +127,7 @@ func newSystemHealthCmd() *cobra.Command {
 				return err
 			}
 
-			if err := client.CheckHealth(); err != nil {
+			if err := client.CheckHealth(cmd.Context()); err != nil {
 				return err
 			}
 			output.Success("Harbor is healthy")
@@ -162,7 +162,7 @@ func newSystemConfigGetCmd() *cobra.Command {
 			}
 
 			svc := harbor.NewConfigService(client)
-			cfg, err := svc.Get()
+			cfg, err := svc.Get(cmd.Context())
 			if err != nil {
 				return fmt.Errorf("failed to get configuration: %w", err)
 			}
@@ -210,7 +210,7 @@ func newSystemConfigSetCmd() *cobra.Command {
 
 			svc := harbor.NewConfigService(client)
 			cfg := map[string]interface{}{args[0]: parseConfigValue(args[1])}
-			if err := svc.Update(cfg); err != nil {
+			if err := svc.Update(cmd.Context(), cfg); err != nil {
 				return fmt.Errorf("failed to update configuration: %w", err)
 			}
 			output.Success("Updated %s", args[0])
@@ -241,7 +241,7 @@ func newSystemGCScheduleCmd() *cobra.Command {
 				return err
 			}
 			svc := harbor.NewSystemService(client)
-			if err := svc.ScheduleGC(); err != nil {
+			if err := svc.ScheduleGC(cmd.Context()); err != nil {
 				return err
 			}
 			output.Success("Garbage collection scheduled")
@@ -260,7 +260,7 @@ func newSystemGCHistoryCmd() *cobra.Command {
 				return err
 			}
 			svc := harbor.NewSystemService(client)
-			history, err := svc.GetGCHistory()
+			history, err := svc.GetGCHistory(cmd.Context())
 			if err != nil {
 				return err
 			}
@@ -302,7 +302,7 @@ func newSystemGCStatusCmd() *cobra.Command {
 				return err
 			}
 			svc := harbor.NewSystemService(client)
-			gc, err := svc.GetGC(id)
+			gc, err := svc.GetGC(cmd.Context(), id)
 			if err != nil {
 				return err
 			}
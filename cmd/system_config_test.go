@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"context"
 	"encoding/json"
 	"io"
 	"net/http"
@@ -42,6 +43,7 @@ func TestSystemConfigSet(t *testing.T) {
 	defer os.Unsetenv("HARBOR_URL")
 
 	cmd := newSystemConfigSetCmd()
+	cmd.SetContext(context.Background())
 	if err := cmd.RunE(cmd, []string{"read_only", "true"}); err != nil {
 		t.Fatalf("run error: %v", err)
 	}
@@ -35,6 +35,7 @@ func newUserListCmd() *cobra.Command {
 		page     int
 		pageSize int
 		detail   bool
+		pages    pageFlags
 	)
 
 	cmd := &cobra.Command{
@@ -51,10 +52,17 @@ func newUserListCmd() *cobra.Command {
 
 			opts := &api.ListOptions{Page: page, PageSize: pageSize}
 			var users []*api.User
-			if search != "" {
-				users, err = userSvc.Search(search, opts)
-			} else {
-				users, err = userSvc.List(opts)
+			switch {
+			case pages.enabled() && search != "":
+				opts.Page, opts.PageSize = 0, 0
+				users, err = userSvc.SearchAll(cmd.Context(), search, opts, pages.limit)
+			case pages.enabled():
+				opts.Page, opts.PageSize = 0, 0
+				users, err = userSvc.ListAll(cmd.Context(), opts, pages.limit)
+			case search != "":
+				users, err = userSvc.Search(cmd.Context(), search, opts)
+			default:
+				users, err = userSvc.List(cmd.Context(), opts)
 			}
 			if err != nil {
 				return fmt.Errorf("failed to list users: %w", err)
@@ -103,6 +111,7 @@ func newUserListCmd() *cobra.Command {
 	cmd.Flags().IntVar(&page, "page", 1, "Page number")
 	cmd.Flags().IntVar(&pageSize, "page-size", 20, "Page size")
 	cmd.Flags().BoolVar(&detail, "detail", false, "Show detailed information")
+	pages.register(cmd)
 
 	return cmd
 }
@@ -146,7 +155,7 @@ func newUserCreateCmd() *cobra.Command {
 				Realname: realname,
 			}
 
-			user, err := userSvc.Create(req)
+			user, err := userSvc.Create(cmd.Context(), req)
 			if err != nil {
 				if apiErr, ok := err.(*api.APIError); ok && apiErr.IsConflict() {
 					msg := apiErr.FriendlyMessage()
@@ -159,7 +168,7 @@ func newUserCreateCmd() *cobra.Command {
 			}
 
 			if admin && user != nil {
-				if err := userSvc.SetAdmin(int64(user.UserID), true); err != nil {
+				if err := userSvc.SetAdmin(cmd.Context(), int64(user.UserID), true); err != nil {
 					output.Warning("Failed to set admin flag: %v", err)
 				}
 				user.SysadminFlag = true
@@ -203,7 +212,7 @@ func newUserDeleteCmd() *cobra.Command {
 
 			userSvc := harbor.NewUserService(client)
 
-			user, err := userSvc.GetByUsername(username)
+			user, err := userSvc.GetByUsername(cmd.Context(), username)
 			if err != nil {
 				return err
 			}
@@ -217,7 +226,7 @@ func newUserDeleteCmd() *cobra.Command {
 				}
 			}
 
-			if err := userSvc.Delete(int64(user.UserID)); err != nil {
+			if err := userSvc.Delete(cmd.Context(), int64(user.UserID)); err != nil {
 				return fmt.Errorf("failed to delete user: %w", err)
 			}
 
@@ -0,0 +1,583 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/manifoldco/promptui"
+	"github.com/spf13/cobra"
+
+	"github.com/pascal71/hrbcli/pkg/api"
+	"github.com/pascal71/hrbcli/pkg/harbor"
+	"github.com/pascal71/hrbcli/pkg/output"
+)
+
+// NewWebhookCmd creates the webhook command
+func NewWebhookCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "webhook",
+		Short: "Manage project webhook policies",
+		Long: `Manage project webhook policies and inspect their delivery history.
+
+Policies can be referenced by ID or by name.`,
+	}
+
+	cmd.AddCommand(newWebhookListCmd())
+	cmd.AddCommand(newWebhookGetCmd())
+	cmd.AddCommand(newWebhookCreateCmd())
+	cmd.AddCommand(newWebhookUpdateCmd())
+	cmd.AddCommand(newWebhookDeleteCmd())
+	cmd.AddCommand(newWebhookEventsCmd())
+	cmd.AddCommand(newWebhookExecutionsCmd())
+	cmd.AddCommand(newWebhookTasksCmd())
+	cmd.AddCommand(newWebhookLogCmd())
+
+	return cmd
+}
+
+func newWebhookListCmd() *cobra.Command {
+	var (
+		page     int
+		pageSize int
+		pages    pageFlags
+	)
+
+	cmd := &cobra.Command{
+		Use:   "list <project>",
+		Short: "List webhook policies",
+		Args:  requireArgs(1, "requires <project>"),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := api.NewClient()
+			if err != nil {
+				return err
+			}
+			svc := harbor.NewWebhookService(client)
+			opts := &api.ListOptions{Page: page, PageSize: pageSize}
+			var policies []*api.WebhookPolicy
+			if pages.enabled() {
+				opts.Page, opts.PageSize = 0, 0
+				policies, err = svc.ListAllPolicies(cmd.Context(), args[0], opts, pages.limit)
+			} else {
+				policies, err = svc.ListPolicies(cmd.Context(), args[0], opts)
+			}
+			if err != nil {
+				return fmt.Errorf("failed to list webhook policies: %w", err)
+			}
+
+			if len(policies) == 0 {
+				output.Info("No webhook policies found")
+				return nil
+			}
+
+			switch output.GetFormat() {
+			case "json":
+				return output.JSON(policies)
+			case "yaml":
+				return output.YAML(policies)
+			default:
+				table := output.Table()
+				table.Append([]string{"ID", "NAME", "ENABLED", "TARGET", "EVENTS"})
+				for _, p := range policies {
+					target := ""
+					if len(p.Targets) > 0 {
+						target = fmt.Sprintf("%s %s", p.Targets[0].Type, p.Targets[0].Address)
+					}
+					table.Append([]string{
+						strconv.FormatInt(p.ID, 10),
+						p.Name,
+						strconv.FormatBool(p.Enabled),
+						target,
+						strings.Join(p.EventTypes, ","),
+					})
+				}
+				table.Render()
+				return nil
+			}
+		},
+	}
+	cmd.Flags().IntVar(&page, "page", 1, "Page number")
+	cmd.Flags().IntVar(&pageSize, "page-size", 20, "Page size")
+	pages.register(cmd)
+	return cmd
+}
+
+func newWebhookGetCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "get <project> <policy>",
+		Short: "Show webhook policy",
+		Args:  requireArgs(2, "requires <project> and <policy>"),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			project := args[0]
+			client, err := api.NewClient()
+			if err != nil {
+				return err
+			}
+			svc := harbor.NewWebhookService(client)
+			id, err := resolveWebhookPolicyID(cmd.Context(), svc, project, args[1])
+			if err != nil {
+				return err
+			}
+			policy, err := svc.GetPolicy(cmd.Context(), project, id)
+			if err != nil {
+				return fmt.Errorf("failed to get webhook policy: %w", err)
+			}
+
+			switch output.GetFormat() {
+			case "json":
+				return output.JSON(policy)
+			case "yaml":
+				return output.YAML(policy)
+			default:
+				output.Info("Webhook: %s", output.Bold(policy.Name))
+				fmt.Printf("ID:          %d\n", policy.ID)
+				fmt.Printf("Enabled:     %v\n", policy.Enabled)
+				if policy.Description != "" {
+					fmt.Printf("Description: %s\n", policy.Description)
+				}
+				fmt.Printf("Events:      %s\n", strings.Join(policy.EventTypes, ", "))
+				fmt.Printf("Creator:     %s\n", policy.Creator)
+				for _, t := range policy.Targets {
+					output.Info("\nTarget:")
+					fmt.Printf("  Type:             %s\n", t.Type)
+					fmt.Printf("  Address:          %s\n", t.Address)
+					if t.PayloadFormat != "" {
+						fmt.Printf("  Payload Format:   %s\n", t.PayloadFormat)
+					}
+					fmt.Printf("  Skip Cert Verify: %v\n", t.SkipCertVerify)
+				}
+				return nil
+			}
+		},
+	}
+	return cmd
+}
+
+// webhookFlags holds the flags shared by webhook create and update
+type webhookFlags struct {
+	name           string
+	description    string
+	eventTypes     []string
+	targetType     string
+	address        string
+	authHeader     string
+	skipCertVerify bool
+	payloadFormat  string
+	disabled       bool
+}
+
+func (f *webhookFlags) register(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&f.name, "name", "", "Policy name")
+	cmd.Flags().StringVar(&f.description, "description", "", "Policy description")
+	cmd.Flags().StringSliceVar(&f.eventTypes, "event-type", nil, "Event types to notify on (e.g. PUSH_ARTIFACT,SCANNING_FAILED)")
+	cmd.Flags().StringVar(&f.targetType, "target-type", api.WebhookTargetHTTP, "Target type (http|slack)")
+	cmd.Flags().StringVar(&f.address, "address", "", "Endpoint URL")
+	cmd.Flags().StringVar(&f.authHeader, "auth-header", "", "Authorization header sent to the endpoint")
+	cmd.Flags().BoolVar(&f.skipCertVerify, "skip-cert-verify", false, "Skip TLS verification of the endpoint")
+	cmd.Flags().StringVar(&f.payloadFormat, "payload-format", "", "Payload format (Default|CloudEvents)")
+	cmd.Flags().BoolVar(&f.disabled, "disabled", false, "Disable the policy")
+}
+
+// apply copies changed flag values onto policy. When all is true every
+// flag is applied regardless of whether it was set.
+func (f *webhookFlags) apply(cmd *cobra.Command, policy *api.WebhookPolicy, all bool) error {
+	changed := func(name string) bool { return all || cmd.Flags().Changed(name) }
+
+	if changed("name") {
+		policy.Name = f.name
+	}
+	if changed("description") {
+		policy.Description = f.description
+	}
+	if changed("event-type") {
+		policy.EventTypes = harbor.NormalizeEventTypes(f.eventTypes)
+	}
+	if changed("disabled") {
+		policy.Enabled = !f.disabled
+	}
+
+	if len(policy.Targets) == 0 {
+		policy.Targets = []*api.WebhookTarget{{}}
+	}
+	target := policy.Targets[0]
+	if changed("target-type") {
+		switch f.targetType {
+		case api.WebhookTargetHTTP, api.WebhookTargetSlack:
+			target.Type = f.targetType
+		default:
+			return fmt.Errorf("invalid target type: %s (valid: http, slack)", f.targetType)
+		}
+	}
+	if changed("address") {
+		target.Address = f.address
+	}
+	if changed("auth-header") {
+		target.AuthHeader = f.authHeader
+	}
+	if changed("skip-cert-verify") {
+		target.SkipCertVerify = f.skipCertVerify
+	}
+	if changed("payload-format") {
+		target.PayloadFormat = f.payloadFormat
+	}
+	return nil
+}
+
+func newWebhookCreateCmd() *cobra.Command {
+	flags := &webhookFlags{}
+
+	cmd := &cobra.Command{
+		Use:   "create <project>",
+		Short: "Create webhook policy",
+		Example: `  # Notify an HTTP endpoint on push and failed scans
+  hrbcli webhook create myproject --name ci-hook \
+    --event-type PUSH_ARTIFACT,SCANNING_FAILED \
+    --address https://ci.example.com/harbor --auth-header "Bearer token"
+
+  # Post to a Slack channel
+  hrbcli webhook create myproject --name slack --target-type slack \
+    --event-type SCANNING_COMPLETED --address https://hooks.slack.com/services/...`,
+		Args: requireArgs(1, "requires <project>"),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			project := args[0]
+			if flags.name == "" || flags.address == "" || len(flags.eventTypes) == 0 {
+				return fmt.Errorf("--name, --address and --event-type are required")
+			}
+
+			policy := &api.WebhookPolicy{}
+			if err := flags.apply(cmd, policy, true); err != nil {
+				return err
+			}
+
+			client, err := api.NewClient()
+			if err != nil {
+				return err
+			}
+			svc := harbor.NewWebhookService(client)
+			created, err := svc.CreatePolicy(cmd.Context(), project, policy)
+			if err != nil {
+				if apiErr, ok := err.(*api.APIError); ok && apiErr.IsConflict() {
+					return fmt.Errorf("webhook policy '%s' already exists", flags.name)
+				}
+				return fmt.Errorf("failed to create webhook policy: %w", err)
+			}
+			output.Success("Created webhook policy %s (ID %d)", created.Name, created.ID)
+			return nil
+		},
+	}
+
+	flags.register(cmd)
+	return cmd
+}
+
+func newWebhookUpdateCmd() *cobra.Command {
+	flags := &webhookFlags{}
+
+	cmd := &cobra.Command{
+		Use:   "update <project> <policy>",
+		Short: "Update webhook policy",
+		Example: `  # Disable a policy
+  hrbcli webhook update myproject ci-hook --disabled
+
+  # Change the notified events
+  hrbcli webhook update myproject ci-hook --event-type PUSH_ARTIFACT,DELETE_ARTIFACT`,
+		Args: requireArgs(2, "requires <project> and <policy>"),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			project := args[0]
+			client, err := api.NewClient()
+			if err != nil {
+				return err
+			}
+			svc := harbor.NewWebhookService(client)
+			id, err := resolveWebhookPolicyID(cmd.Context(), svc, project, args[1])
+			if err != nil {
+				return err
+			}
+			policy, err := svc.GetPolicy(cmd.Context(), project, id)
+			if err != nil {
+				return fmt.Errorf("failed to get webhook policy: %w", err)
+			}
+
+			if err := flags.apply(cmd, policy, false); err != nil {
+				return err
+			}
+
+			if err := svc.UpdatePolicy(cmd.Context(), project, id, policy); err != nil {
+				return fmt.Errorf("failed to update webhook policy: %w", err)
+			}
+			output.Success("Updated webhook policy %d", id)
+			return nil
+		},
+	}
+
+	flags.register(cmd)
+	return cmd
+}
+
+func newWebhookDeleteCmd() *cobra.Command {
+	var force bool
+
+	cmd := &cobra.Command{
+		Use:   "delete <project> <policy>",
+		Short: "Delete webhook policy",
+		Args:  requireArgs(2, "requires <project> and <policy>"),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			project := args[0]
+			client, err := api.NewClient()
+			if err != nil {
+				return err
+			}
+			svc := harbor.NewWebhookService(client)
+			id, err := resolveWebhookPolicyID(cmd.Context(), svc, project, args[1])
+			if err != nil {
+				return err
+			}
+
+			if !force {
+				prompt := promptui.Prompt{
+					Label:     fmt.Sprintf("Delete webhook policy %d", id),
+					IsConfirm: true,
+				}
+				result, err := prompt.Run()
+				if err != nil || strings.ToLower(result) != "y" {
+					output.Info("Deletion cancelled")
+					return nil
+				}
+			}
+
+			if err := svc.DeletePolicy(cmd.Context(), project, id); err != nil {
+				return fmt.Errorf("failed to delete webhook policy: %w", err)
+			}
+			output.Success("Deleted webhook policy %d", id)
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&force, "force", false, "Force deletion without confirmation")
+	return cmd
+}
+
+func newWebhookEventsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "events <project>",
+		Short: "List supported webhook event types",
+		Args:  requireArgs(1, "requires <project>"),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := api.NewClient()
+			if err != nil {
+				return err
+			}
+			svc := harbor.NewWebhookService(client)
+			events, err := svc.ListEventTypes(cmd.Context(), args[0])
+			if err != nil {
+				return fmt.Errorf("failed to list event types: %w", err)
+			}
+
+			switch output.GetFormat() {
+			case "json":
+				return output.JSON(events)
+			case "yaml":
+				return output.YAML(events)
+			default:
+				output.PrintList("Event types", events.EventType)
+				output.Info("")
+				output.PrintList("Target types", events.NotifyType)
+				return nil
+			}
+		},
+	}
+	return cmd
+}
+
+func newWebhookExecutionsCmd() *cobra.Command {
+	var (
+		page     int
+		pageSize int
+		pages    pageFlags
+	)
+
+	cmd := &cobra.Command{
+		Use:   "executions <project> <policy>",
+		Short: "List webhook executions",
+		Args:  requireArgs(2, "requires <project> and <policy>"),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			project := args[0]
+			client, err := api.NewClient()
+			if err != nil {
+				return err
+			}
+			svc := harbor.NewWebhookService(client)
+			id, err := resolveWebhookPolicyID(cmd.Context(), svc, project, args[1])
+			if err != nil {
+				return err
+			}
+			opts := &api.ListOptions{Page: page, PageSize: pageSize}
+			var execs []*api.WebhookExecution
+			if pages.enabled() {
+				opts.Page, opts.PageSize = 0, 0
+				execs, err = svc.ListAllExecutions(cmd.Context(), project, id, opts, pages.limit)
+			} else {
+				execs, err = svc.ListExecutions(cmd.Context(), project, id, opts)
+			}
+			if err != nil {
+				return err
+			}
+			switch output.GetFormat() {
+			case "json":
+				return output.JSON(execs)
+			case "yaml":
+				return output.YAML(execs)
+			default:
+				table := output.Table()
+				table.Append([]string{"ID", "EVENT", "STATUS", "START", "END"})
+				for _, e := range execs {
+					event := ""
+					if v, ok := e.ExtraAttrs["event_type"]; ok {
+						event = fmt.Sprintf("%v", v)
+					}
+					table.Append([]string{
+						strconv.FormatInt(e.ID, 10),
+						event,
+						e.Status,
+						e.StartTime.Format("2006-01-02 15:04"),
+						e.EndTime.Format("2006-01-02 15:04"),
+					})
+				}
+				table.Render()
+			}
+			return nil
+		},
+	}
+	cmd.Flags().IntVar(&page, "page", 1, "Page number")
+	cmd.Flags().IntVar(&pageSize, "page-size", 20, "Page size")
+	pages.register(cmd)
+	return cmd
+}
+
+func newWebhookTasksCmd() *cobra.Command {
+	var (
+		page     int
+		pageSize int
+		pages    pageFlags
+	)
+
+	cmd := &cobra.Command{
+		Use:   "tasks <project> <policy> <execution-id>",
+		Short: "List tasks of a webhook execution",
+		Args:  requireArgs(3, "requires <project>, <policy> and <execution-id>"),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			project := args[0]
+			execID, err := strconv.ParseInt(args[2], 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid id: %w", err)
+			}
+			client, err := api.NewClient()
+			if err != nil {
+				return err
+			}
+			svc := harbor.NewWebhookService(client)
+			id, err := resolveWebhookPolicyID(cmd.Context(), svc, project, args[1])
+			if err != nil {
+				return err
+			}
+			opts := &api.ListOptions{Page: page, PageSize: pageSize}
+			var tasks []*api.WebhookTask
+			if pages.enabled() {
+				opts.Page, opts.PageSize = 0, 0
+				tasks, err = svc.ListAllTasks(cmd.Context(), project, id, execID, opts, pages.limit)
+			} else {
+				tasks, err = svc.ListTasks(cmd.Context(), project, id, execID, opts)
+			}
+			if err != nil {
+				return err
+			}
+			switch output.GetFormat() {
+			case "json":
+				return output.JSON(tasks)
+			case "yaml":
+				return output.YAML(tasks)
+			default:
+				table := output.Table()
+				table.Append([]string{"ID", "STATUS", "RUNS", "START", "END", "MESSAGE"})
+				for _, t := range tasks {
+					table.Append([]string{
+						strconv.FormatInt(t.ID, 10),
+						t.Status,
+						strconv.Itoa(t.RunCount),
+						t.StartTime.Format("2006-01-02 15:04"),
+						t.EndTime.Format("2006-01-02 15:04"),
+						output.Truncate(t.StatusMessage, 60),
+					})
+				}
+				table.Render()
+			}
+			return nil
+		},
+	}
+	cmd.Flags().IntVar(&page, "page", 1, "Page number")
+	cmd.Flags().IntVar(&pageSize, "page-size", 20, "Page size")
+	pages.register(cmd)
+	return cmd
+}
+
+func newWebhookLogCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "log <project> <policy> <execution-id> [task-id]",
+		Short: "Show webhook delivery logs",
+		Long:  `Show the log of a webhook task, or of every task of the execution when no task ID is given.`,
+		Args:  cobra.RangeArgs(3, 4),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			project := args[0]
+			execID, err := strconv.ParseInt(args[2], 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid id: %w", err)
+			}
+			client, err := api.NewClient()
+			if err != nil {
+				return err
+			}
+			svc := harbor.NewWebhookService(client)
+			id, err := resolveWebhookPolicyID(cmd.Context(), svc, project, args[1])
+			if err != nil {
+				return err
+			}
+
+			var taskIDs []int64
+			if len(args) == 4 {
+				taskID, err := strconv.ParseInt(args[3], 10, 64)
+				if err != nil {
+					return fmt.Errorf("invalid id: %w", err)
+				}
+				taskIDs = []int64{taskID}
+			} else {
+				tasks, err := svc.ListAllTasks(cmd.Context(), project, id, execID, nil, 0)
+				if err != nil {
+					return err
+				}
+				for _, t := range tasks {
+					taskIDs = append(taskIDs, t.ID)
+				}
+			}
+
+			for _, taskID := range taskIDs {
+				log, err := svc.GetTaskLog(cmd.Context(), project, id, execID, taskID)
+				if err != nil {
+					return err
+				}
+				output.Info("Task %d", taskID)
+				fmt.Println(log)
+			}
+			return nil
+		},
+	}
+	return cmd
+}
+
+// resolveWebhookPolicyID accepts a policy ID or name
+func resolveWebhookPolicyID(ctx context.Context, svc *harbor.WebhookService, project, ref string) (int64, error) {
+	if id, err := strconv.ParseInt(ref, 10, 64); err == nil {
+		return id, nil
+	}
+	return svc.ResolvePolicyID(ctx, project, ref)
+}
@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/pascal71/hrbcli/pkg/api"
+)
+
+func TestNewWebhookCreateCmd(t *testing.T) {
+	var reqs []recordedReq
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		r.Body.Close()
+		reqs = append(reqs, recordedReq{r.URL.Path, r.Method, body})
+		switch r.URL.Path {
+		case "/api/v2.0/projects/demo/webhook/policies":
+			w.Header().Set("Location", "/api/v2.0/projects/demo/webhook/policies/4")
+			w.WriteHeader(http.StatusCreated)
+		case "/api/v2.0/projects/demo/webhook/policies/4":
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"id":4,"name":"ci","enabled":true}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	setupEnv(server.URL)
+	defer os.Unsetenv("HARBOR_URL")
+
+	cmd := newWebhookCreateCmd()
+	cmd.SetContext(context.Background())
+	cmd.Flags().Set("name", "ci")
+	cmd.Flags().Set("address", "https://ci.example.com")
+	cmd.Flags().Set("event-type", "push_artifact,SCANNING_FAILED")
+	if err := cmd.RunE(cmd, []string{"demo"}); err != nil {
+		t.Fatalf("run error: %v", err)
+	}
+
+	if len(reqs) != 2 {
+		t.Fatalf("expected 2 requests, got %d", len(reqs))
+	}
+	var policy api.WebhookPolicy
+	json.Unmarshal(reqs[0].Body, &policy)
+	if !policy.Enabled || len(policy.Targets) != 1 || policy.Targets[0].Type != "http" || policy.Targets[0].Address != "https://ci.example.com" {
+		t.Fatalf("unexpected policy: %+v", policy)
+	}
+	if len(policy.EventTypes) != 2 || policy.EventTypes[0] != "PUSH_ARTIFACT" {
+		t.Fatalf("unexpected event types: %v", policy.EventTypes)
+	}
+}
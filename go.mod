@@ -3,6 +3,7 @@ module github.com/pascal71/hrbcli
 go 1.24.3
 
 require (
+	github.com/bmatcuk/doublestar/v4 v4.8.1
 	github.com/fatih/color v1.18.0
 	github.com/manifoldco/promptui v0.9.0
 	github.com/olekukonko/tablewriter v1.0.7
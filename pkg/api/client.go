@@ -2,9 +2,11 @@ package api
 
 import (
 	"bytes"
+	"context"
 	"crypto/tls"
 	"crypto/x509"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -24,6 +26,7 @@ type Client struct {
 	APIVersion string
 	HTTPClient *http.Client
 	Debug      bool
+	Retry      RetryPolicy
 }
 
 // NewClient creates a new Harbor API client
@@ -33,6 +36,30 @@ func NewClient() (*Client, error) {
 		return nil, fmt.Errorf("failed to load config: %w", err)
 	}
 
+	// Fall back to the credential store when no password was given
+	if err := config.ResolveCredentials(cfg); err != nil {
+		return nil, err
+	}
+
+	return NewClientFromConfig(cfg)
+}
+
+// NewClientForContext creates a Harbor API client for the named context
+// rather than the active one, using the credentials stored for it
+func NewClientForContext(name string) (*Client, error) {
+	cfg, err := config.LoadContext(name)
+	if err != nil {
+		return nil, err
+	}
+	if err := config.ResolveCredentials(cfg); err != nil {
+		return nil, err
+	}
+	return NewClientFromConfig(cfg)
+}
+
+// NewClientFromConfig creates a Harbor API client for an already loaded
+// configuration. Credentials are used as given.
+func NewClientFromConfig(cfg *config.Config) (*Client, error) {
 	if cfg.HarborURL == "" {
 		return nil, fmt.Errorf("Harbor URL not configured")
 	}
@@ -40,16 +67,16 @@ func NewClient() (*Client, error) {
 	// Ensure URL has no trailing slash
 	baseURL := strings.TrimRight(cfg.HarborURL, "/")
 
-	// Create HTTP client
-	httpClient := &http.Client{
-		Timeout: 30 * time.Second,
+	// Configure TLS
+	transport, err := newTransport(cfg)
+	if err != nil {
+		return nil, err
 	}
 
-	// Configure TLS
-	if cfg.Insecure {
-		httpClient.Transport = &http.Transport{
-			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
-		}
+	// Create HTTP client
+	httpClient := &http.Client{
+		Timeout:   30 * time.Second,
+		Transport: transport,
 	}
 
 	client := &Client{
@@ -59,6 +86,11 @@ func NewClient() (*Client, error) {
 		APIVersion: cfg.APIVersion,
 		HTTPClient: httpClient,
 		Debug:      cfg.Debug,
+		Retry: RetryPolicy{
+			MaxAttempts: cfg.MaxAttempts,
+			WaitMin:     cfg.RetryWaitMin,
+			WaitMax:     cfg.RetryWaitMax,
+		},
 	}
 
 	// Ensure global debug matches configuration
@@ -67,55 +99,78 @@ func NewClient() (*Client, error) {
 	return client, nil
 }
 
-// Request makes an HTTP request to the Harbor API
-func (c *Client) Request(method, path string, body interface{}) (*http.Response, error) {
+// Request makes an HTTP request to the Harbor API. Transient failures
+// are retried according to the client's retry policy. Cancelling ctx
+// aborts the request in flight as well as any pending retry.
+func (c *Client) Request(ctx context.Context, method, path string, body interface{}) (*http.Response, error) {
 	// Build URL
 	fullURL := fmt.Sprintf("%s/api/%s%s", c.BaseURL, c.APIVersion, path)
 
 	// Prepare body
-	var bodyReader io.Reader
+	var jsonBody []byte
 	if body != nil {
-		jsonBody, err := json.Marshal(body)
+		var err error
+		jsonBody, err = json.Marshal(body)
 		if err != nil {
 			return nil, fmt.Errorf("failed to marshal request body: %w", err)
 		}
-		bodyReader = bytes.NewReader(jsonBody)
 
 		if c.Debug {
 			output.Debug("Request body: %s", string(jsonBody))
 		}
 	}
 
-	// Create request
-	req, err := http.NewRequest(method, fullURL, bodyReader)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+	attempts := c.Retry.MaxAttempts
+	if attempts < 1 {
+		attempts = 1
 	}
 
-	// Set headers
-	req.Header.Set("Accept", "application/json")
-	if body != nil {
-		req.Header.Set("Content-Type", "application/json")
-	}
+	var (
+		resp *http.Response
+		err  error
+	)
+	for attempt := 1; ; attempt++ {
+		resp, err = c.do(ctx, method, fullURL, jsonBody)
+		if attempt >= attempts {
+			break
+		}
 
-	// Set basic auth
-	if c.Username != "" && c.Password != "" {
-		req.SetBasicAuth(c.Username, c.Password)
-	}
+		var wait time.Duration
+		if err != nil {
+			if ctx.Err() != nil || !idempotentMethod(method) || isTLSError(err) {
+				break
+			}
+			wait = c.Retry.backoff(attempt)
+		} else {
+			if !retryableStatus(method, resp.StatusCode) {
+				break
+			}
+			wait = c.Retry.backoff(attempt)
+			if d, ok := retryAfter(resp, time.Now()); ok {
+				wait = c.Retry.limit(d)
+			}
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
 
-	if c.Debug {
-		output.Debug("%s %s", method, fullURL)
+		if c.Debug {
+			reason := ""
+			if err != nil {
+				reason = err.Error()
+			} else {
+				reason = resp.Status
+			}
+			output.Debug("Retrying %s %s in %s after %s (attempt %d/%d)", method, fullURL, wait, reason, attempt+1, attempts)
+		}
+		if err = sleep(ctx, wait); err != nil {
+			return nil, fmt.Errorf("request failed: %w", err)
+		}
 	}
 
-	// Make request
-	resp, err := c.HTTPClient.Do(req)
 	if err != nil {
 		// Provide clearer error for TLS verification failures
-		if urlErr, ok := err.(*url.Error); ok {
-			switch urlErr.Err.(type) {
-			case x509.UnknownAuthorityError, x509.HostnameError, x509.CertificateInvalidError:
-				return nil, fmt.Errorf("TLS certificate verification failed: %w", urlErr.Err)
-			}
+		if urlErr, ok := err.(*url.Error); ok && isTLSError(err) {
+			return nil, fmt.Errorf("TLS certificate verification failed: %w (trust the Harbor CA with --ca-cert or 'hrbcli config fetch-ca')", urlErr.Err)
 		}
 		return nil, fmt.Errorf("request failed: %w", err)
 	}
@@ -143,8 +198,51 @@ func (c *Client) Request(method, path string, body interface{}) (*http.Response,
 	return resp, nil
 }
 
+// do sends a single attempt of a request. jsonBody is nil for requests
+// without a body.
+func (c *Client) do(ctx context.Context, method, fullURL string, jsonBody []byte) (*http.Response, error) {
+	var bodyReader io.Reader
+	if jsonBody != nil {
+		bodyReader = bytes.NewReader(jsonBody)
+	}
+
+	// Create request
+	req, err := http.NewRequestWithContext(ctx, method, fullURL, bodyReader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	// Set headers
+	req.Header.Set("Accept", "application/json")
+	if jsonBody != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	// Set basic auth
+	if c.Username != "" && c.Password != "" {
+		req.SetBasicAuth(c.Username, c.Password)
+	}
+
+	if c.Debug {
+		output.Debug("%s %s", method, fullURL)
+	}
+
+	return c.HTTPClient.Do(req)
+}
+
+// isTLSError reports whether err is a certificate verification failure,
+// which retrying cannot fix
+func isTLSError(err error) bool {
+	var verifyErr *tls.CertificateVerificationError
+	var authorityErr x509.UnknownAuthorityError
+	var hostnameErr x509.HostnameError
+	var invalidErr x509.CertificateInvalidError
+	return errors.As(err, &verifyErr) || errors.As(err, &authorityErr) ||
+		errors.As(err, &hostnameErr) || errors.As(err, &invalidErr)
+}
+
 // Get makes a GET request
-func (c *Client) Get(path string, params map[string]string) (*http.Response, error) {
+func (c *Client) Get(ctx context.Context, path string, params map[string]string) (*http.Response, error) {
 	if len(params) > 0 {
 		values := url.Values{}
 		for k, v := range params {
@@ -152,27 +250,32 @@ func (c *Client) Get(path string, params map[string]string) (*http.Response, err
 		}
 		path = path + "?" + values.Encode()
 	}
-	return c.Request("GET", path, nil)
+	return c.Request(ctx, "GET", path, nil)
 }
 
 // Post makes a POST request
-func (c *Client) Post(path string, body interface{}) (*http.Response, error) {
-	return c.Request("POST", path, body)
+func (c *Client) Post(ctx context.Context, path string, body interface{}) (*http.Response, error) {
+	return c.Request(ctx, "POST", path, body)
 }
 
 // Put makes a PUT request
-func (c *Client) Put(path string, body interface{}) (*http.Response, error) {
-	return c.Request("PUT", path, body)
+func (c *Client) Put(ctx context.Context, path string, body interface{}) (*http.Response, error) {
+	return c.Request(ctx, "PUT", path, body)
+}
+
+// Patch makes a PATCH request
+func (c *Client) Patch(ctx context.Context, path string, body interface{}) (*http.Response, error) {
+	return c.Request(ctx, "PATCH", path, body)
 }
 
 // Delete makes a DELETE request
-func (c *Client) Delete(path string) (*http.Response, error) {
-	return c.Request("DELETE", path, nil)
+func (c *Client) Delete(ctx context.Context, path string) (*http.Response, error) {
+	return c.Request(ctx, "DELETE", path, nil)
 }
 
 // Head makes a HEAD request
-func (c *Client) Head(path string) (*http.Response, error) {
-	return c.Request("HEAD", path, nil)
+func (c *Client) Head(ctx context.Context, path string) (*http.Response, error) {
+	return c.Request(ctx, "HEAD", path, nil)
 }
 
 // DecodeResponse decodes a JSON response
@@ -196,8 +299,8 @@ func (c *Client) DecodeResponse(resp *http.Response, v interface{}) error {
 }
 
 // CheckHealth checks if Harbor is healthy
-func (c *Client) CheckHealth() error {
-	resp, err := c.Get("/health", nil)
+func (c *Client) CheckHealth(ctx context.Context) error {
+	resp, err := c.Get(ctx, "/health", nil)
 	if err != nil {
 		return err
 	}
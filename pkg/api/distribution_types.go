@@ -17,6 +17,7 @@ type PreheatPolicy struct {
 	ID           int64     `json:"id"`
 	Name         string    `json:"name"`
 	Description  string    `json:"description,omitempty"`
+	ProjectID    int64     `json:"project_id,omitempty"`
 	ProviderID   int64     `json:"provider_id,omitempty"`
 	ProviderName string    `json:"provider_name,omitempty"`
 	Filters      string    `json:"filters,omitempty"`
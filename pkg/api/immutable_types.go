@@ -0,0 +1,27 @@
+package api
+
+// Fixed values Harbor expects on immutable tag rules
+const (
+	ImmutableRuleAction   = "immutable"
+	ImmutableRuleTemplate = "immutable_template"
+)
+
+// ImmutableRule represents a project immutable tag rule
+type ImmutableRule struct {
+	ID             int64                           `json:"id,omitempty"`
+	Priority       int                             `json:"priority"`
+	Disabled       bool                            `json:"disabled"`
+	Action         string                          `json:"action"`
+	Template       string                          `json:"template"`
+	Params         map[string]interface{}          `json:"params,omitempty"`
+	TagSelectors   []*ImmutableSelector            `json:"tag_selectors"`
+	ScopeSelectors map[string][]*ImmutableSelector `json:"scope_selectors"`
+}
+
+// ImmutableSelector selects repositories or tags by pattern
+type ImmutableSelector struct {
+	Kind       string `json:"kind"`
+	Decoration string `json:"decoration"`
+	Pattern    string `json:"pattern"`
+	Extras     string `json:"extras,omitempty"`
+}
@@ -0,0 +1,68 @@
+package api
+
+// Project member roles as defined by Harbor
+const (
+	RoleProjectAdmin = 1
+	RoleDeveloper    = 2
+	RoleGuest        = 3
+	RoleMaintainer   = 4
+	RoleLimitedGuest = 5
+)
+
+// Member entity types
+const (
+	MemberEntityUser  = "u"
+	MemberEntityGroup = "g"
+)
+
+// User group types
+const (
+	GroupTypeLDAP = 1
+	GroupTypeHTTP = 2
+	GroupTypeOIDC = 3
+)
+
+// ProjectMemberEntity represents a member of a project
+type ProjectMemberEntity struct {
+	ID         int64  `json:"id"`
+	ProjectID  int64  `json:"project_id"`
+	EntityName string `json:"entity_name"`
+	RoleName   string `json:"role_name"`
+	RoleID     int64  `json:"role_id"`
+	EntityID   int64  `json:"entity_id"`
+	EntityType string `json:"entity_type"`
+}
+
+// ProjectMember represents a request to add a member to a project.
+// Either MemberUser or MemberGroup must be set.
+type ProjectMember struct {
+	RoleID      int64       `json:"role_id"`
+	MemberUser  *UserEntity `json:"member_user,omitempty"`
+	MemberGroup *UserGroup  `json:"member_group,omitempty"`
+}
+
+// UserEntity identifies a user when adding a project member
+type UserEntity struct {
+	UserID   int    `json:"user_id,omitempty"`
+	Username string `json:"username,omitempty"`
+}
+
+// UserGroup identifies an LDAP, HTTP or OIDC group
+type UserGroup struct {
+	ID          int64  `json:"id,omitempty"`
+	GroupName   string `json:"group_name,omitempty"`
+	GroupType   int    `json:"group_type,omitempty"`
+	LdapGroupDN string `json:"ldap_group_dn,omitempty"`
+}
+
+// RoleRequest represents a request to change a member's role
+type RoleRequest struct {
+	RoleID int64 `json:"role_id"`
+}
+
+// MemberListOptions represents options when listing project members
+type MemberListOptions struct {
+	Page       int    `json:"page,omitempty"`
+	PageSize   int    `json:"page_size,omitempty"`
+	EntityName string `json:"entityname,omitempty"`
+}
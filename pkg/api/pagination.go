@@ -0,0 +1,141 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+)
+
+// MaxPageSize is the largest page size Harbor accepts on list endpoints
+const MaxPageSize = 100
+
+// PageInfo holds the pagination headers of a Harbor list response
+type PageInfo struct {
+	// Total is the X-Total-Count header, or -1 when it is absent
+	Total int64
+	// HasLink reports whether a Link header was present at all
+	HasLink bool
+	// Next is the target of the rel="next" link, empty on the last page
+	Next string
+}
+
+var linkPattern = regexp.MustCompile(`<([^>]*)>\s*;\s*rel="?([^",;]+)"?`)
+
+// ParsePageInfo extracts pagination information from response headers
+func ParsePageInfo(header http.Header) PageInfo {
+	info := PageInfo{Total: -1}
+
+	if v := header.Get("X-Total-Count"); v != "" {
+		if n, err := strconv.ParseInt(v, 10, 64); err == nil {
+			info.Total = n
+		}
+	}
+
+	for _, link := range header.Values("Link") {
+		info.HasLink = true
+		for _, m := range linkPattern.FindAllStringSubmatch(link, -1) {
+			if m[2] == "next" {
+				info.Next = m[1]
+			}
+		}
+	}
+
+	return info
+}
+
+// ListAll walks every page of a list endpoint and decodes the items.
+// params are the query parameters of the first request; page and
+// page_size default to 1 and MaxPageSize. Pages are followed using the
+// Link header when Harbor sends one, otherwise until X-Total-Count items
+// have been read or a short page is returned. A positive limit stops the
+// walk once that many items have been collected.
+func ListAll[T any](ctx context.Context, c *Client, path string, params map[string]string, limit int) ([]T, error) {
+	query := make(map[string]string, len(params)+2)
+	for k, v := range params {
+		query[k] = v
+	}
+	if query["page"] == "" {
+		query["page"] = "1"
+	}
+	if query["page_size"] == "" {
+		query["page_size"] = strconv.Itoa(MaxPageSize)
+	}
+	pageSize, err := strconv.Atoi(query["page_size"])
+	if err != nil || pageSize <= 0 {
+		return nil, fmt.Errorf("invalid page size: %s", query["page_size"])
+	}
+
+	var all []T
+	for {
+		resp, err := c.Get(ctx, path, query)
+		if err != nil {
+			return nil, err
+		}
+		info := ParsePageInfo(resp.Header)
+
+		var items []T
+		if err := c.DecodeResponse(resp, &items); err != nil {
+			return nil, fmt.Errorf("failed to decode page %s: %w", query["page"], err)
+		}
+		all = append(all, items...)
+
+		if limit > 0 && len(all) >= limit {
+			return all[:limit], nil
+		}
+		if len(items) == 0 {
+			return all, nil
+		}
+
+		next, ok := nextPageQuery(query, info, len(items), pageSize, len(all))
+		if !ok {
+			return all, nil
+		}
+		query = next
+	}
+}
+
+// nextPageQuery returns the query for the page after query, or false when
+// the current page was the last one
+func nextPageQuery(query map[string]string, info PageInfo, pageItems, pageSize, seen int) (map[string]string, bool) {
+	next := make(map[string]string, len(query))
+	for k, v := range query {
+		next[k] = v
+	}
+
+	if info.HasLink {
+		if info.Next == "" {
+			return nil, false
+		}
+		u, err := url.Parse(info.Next)
+		if err != nil {
+			return nil, false
+		}
+		for k, v := range u.Query() {
+			if len(v) > 0 {
+				next[k] = v[0]
+			}
+		}
+		// Guard against a server repeating the same link
+		if next["page"] == query["page"] {
+			return nil, false
+		}
+		return next, true
+	}
+
+	if info.Total >= 0 && int64(seen) >= info.Total {
+		return nil, false
+	}
+	if pageItems < pageSize {
+		return nil, false
+	}
+
+	page, err := strconv.Atoi(query["page"])
+	if err != nil {
+		return nil, false
+	}
+	next["page"] = strconv.Itoa(page + 1)
+	return next, true
+}
@@ -0,0 +1,79 @@
+package api
+
+import "time"
+
+// Retention rule templates supported by Harbor
+const (
+	RetentionTemplateLatestPushedK      = "latestPushedK"
+	RetentionTemplateLatestPulledN      = "latestPulledN"
+	RetentionTemplateNDaysSinceLastPush = "nDaysSinceLastPush"
+	RetentionTemplateNDaysSinceLastPull = "nDaysSinceLastPull"
+	RetentionTemplateAlways             = "always"
+)
+
+// RetentionPolicy represents a tag retention policy
+type RetentionPolicy struct {
+	ID        int64             `json:"id,omitempty"`
+	Algorithm string            `json:"algorithm"`
+	Rules     []*RetentionRule  `json:"rules"`
+	Trigger   *RetentionTrigger `json:"trigger,omitempty"`
+	Scope     *RetentionScope   `json:"scope,omitempty"`
+}
+
+// RetentionRule represents a single retention rule
+type RetentionRule struct {
+	ID             int64                           `json:"id,omitempty"`
+	Priority       int                             `json:"priority"`
+	Disabled       bool                            `json:"disabled"`
+	Action         string                          `json:"action"`
+	Template       string                          `json:"template"`
+	Params         map[string]interface{}          `json:"params,omitempty"`
+	TagSelectors   []*RetentionSelector            `json:"tag_selectors"`
+	ScopeSelectors map[string][]*RetentionSelector `json:"scope_selectors"`
+}
+
+// RetentionSelector selects repositories or tags by pattern
+type RetentionSelector struct {
+	Kind       string `json:"kind"`
+	Decoration string `json:"decoration"`
+	Pattern    string `json:"pattern"`
+	Extras     string `json:"extras,omitempty"`
+}
+
+// RetentionTrigger represents when a retention policy runs
+type RetentionTrigger struct {
+	Kind       string                 `json:"kind"`
+	Settings   map[string]interface{} `json:"settings,omitempty"`
+	References map[string]interface{} `json:"references,omitempty"`
+}
+
+// RetentionScope binds a retention policy to a project
+type RetentionScope struct {
+	Level string `json:"level"`
+	Ref   int64  `json:"ref"`
+}
+
+// RetentionExecution represents a retention policy run
+type RetentionExecution struct {
+	ID        int64     `json:"id"`
+	PolicyID  int64     `json:"policy_id"`
+	StartTime time.Time `json:"start_time"`
+	EndTime   time.Time `json:"end_time,omitempty"`
+	Status    string    `json:"status"`
+	Trigger   string    `json:"trigger"`
+	DryRun    bool      `json:"dry_run"`
+}
+
+// RetentionExecutionTask represents the retention run of one repository
+type RetentionExecutionTask struct {
+	ID          int64     `json:"id"`
+	ExecutionID int64     `json:"execution_id"`
+	Repository  string    `json:"repository"`
+	JobID       string    `json:"job_id"`
+	Status      string    `json:"status"`
+	StatusCode  int       `json:"status_code"`
+	StartTime   time.Time `json:"start_time"`
+	EndTime     time.Time `json:"end_time,omitempty"`
+	Total       int       `json:"total"`
+	Retained    int       `json:"retained"`
+}
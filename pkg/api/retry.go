@@ -0,0 +1,138 @@
+package api
+
+import (
+	"context"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/pascal71/hrbcli/pkg/config"
+)
+
+// Default retry settings used when none are configured
+const (
+	DefaultMaxAttempts  = config.DefaultMaxAttempts
+	DefaultRetryWaitMin = config.DefaultRetryWaitMin
+	DefaultRetryWaitMax = config.DefaultRetryWaitMax
+)
+
+// RetryPolicy controls how failed requests are retried. The zero value
+// makes a single attempt.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts including the first
+	MaxAttempts int
+	// WaitMin is the backoff before the first retry; it doubles on every
+	// further retry up to WaitMax
+	WaitMin time.Duration
+	WaitMax time.Duration
+}
+
+// DefaultRetryPolicy returns the retry policy used by NewClient when the
+// configuration does not override it
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: DefaultMaxAttempts,
+		WaitMin:     DefaultRetryWaitMin,
+		WaitMax:     DefaultRetryWaitMax,
+	}
+}
+
+// sleep waits for d or until ctx is done, whichever comes first. It is
+// replaced in tests.
+var sleep = func(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// idempotentMethod reports whether repeating a request with method has
+// the same effect as sending it once
+func idempotentMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// retryableStatus reports whether a response status is worth retrying.
+// 429 and 503 mean the request was rejected before being processed, so
+// they are safe for every method. Gateway errors are only retried for
+// idempotent methods since the backend may have handled the request.
+func retryableStatus(method string, status int) bool {
+	switch status {
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+		return true
+	case http.StatusBadGateway, http.StatusGatewayTimeout:
+		return idempotentMethod(method)
+	}
+	return false
+}
+
+// bounds returns the configured minimum and maximum wait, falling back
+// to the default minimum
+func (p RetryPolicy) bounds() (waitMin, waitMax time.Duration) {
+	waitMin, waitMax = p.WaitMin, p.WaitMax
+	if waitMin <= 0 {
+		waitMin = DefaultRetryWaitMin
+	}
+	if waitMax < waitMin {
+		waitMax = waitMin
+	}
+	return waitMin, waitMax
+}
+
+// limit caps a wait requested by the server at WaitMax, so a large
+// Retry-After cannot stall a command
+func (p RetryPolicy) limit(d time.Duration) time.Duration {
+	if _, waitMax := p.bounds(); d > waitMax {
+		return waitMax
+	}
+	return d
+}
+
+// backoff returns the wait before retry number attempt (1-based) using
+// exponential backoff with jitter
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	waitMin, waitMax := p.bounds()
+
+	wait := waitMin
+	for i := 1; i < attempt && wait < waitMax; i++ {
+		wait *= 2
+	}
+	if wait > waitMax {
+		wait = waitMax
+	}
+
+	// Jitter in [wait/2, wait) spreads out clients retrying together
+	half := wait / 2
+	if half <= 0 {
+		return wait
+	}
+	return half + time.Duration(rand.Int63n(int64(half)))
+}
+
+// retryAfter parses a Retry-After header given in seconds or as an HTTP
+// date. It returns false when the header is absent or invalid.
+func retryAfter(resp *http.Response, now time.Time) (time.Duration, bool) {
+	v := resp.Header.Get("Retry-After")
+	if v == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(v); err == nil && secs >= 0 {
+		return time.Duration(secs) * time.Second, true
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		if d := t.Sub(now); d > 0 {
+			return d, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
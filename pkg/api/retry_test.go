@@ -0,0 +1,152 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func newRetryTestClient(t *testing.T, handler http.HandlerFunc) (*Client, *[]time.Duration) {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	var waits []time.Duration
+	orig := sleep
+	sleep = func(ctx context.Context, d time.Duration) error {
+		waits = append(waits, d)
+		return ctx.Err()
+	}
+	t.Cleanup(func() { sleep = orig })
+
+	return &Client{
+		BaseURL:    server.URL,
+		APIVersion: "v2.0",
+		HTTPClient: server.Client(),
+		Retry:      RetryPolicy{MaxAttempts: 3, WaitMin: 10 * time.Millisecond, WaitMax: time.Second},
+	}, &waits
+}
+
+func TestRequestRetriesHonorRetryAfter(t *testing.T) {
+	calls := 0
+	client, waits := newRetryTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte("{}"))
+	})
+
+	resp, err := client.Get(context.Background(), "/projects", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp.Body.Close()
+
+	if calls != 2 {
+		t.Fatalf("expected 2 calls, got %d", calls)
+	}
+	if len(*waits) != 1 || (*waits)[0] != time.Second {
+		t.Fatalf("expected a 1s wait, got %v", *waits)
+	}
+}
+
+func TestRequestCapsRetryAfterAtWaitMax(t *testing.T) {
+	calls := 0
+	client, waits := newRetryTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.Header().Set("Retry-After", "3600")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte("{}"))
+	})
+
+	resp, err := client.Get(context.Background(), "/projects", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp.Body.Close()
+
+	if len(*waits) != 1 || (*waits)[0] != client.Retry.WaitMax {
+		t.Fatalf("expected the wait capped at %s, got %v", client.Retry.WaitMax, *waits)
+	}
+}
+
+func TestRequestDoesNotRetryNonIdempotentOnGatewayError(t *testing.T) {
+	calls := 0
+	client, _ := newRetryTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusBadGateway)
+	})
+
+	if _, err := client.Post(context.Background(), "/projects", map[string]string{"project_name": "demo"}); err == nil {
+		t.Fatalf("expected error")
+	}
+	if calls != 1 {
+		t.Fatalf("expected 1 call, got %d", calls)
+	}
+}
+
+func TestRequestRetriesPostOnTooManyRequests(t *testing.T) {
+	var bodies []string
+	client, waits := newRetryTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		buf := make([]byte, r.ContentLength)
+		r.Body.Read(buf)
+		bodies = append(bodies, string(buf))
+		w.WriteHeader(http.StatusTooManyRequests)
+	})
+
+	_, err := client.Post(context.Background(), "/projects", map[string]string{"project_name": "demo"})
+	apiErr, ok := err.(*APIError)
+	if !ok || apiErr.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected 429 APIError, got %v", err)
+	}
+	if len(bodies) != 3 || bodies[2] != bodies[0] || bodies[0] == "" {
+		t.Fatalf("expected the body to be resent on every attempt, got %q", bodies)
+	}
+	if len(*waits) != 2 {
+		t.Fatalf("expected 2 waits, got %v", *waits)
+	}
+	for _, w := range *waits {
+		if w <= 0 || w > time.Second {
+			t.Fatalf("backoff out of range: %v", w)
+		}
+	}
+}
+
+func TestRequestStopsRetryingWhenContextIsCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	calls := 0
+	client, _ := newRetryTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		cancel()
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+
+	_, err := client.Get(ctx, "/projects", nil)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if calls != 1 {
+		t.Fatalf("expected 1 call, got %d", calls)
+	}
+}
+
+func TestRetryPolicyBackoffGrowsAndCaps(t *testing.T) {
+	p := RetryPolicy{WaitMin: 100 * time.Millisecond, WaitMax: 400 * time.Millisecond}
+	for attempt, max := range map[int]time.Duration{1: 100, 2: 200, 3: 400, 6: 400} {
+		d := p.backoff(attempt)
+		if d < max*time.Millisecond/2 || d >= max*time.Millisecond {
+			t.Errorf("attempt %d: backoff %v outside [%v, %v)", attempt, d, max*time.Millisecond/2, max*time.Millisecond)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package api
+
+import "time"
+
+// Robot levels supported by Harbor
+const (
+	RobotLevelSystem  = "system"
+	RobotLevelProject = "project"
+)
+
+// Robot represents a Harbor robot account
+type Robot struct {
+	ID           int64              `json:"id"`
+	Name         string             `json:"name"`
+	Description  string             `json:"description,omitempty"`
+	Secret       string             `json:"secret,omitempty"`
+	Level        string             `json:"level"`
+	Duration     int64              `json:"duration"`
+	Editable     bool               `json:"editable"`
+	Disable      bool               `json:"disable"`
+	ExpiresAt    int64              `json:"expires_at"`
+	Permissions  []*RobotPermission `json:"permissions"`
+	CreationTime time.Time          `json:"creation_time,omitempty"`
+	UpdateTime   time.Time          `json:"update_time,omitempty"`
+}
+
+// RobotPermission grants a set of accesses on a namespace.
+// Kind is either "system" or "project"; Namespace is a project
+// name, "*" for all projects, or "/" for system permissions.
+type RobotPermission struct {
+	Kind      string         `json:"kind" yaml:"kind"`
+	Namespace string         `json:"namespace" yaml:"namespace"`
+	Access    []*RobotAccess `json:"access" yaml:"access"`
+}
+
+// RobotAccess represents a single resource/action pair
+type RobotAccess struct {
+	Resource string `json:"resource" yaml:"resource"`
+	Action   string `json:"action" yaml:"action"`
+	Effect   string `json:"effect,omitempty" yaml:"effect,omitempty"`
+}
+
+// RobotCreate represents a robot account creation request
+type RobotCreate struct {
+	Name        string             `json:"name"`
+	Description string             `json:"description,omitempty"`
+	Secret      string             `json:"secret,omitempty"`
+	Level       string             `json:"level"`
+	Disable     bool               `json:"disable"`
+	Duration    int64              `json:"duration"`
+	Permissions []*RobotPermission `json:"permissions"`
+}
+
+// RobotCreated is returned by Harbor after creating a robot account.
+// The secret is only available in this response.
+type RobotCreated struct {
+	ID           int64     `json:"id"`
+	Name         string    `json:"name"`
+	Secret       string    `json:"secret"`
+	CreationTime time.Time `json:"creation_time"`
+	ExpiresAt    int64     `json:"expires_at"`
+}
+
+// RobotSec represents a robot secret refresh request or response
+type RobotSec struct {
+	Secret string `json:"secret,omitempty"`
+}
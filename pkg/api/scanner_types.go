@@ -1,5 +1,7 @@
 package api
 
+import "time"
+
 // VulnerabilityReport represents a vulnerability scanning report
 // Only fields needed by the CLI are included.
 type VulnerabilityReport struct {
@@ -19,20 +21,102 @@ type VulnerabilitySummary struct {
 // VulnerabilityItem represents a single vulnerability entry
 // returned by Harbor scanners.
 type VulnerabilityItem struct {
-	CVEID        string `json:"id"`
-	Package      string `json:"package"`
-	Version      string `json:"version"`
-	FixedVersion string `json:"fix_version"`
-	Severity     string `json:"severity"`
+	CVEID        string   `json:"id" yaml:"id"`
+	Package      string   `json:"package" yaml:"package"`
+	Version      string   `json:"version" yaml:"version"`
+	FixedVersion string   `json:"fix_version" yaml:"fix_version"`
+	Severity     string   `json:"severity" yaml:"severity"`
+	Description  string   `json:"description,omitempty" yaml:"description,omitempty"`
+	Links        []string `json:"links,omitempty" yaml:"links,omitempty"`
 }
 
 // NativeReportSummary represents the summary of a scan report
 // attached to an artifact. Only fields relevant for displaying
-// running scans are included.
+// running and finished scans are included.
 type NativeReportSummary struct {
 	ReportID    string               `json:"report_id"`
 	ScanStatus  string               `json:"scan_status"`
 	Severity    string               `json:"severity"`
 	CompletePct int                  `json:"complete_percent"`
+	StartTime   time.Time            `json:"start_time,omitempty"`
+	EndTime     time.Time            `json:"end_time,omitempty"`
+	Duration    int64                `json:"duration,omitempty"`
 	Summary     VulnerabilitySummary `json:"summary"`
 }
+
+// ScanAllSchedule is the schedule of the system-wide scan of all
+// artifacts
+type ScanAllSchedule struct {
+	ID           int64     `json:"id,omitempty"`
+	Status       string    `json:"status,omitempty"`
+	Schedule     *Schedule `json:"schedule,omitempty"`
+	CreationTime time.Time `json:"creation_time,omitempty"`
+	UpdateTime   time.Time `json:"update_time,omitempty"`
+}
+
+// ScanAllMetrics reports the progress of the latest scan of all
+// artifacts
+type ScanAllMetrics struct {
+	Total     int `json:"total"`
+	Completed int `json:"completed"`
+	// Metrics counts the scans per status, e.g. Success or Error
+	Metrics map[string]int `json:"metrics"`
+	Ongoing bool           `json:"ongoing"`
+	Trigger string         `json:"trigger"`
+}
+
+// ScannerRegistration is a scanner adapter registered in Harbor
+type ScannerRegistration struct {
+	UUID             string                 `json:"uuid"`
+	Name             string                 `json:"name"`
+	Description      string                 `json:"description,omitempty"`
+	URL              string                 `json:"url"`
+	Disabled         bool                   `json:"disabled"`
+	IsDefault        bool                   `json:"is_default"`
+	Health           string                 `json:"health,omitempty"`
+	Auth             string                 `json:"auth,omitempty"`
+	AccessCredential string                 `json:"access_credential,omitempty"`
+	SkipCertVerify   bool                   `json:"skip_certVerify"`
+	UseInternalAddr  bool                   `json:"use_internal_addr"`
+	Adapter          string                 `json:"adapter,omitempty"`
+	Vendor           string                 `json:"vendor,omitempty"`
+	Version          string                 `json:"version,omitempty"`
+	Capabilities     map[string]interface{} `json:"capabilities,omitempty"`
+	CreateTime       time.Time              `json:"create_time,omitempty"`
+	UpdateTime       time.Time              `json:"update_time,omitempty"`
+}
+
+// ScannerRegistrationReq creates or updates a scanner registration. It
+// is also used to ping a scanner adapter before registering it.
+type ScannerRegistrationReq struct {
+	Name             string `json:"name"`
+	Description      string `json:"description,omitempty"`
+	URL              string `json:"url"`
+	Auth             string `json:"auth,omitempty"`
+	AccessCredential string `json:"access_credential,omitempty"`
+	SkipCertVerify   bool   `json:"skip_certVerify"`
+	UseInternalAddr  bool   `json:"use_internal_addr"`
+	Disabled         bool   `json:"disabled"`
+}
+
+// ScannerAdapterMetadata describes a scanner adapter and what it can scan
+type ScannerAdapterMetadata struct {
+	Scanner      *Scanner             `json:"scanner"`
+	Capabilities []*ScannerCapability `json:"capabilities"`
+	Properties   map[string]string    `json:"properties,omitempty"`
+}
+
+// Scanner identifies the scanner behind an adapter
+type Scanner struct {
+	Name    string `json:"name"`
+	Vendor  string `json:"vendor"`
+	Version string `json:"version"`
+}
+
+// ScannerCapability lists the artifact types a scanner consumes and the
+// reports it produces
+type ScannerCapability struct {
+	Type              string   `json:"type,omitempty"`
+	ConsumesMimeTypes []string `json:"consumes_mime_types"`
+	ProducesMimeTypes []string `json:"produces_mime_types"`
+}
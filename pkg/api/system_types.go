@@ -4,8 +4,9 @@ import "time"
 
 // Schedule represents a job schedule configuration.
 type Schedule struct {
-	Type string `json:"type"`
-	Cron string `json:"cron,omitempty"`
+	Type              string     `json:"type"`
+	Cron              string     `json:"cron,omitempty"`
+	NextScheduledTime *time.Time `json:"next_scheduled_time,omitempty"`
 }
 
 // GCHistory represents a garbage collection execution record.
@@ -0,0 +1,109 @@
+package api
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pascal71/hrbcli/pkg/config"
+)
+
+func writePEM(t *testing.T, path, typ string, der []byte) {
+	t.Helper()
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: typ, Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// writeClientCert writes a self-signed client certificate and its key
+func writeClientCert(t *testing.T, dir string) (string, string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "hrbcli"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certFile, keyFile := filepath.Join(dir, "client.crt"), filepath.Join(dir, "client.key")
+	writePEM(t, certFile, "CERTIFICATE", der)
+	writePEM(t, keyFile, "EC PRIVATE KEY", keyDER)
+	return certFile, keyFile
+}
+
+func TestNewClientFromConfigTrustsCACert(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.TLS == nil || len(r.TLS.PeerCertificates) == 0 {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+	server.StartTLS()
+	defer server.Close()
+
+	dir := t.TempDir()
+	caFile := filepath.Join(dir, "ca.crt")
+	writePEM(t, caFile, "CERTIFICATE", server.Certificate().Raw)
+	certFile, keyFile := writeClientCert(t, dir)
+
+	cfg := &config.Config{HarborURL: server.URL, APIVersion: "v2.0", MaxAttempts: 1}
+
+	// Neither the CA nor a client certificate: verification fails
+	client, err := NewClientFromConfig(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = client.CheckHealth(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "TLS certificate verification failed") {
+		t.Fatalf("expected a verification error, got %v", err)
+	}
+
+	cfg.CACert, cfg.ClientCert, cfg.ClientKey = caFile, certFile, keyFile
+	client, err = NewClientFromConfig(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := client.CheckHealth(context.Background()); err != nil {
+		t.Fatalf("expected the trusted CA and client certificate to work: %v", err)
+	}
+}
+
+func TestTLSConfigRequiresCertAndKey(t *testing.T) {
+	if _, err := TLSConfig(&config.Config{ClientCert: "client.crt"}); err == nil {
+		t.Fatalf("expected an error without client_key")
+	}
+	caFile := filepath.Join(t.TempDir(), "ca.crt")
+	if err := os.WriteFile(caFile, []byte("not a certificate"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := TLSConfig(&config.Config{CACert: caFile}); err == nil {
+		t.Fatalf("expected an error for a CA file without certificates")
+	}
+}
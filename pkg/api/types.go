@@ -18,6 +18,7 @@ type Project struct {
 	Metadata     *ProjectMetadata `json:"metadata,omitempty"`
 	CVEAllowlist *CVEAllowlist    `json:"cve_allowlist,omitempty"`
 	StorageLimit int64            `json:"storage_limit,omitempty"`
+	RegistryID   int64            `json:"registry_id,omitempty"`
 }
 
 // ProjectReq represents a project creation/update request
@@ -184,6 +185,8 @@ type Artifact struct {
 	ExtraAttrs   *ExtraAttrs                    `json:"extra_attrs,omitempty"`
 	Signatures   []Signature                    `json:"signatures,omitempty"`
 	ScanOverview map[string]NativeReportSummary `json:"scan_overview,omitempty"`
+	PushTime     time.Time                      `json:"push_time,omitempty"`
+	PullTime     time.Time                      `json:"pull_time,omitempty"`
 }
 
 // ArtifactTag represents a tag associated with an artifact
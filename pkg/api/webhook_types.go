@@ -0,0 +1,85 @@
+package api
+
+import "time"
+
+// Webhook target types
+const (
+	WebhookTargetHTTP  = "http"
+	WebhookTargetSlack = "slack"
+)
+
+// WebhookPolicy represents a project webhook policy
+type WebhookPolicy struct {
+	ID           int64            `json:"id,omitempty"`
+	Name         string           `json:"name"`
+	Description  string           `json:"description,omitempty"`
+	ProjectID    int64            `json:"project_id,omitempty"`
+	Targets      []*WebhookTarget `json:"targets"`
+	EventTypes   []string         `json:"event_types"`
+	Creator      string           `json:"creator,omitempty"`
+	Enabled      bool             `json:"enabled"`
+	CreationTime time.Time        `json:"creation_time,omitempty"`
+	UpdateTime   time.Time        `json:"update_time,omitempty"`
+}
+
+// WebhookTarget represents the endpoint notified by a webhook policy
+type WebhookTarget struct {
+	Type           string `json:"type"`
+	Address        string `json:"address"`
+	AuthHeader     string `json:"auth_header,omitempty"`
+	SkipCertVerify bool   `json:"skip_cert_verify"`
+	PayloadFormat  string `json:"payload_format,omitempty"`
+}
+
+// SupportedWebhookEventTypes lists event and notify types supported
+// by the Harbor instance
+type SupportedWebhookEventTypes struct {
+	EventType      []string                `json:"event_type"`
+	NotifyType     []string                `json:"notify_type"`
+	PayloadFormats []*WebhookPayloadFormat `json:"payload_formats,omitempty"`
+}
+
+// WebhookPayloadFormat lists payload formats available for a notify type
+type WebhookPayloadFormat struct {
+	NotifyType string   `json:"notify_type"`
+	Formats    []string `json:"formats"`
+}
+
+// WebhookExecution represents a webhook delivery execution
+type WebhookExecution struct {
+	ID            int64                  `json:"id"`
+	VendorType    string                 `json:"vendor_type"`
+	VendorID      int64                  `json:"vendor_id"`
+	Status        string                 `json:"status"`
+	StatusMessage string                 `json:"status_message,omitempty"`
+	Metrics       *ExecutionMetrics      `json:"metrics,omitempty"`
+	Trigger       string                 `json:"trigger"`
+	ExtraAttrs    map[string]interface{} `json:"extra_attrs,omitempty"`
+	StartTime     time.Time              `json:"start_time"`
+	EndTime       time.Time              `json:"end_time"`
+}
+
+// ExecutionMetrics summarizes the task states of an execution
+type ExecutionMetrics struct {
+	TaskCount          int `json:"task_count"`
+	SuccessTaskCount   int `json:"success_task_count"`
+	ErrorTaskCount     int `json:"error_task_count"`
+	PendingTaskCount   int `json:"pending_task_count"`
+	RunningTaskCount   int `json:"running_task_count"`
+	ScheduledTaskCount int `json:"scheduled_task_count"`
+	StoppedTaskCount   int `json:"stopped_task_count"`
+}
+
+// WebhookTask represents a single webhook delivery attempt
+type WebhookTask struct {
+	ID            int64                  `json:"id"`
+	ExecutionID   int64                  `json:"execution_id"`
+	Status        string                 `json:"status"`
+	StatusMessage string                 `json:"status_message,omitempty"`
+	RunCount      int                    `json:"run_count"`
+	ExtraAttrs    map[string]interface{} `json:"extra_attrs,omitempty"`
+	CreationTime  time.Time              `json:"creation_time"`
+	UpdateTime    time.Time              `json:"update_time"`
+	StartTime     time.Time              `json:"start_time"`
+	EndTime       time.Time              `json:"end_time"`
+}
@@ -0,0 +1,132 @@
+package apply
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/pascal71/hrbcli/pkg/api"
+	"github.com/pascal71/hrbcli/pkg/harbor"
+)
+
+// env gives kinds access to Harbor and caches lookups shared between
+// them, such as registry and project IDs
+type env struct {
+	client *api.Client
+
+	registries []*api.Registry
+	projectIDs map[string]int64
+}
+
+func newEnv(client *api.Client) *env {
+	return &env{client: client, projectIDs: map[string]int64{}}
+}
+
+// changed drops cached lookups made stale by a change to kind
+func (e *env) changed(kind string) {
+	switch kind {
+	case "Registry":
+		e.registries = nil
+	case "Project":
+		e.projectIDs = map[string]int64{}
+	}
+}
+
+func (e *env) registryList(ctx context.Context) ([]*api.Registry, error) {
+	if e.registries == nil {
+		registries, err := harbor.NewRegistryService(e.client).ListAll(ctx, nil, 0)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list registries: %w", err)
+		}
+		e.registries = registries
+	}
+	return e.registries, nil
+}
+
+// registryID resolves a registry name. The empty name is the local
+// Harbor and resolves to 0.
+func (e *env) registryID(ctx context.Context, name string) (int64, error) {
+	if name == "" {
+		return 0, nil
+	}
+	registries, err := e.registryList(ctx)
+	if err != nil {
+		return 0, err
+	}
+	for _, r := range registries {
+		if r.Name == name {
+			return r.ID, nil
+		}
+	}
+	return 0, fmt.Errorf("registry %q not found", name)
+}
+
+// registryName returns the name of the registry with id, or "" for the
+// local Harbor
+func (e *env) registryName(ctx context.Context, id int64) (string, error) {
+	if id == 0 {
+		return "", nil
+	}
+	registries, err := e.registryList(ctx)
+	if err != nil {
+		return "", err
+	}
+	for _, r := range registries {
+		if r.ID == id {
+			return r.Name, nil
+		}
+	}
+	return strconv.FormatInt(id, 10), nil
+}
+
+// projectID resolves a project name. Missing projects resolve to 0 so
+// that resources in projects that are still to be created plan as
+// creates.
+func (e *env) projectID(ctx context.Context, name string) (int64, error) {
+	if id, ok := e.projectIDs[name]; ok {
+		return id, nil
+	}
+	project, err := harbor.NewProjectService(e.client).Get(ctx, name)
+	if err != nil {
+		if apiErr, ok := err.(*api.APIError); ok && apiErr.IsNotFound() {
+			e.projectIDs[name] = 0
+			return 0, nil
+		}
+		return 0, fmt.Errorf("failed to get project %s: %w", name, err)
+	}
+	e.projectIDs[name] = project.ProjectID
+	return project.ProjectID, nil
+}
+
+// projects returns the distinct projects referenced by resources
+func projects(resources []*Resource) []string {
+	var names []string
+	seen := map[string]bool{}
+	for _, r := range resources {
+		s, ok := r.Spec.(interface{ project() string })
+		if !ok || seen[s.project()] {
+			continue
+		}
+		seen[s.project()] = true
+		names = append(names, s.project())
+	}
+	return names
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
+
+// metadataBool converts a project metadata value, where unset means
+// false
+func metadataBool(v string) *bool {
+	return boolPtr(v == "true")
+}
+
+// metadataString converts a managed boolean to a project metadata value
+func metadataString(b *bool) string {
+	if b == nil {
+		return ""
+	}
+	return strconv.FormatBool(*b)
+}
@@ -0,0 +1,107 @@
+package apply
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pascal71/hrbcli/pkg/api"
+	"github.com/pascal71/hrbcli/pkg/harbor"
+)
+
+// LabelSpec is the desired state of a label. Labels without a project
+// are global.
+type LabelSpec struct {
+	Project     string  `yaml:"project,omitempty" json:"project,omitempty"`
+	Description *string `yaml:"description,omitempty" json:"description,omitempty"`
+	Color       *string `yaml:"color,omitempty" json:"color,omitempty"`
+}
+
+func (s *LabelSpec) project() string { return s.Project }
+
+type labelKind struct{}
+
+func (labelKind) newSpec() interface{} { return &LabelSpec{} }
+
+func (labelKind) validate(r *Resource) error { return nil }
+
+func (labelKind) live(ctx context.Context, e *env, desired []*Resource) (map[string]*liveObject, error) {
+	svc := harbor.NewLabelService(e.client)
+	objs := map[string]*liveObject{}
+	add := func(project string, labels []*api.Label) {
+		for _, l := range labels {
+			spec := &LabelSpec{Project: project, Description: &l.Description, Color: &l.Color}
+			r := &Resource{Name: l.Name, Spec: spec}
+			objs[r.Key()] = &liveObject{ID: l.ID, Spec: spec, raw: l}
+		}
+	}
+
+	for _, project := range projects(desired) {
+		if project == "" {
+			labels, err := svc.ListAll(ctx, &api.LabelListOptions{Scope: "g"}, 0)
+			if err != nil {
+				return nil, err
+			}
+			add("", labels)
+			continue
+		}
+		id, err := e.projectID(ctx, project)
+		if err != nil {
+			return nil, err
+		}
+		if id == 0 {
+			continue
+		}
+		labels, err := svc.ListAll(ctx, &api.LabelListOptions{Scope: "p", ProjectID: id}, 0)
+		if err != nil {
+			return nil, err
+		}
+		add(project, labels)
+	}
+	return objs, nil
+}
+
+// label overlays spec on base, the current label when updating
+func label(ctx context.Context, e *env, name string, s *LabelSpec, base *api.Label) (*api.Label, error) {
+	l := &api.Label{Name: name, Scope: "g"}
+	if base != nil {
+		l.Description, l.Color = base.Description, base.Color
+	}
+	if s.Project != "" {
+		id, err := e.projectID(ctx, s.Project)
+		if err != nil {
+			return nil, err
+		}
+		if id == 0 {
+			return nil, fmt.Errorf("project %s not found", s.Project)
+		}
+		l.Scope, l.ProjectID = "p", id
+	}
+	if s.Description != nil {
+		l.Description = *s.Description
+	}
+	if s.Color != nil {
+		l.Color = *s.Color
+	}
+	return l, nil
+}
+
+func (labelKind) create(ctx context.Context, e *env, r *Resource) (string, error) {
+	l, err := label(ctx, e, r.Name, r.Spec.(*LabelSpec), nil)
+	if err != nil {
+		return "", err
+	}
+	_, err = harbor.NewLabelService(e.client).Create(ctx, l)
+	return "", err
+}
+
+func (labelKind) update(ctx context.Context, e *env, obj *liveObject, r *Resource) error {
+	l, err := label(ctx, e, r.Name, r.Spec.(*LabelSpec), obj.raw.(*api.Label))
+	if err != nil {
+		return err
+	}
+	return harbor.NewLabelService(e.client).Update(ctx, obj.ID, l)
+}
+
+func (labelKind) delete(ctx context.Context, e *env, obj *liveObject) error {
+	return harbor.NewLabelService(e.client).Delete(ctx, obj.ID)
+}
@@ -0,0 +1,154 @@
+package apply
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Resource is a single desired object read from a manifest:
+//
+//	kind: Project
+//	name: team-a
+//	spec:
+//	  public: false
+//	  auto_scan: true
+type Resource struct {
+	Kind string
+	Name string
+	// Spec points to the spec type of the kind, e.g. *ProjectSpec
+	Spec interface{}
+	// Source is the file and document the resource was read from
+	Source string
+}
+
+// document is the raw layout of a manifest document
+type document struct {
+	Kind string    `yaml:"kind"`
+	Name string    `yaml:"name"`
+	Spec yaml.Node `yaml:"spec"`
+}
+
+// LoadManifests reads every .yaml and .yml file in paths. Directories are
+// walked recursively and files may hold several documents separated by
+// "---".
+func LoadManifests(paths []string) ([]*Resource, error) {
+	var files []string
+	for _, p := range paths {
+		info, err := os.Stat(p)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			files = append(files, p)
+			continue
+		}
+		err = filepath.WalkDir(p, func(path string, d os.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			ext := strings.ToLower(filepath.Ext(path))
+			if !d.IsDir() && (ext == ".yaml" || ext == ".yml") {
+				files = append(files, path)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	sort.Strings(files)
+
+	var resources []*Resource
+	seen := map[string]string{}
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		docs, err := ParseManifest(data, file)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range docs {
+			id := r.Kind + "/" + r.Key()
+			if prev, ok := seen[id]; ok {
+				return nil, fmt.Errorf("%s: %s %s is already defined in %s", r.Source, r.Kind, r.Key(), prev)
+			}
+			seen[id] = r.Source
+			resources = append(resources, r)
+		}
+	}
+	return resources, nil
+}
+
+// ParseManifest decodes the documents of a single manifest file. source
+// is used in error messages.
+func ParseManifest(data []byte, source string) ([]*Resource, error) {
+	var resources []*Resource
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	for i := 1; ; i++ {
+		var doc document
+		err := dec.Decode(&doc)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		where := fmt.Sprintf("%s#%d", source, i)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", where, err)
+		}
+		if doc.Kind == "" && doc.Name == "" {
+			// Empty document, e.g. a trailing "---"
+			continue
+		}
+
+		k, ok := kinds[doc.Kind]
+		if !ok {
+			return nil, fmt.Errorf("%s: unknown kind %q (valid: %s)", where, doc.Kind, strings.Join(KindNames(), ", "))
+		}
+		if doc.Name == "" {
+			return nil, fmt.Errorf("%s: %s without name", where, doc.Kind)
+		}
+
+		spec := k.newSpec()
+		if doc.Spec.Kind != 0 {
+			if err := decodeStrict(&doc.Spec, spec); err != nil {
+				return nil, fmt.Errorf("%s: %s %s: %w", where, doc.Kind, doc.Name, err)
+			}
+		}
+		r := &Resource{Kind: doc.Kind, Name: doc.Name, Spec: spec, Source: where}
+		if err := k.validate(r); err != nil {
+			return nil, fmt.Errorf("%s: %s %s: %w", where, doc.Kind, doc.Name, err)
+		}
+		resources = append(resources, r)
+	}
+	return resources, nil
+}
+
+// decodeStrict decodes node into v, rejecting unknown fields so that
+// typos do not silently leave settings unmanaged
+func decodeStrict(node *yaml.Node, v interface{}) error {
+	data, err := yaml.Marshal(node)
+	if err != nil {
+		return err
+	}
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	return dec.Decode(v)
+}
+
+// Key identifies the resource among others of its kind. Project scoped
+// kinds are keyed as "<project>/<name>".
+func (r *Resource) Key() string {
+	if s, ok := r.Spec.(interface{ project() string }); ok && s.project() != "" {
+		return s.project() + "/" + r.Name
+	}
+	return r.Name
+}
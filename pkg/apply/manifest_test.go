@@ -0,0 +1,86 @@
+package apply
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseManifestMultipleDocuments(t *testing.T) {
+	data := []byte(`
+kind: Project
+name: team-a
+spec:
+  public: true
+  severity: High
+---
+kind: Robot
+name: robot$ci
+spec:
+  permissions:
+    - kind: project
+      namespace: team-a
+      access:
+        - resource: repository
+          action: push
+        - resource: repository
+          action: pull
+---
+`)
+	resources, err := ParseManifest(data, "test.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resources) != 2 {
+		t.Fatalf("expected 2 resources, got %d", len(resources))
+	}
+
+	project := resources[0].Spec.(*ProjectSpec)
+	if project.Public == nil || !*project.Public || project.Severity != "high" {
+		t.Fatalf("unexpected project spec: %+v", project)
+	}
+	if project.AutoScan != nil {
+		t.Fatalf("unset fields must stay unmanaged")
+	}
+
+	robot := resources[1]
+	if robot.Name != "ci" {
+		t.Fatalf("expected the robot prefix to be trimmed, got %q", robot.Name)
+	}
+	if access := robot.Spec.(*RobotSpec).Permissions[0].Access; access[0].Action != "pull" {
+		t.Fatalf("expected accesses to be sorted, got %+v", access[0])
+	}
+}
+
+func TestParseManifestRejectsInvalidDocuments(t *testing.T) {
+	tests := map[string]string{
+		"unknown kind":  "kind: Bucket\nname: x\n",
+		"unknown field": "kind: Project\nname: x\nspec:\n  publik: true\n",
+		"missing name":  "kind: Project\nspec:\n  public: true\n",
+		"invalid spec":  "kind: Registry\nname: hub\nspec:\n  type: docker-hub\n",
+	}
+	for name, data := range tests {
+		if _, err := ParseManifest([]byte(data), "test.yaml"); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestLoadManifestsRejectsDuplicates(t *testing.T) {
+	dir := t.TempDir()
+	doc := "kind: Label\nname: prod\nspec:\n  project: team-a\n"
+	for _, name := range []string{"a.yaml", "b.yml"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(doc), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(dir, "README.md"), []byte("not a manifest"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := LoadManifests([]string{dir})
+	if err == nil || !strings.Contains(err.Error(), "already defined") {
+		t.Fatalf("expected a duplicate error, got %v", err)
+	}
+}
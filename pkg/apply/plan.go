@@ -0,0 +1,340 @@
+package apply
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/pascal71/hrbcli/pkg/api"
+)
+
+// kind reconciles one resource kind against Harbor
+type kind interface {
+	// newSpec returns a pointer to an empty spec of the kind
+	newSpec() interface{}
+	// validate checks a decoded resource before anything is fetched
+	validate(r *Resource) error
+	// live returns the existing objects in scope of the desired
+	// resources, keyed like Resource.Key
+	live(ctx context.Context, e *env, desired []*Resource) (map[string]*liveObject, error)
+	// create creates the resource and returns the secret generated for
+	// it, if any
+	create(ctx context.Context, e *env, r *Resource) (string, error)
+	update(ctx context.Context, e *env, obj *liveObject, r *Resource) error
+	delete(ctx context.Context, e *env, obj *liveObject) error
+}
+
+// secretGenerator is implemented by kinds for which Harbor generates a
+// secret on creation
+type secretGenerator interface {
+	generatesSecret()
+}
+
+// createOnly is implemented by kinds with fields that cannot be changed
+// after creation; they are not compared
+type createOnly interface {
+	createOnlyFields() []string
+}
+
+// liveObject is an existing Harbor object in spec form
+type liveObject struct {
+	ID   int64
+	Spec interface{}
+	// raw is the API object the spec was built from
+	raw interface{}
+}
+
+// kinds holds the supported kinds by manifest name
+var kinds = map[string]kind{
+	"Registry":          registryKind{},
+	"Project":           projectKind{},
+	"Label":             labelKind{},
+	"ReplicationPolicy": replicationKind{},
+	"Robot":             robotKind{},
+	"Webhook":           webhookKind{},
+}
+
+// kindOrder is the order resources are created and updated in, so that
+// references resolve. Deletions run in reverse.
+var kindOrder = []string{"Registry", "Project", "Label", "ReplicationPolicy", "Robot", "Webhook"}
+
+// KindNames returns the supported manifest kinds
+func KindNames() []string {
+	return append([]string(nil), kindOrder...)
+}
+
+// Action is what a change does to a resource
+type Action string
+
+// Plan actions
+const (
+	ActionCreate Action = "create"
+	ActionUpdate Action = "update"
+	ActionDelete Action = "delete"
+)
+
+// FieldChange is a single differing field. Nested fields are joined with
+// dots, e.g. "credential.access_key".
+type FieldChange struct {
+	Field string      `json:"field" yaml:"field"`
+	Old   interface{} `json:"old,omitempty" yaml:"old,omitempty"`
+	New   interface{} `json:"new,omitempty" yaml:"new,omitempty"`
+}
+
+// Change is one step of a plan
+type Change struct {
+	Action Action        `json:"action" yaml:"action"`
+	Kind   string        `json:"kind" yaml:"kind"`
+	Name   string        `json:"name" yaml:"name"`
+	Fields []FieldChange `json:"fields,omitempty" yaml:"fields,omitempty"`
+
+	resource *Resource
+	live     *liveObject
+	// secret is generated by Harbor when the change is applied. It is
+	// kept out of the serialized plan.
+	secret string
+}
+
+// GeneratesSecret reports whether applying the change generates a secret,
+// as creating a robot does
+func (c *Change) GeneratesSecret() bool {
+	_, ok := kinds[c.Kind].(secretGenerator)
+	return ok && c.Action == ActionCreate
+}
+
+// Secret returns the secret generated when the change was applied
+func (c *Change) Secret() string {
+	return c.secret
+}
+
+// Plan lists the changes needed to converge Harbor to the manifests
+type Plan struct {
+	Changes   []*Change `json:"changes" yaml:"changes"`
+	Unchanged int       `json:"unchanged" yaml:"unchanged"`
+}
+
+// HasChanges reports whether Harbor differs from the manifests
+func (p *Plan) HasChanges() bool {
+	return len(p.Changes) > 0
+}
+
+// Counts returns the number of creates, updates and deletes
+func (p *Plan) Counts() (create, update, del int) {
+	for _, c := range p.Changes {
+		switch c.Action {
+		case ActionCreate:
+			create++
+		case ActionUpdate:
+			update++
+		case ActionDelete:
+			del++
+		}
+	}
+	return create, update, del
+}
+
+// Options control planning
+type Options struct {
+	// Prune deletes objects of the declared kinds that are in scope but
+	// missing from the manifests
+	Prune bool
+	// PruneKinds limits pruning to these kinds. Projects hold everything
+	// else and may belong to other teams, so they are only pruned when
+	// listed here.
+	PruneKinds []string
+}
+
+// prunes reports whether undeclared objects of kind are deleted
+func (o Options) prunes(kind string) bool {
+	if !o.Prune {
+		return false
+	}
+	for _, k := range o.PruneKinds {
+		if k == kind {
+			return true
+		}
+	}
+	return len(o.PruneKinds) == 0 && kind != "Project"
+}
+
+// Engine plans and applies manifests against a Harbor instance
+type Engine struct {
+	env *env
+}
+
+// NewEngine creates an engine using client
+func NewEngine(client *api.Client) *Engine {
+	return &Engine{env: newEnv(client)}
+}
+
+// Plan compares the resources with the live state
+func (e *Engine) Plan(ctx context.Context, resources []*Resource, opts Options) (*Plan, error) {
+	for _, k := range opts.PruneKinds {
+		if _, ok := kinds[k]; !ok {
+			return nil, fmt.Errorf("unsupported prune kind %q", k)
+		}
+	}
+
+	byKind := map[string][]*Resource{}
+	for _, r := range resources {
+		byKind[r.Kind] = append(byKind[r.Kind], r)
+	}
+
+	plan := &Plan{}
+	var deletes []*Change
+	for _, name := range kindOrder {
+		desired := byKind[name]
+		if len(desired) == 0 {
+			continue
+		}
+		k := kinds[name]
+
+		live, err := k.live(ctx, e.env, desired)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read live %s objects: %w", name, err)
+		}
+
+		var ignore []string
+		if c, ok := k.(createOnly); ok {
+			ignore = c.createOnlyFields()
+		}
+
+		declared := map[string]bool{}
+		for _, r := range desired {
+			key := r.Key()
+			declared[key] = true
+
+			obj, ok := live[key]
+			if !ok {
+				fields, err := diffSpecs(r.Spec, nil, nil)
+				if err != nil {
+					return nil, err
+				}
+				plan.Changes = append(plan.Changes, &Change{
+					Action: ActionCreate, Kind: name, Name: key, Fields: fields, resource: r,
+				})
+				continue
+			}
+
+			fields, err := diffSpecs(r.Spec, obj.Spec, ignore)
+			if err != nil {
+				return nil, err
+			}
+			if len(fields) == 0 {
+				plan.Unchanged++
+				continue
+			}
+			plan.Changes = append(plan.Changes, &Change{
+				Action: ActionUpdate, Kind: name, Name: key, Fields: fields, resource: r, live: obj,
+			})
+		}
+
+		if opts.prunes(name) {
+			keys := make([]string, 0, len(live))
+			for key := range live {
+				if !declared[key] {
+					keys = append(keys, key)
+				}
+			}
+			sort.Strings(keys)
+			var kindDeletes []*Change
+			for _, key := range keys {
+				kindDeletes = append(kindDeletes, &Change{Action: ActionDelete, Kind: name, Name: key, live: live[key]})
+			}
+			// Later kinds are deleted first
+			deletes = append(kindDeletes, deletes...)
+		}
+	}
+
+	plan.Changes = append(plan.Changes, deletes...)
+	return plan, nil
+}
+
+// ApplyChange performs a single change of a plan
+func (e *Engine) ApplyChange(ctx context.Context, c *Change) error {
+	k := kinds[c.Kind]
+	var err error
+	switch c.Action {
+	case ActionCreate:
+		c.secret, err = k.create(ctx, e.env, c.resource)
+	case ActionUpdate:
+		err = k.update(ctx, e.env, c.live, c.resource)
+	case ActionDelete:
+		err = k.delete(ctx, e.env, c.live)
+	default:
+		err = fmt.Errorf("unknown action %q", c.Action)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to %s %s %s: %w", c.Action, c.Kind, c.Name, err)
+	}
+	e.env.changed(c.Kind)
+	return nil
+}
+
+// diffSpecs returns the fields set in desired that differ from live.
+// Fields left unset in the manifest are not managed and never reported.
+// A nil live reports every desired field as new.
+func diffSpecs(desired, live interface{}, ignore []string) ([]FieldChange, error) {
+	d, err := specMap(desired)
+	if err != nil {
+		return nil, err
+	}
+	l := map[string]interface{}{}
+	if live != nil {
+		if l, err = specMap(live); err != nil {
+			return nil, err
+		}
+	}
+
+	skip := map[string]bool{}
+	for _, f := range ignore {
+		skip[f] = true
+	}
+	var changes []FieldChange
+	diffMaps("", d, l, skip, &changes)
+	return changes, nil
+}
+
+func diffMaps(prefix string, desired, live map[string]interface{}, skip map[string]bool, changes *[]FieldChange) {
+	keys := make([]string, 0, len(desired))
+	for k := range desired {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		field := prefix + k
+		if skip[field] {
+			continue
+		}
+		dv, lv := desired[k], live[k]
+		dm, dIsMap := dv.(map[string]interface{})
+		lm, lIsMap := lv.(map[string]interface{})
+		if dIsMap && (lIsMap || lv == nil) {
+			if lm == nil {
+				lm = map[string]interface{}{}
+			}
+			diffMaps(field+".", dm, lm, skip, changes)
+			continue
+		}
+		if !reflect.DeepEqual(dv, lv) {
+			*changes = append(*changes, FieldChange{Field: field, Old: lv, New: dv})
+		}
+	}
+}
+
+// specMap normalizes a spec to generic JSON values so that specs decoded
+// from YAML compare equal to specs built from API objects
+func specMap(spec interface{}) (map[string]interface{}, error) {
+	data, err := json.Marshal(spec)
+	if err != nil {
+		return nil, err
+	}
+	m := map[string]interface{}{}
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
@@ -0,0 +1,178 @@
+package apply
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/pascal71/hrbcli/pkg/api"
+)
+
+func TestPlanAndApply(t *testing.T) {
+	var updated map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.URL.Path {
+		case "GET /api/v2.0/registries":
+			w.Write([]byte(`[{"id":3,"name":"hub","type":"docker-hub","url":"https://hub.docker.com","insecure":false}]`))
+		case "GET /api/v2.0/projects":
+			w.Write([]byte(`[
+				{"project_id":1,"name":"team-a","public":false,"metadata":{"auto_scan":"true"}},
+				{"project_id":2,"name":"old","public":false}
+			]`))
+		case "PUT /api/v2.0/projects/team-a":
+			data, _ := io.ReadAll(r.Body)
+			json.Unmarshal(data, &updated)
+			w.WriteHeader(http.StatusOK)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	resources, err := ParseManifest([]byte(`
+kind: Registry
+name: hub
+spec:
+  type: docker-hub
+  url: https://hub.docker.com
+---
+kind: Project
+name: team-a
+spec:
+  public: true
+  auto_scan: true
+---
+kind: Project
+name: cache
+spec:
+  proxy_cache_registry: hub
+`), "test.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client := &api.Client{BaseURL: server.URL, APIVersion: "v2.0", HTTPClient: server.Client()}
+	engine := NewEngine(client)
+	plan, err := engine.Plan(context.Background(), resources, Options{Prune: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range plan.Changes {
+		if c.Action == ActionDelete {
+			t.Fatalf("projects must only be pruned when requested, got %+v", c)
+		}
+	}
+
+	plan, err = engine.Plan(context.Background(), resources, Options{Prune: true, PruneKinds: []string{"Project"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if plan.Unchanged != 1 || len(plan.Changes) != 3 {
+		t.Fatalf("unexpected plan: %d unchanged, %+v", plan.Unchanged, plan.Changes)
+	}
+	want := []struct {
+		action Action
+		name   string
+	}{{ActionUpdate, "team-a"}, {ActionCreate, "cache"}, {ActionDelete, "old"}}
+	for i, w := range want {
+		if c := plan.Changes[i]; c.Action != w.action || c.Name != w.name {
+			t.Fatalf("change %d: got %s %s, want %s %s", i, c.Action, c.Name, w.action, w.name)
+		}
+	}
+	fields := plan.Changes[0].Fields
+	if len(fields) != 1 || fields[0].Field != "public" || fields[0].Old != false || fields[0].New != true {
+		t.Fatalf("unexpected fields: %+v", fields)
+	}
+
+	if err := engine.ApplyChange(context.Background(), plan.Changes[0]); err != nil {
+		t.Fatal(err)
+	}
+	if updated["public"] != true {
+		t.Fatalf("expected public to be updated, got %v", updated)
+	}
+}
+
+func TestApplyKeepsRobotSecretOutOfPlan(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.URL.Path {
+		case "GET /api/v2.0/robots":
+			w.Write([]byte(`[]`))
+		case "POST /api/v2.0/robots":
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"id":5,"name":"robot$ci","secret":"s3cret"}`))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	resources, err := ParseManifest([]byte(`
+kind: Robot
+name: ci
+spec:
+  permissions:
+    - kind: project
+      namespace: team-a
+      access:
+        - resource: repository
+          action: pull
+`), "test.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client := &api.Client{BaseURL: server.URL, APIVersion: "v2.0", HTTPClient: server.Client()}
+	engine := NewEngine(client)
+	plan, err := engine.Plan(context.Background(), resources, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plan.Changes) != 1 || !plan.Changes[0].GeneratesSecret() {
+		t.Fatalf("expected a robot creation that generates a secret, got %+v", plan.Changes)
+	}
+	if err := engine.ApplyChange(context.Background(), plan.Changes[0]); err != nil {
+		t.Fatal(err)
+	}
+	if got := plan.Changes[0].Secret(); got != "s3cret" {
+		t.Fatalf("expected the generated secret, got %q", got)
+	}
+
+	data, err := json.Marshal(plan)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "s3cret") {
+		t.Fatalf("serialized plan contains the secret: %s", data)
+	}
+}
+
+func TestDiffSpecsIgnoresUnsetAndSecretFields(t *testing.T) {
+	desc := "Docker Hub"
+	desired := &RegistrySpec{
+		Type:        "docker-hub",
+		URL:         "https://hub.docker.com",
+		Description: &desc,
+		Credential:  &RegistryCredentialSpec{Type: "basic", AccessKey: "bot", AccessSecret: "s3cret"},
+	}
+	live := &RegistrySpec{
+		Type:       "docker-hub",
+		URL:        "https://hub.docker.com",
+		Insecure:   boolPtr(true),
+		Credential: &RegistryCredentialSpec{Type: "basic", AccessKey: "admin"},
+	}
+
+	changes, err := diffSpecs(desired, live, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 2 || changes[0].Field != "credential.access_key" || changes[1].Field != "description" {
+		t.Fatalf("unexpected changes: %+v", changes)
+	}
+}
@@ -0,0 +1,132 @@
+package apply
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/pascal71/hrbcli/pkg/api"
+	"github.com/pascal71/hrbcli/pkg/harbor"
+)
+
+// ProjectSpec is the desired state of a project. Unset fields are left
+// as they are in Harbor.
+type ProjectSpec struct {
+	Public               *bool    `yaml:"public,omitempty" json:"public,omitempty"`
+	AutoScan             *bool    `yaml:"auto_scan,omitempty" json:"auto_scan,omitempty"`
+	PreventVul           *bool    `yaml:"prevent_vul,omitempty" json:"prevent_vul,omitempty"`
+	Severity             string   `yaml:"severity,omitempty" json:"severity,omitempty"`
+	EnableContentTrust   *bool    `yaml:"enable_content_trust,omitempty" json:"enable_content_trust,omitempty"`
+	ReuseSysCVEAllowlist *bool    `yaml:"reuse_sys_cve_allowlist,omitempty" json:"reuse_sys_cve_allowlist,omitempty"`
+	CVEAllowlist         []string `yaml:"cve_allowlist,omitempty" json:"cve_allowlist,omitempty"`
+	// ProxyCacheRegistry makes the project a proxy cache of the named
+	// registry. It can only be set when the project is created.
+	ProxyCacheRegistry string `yaml:"proxy_cache_registry,omitempty" json:"proxy_cache_registry,omitempty"`
+}
+
+type projectKind struct{}
+
+func (projectKind) newSpec() interface{} { return &ProjectSpec{} }
+
+func (projectKind) createOnlyFields() []string { return []string{"proxy_cache_registry"} }
+
+func (projectKind) validate(r *Resource) error {
+	s := r.Spec.(*ProjectSpec)
+	switch strings.ToLower(s.Severity) {
+	case "", "none", "low", "medium", "high", "critical":
+		s.Severity = strings.ToLower(s.Severity)
+	default:
+		return fmt.Errorf("invalid severity %q", s.Severity)
+	}
+	return nil
+}
+
+func (projectKind) live(ctx context.Context, e *env, desired []*Resource) (map[string]*liveObject, error) {
+	list, err := harbor.NewProjectService(e.client).ListAll(ctx, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+	objs := map[string]*liveObject{}
+	for _, p := range list {
+		spec, err := projectSpecFromAPI(ctx, e, p)
+		if err != nil {
+			return nil, err
+		}
+		objs[p.Name] = &liveObject{ID: p.ProjectID, Spec: spec, raw: p}
+	}
+	return objs, nil
+}
+
+func projectSpecFromAPI(ctx context.Context, e *env, p *api.Project) (*ProjectSpec, error) {
+	spec := &ProjectSpec{Public: boolPtr(p.Public)}
+	md := p.Metadata
+	if md == nil {
+		md = &api.ProjectMetadata{}
+	}
+	spec.AutoScan = metadataBool(md.AutoScan)
+	spec.PreventVul = metadataBool(md.PreventVul)
+	spec.Severity = md.Severity
+	spec.EnableContentTrust = metadataBool(md.EnableContentTrust)
+	spec.ReuseSysCVEAllowlist = metadataBool(md.ReuseSysCVEAllowlist)
+	if p.CVEAllowlist != nil {
+		for _, item := range p.CVEAllowlist.Items {
+			spec.CVEAllowlist = append(spec.CVEAllowlist, item.CVEID)
+		}
+	}
+	name, err := e.registryName(ctx, p.RegistryID)
+	if err != nil {
+		return nil, err
+	}
+	spec.ProxyCacheRegistry = name
+	return spec, nil
+}
+
+// projectReq builds the fields of a create or update request from spec
+func projectReq(name string, s *ProjectSpec) *api.ProjectReq {
+	req := &api.ProjectReq{ProjectName: name, Public: s.Public}
+	md := &api.ProjectMetadata{
+		Public:               metadataString(s.Public),
+		AutoScan:             metadataString(s.AutoScan),
+		PreventVul:           metadataString(s.PreventVul),
+		Severity:             s.Severity,
+		EnableContentTrust:   metadataString(s.EnableContentTrust),
+		ReuseSysCVEAllowlist: metadataString(s.ReuseSysCVEAllowlist),
+	}
+	if *md != (api.ProjectMetadata{}) {
+		req.Metadata = md
+	}
+	if s.CVEAllowlist != nil {
+		req.CVEAllowlist = &api.CVEAllowlist{}
+		for _, id := range s.CVEAllowlist {
+			req.CVEAllowlist.Items = append(req.CVEAllowlist.Items, api.CVEAllowlistItem{CVEID: id})
+		}
+	}
+	return req
+}
+
+func (projectKind) create(ctx context.Context, e *env, r *Resource) (string, error) {
+	s := r.Spec.(*ProjectSpec)
+	req := projectReq(r.Name, s)
+	if s.ProxyCacheRegistry != "" {
+		id, err := e.registryID(ctx, s.ProxyCacheRegistry)
+		if err != nil {
+			return "", err
+		}
+		req.RegistryID = &id
+	}
+	return "", harbor.NewProjectService(e.client).Create(ctx, req)
+}
+
+func (projectKind) update(ctx context.Context, e *env, obj *liveObject, r *Resource) error {
+	req := projectReq(r.Name, r.Spec.(*ProjectSpec))
+	if cur := obj.raw.(*api.Project).CVEAllowlist; req.CVEAllowlist != nil && cur != nil {
+		// Harbor replaces the allowlist in place
+		req.CVEAllowlist.ID = cur.ID
+		req.CVEAllowlist.ProjectID = cur.ProjectID
+	}
+	return harbor.NewProjectService(e.client).Update(ctx, r.Name, req)
+}
+
+func (projectKind) delete(ctx context.Context, e *env, obj *liveObject) error {
+	return harbor.NewProjectService(e.client).Delete(ctx, obj.raw.(*api.Project).Name)
+}
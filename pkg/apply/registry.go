@@ -0,0 +1,102 @@
+package apply
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/pascal71/hrbcli/pkg/api"
+	"github.com/pascal71/hrbcli/pkg/harbor"
+)
+
+// RegistrySpec is the desired state of a registry endpoint
+type RegistrySpec struct {
+	Type        string                  `yaml:"type" json:"type"`
+	URL         string                  `yaml:"url" json:"url"`
+	Description *string                 `yaml:"description,omitempty" json:"description,omitempty"`
+	Insecure    *bool                   `yaml:"insecure,omitempty" json:"insecure,omitempty"`
+	Credential  *RegistryCredentialSpec `yaml:"credential,omitempty" json:"credential,omitempty"`
+}
+
+// RegistryCredentialSpec holds registry credentials. AccessSecret may
+// reference environment variables, e.g. "${DOCKERHUB_TOKEN}", so that
+// secrets stay out of manifests. Harbor never returns the secret, so it
+// is sent on every create and update but not compared.
+type RegistryCredentialSpec struct {
+	Type         string `yaml:"type,omitempty" json:"type,omitempty"`
+	AccessKey    string `yaml:"access_key,omitempty" json:"access_key,omitempty"`
+	AccessSecret string `yaml:"access_secret,omitempty" json:"-"`
+}
+
+type registryKind struct{}
+
+func (registryKind) newSpec() interface{} { return &RegistrySpec{} }
+
+func (registryKind) validate(r *Resource) error {
+	s := r.Spec.(*RegistrySpec)
+	if s.Type == "" || s.URL == "" {
+		return fmt.Errorf("type and url are required")
+	}
+	if s.Credential != nil && s.Credential.Type == "" {
+		s.Credential.Type = "basic"
+	}
+	return nil
+}
+
+func (registryKind) live(ctx context.Context, e *env, desired []*Resource) (map[string]*liveObject, error) {
+	list, err := e.registryList(ctx)
+	if err != nil {
+		return nil, err
+	}
+	objs := map[string]*liveObject{}
+	for _, reg := range list {
+		spec := &RegistrySpec{
+			Type:        reg.Type,
+			URL:         reg.URL,
+			Description: &reg.Description,
+			Insecure:    boolPtr(reg.Insecure),
+		}
+		if reg.Credential != nil {
+			spec.Credential = &RegistryCredentialSpec{Type: reg.Credential.Type, AccessKey: reg.Credential.AccessKey}
+		}
+		objs[reg.Name] = &liveObject{ID: reg.ID, Spec: spec, raw: reg}
+	}
+	return objs, nil
+}
+
+// registryReq overlays spec on base, the current registry when updating
+func registryReq(name string, s *RegistrySpec, base *api.Registry) *api.RegistryReq {
+	req := &api.RegistryReq{Name: name, Type: s.Type, URL: s.URL}
+	if base != nil {
+		req.Description = base.Description
+		req.Insecure = base.Insecure
+	}
+	if s.Description != nil {
+		req.Description = *s.Description
+	}
+	if s.Insecure != nil {
+		req.Insecure = *s.Insecure
+	}
+	if s.Credential != nil {
+		req.Credential = &api.Credential{
+			Type:         s.Credential.Type,
+			AccessKey:    s.Credential.AccessKey,
+			AccessSecret: os.ExpandEnv(s.Credential.AccessSecret),
+		}
+	}
+	return req
+}
+
+func (registryKind) create(ctx context.Context, e *env, r *Resource) (string, error) {
+	_, err := harbor.NewRegistryService(e.client).Create(ctx, registryReq(r.Name, r.Spec.(*RegistrySpec), nil))
+	return "", err
+}
+
+func (registryKind) update(ctx context.Context, e *env, obj *liveObject, r *Resource) error {
+	req := registryReq(r.Name, r.Spec.(*RegistrySpec), obj.raw.(*api.Registry))
+	return harbor.NewRegistryService(e.client).Update(ctx, obj.ID, req)
+}
+
+func (registryKind) delete(ctx context.Context, e *env, obj *liveObject) error {
+	return harbor.NewRegistryService(e.client).Delete(ctx, obj.ID)
+}
@@ -0,0 +1,167 @@
+package apply
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pascal71/hrbcli/pkg/api"
+	"github.com/pascal71/hrbcli/pkg/harbor"
+)
+
+// ReplicationPolicySpec is the desired state of a replication policy.
+// Registries are referenced by name; leave one side empty for the local
+// Harbor.
+type ReplicationPolicySpec struct {
+	Description       *string                 `yaml:"description,omitempty" json:"description,omitempty"`
+	SrcRegistry       string                  `yaml:"src_registry,omitempty" json:"src_registry,omitempty"`
+	DestRegistry      string                  `yaml:"dest_registry,omitempty" json:"dest_registry,omitempty"`
+	DestNamespace     *string                 `yaml:"dest_namespace,omitempty" json:"dest_namespace,omitempty"`
+	Trigger           *ReplicationTriggerSpec `yaml:"trigger,omitempty" json:"trigger,omitempty"`
+	Filters           []ReplicationFilterSpec `yaml:"filters,omitempty" json:"filters,omitempty"`
+	ReplicateDeletion *bool                   `yaml:"replicate_deletion,omitempty" json:"replicate_deletion,omitempty"`
+	Override          *bool                   `yaml:"override,omitempty" json:"override,omitempty"`
+	Enabled           *bool                   `yaml:"enabled,omitempty" json:"enabled,omitempty"`
+}
+
+// ReplicationTriggerSpec selects when a policy runs: manual,
+// event_based or scheduled with a cron expression
+type ReplicationTriggerSpec struct {
+	Type string `yaml:"type" json:"type"`
+	Cron string `yaml:"cron,omitempty" json:"cron,omitempty"`
+}
+
+// ReplicationFilterSpec limits the replicated resources
+type ReplicationFilterSpec struct {
+	Type       string      `yaml:"type" json:"type"`
+	Value      interface{} `yaml:"value" json:"value"`
+	Decoration string      `yaml:"decoration,omitempty" json:"decoration,omitempty"`
+}
+
+type replicationKind struct{}
+
+func (replicationKind) newSpec() interface{} { return &ReplicationPolicySpec{} }
+
+func (replicationKind) validate(r *Resource) error {
+	s := r.Spec.(*ReplicationPolicySpec)
+	if (s.SrcRegistry == "") == (s.DestRegistry == "") {
+		return fmt.Errorf("exactly one of src_registry and dest_registry must be set")
+	}
+	if s.Trigger != nil && s.Trigger.Type == "scheduled" && s.Trigger.Cron == "" {
+		return fmt.Errorf("scheduled trigger requires cron")
+	}
+	return nil
+}
+
+func (replicationKind) live(ctx context.Context, e *env, desired []*Resource) (map[string]*liveObject, error) {
+	list, err := harbor.NewReplicationService(e.client).ListAllPolicies(ctx, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+	objs := map[string]*liveObject{}
+	for _, p := range list {
+		spec := &ReplicationPolicySpec{
+			Description:       &p.Description,
+			DestNamespace:     &p.DestNamespace,
+			ReplicateDeletion: boolPtr(p.ReplicateDeletion),
+			Override:          boolPtr(p.Override),
+			Enabled:           boolPtr(p.Enabled),
+		}
+		if p.SrcRegistry != nil {
+			if spec.SrcRegistry, err = e.registryName(ctx, p.SrcRegistry.ID); err != nil {
+				return nil, err
+			}
+		}
+		if p.DestRegistry != nil {
+			if spec.DestRegistry, err = e.registryName(ctx, p.DestRegistry.ID); err != nil {
+				return nil, err
+			}
+		}
+		if p.Trigger != nil {
+			spec.Trigger = &ReplicationTriggerSpec{Type: p.Trigger.Type}
+			if p.Trigger.TriggerSettings != nil {
+				spec.Trigger.Cron = p.Trigger.TriggerSettings.Cron
+			}
+		}
+		for _, f := range p.Filters {
+			spec.Filters = append(spec.Filters, ReplicationFilterSpec{Type: f.Type, Value: f.Value, Decoration: f.Decoration})
+		}
+		objs[p.Name] = &liveObject{ID: p.ID, Spec: spec, raw: p}
+	}
+	return objs, nil
+}
+
+// replicationPolicy overlays spec on base, the current policy when
+// updating
+func replicationPolicy(ctx context.Context, e *env, name string, s *ReplicationPolicySpec, base *api.ReplicationPolicy) (*api.ReplicationPolicy, error) {
+	p := &api.ReplicationPolicy{Name: name, Enabled: true, Trigger: &api.ReplicationTrigger{Type: "manual"}}
+	if base != nil {
+		copied := *base
+		p = &copied
+	}
+
+	srcID, err := e.registryID(ctx, s.SrcRegistry)
+	if err != nil {
+		return nil, err
+	}
+	destID, err := e.registryID(ctx, s.DestRegistry)
+	if err != nil {
+		return nil, err
+	}
+	p.SrcRegistry, p.DestRegistry = nil, nil
+	if s.SrcRegistry != "" {
+		p.SrcRegistry = &api.Registry{ID: srcID}
+	}
+	if s.DestRegistry != "" {
+		p.DestRegistry = &api.Registry{ID: destID}
+	}
+
+	if s.Description != nil {
+		p.Description = *s.Description
+	}
+	if s.DestNamespace != nil {
+		p.DestNamespace = *s.DestNamespace
+	}
+	if s.Trigger != nil {
+		p.Trigger = &api.ReplicationTrigger{Type: s.Trigger.Type}
+		if s.Trigger.Cron != "" {
+			p.Trigger.TriggerSettings = &api.ReplicationTriggerSettings{Cron: s.Trigger.Cron}
+		}
+	}
+	if s.Filters != nil {
+		p.Filters = nil
+		for _, f := range s.Filters {
+			p.Filters = append(p.Filters, api.ReplicationFilter{Type: f.Type, Value: f.Value, Decoration: f.Decoration})
+		}
+	}
+	if s.ReplicateDeletion != nil {
+		p.ReplicateDeletion = *s.ReplicateDeletion
+	}
+	if s.Override != nil {
+		p.Override = *s.Override
+	}
+	if s.Enabled != nil {
+		p.Enabled = *s.Enabled
+	}
+	return p, nil
+}
+
+func (replicationKind) create(ctx context.Context, e *env, r *Resource) (string, error) {
+	p, err := replicationPolicy(ctx, e, r.Name, r.Spec.(*ReplicationPolicySpec), nil)
+	if err != nil {
+		return "", err
+	}
+	_, err = harbor.NewReplicationService(e.client).CreatePolicy(ctx, p)
+	return "", err
+}
+
+func (replicationKind) update(ctx context.Context, e *env, obj *liveObject, r *Resource) error {
+	p, err := replicationPolicy(ctx, e, r.Name, r.Spec.(*ReplicationPolicySpec), obj.raw.(*api.ReplicationPolicy))
+	if err != nil {
+		return err
+	}
+	return harbor.NewReplicationService(e.client).UpdatePolicy(ctx, obj.ID, p)
+}
+
+func (replicationKind) delete(ctx context.Context, e *env, obj *liveObject) error {
+	return harbor.NewReplicationService(e.client).DeletePolicy(ctx, obj.ID)
+}
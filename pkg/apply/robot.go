@@ -0,0 +1,131 @@
+package apply
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pascal71/hrbcli/pkg/api"
+	"github.com/pascal71/hrbcli/pkg/harbor"
+)
+
+// robotPrefix is the default prefix Harbor adds to robot names
+const robotPrefix = "robot$"
+
+// RobotSpec is the desired state of a system level robot account. The
+// name is given without Harbor's "robot$" prefix.
+type RobotSpec struct {
+	Description *string                `yaml:"description,omitempty" json:"description,omitempty"`
+	Duration    *int64                 `yaml:"duration,omitempty" json:"duration,omitempty"`
+	Disable     *bool                  `yaml:"disable,omitempty" json:"disable,omitempty"`
+	Permissions []*api.RobotPermission `yaml:"permissions" json:"permissions"`
+}
+
+type robotKind struct{}
+
+func (robotKind) generatesSecret() {}
+
+func (robotKind) newSpec() interface{} { return &RobotSpec{} }
+
+func (robotKind) validate(r *Resource) error {
+	r.Name = strings.TrimPrefix(r.Name, robotPrefix)
+	s := r.Spec.(*RobotSpec)
+	if len(s.Permissions) == 0 {
+		return fmt.Errorf("permissions are required")
+	}
+	s.Permissions = normalizePermissions(s.Permissions)
+	return nil
+}
+
+// normalizePermissions sorts permissions and accesses and drops the
+// default effect so that equal grants compare equal
+func normalizePermissions(perms []*api.RobotPermission) []*api.RobotPermission {
+	out := make([]*api.RobotPermission, 0, len(perms))
+	for _, p := range perms {
+		np := &api.RobotPermission{Kind: p.Kind, Namespace: p.Namespace}
+		for _, a := range p.Access {
+			na := *a
+			if na.Effect == "allow" {
+				na.Effect = ""
+			}
+			np.Access = append(np.Access, &na)
+		}
+		sort.Slice(np.Access, func(i, j int) bool {
+			if np.Access[i].Resource != np.Access[j].Resource {
+				return np.Access[i].Resource < np.Access[j].Resource
+			}
+			return np.Access[i].Action < np.Access[j].Action
+		})
+		out = append(out, np)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Kind != out[j].Kind {
+			return out[i].Kind < out[j].Kind
+		}
+		return out[i].Namespace < out[j].Namespace
+	})
+	return out
+}
+
+func (robotKind) live(ctx context.Context, e *env, desired []*Resource) (map[string]*liveObject, error) {
+	list, err := harbor.NewRobotService(e.client).ListAll(ctx, &api.ListOptions{Query: "Level=" + api.RobotLevelSystem}, 0)
+	if err != nil {
+		return nil, err
+	}
+	objs := map[string]*liveObject{}
+	for _, robot := range list {
+		spec := &RobotSpec{
+			Description: &robot.Description,
+			Duration:    &robot.Duration,
+			Disable:     boolPtr(robot.Disable),
+			Permissions: normalizePermissions(robot.Permissions),
+		}
+		objs[strings.TrimPrefix(robot.Name, robotPrefix)] = &liveObject{ID: robot.ID, Spec: spec, raw: robot}
+	}
+	return objs, nil
+}
+
+func (robotKind) create(ctx context.Context, e *env, r *Resource) (string, error) {
+	s := r.Spec.(*RobotSpec)
+	req := &api.RobotCreate{
+		Name:        r.Name,
+		Level:       api.RobotLevelSystem,
+		Duration:    -1,
+		Permissions: s.Permissions,
+	}
+	if s.Description != nil {
+		req.Description = *s.Description
+	}
+	if s.Duration != nil {
+		req.Duration = *s.Duration
+	}
+	if s.Disable != nil {
+		req.Disable = *s.Disable
+	}
+	created, err := harbor.NewRobotService(e.client).Create(ctx, req)
+	if err != nil {
+		return "", err
+	}
+	return created.Secret, nil
+}
+
+func (robotKind) update(ctx context.Context, e *env, obj *liveObject, r *Resource) error {
+	s := r.Spec.(*RobotSpec)
+	robot := *obj.raw.(*api.Robot)
+	robot.Permissions = s.Permissions
+	if s.Description != nil {
+		robot.Description = *s.Description
+	}
+	if s.Duration != nil {
+		robot.Duration = *s.Duration
+	}
+	if s.Disable != nil {
+		robot.Disable = *s.Disable
+	}
+	return harbor.NewRobotService(e.client).Update(ctx, obj.ID, &robot)
+}
+
+func (robotKind) delete(ctx context.Context, e *env, obj *liveObject) error {
+	return harbor.NewRobotService(e.client).Delete(ctx, obj.ID)
+}
@@ -0,0 +1,133 @@
+package apply
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/pascal71/hrbcli/pkg/api"
+	"github.com/pascal71/hrbcli/pkg/harbor"
+)
+
+// WebhookSpec is the desired state of a project webhook policy
+type WebhookSpec struct {
+	Project     string              `yaml:"project" json:"project"`
+	Description *string             `yaml:"description,omitempty" json:"description,omitempty"`
+	Enabled     *bool               `yaml:"enabled,omitempty" json:"enabled,omitempty"`
+	EventTypes  []string            `yaml:"event_types,omitempty" json:"event_types,omitempty"`
+	Targets     []WebhookTargetSpec `yaml:"targets,omitempty" json:"targets,omitempty"`
+}
+
+// WebhookTargetSpec is an endpoint notified by a webhook. The auth
+// header may reference environment variables and is not compared.
+type WebhookTargetSpec struct {
+	Type           string `yaml:"type,omitempty" json:"type"`
+	Address        string `yaml:"address" json:"address"`
+	AuthHeader     string `yaml:"auth_header,omitempty" json:"-"`
+	SkipCertVerify bool   `yaml:"skip_cert_verify,omitempty" json:"skip_cert_verify"`
+	PayloadFormat  string `yaml:"payload_format,omitempty" json:"payload_format,omitempty"`
+}
+
+func (s *WebhookSpec) project() string { return s.Project }
+
+type webhookKind struct{}
+
+func (webhookKind) newSpec() interface{} { return &WebhookSpec{} }
+
+func (webhookKind) validate(r *Resource) error {
+	s := r.Spec.(*WebhookSpec)
+	if s.Project == "" {
+		return fmt.Errorf("project is required")
+	}
+	for i, t := range s.Targets {
+		if t.Address == "" {
+			return fmt.Errorf("target %d has no address", i+1)
+		}
+		if t.Type == "" {
+			s.Targets[i].Type = api.WebhookTargetHTTP
+		}
+	}
+	s.EventTypes = harbor.NormalizeEventTypes(s.EventTypes)
+	return nil
+}
+
+func (webhookKind) live(ctx context.Context, e *env, desired []*Resource) (map[string]*liveObject, error) {
+	svc := harbor.NewWebhookService(e.client)
+	objs := map[string]*liveObject{}
+	for _, project := range projects(desired) {
+		id, err := e.projectID(ctx, project)
+		if err != nil {
+			return nil, err
+		}
+		if id == 0 {
+			continue
+		}
+		policies, err := svc.ListAllPolicies(ctx, project, nil, 0)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range policies {
+			spec := &WebhookSpec{
+				Project:     project,
+				Description: &p.Description,
+				Enabled:     boolPtr(p.Enabled),
+				EventTypes:  harbor.NormalizeEventTypes(p.EventTypes),
+			}
+			for _, t := range p.Targets {
+				spec.Targets = append(spec.Targets, WebhookTargetSpec{
+					Type: t.Type, Address: t.Address, SkipCertVerify: t.SkipCertVerify, PayloadFormat: t.PayloadFormat,
+				})
+			}
+			objs[project+"/"+p.Name] = &liveObject{ID: p.ID, Spec: spec, raw: p}
+		}
+	}
+	return objs, nil
+}
+
+// webhookPolicy overlays spec on base, the current policy when updating
+func webhookPolicy(name string, s *WebhookSpec, base *api.WebhookPolicy) *api.WebhookPolicy {
+	p := &api.WebhookPolicy{Name: name, Enabled: true}
+	if base != nil {
+		copied := *base
+		p = &copied
+	}
+	if s.Description != nil {
+		p.Description = *s.Description
+	}
+	if s.Enabled != nil {
+		p.Enabled = *s.Enabled
+	}
+	if s.EventTypes != nil {
+		p.EventTypes = s.EventTypes
+	}
+	if s.Targets != nil {
+		p.Targets = nil
+		for _, t := range s.Targets {
+			p.Targets = append(p.Targets, &api.WebhookTarget{
+				Type:           t.Type,
+				Address:        t.Address,
+				AuthHeader:     os.ExpandEnv(t.AuthHeader),
+				SkipCertVerify: t.SkipCertVerify,
+				PayloadFormat:  t.PayloadFormat,
+			})
+		}
+	}
+	return p
+}
+
+func (webhookKind) create(ctx context.Context, e *env, r *Resource) (string, error) {
+	s := r.Spec.(*WebhookSpec)
+	_, err := harbor.NewWebhookService(e.client).CreatePolicy(ctx, s.Project, webhookPolicy(r.Name, s, nil))
+	return "", err
+}
+
+func (webhookKind) update(ctx context.Context, e *env, obj *liveObject, r *Resource) error {
+	s := r.Spec.(*WebhookSpec)
+	p := webhookPolicy(r.Name, s, obj.raw.(*api.WebhookPolicy))
+	return harbor.NewWebhookService(e.client).UpdatePolicy(ctx, s.Project, obj.ID, p)
+}
+
+func (webhookKind) delete(ctx context.Context, e *env, obj *liveObject) error {
+	s := obj.Spec.(*WebhookSpec)
+	return harbor.NewWebhookService(e.client).DeletePolicy(ctx, s.Project, obj.ID)
+}
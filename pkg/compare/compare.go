@@ -0,0 +1,256 @@
+package compare
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/pascal71/hrbcli/pkg/api"
+	"github.com/pascal71/hrbcli/pkg/harbor"
+)
+
+// Sections lists what is compared, in report order
+var Sections = []string{"projects", "registries", "replication", "labels", "users", "configuration"}
+
+// Difference types
+const (
+	OnlyInFrom = "only-in-from"
+	OnlyInTo   = "only-in-to"
+	Changed    = "changed"
+)
+
+// Difference is an object or field that differs between two instances
+type Difference struct {
+	Section string `json:"section" yaml:"section"`
+	Name    string `json:"name" yaml:"name"`
+	Type    string `json:"type" yaml:"type"`
+	// Field is set for changed objects, e.g. "metadata.auto_scan"
+	Field string      `json:"field,omitempty" yaml:"field,omitempty"`
+	From  interface{} `json:"from,omitempty" yaml:"from,omitempty"`
+	To    interface{} `json:"to,omitempty" yaml:"to,omitempty"`
+}
+
+// object holds the comparable fields of one object, flattened to dotted
+// paths
+type object map[string]interface{}
+
+// Inventory holds the comparable state of an instance by section and
+// object name. IDs are replaced by names so that instances compare
+// equal when configured alike.
+type Inventory map[string]map[string]object
+
+// Collect reads the comparable state of the instance behind client
+func Collect(ctx context.Context, client *api.Client) (Inventory, error) {
+	inv := Inventory{}
+	for _, s := range Sections {
+		inv[s] = map[string]object{}
+	}
+
+	registries, err := harbor.NewRegistryService(client).ListAll(ctx, nil, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list registries: %w", err)
+	}
+	registryNames := map[int64]string{}
+	for _, r := range registries {
+		registryNames[r.ID] = r.Name
+		fields := map[string]interface{}{
+			"type":        r.Type,
+			"url":         r.URL,
+			"description": r.Description,
+			"insecure":    r.Insecure,
+		}
+		if r.Credential != nil {
+			fields["credential"] = map[string]interface{}{"type": r.Credential.Type, "access_key": r.Credential.AccessKey}
+		}
+		if inv["registries"][r.Name], err = flatten(fields); err != nil {
+			return nil, err
+		}
+	}
+	registryName := func(reg *api.Registry) string {
+		if reg == nil || reg.ID == 0 {
+			return "(local)"
+		}
+		return registryNames[reg.ID]
+	}
+
+	projects, err := harbor.NewProjectService(client).ListAll(ctx, nil, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list projects: %w", err)
+	}
+	labelSvc := harbor.NewLabelService(client)
+	for _, p := range projects {
+		fields := map[string]interface{}{"public": p.Public, "metadata": p.Metadata}
+		if p.Metadata != nil {
+			// Retention policy IDs differ between instances
+			md := *p.Metadata
+			md.RetentionID = ""
+			fields["metadata"] = md
+		}
+		var cves []string
+		if p.CVEAllowlist != nil {
+			for _, item := range p.CVEAllowlist.Items {
+				cves = append(cves, item.CVEID)
+			}
+		}
+		sort.Strings(cves)
+		fields["cve_allowlist"] = cves
+		if p.RegistryID != 0 {
+			fields["proxy_cache_registry"] = registryNames[p.RegistryID]
+		}
+		if inv["projects"][p.Name], err = flatten(fields); err != nil {
+			return nil, err
+		}
+
+		labels, err := labelSvc.ListAll(ctx, &api.LabelListOptions{Scope: "p", ProjectID: p.ProjectID}, 0)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list labels of project %s: %w", p.Name, err)
+		}
+		for _, l := range labels {
+			inv["labels"][p.Name+"/"+l.Name] = object{"description": l.Description, "color": l.Color}
+		}
+	}
+
+	labels, err := labelSvc.ListAll(ctx, &api.LabelListOptions{Scope: "g"}, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list labels: %w", err)
+	}
+	for _, l := range labels {
+		inv["labels"][l.Name] = object{"description": l.Description, "color": l.Color}
+	}
+
+	policies, err := harbor.NewReplicationService(client).ListAllPolicies(ctx, nil, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list replication policies: %w", err)
+	}
+	for _, p := range policies {
+		fields := map[string]interface{}{
+			"description":        p.Description,
+			"src_registry":       registryName(p.SrcRegistry),
+			"dest_registry":      registryName(p.DestRegistry),
+			"dest_namespace":     p.DestNamespace,
+			"trigger":            p.Trigger,
+			"filters":            p.Filters,
+			"replicate_deletion": p.ReplicateDeletion,
+			"override":           p.Override,
+			"enabled":            p.Enabled,
+		}
+		if inv["replication"][p.Name], err = flatten(fields); err != nil {
+			return nil, err
+		}
+	}
+
+	users, err := harbor.NewUserService(client).ListAll(ctx, nil, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list users: %w", err)
+	}
+	for _, u := range users {
+		inv["users"][u.Username] = object{
+			"email":         u.Email,
+			"realname":      u.Realname,
+			"comment":       u.Comment,
+			"sysadmin_flag": u.SysadminFlag,
+		}
+	}
+
+	settings, err := harbor.NewConfigService(client).Get(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get configuration: %w", err)
+	}
+	for k, v := range settings {
+		if inv["configuration"][k], err = flatten(map[string]interface{}{"value": v}); err != nil {
+			return nil, err
+		}
+	}
+
+	return inv, nil
+}
+
+// flatten normalizes v to JSON values and joins nested keys with dots
+func flatten(v interface{}) (object, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+	obj := object{}
+	flattenInto(obj, "", m)
+	return obj, nil
+}
+
+func flattenInto(obj object, prefix string, m map[string]interface{}) {
+	for k, v := range m {
+		if nested, ok := v.(map[string]interface{}); ok {
+			flattenInto(obj, prefix+k+".", nested)
+			continue
+		}
+		obj[prefix+k] = v
+	}
+}
+
+// Compare returns the differences between two inventories, sorted by
+// section, name and field
+func Compare(from, to Inventory) []*Difference {
+	var diffs []*Difference
+	for _, section := range Sections {
+		names := map[string]bool{}
+		for name := range from[section] {
+			names[name] = true
+		}
+		for name := range to[section] {
+			names[name] = true
+		}
+		sorted := make([]string, 0, len(names))
+		for name := range names {
+			sorted = append(sorted, name)
+		}
+		sort.Strings(sorted)
+
+		for _, name := range sorted {
+			f, inFrom := from[section][name]
+			t, inTo := to[section][name]
+			switch {
+			case !inTo:
+				diffs = append(diffs, &Difference{Section: section, Name: name, Type: OnlyInFrom})
+			case !inFrom:
+				diffs = append(diffs, &Difference{Section: section, Name: name, Type: OnlyInTo})
+			default:
+				diffs = append(diffs, compareObjects(section, name, f, t)...)
+			}
+		}
+	}
+	return diffs
+}
+
+func compareObjects(section, name string, from, to object) []*Difference {
+	fields := map[string]bool{}
+	for k := range from {
+		fields[k] = true
+	}
+	for k := range to {
+		fields[k] = true
+	}
+	sorted := make([]string, 0, len(fields))
+	for k := range fields {
+		sorted = append(sorted, k)
+	}
+	sort.Strings(sorted)
+
+	var diffs []*Difference
+	for _, field := range sorted {
+		if reflect.DeepEqual(from[field], to[field]) {
+			continue
+		}
+		diff := &Difference{Section: section, Name: name, Type: Changed, Field: field, From: from[field], To: to[field]}
+		if section == "configuration" {
+			// Settings are single values; the name says it all
+			diff.Field = ""
+		}
+		diffs = append(diffs, diff)
+	}
+	return diffs
+}
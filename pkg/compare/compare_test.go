@@ -0,0 +1,69 @@
+package compare
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/pascal71/hrbcli/pkg/api"
+)
+
+// fakeHarbor serves fixed list responses by path
+func fakeHarbor(t *testing.T, responses map[string]string) *api.Client {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, ok := responses[r.URL.Path]
+		if !ok {
+			body = "[]"
+		}
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+	return &api.Client{BaseURL: server.URL, APIVersion: "v2.0", HTTPClient: server.Client()}
+}
+
+func TestCollectAndCompare(t *testing.T) {
+	prod := fakeHarbor(t, map[string]string{
+		"/api/v2.0/registries":           `[{"id":1,"name":"hub","type":"docker-hub","url":"https://hub.docker.com"}]`,
+		"/api/v2.0/projects":             `[{"project_id":1,"name":"cache","public":true,"registry_id":1,"metadata":{"auto_scan":"true","retention_id":"4"}}]`,
+		"/api/v2.0/replication/policies": `[{"id":1,"name":"pull","src_registry":{"id":1},"enabled":true}]`,
+		"/api/v2.0/users":                `[{"username":"alice","email":"alice@example.com"}]`,
+		"/api/v2.0/configurations":       `{"auth_mode":{"value":"db_auth"},"robot_token_duration":{"value":30}}`,
+	})
+	// Same objects with other IDs, one changed field, a missing user and
+	// a different setting
+	staging := fakeHarbor(t, map[string]string{
+		"/api/v2.0/registries":           `[{"id":8,"name":"hub","type":"docker-hub","url":"https://hub.docker.com"}]`,
+		"/api/v2.0/projects":             `[{"project_id":5,"name":"cache","public":true,"registry_id":8,"metadata":{"auto_scan":"false","retention_id":"9"}}]`,
+		"/api/v2.0/replication/policies": `[{"id":3,"name":"pull","src_registry":{"id":8},"enabled":true}]`,
+		"/api/v2.0/configurations":       `{"auth_mode":{"value":"db_auth"},"robot_token_duration":{"value":60}}`,
+	})
+
+	from, err := Collect(context.Background(), prod)
+	if err != nil {
+		t.Fatal(err)
+	}
+	to, err := Collect(context.Background(), staging)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	diffs := Compare(from, to)
+	want := []Difference{
+		{Section: "projects", Name: "cache", Type: Changed, Field: "metadata.auto_scan", From: "true", To: "false"},
+		{Section: "users", Name: "alice", Type: OnlyInFrom},
+		{Section: "configuration", Name: "robot_token_duration", Type: Changed, From: float64(30), To: float64(60)},
+	}
+	if len(diffs) != len(want) {
+		for _, d := range diffs {
+			t.Logf("%+v", d)
+		}
+		t.Fatalf("expected %d differences, got %d", len(want), len(diffs))
+	}
+	for i, w := range want {
+		if *diffs[i] != w {
+			t.Errorf("difference %d: got %+v, want %+v", i, *diffs[i], w)
+		}
+	}
+}
@@ -2,11 +2,20 @@ package config
 
 import (
 	"fmt"
+	"net/url"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/spf13/viper"
-	"gopkg.in/yaml.v3"
+)
+
+// Default retry settings for transient API failures
+const (
+	DefaultMaxAttempts  = 3
+	DefaultRetryWaitMin = 500 * time.Millisecond
+	DefaultRetryWaitMax = 30 * time.Second
 )
 
 // Config represents the Harbor CLI configuration
@@ -16,10 +25,26 @@ type Config struct {
 	Password       string `yaml:"password,omitempty" json:"password,omitempty"`
 	APIVersion     string `yaml:"api_version" json:"api_version"`
 	Insecure       bool   `yaml:"insecure" json:"insecure"`
+	CACert         string `yaml:"ca_cert,omitempty" json:"ca_cert,omitempty"`
+	ClientCert     string `yaml:"client_cert,omitempty" json:"client_cert,omitempty"`
+	ClientKey      string `yaml:"client_key,omitempty" json:"client_key,omitempty"`
 	OutputFormat   string `yaml:"output_format" json:"output_format"`
 	DefaultProject string `yaml:"default_project,omitempty" json:"default_project,omitempty"`
 	NoColor        bool   `yaml:"no_color" json:"no_color"`
 	Debug          bool   `yaml:"debug" json:"debug"`
+
+	// Retry settings for transient API failures
+	MaxAttempts  int           `yaml:"max_attempts,omitempty" json:"max_attempts,omitempty"`
+	RetryWaitMin time.Duration `yaml:"retry_wait_min,omitempty" json:"retry_wait_min,omitempty"`
+	RetryWaitMax time.Duration `yaml:"retry_wait_max,omitempty" json:"retry_wait_max,omitempty"`
+
+	// Credential store for passwords: "file" or a Docker credential
+	// helper name
+	CredentialsStore string `yaml:"credentials_store,omitempty" json:"credentials_store,omitempty"`
+	CredentialsFile  string `yaml:"credentials_file,omitempty" json:"credentials_file,omitempty"`
+
+	// Context is the name of the context the settings were loaded from
+	Context string `yaml:"context,omitempty" json:"context,omitempty"`
 }
 
 // GetConfigPath returns the path to the config file
@@ -36,18 +61,47 @@ func GetConfigPath() string {
 	return filepath.Join(home, ".hrbcli.yaml")
 }
 
-// Load loads the configuration from file
+// DefaultCACertFile returns where 'config fetch-ca' saves the root
+// certificate of harborURL. It lives next to the config file.
+func DefaultCACertFile(harborURL string) string {
+	host := harborURL
+	if u, err := url.Parse(harborURL); err == nil && u.Host != "" {
+		host = u.Host
+	}
+	host = strings.NewReplacer(":", "_", "/", "_").Replace(host)
+	return filepath.Join(filepath.Dir(GetConfigPath()), ".hrbcli-ca", host+".crt")
+}
+
+// Load loads the configuration from file. When a context is active its
+// connection settings replace the global ones.
 func Load() (*Config, error) {
+	name := ActiveContext()
+	if name != "" {
+		if err := applyContext(name); err != nil {
+			return nil, err
+		}
+	}
+
 	cfg := &Config{
 		HarborURL:      viper.GetString("harbor_url"),
 		Username:       viper.GetString("username"),
 		Password:       viper.GetString("password"),
 		APIVersion:     viper.GetString("api_version"),
 		Insecure:       viper.GetBool("insecure"),
+		CACert:         viper.GetString("ca_cert"),
+		ClientCert:     viper.GetString("client_cert"),
+		ClientKey:      viper.GetString("client_key"),
 		OutputFormat:   viper.GetString("output_format"),
 		DefaultProject: viper.GetString("default_project"),
 		NoColor:        viper.GetBool("no_color"),
 		Debug:          viper.GetBool("debug"),
+		MaxAttempts:    viper.GetInt("max_attempts"),
+		RetryWaitMin:   viper.GetDuration("retry_wait_min"),
+		RetryWaitMax:   viper.GetDuration("retry_wait_max"),
+		Context:        name,
+
+		CredentialsStore: viper.GetString("credentials_store"),
+		CredentialsFile:  viper.GetString("credentials_file"),
 	}
 
 	// Set defaults
@@ -57,49 +111,54 @@ func Load() (*Config, error) {
 	if cfg.OutputFormat == "" {
 		cfg.OutputFormat = "table"
 	}
+	if cfg.MaxAttempts == 0 {
+		cfg.MaxAttempts = DefaultMaxAttempts
+	}
+	if cfg.RetryWaitMin == 0 {
+		cfg.RetryWaitMin = DefaultRetryWaitMin
+	}
+	if cfg.RetryWaitMax == 0 {
+		cfg.RetryWaitMax = DefaultRetryWaitMax
+	}
 
 	return cfg, nil
 }
 
-// Save saves the configuration to file
+// Save saves the configuration to file as the global settings. Contexts
+// already in the file are kept.
 func Save(cfg *Config) error {
-	configPath := GetConfigPath()
-
-	// Create directory if it doesn't exist
-	dir := filepath.Dir(configPath)
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		return fmt.Errorf("failed to create config directory: %w", err)
-	}
-
-	// Don't save password to file
-	cfgToSave := *cfg
-	cfgToSave.Password = ""
-
-	// Marshal to YAML
-	data, err := yaml.Marshal(&cfgToSave)
+	f, err := LoadFile()
 	if err != nil {
-		return fmt.Errorf("failed to marshal config: %w", err)
-	}
-
-	// Write to file with restricted permissions
-	if err := os.WriteFile(configPath, data, 0600); err != nil {
-		return fmt.Errorf("failed to write config file: %w", err)
+		return err
 	}
-
-	return nil
+	f.Config = *cfg
+	return f.Save()
 }
 
 // Set sets a configuration value
 func Set(key string, value interface{}) error {
 	viper.Set(key, value)
 
-	// Load current config
-	cfg, err := Load()
+	f, err := LoadFile()
 	if err != nil {
 		return err
 	}
 
+	// Connection settings go to the active context, creating it if needed
+	if name := ActiveContext(); name != "" && IsContextKey(key) {
+		c, ok := f.Contexts[name]
+		if !ok {
+			c = &Context{}
+			f.Contexts[name] = c
+		}
+		if err := c.set(key, value); err != nil {
+			return err
+		}
+		return f.Save()
+	}
+
 	// Update the specific field
+	cfg := &f.Config
 	switch key {
 	case "harbor_url":
 		cfg.HarborURL = value.(string)
@@ -111,6 +170,12 @@ func Set(key string, value interface{}) error {
 		cfg.APIVersion = value.(string)
 	case "insecure":
 		cfg.Insecure = value.(bool)
+	case "ca_cert":
+		cfg.CACert = value.(string)
+	case "client_cert":
+		cfg.ClientCert = value.(string)
+	case "client_key":
+		cfg.ClientKey = value.(string)
 	case "output_format":
 		cfg.OutputFormat = value.(string)
 	case "default_project":
@@ -119,12 +184,22 @@ func Set(key string, value interface{}) error {
 		cfg.NoColor = value.(bool)
 	case "debug":
 		cfg.Debug = value.(bool)
+	case "max_attempts":
+		cfg.MaxAttempts = value.(int)
+	case "retry_wait_min":
+		cfg.RetryWaitMin = value.(time.Duration)
+	case "retry_wait_max":
+		cfg.RetryWaitMax = value.(time.Duration)
+	case "credentials_store":
+		cfg.CredentialsStore = value.(string)
+	case "credentials_file":
+		cfg.CredentialsFile = value.(string)
 	default:
 		return fmt.Errorf("unknown configuration key: %s", key)
 	}
 
 	// Save to file
-	return Save(cfg)
+	return f.Save()
 }
 
 // Get gets a configuration value
@@ -157,5 +232,9 @@ func ValidateConfig() error {
 		return fmt.Errorf("invalid output format: %s (valid: table, json, yaml)", format)
 	}
 
+	if viper.GetInt("max_attempts") < 0 {
+		return fmt.Errorf("max_attempts must not be negative")
+	}
+
 	return nil
 }
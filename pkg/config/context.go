@@ -0,0 +1,277 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
+)
+
+// Context holds the connection settings of one named Harbor instance
+type Context struct {
+	HarborURL      string `yaml:"harbor_url" json:"harbor_url"`
+	Username       string `yaml:"username,omitempty" json:"username,omitempty"`
+	APIVersion     string `yaml:"api_version,omitempty" json:"api_version,omitempty"`
+	Insecure       bool   `yaml:"insecure,omitempty" json:"insecure,omitempty"`
+	CACert         string `yaml:"ca_cert,omitempty" json:"ca_cert,omitempty"`
+	ClientCert     string `yaml:"client_cert,omitempty" json:"client_cert,omitempty"`
+	ClientKey      string `yaml:"client_key,omitempty" json:"client_key,omitempty"`
+	DefaultProject string `yaml:"default_project,omitempty" json:"default_project,omitempty"`
+}
+
+// contextKeys are the configuration keys that a context overrides
+var contextKeys = []string{
+	"harbor_url",
+	"username",
+	"api_version",
+	"insecure",
+	"ca_cert",
+	"client_cert",
+	"client_key",
+	"default_project",
+}
+
+// IsContextKey reports whether key is stored per context rather than
+// globally
+func IsContextKey(key string) bool {
+	for _, k := range contextKeys {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+// settings returns the context as configuration keys. Every connection
+// key is included so that a context fully replaces the global settings.
+func (c *Context) settings() map[string]interface{} {
+	return map[string]interface{}{
+		"harbor_url":      c.HarborURL,
+		"username":        c.Username,
+		"api_version":     c.APIVersion,
+		"insecure":        c.Insecure,
+		"ca_cert":         c.CACert,
+		"client_cert":     c.ClientCert,
+		"client_key":      c.ClientKey,
+		"default_project": c.DefaultProject,
+	}
+}
+
+// set updates a single context key
+func (c *Context) set(key string, value interface{}) error {
+	switch key {
+	case "harbor_url":
+		c.HarborURL = value.(string)
+	case "username":
+		c.Username = value.(string)
+	case "api_version":
+		c.APIVersion = value.(string)
+	case "insecure":
+		c.Insecure = value.(bool)
+	case "ca_cert":
+		c.CACert = value.(string)
+	case "client_cert":
+		c.ClientCert = value.(string)
+	case "client_key":
+		c.ClientKey = value.(string)
+	case "default_project":
+		c.DefaultProject = value.(string)
+	default:
+		return fmt.Errorf("unknown context key: %s", key)
+	}
+	return nil
+}
+
+// File is the on-disk layout of the config file: global settings plus
+// any number of named contexts
+type File struct {
+	Config         `yaml:",inline"`
+	CurrentContext string              `yaml:"current_context,omitempty"`
+	Contexts       map[string]*Context `yaml:"contexts,omitempty"`
+}
+
+// LoadFile reads the config file as stored on disk, without flags or
+// environment variables applied. A missing file yields an empty File.
+func LoadFile() (*File, error) {
+	f := &File{}
+	data, err := os.ReadFile(GetConfigPath())
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			f.Contexts = map[string]*Context{}
+			return f, nil
+		}
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+	if err := yaml.Unmarshal(data, f); err != nil {
+		return nil, fmt.Errorf("failed to parse config file: %w", err)
+	}
+	if f.Contexts == nil {
+		f.Contexts = map[string]*Context{}
+	}
+	return f, nil
+}
+
+// Save writes the file back to disk with restricted permissions
+func (f *File) Save() error {
+	configPath := GetConfigPath()
+
+	// Create directory if it doesn't exist
+	if err := os.MkdirAll(filepath.Dir(configPath), 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+
+	// Don't save password to file
+	toSave := *f
+	toSave.Password = ""
+	toSave.Context = ""
+
+	data, err := yaml.Marshal(&toSave)
+	if err != nil {
+		return fmt.Errorf("failed to marshal config: %w", err)
+	}
+
+	if err := os.WriteFile(configPath, data, 0600); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	return nil
+}
+
+// ContextNames returns the names of all contexts in sorted order
+func (f *File) ContextNames() []string {
+	names := make([]string, 0, len(f.Contexts))
+	for name := range f.Contexts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ActiveContext returns the context selected with --context or
+// HARBOR_CONTEXT, falling back to current_context from the config file.
+// It returns an empty string when no context is in use.
+func ActiveContext() string {
+	if name := viper.GetString("context"); name != "" {
+		return name
+	}
+	return viper.GetString("current_context")
+}
+
+// applyContext makes the settings of the named context take precedence
+// over the global ones in the config file. Flags and environment
+// variables still override them.
+func applyContext(name string) error {
+	f, err := LoadFile()
+	if err != nil {
+		return err
+	}
+	c, ok := f.Contexts[name]
+	if !ok {
+		return fmt.Errorf("context %q not found in %s", name, GetConfigPath())
+	}
+	return viper.MergeConfigMap(c.settings())
+}
+
+// LoadContext returns the configuration for the named context. Global
+// settings such as retries apply as usual, but the connection settings
+// come from the context alone, so flags like --harbor-url and the
+// password given for the active context do not leak into it.
+func LoadContext(name string) (*Config, error) {
+	f, err := LoadFile()
+	if err != nil {
+		return nil, err
+	}
+	c, ok := f.Contexts[name]
+	if !ok {
+		return nil, fmt.Errorf("context %q not found in %s", name, GetConfigPath())
+	}
+
+	cfg, err := Load()
+	if err != nil {
+		return nil, err
+	}
+	cfg.Context = name
+	cfg.HarborURL = c.HarborURL
+	cfg.Username = c.Username
+	cfg.Password = ""
+	cfg.APIVersion = c.APIVersion
+	cfg.Insecure = c.Insecure
+	cfg.CACert = c.CACert
+	cfg.ClientCert = c.ClientCert
+	cfg.ClientKey = c.ClientKey
+	cfg.DefaultProject = c.DefaultProject
+	if cfg.APIVersion == "" {
+		cfg.APIVersion = "v2.0"
+	}
+	return cfg, nil
+}
+
+// SaveContext creates or replaces the named context
+func SaveContext(name string, c *Context) error {
+	if name == "" {
+		return fmt.Errorf("context name is required")
+	}
+	f, err := LoadFile()
+	if err != nil {
+		return err
+	}
+	f.Contexts[name] = c
+	return f.Save()
+}
+
+// UseContext makes name the current context
+func UseContext(name string) error {
+	f, err := LoadFile()
+	if err != nil {
+		return err
+	}
+	if _, ok := f.Contexts[name]; !ok {
+		return fmt.Errorf("context %q not found", name)
+	}
+	f.CurrentContext = name
+	return f.Save()
+}
+
+// RenameContext renames a context, updating current_context when needed
+func RenameContext(oldName, newName string) error {
+	if newName == "" {
+		return fmt.Errorf("context name is required")
+	}
+	f, err := LoadFile()
+	if err != nil {
+		return err
+	}
+	c, ok := f.Contexts[oldName]
+	if !ok {
+		return fmt.Errorf("context %q not found", oldName)
+	}
+	if _, exists := f.Contexts[newName]; exists {
+		return fmt.Errorf("context %q already exists", newName)
+	}
+	delete(f.Contexts, oldName)
+	f.Contexts[newName] = c
+	if f.CurrentContext == oldName {
+		f.CurrentContext = newName
+	}
+	return f.Save()
+}
+
+// DeleteContext removes a context. Deleting the current context leaves
+// no context selected.
+func DeleteContext(name string) error {
+	f, err := LoadFile()
+	if err != nil {
+		return err
+	}
+	if _, ok := f.Contexts[name]; !ok {
+		return fmt.Errorf("context %q not found", name)
+	}
+	delete(f.Contexts, name)
+	if f.CurrentContext == name {
+		f.CurrentContext = ""
+	}
+	return f.Save()
+}
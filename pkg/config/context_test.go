@@ -0,0 +1,124 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+const contextsFile = `harbor_url: https://global.example.com
+username: global
+output_format: json
+current_context: prod
+contexts:
+  prod:
+    harbor_url: https://prod.example.com
+    username: admin
+    insecure: true
+  Staging:
+    harbor_url: https://staging.example.com
+    api_version: v1.0
+`
+
+func loadContextsFile(t *testing.T) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(contextsFile), 0600); err != nil {
+		t.Fatal(err)
+	}
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+	viper.SetConfigFile(path)
+	if err := viper.ReadInConfig(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadUsesCurrentContext(t *testing.T) {
+	loadContextsFile(t)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Context != "prod" || cfg.HarborURL != "https://prod.example.com" || cfg.Username != "admin" || !cfg.Insecure {
+		t.Fatalf("prod context not applied: %+v", cfg)
+	}
+	if cfg.OutputFormat != "json" {
+		t.Fatalf("expected global output format, got %s", cfg.OutputFormat)
+	}
+}
+
+func TestLoadContextOverrideAndPrecedence(t *testing.T) {
+	loadContextsFile(t)
+	viper.Set("context", "Staging")
+	viper.Set("username", "from-flag")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.HarborURL != "https://staging.example.com" || cfg.APIVersion != "v1.0" || cfg.Insecure {
+		t.Fatalf("staging context not applied: %+v", cfg)
+	}
+	if cfg.Username != "from-flag" {
+		t.Fatalf("expected the flag to win over the context, got %s", cfg.Username)
+	}
+}
+
+func TestLoadUnknownContext(t *testing.T) {
+	loadContextsFile(t)
+	viper.Set("context", "missing")
+
+	if _, err := Load(); err == nil {
+		t.Fatalf("expected error for unknown context")
+	}
+}
+
+func TestRenameAndDeleteContext(t *testing.T) {
+	loadContextsFile(t)
+
+	if err := RenameContext("prod", "production"); err != nil {
+		t.Fatalf("rename failed: %v", err)
+	}
+	f, err := LoadFile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.CurrentContext != "production" || f.Contexts["production"] == nil || f.Contexts["prod"] != nil {
+		t.Fatalf("unexpected file after rename: %+v", f)
+	}
+	if f.HarborURL != "https://global.example.com" {
+		t.Fatalf("global settings lost: %+v", f.Config)
+	}
+
+	if err := DeleteContext("production"); err != nil {
+		t.Fatalf("delete failed: %v", err)
+	}
+	f, _ = LoadFile()
+	if f.CurrentContext != "" || len(f.Contexts) != 1 {
+		t.Fatalf("unexpected file after delete: %+v", f)
+	}
+}
+
+func TestLoadContextIgnoresActiveOverrides(t *testing.T) {
+	loadContextsFile(t)
+	viper.Set("harbor_url", "https://from-flag.example.com")
+	viper.Set("password", "secret")
+
+	cfg, err := LoadContext("Staging")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.HarborURL != "https://staging.example.com" || cfg.APIVersion != "v1.0" || cfg.Password != "" || cfg.Insecure {
+		t.Fatalf("unexpected staging config: %+v", cfg)
+	}
+	if cfg.OutputFormat != "json" {
+		t.Fatalf("expected global output format, got %s", cfg.OutputFormat)
+	}
+	if _, err := LoadContext("missing"); err == nil {
+		t.Fatalf("expected an error for an unknown context")
+	}
+}
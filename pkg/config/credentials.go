@@ -0,0 +1,354 @@
+package config
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// CredentialsStoreFile selects the passphrase-encrypted credentials file.
+// Any other non-empty credentials_store value names a Docker credential
+// helper, e.g. "osxkeychain" for docker-credential-osxkeychain.
+const CredentialsStoreFile = "file"
+
+// PassphraseEnv is the environment variable holding the passphrase of
+// the encrypted credentials file
+const PassphraseEnv = "HARBOR_CREDENTIALS_PASSPHRASE"
+
+// ErrCredentialsNotFound is returned when a store has no credentials for
+// a server
+var ErrCredentialsNotFound = errors.New("credentials not found")
+
+// CredentialStore saves Harbor credentials keyed by server URL
+type CredentialStore interface {
+	// Get returns the username and secret stored for serverURL, or
+	// ErrCredentialsNotFound
+	Get(serverURL string) (username, secret string, err error)
+	// Store saves the credentials for serverURL, replacing existing ones
+	Store(serverURL, username, secret string) error
+	// Erase removes the credentials for serverURL
+	Erase(serverURL string) error
+}
+
+// PromptPassphrase asks the user for the passphrase of the credentials
+// file when PassphraseEnv is not set. confirm is true when a new file is
+// about to be created. It is nil when no terminal is available.
+var PromptPassphrase func(confirm bool) (string, error)
+
+// NewCredentialStore returns the credential store selected by
+// cfg.CredentialsStore, or nil when none is configured
+func NewCredentialStore(cfg *Config) CredentialStore {
+	switch cfg.CredentialsStore {
+	case "":
+		return nil
+	case CredentialsStoreFile:
+		path := cfg.CredentialsFile
+		if path == "" {
+			path = DefaultCredentialsFile()
+		}
+		return &FileStore{Path: path, Passphrase: filePassphrase}
+	default:
+		return &HelperStore{Program: "docker-credential-" + cfg.CredentialsStore}
+	}
+}
+
+// DefaultCredentialsFile returns the encrypted credentials file used when
+// credentials_file is not set. It lives next to the config file.
+func DefaultCredentialsFile() string {
+	return filepath.Join(filepath.Dir(GetConfigPath()), ".hrbcli-credentials")
+}
+
+// ResolveCredentials fills in the username and password from the
+// credential store when no password was given by flag, environment or
+// config file. Stored credentials for a different user are ignored.
+func ResolveCredentials(cfg *Config) error {
+	if cfg.Password != "" || cfg.HarborURL == "" {
+		return nil
+	}
+	store := NewCredentialStore(cfg)
+	if store == nil {
+		return nil
+	}
+
+	username, secret, err := store.Get(strings.TrimRight(cfg.HarborURL, "/"))
+	if errors.Is(err, ErrCredentialsNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read credentials: %w", err)
+	}
+	if cfg.Username != "" && cfg.Username != username {
+		return nil
+	}
+	cfg.Username = username
+	cfg.Password = secret
+	return nil
+}
+
+func filePassphrase(confirm bool) (string, error) {
+	if p := os.Getenv(PassphraseEnv); p != "" {
+		return p, nil
+	}
+	if PromptPassphrase == nil {
+		return "", fmt.Errorf("set %s to unlock the credentials file", PassphraseEnv)
+	}
+	return PromptPassphrase(confirm)
+}
+
+// HelperStore keeps credentials in a Docker credential helper such as
+// docker-credential-osxkeychain, -wincred, -secretservice or -pass
+type HelperStore struct {
+	// Program is the helper binary name or path
+	Program string
+}
+
+// helperCredentials is the JSON payload of the credential helper protocol
+type helperCredentials struct {
+	ServerURL string `json:"ServerURL"`
+	Username  string `json:"Username"`
+	Secret    string `json:"Secret"`
+}
+
+func (h *HelperStore) run(action string, input []byte) ([]byte, error) {
+	cmd := exec.Command(h.Program, action)
+	cmd.Stdin = bytes.NewReader(input)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		msg := strings.TrimSpace(string(out))
+		if msg == "" {
+			msg = strings.TrimSpace(stderr.String())
+		}
+		// Helpers report a missing entry with this message on stdout
+		if strings.Contains(msg, "credentials not found") {
+			return nil, ErrCredentialsNotFound
+		}
+		if msg == "" {
+			msg = err.Error()
+		}
+		return nil, fmt.Errorf("%s %s: %s", h.Program, action, msg)
+	}
+	return out, nil
+}
+
+// Get implements CredentialStore
+func (h *HelperStore) Get(serverURL string) (string, string, error) {
+	out, err := h.run("get", []byte(serverURL))
+	if err != nil {
+		return "", "", err
+	}
+	var creds helperCredentials
+	if err := json.Unmarshal(out, &creds); err != nil {
+		return "", "", fmt.Errorf("failed to decode %s output: %w", h.Program, err)
+	}
+	if creds.Secret == "" {
+		return "", "", ErrCredentialsNotFound
+	}
+	return creds.Username, creds.Secret, nil
+}
+
+// Store implements CredentialStore
+func (h *HelperStore) Store(serverURL, username, secret string) error {
+	data, err := json.Marshal(&helperCredentials{ServerURL: serverURL, Username: username, Secret: secret})
+	if err != nil {
+		return err
+	}
+	_, err = h.run("store", data)
+	return err
+}
+
+// Erase implements CredentialStore
+func (h *HelperStore) Erase(serverURL string) error {
+	_, err := h.run("erase", []byte(serverURL))
+	return err
+}
+
+// Key derivation parameters for new credentials files
+const (
+	fileKDF        = "pbkdf2-sha256"
+	fileIterations = 600000
+	fileKeyLength  = 32
+	fileSaltLength = 16
+)
+
+// FileStore keeps credentials in a local file encrypted with AES-256-GCM
+// under a key derived from a passphrase
+type FileStore struct {
+	Path string
+	// Passphrase returns the passphrase; confirm is true when the file
+	// does not exist yet
+	Passphrase func(confirm bool) (string, error)
+
+	passphrase string
+}
+
+// encryptedFile is the on-disk layout of the credentials file
+type encryptedFile struct {
+	KDF        string `json:"kdf"`
+	Iterations int    `json:"iterations"`
+	Salt       []byte `json:"salt"`
+	Nonce      []byte `json:"nonce"`
+	Data       []byte `json:"data"`
+}
+
+type fileCredentials struct {
+	Username string `json:"username"`
+	Secret   string `json:"secret"`
+}
+
+// unlock returns the passphrase, asking for it at most once
+func (f *FileStore) unlock(confirm bool) (string, error) {
+	if f.passphrase != "" {
+		return f.passphrase, nil
+	}
+	p, err := f.Passphrase(confirm)
+	if err != nil {
+		return "", err
+	}
+	if p == "" {
+		return "", fmt.Errorf("passphrase must not be empty")
+	}
+	f.passphrase = p
+	return p, nil
+}
+
+func fileCipher(passphrase string, salt []byte, iterations int) (cipher.AEAD, error) {
+	key, err := pbkdf2.Key(sha256.New, passphrase, salt, iterations, fileKeyLength)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// load decrypts the file. A missing file yields no credentials.
+func (f *FileStore) load() (map[string]fileCredentials, error) {
+	raw, err := os.ReadFile(f.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return map[string]fileCredentials{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read credentials file: %w", err)
+	}
+
+	var enc encryptedFile
+	if err := json.Unmarshal(raw, &enc); err != nil {
+		return nil, fmt.Errorf("failed to parse credentials file: %w", err)
+	}
+	if enc.KDF != fileKDF {
+		return nil, fmt.Errorf("unsupported credentials file key derivation: %s", enc.KDF)
+	}
+
+	passphrase, err := f.unlock(false)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := fileCipher(passphrase, enc.Salt, enc.Iterations)
+	if err != nil {
+		return nil, err
+	}
+	plain, err := aead.Open(nil, enc.Nonce, enc.Data, nil)
+	if err != nil {
+		f.passphrase = ""
+		return nil, fmt.Errorf("failed to decrypt credentials file: wrong passphrase?")
+	}
+
+	creds := map[string]fileCredentials{}
+	if err := json.Unmarshal(plain, &creds); err != nil {
+		return nil, fmt.Errorf("failed to decode credentials: %w", err)
+	}
+	return creds, nil
+}
+
+// save encrypts creds with a fresh salt and nonce and writes the file
+func (f *FileStore) save(creds map[string]fileCredentials) error {
+	_, statErr := os.Stat(f.Path)
+	passphrase, err := f.unlock(errors.Is(statErr, os.ErrNotExist))
+	if err != nil {
+		return err
+	}
+
+	plain, err := json.Marshal(creds)
+	if err != nil {
+		return err
+	}
+	enc := encryptedFile{KDF: fileKDF, Iterations: fileIterations, Salt: make([]byte, fileSaltLength)}
+	if _, err := rand.Read(enc.Salt); err != nil {
+		return err
+	}
+	aead, err := fileCipher(passphrase, enc.Salt, enc.Iterations)
+	if err != nil {
+		return err
+	}
+	enc.Nonce = make([]byte, aead.NonceSize())
+	if _, err := rand.Read(enc.Nonce); err != nil {
+		return err
+	}
+	enc.Data = aead.Seal(nil, enc.Nonce, plain, nil)
+
+	data, err := json.MarshalIndent(&enc, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(f.Path), 0700); err != nil {
+		return fmt.Errorf("failed to create credentials directory: %w", err)
+	}
+	if err := os.WriteFile(f.Path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write credentials file: %w", err)
+	}
+	return nil
+}
+
+// Get implements CredentialStore
+func (f *FileStore) Get(serverURL string) (string, string, error) {
+	if _, err := os.Stat(f.Path); errors.Is(err, os.ErrNotExist) {
+		return "", "", ErrCredentialsNotFound
+	}
+	creds, err := f.load()
+	if err != nil {
+		return "", "", err
+	}
+	c, ok := creds[serverURL]
+	if !ok {
+		return "", "", ErrCredentialsNotFound
+	}
+	return c.Username, c.Secret, nil
+}
+
+// Store implements CredentialStore
+func (f *FileStore) Store(serverURL, username, secret string) error {
+	creds, err := f.load()
+	if err != nil {
+		return err
+	}
+	creds[serverURL] = fileCredentials{Username: username, Secret: secret}
+	return f.save(creds)
+}
+
+// Erase implements CredentialStore
+func (f *FileStore) Erase(serverURL string) error {
+	creds, err := f.load()
+	if err != nil {
+		return err
+	}
+	if _, ok := creds[serverURL]; !ok {
+		return ErrCredentialsNotFound
+	}
+	delete(creds, serverURL)
+	return f.save(creds)
+}
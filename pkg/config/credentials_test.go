@@ -0,0 +1,111 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func fixedPassphrase(p string) func(bool) (string, error) {
+	return func(bool) (string, error) { return p, nil }
+}
+
+func TestFileStoreRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "credentials")
+	store := &FileStore{Path: path, Passphrase: fixedPassphrase("correct horse")}
+
+	if _, _, err := store.Get("https://harbor.example.com"); !errors.Is(err, ErrCredentialsNotFound) {
+		t.Fatalf("expected not found before login, got %v", err)
+	}
+	if err := store.Store("https://harbor.example.com", "admin", "s3cret"); err != nil {
+		t.Fatalf("store failed: %v", err)
+	}
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(raw), "s3cret") {
+		t.Fatalf("secret stored in plain text")
+	}
+
+	reopened := &FileStore{Path: path, Passphrase: fixedPassphrase("correct horse")}
+	user, secret, err := reopened.Get("https://harbor.example.com")
+	if err != nil || user != "admin" || secret != "s3cret" {
+		t.Fatalf("unexpected credentials %q/%q: %v", user, secret, err)
+	}
+
+	wrong := &FileStore{Path: path, Passphrase: fixedPassphrase("battery staple")}
+	if _, _, err := wrong.Get("https://harbor.example.com"); err == nil {
+		t.Fatalf("expected an error with the wrong passphrase")
+	}
+
+	if err := reopened.Erase("https://harbor.example.com"); err != nil {
+		t.Fatalf("erase failed: %v", err)
+	}
+	if _, _, err := reopened.Get("https://harbor.example.com"); !errors.Is(err, ErrCredentialsNotFound) {
+		t.Fatalf("expected not found after erase, got %v", err)
+	}
+}
+
+// fakeHelper is a docker-credential helper keeping one entry in a file
+const fakeHelper = `#!/bin/sh
+db="$(dirname "$0")/db"
+case "$1" in
+store) cat > "$db" ;;
+get)
+	read server
+	if [ -f "$db" ] && grep -q "\"ServerURL\":\"$server\"" "$db"; then cat "$db"; else echo "credentials not found in native keychain"; exit 1; fi ;;
+erase) rm -f "$db" ;;
+esac
+`
+
+func TestHelperStore(t *testing.T) {
+	dir := t.TempDir()
+	program := filepath.Join(dir, "docker-credential-fake")
+	if err := os.WriteFile(program, []byte(fakeHelper), 0700); err != nil {
+		t.Fatal(err)
+	}
+	store := &HelperStore{Program: program}
+
+	if _, _, err := store.Get("https://harbor.example.com"); !errors.Is(err, ErrCredentialsNotFound) {
+		t.Fatalf("expected not found, got %v", err)
+	}
+	if err := store.Store("https://harbor.example.com", "robot$ci", "token"); err != nil {
+		t.Fatalf("store failed: %v", err)
+	}
+	user, secret, err := store.Get("https://harbor.example.com")
+	if err != nil || user != "robot$ci" || secret != "token" {
+		t.Fatalf("unexpected credentials %q/%q: %v", user, secret, err)
+	}
+	if err := store.Erase("https://harbor.example.com"); err != nil {
+		t.Fatalf("erase failed: %v", err)
+	}
+}
+
+func TestResolveCredentialsIgnoresOtherUser(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "credentials")
+	t.Setenv(PassphraseEnv, "pp")
+	store := &FileStore{Path: path, Passphrase: filePassphrase}
+	if err := store.Store("https://harbor.example.com", "admin", "s3cret"); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &Config{HarborURL: "https://harbor.example.com/", CredentialsStore: CredentialsStoreFile, CredentialsFile: path}
+	if err := ResolveCredentials(cfg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Username != "admin" || cfg.Password != "s3cret" {
+		t.Fatalf("credentials not resolved: %+v", cfg)
+	}
+
+	cfg = &Config{HarborURL: "https://harbor.example.com", Username: "bob", CredentialsStore: CredentialsStoreFile, CredentialsFile: path}
+	if err := ResolveCredentials(cfg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Password != "" {
+		t.Fatalf("stored secret used for a different user")
+	}
+}
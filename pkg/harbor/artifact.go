@@ -1,12 +1,16 @@
 package harbor
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/url"
 	"strconv"
+	"strings"
 
 	"github.com/pascal71/hrbcli/pkg/api"
+	"github.com/pascal71/hrbcli/pkg/sbom"
 )
 
 // ArtifactService handles artifact related operations
@@ -19,12 +23,31 @@ func NewArtifactService(client *api.Client) *ArtifactService {
 	return &ArtifactService{client: client}
 }
 
+func artifactsPath(project, repository string) string {
+	return fmt.Sprintf("/projects/%s/repositories/%s/artifacts", url.PathEscape(project), url.PathEscape(repository))
+}
+
 // List lists artifacts in a repository
-func (s *ArtifactService) List(project, repository string, opts *api.ArtifactListOptions) ([]*api.Artifact, error) {
-	projectEsc := url.PathEscape(project)
-	repoEsc := url.PathEscape(repository)
-	path := fmt.Sprintf("/projects/%s/repositories/%s/artifacts", projectEsc, repoEsc)
+func (s *ArtifactService) List(ctx context.Context, project, repository string, opts *api.ArtifactListOptions) ([]*api.Artifact, error) {
+	resp, err := s.client.Get(ctx, artifactsPath(project, repository), artifactListParams(opts))
+	if err != nil {
+		return nil, err
+	}
+
+	var artifacts []*api.Artifact
+	if err := s.client.DecodeResponse(resp, &artifacts); err != nil {
+		return nil, fmt.Errorf("failed to decode artifacts: %w", err)
+	}
+
+	return artifacts, nil
+}
 
+// ListAll lists artifacts in a repository across all pages
+func (s *ArtifactService) ListAll(ctx context.Context, project, repository string, opts *api.ArtifactListOptions, limit int) ([]*api.Artifact, error) {
+	return api.ListAll[*api.Artifact](ctx, s.client, artifactsPath(project, repository), artifactListParams(opts), limit)
+}
+
+func artifactListParams(opts *api.ArtifactListOptions) map[string]string {
 	params := make(map[string]string)
 	if opts != nil {
 		if opts.Page > 0 {
@@ -46,32 +69,21 @@ func (s *ArtifactService) List(project, repository string, opts *api.ArtifactLis
 			params["with_scan_overview"] = "true"
 		}
 	}
-
-	resp, err := s.client.Get(path, params)
-	if err != nil {
-		return nil, err
-	}
-
-	var artifacts []*api.Artifact
-	if err := s.client.DecodeResponse(resp, &artifacts); err != nil {
-		return nil, fmt.Errorf("failed to decode artifacts: %w", err)
-	}
-
-	return artifacts, nil
+	return params
 }
 
 // Get retrieves details of a specific artifact
-func (s *ArtifactService) Get(project, repository, reference string) (*api.Artifact, error) {
+func (s *ArtifactService) Get(ctx context.Context, project, repository, reference string) (*api.Artifact, error) {
 	opts := &api.ArtifactGetOptions{
 		WithTag:       true,
 		WithLabel:     true,
 		WithSignature: true,
 	}
-	return s.GetWithOptions(project, repository, reference, opts)
+	return s.GetWithOptions(ctx, project, repository, reference, opts)
 }
 
 // GetWithOptions retrieves details of a specific artifact with optional parameters
-func (s *ArtifactService) GetWithOptions(project, repository, reference string, opts *api.ArtifactGetOptions) (*api.Artifact, error) {
+func (s *ArtifactService) GetWithOptions(ctx context.Context, project, repository, reference string, opts *api.ArtifactGetOptions) (*api.Artifact, error) {
 	projectEsc := url.PathEscape(project)
 	repoEsc := url.PathEscape(repository)
 	refEsc := url.PathEscape(reference)
@@ -93,7 +105,7 @@ func (s *ArtifactService) GetWithOptions(project, repository, reference string,
 		}
 	}
 
-	resp, err := s.client.Get(path, params)
+	resp, err := s.client.Get(ctx, path, params)
 	if err != nil {
 		return nil, err
 	}
@@ -107,7 +119,7 @@ func (s *ArtifactService) GetWithOptions(project, repository, reference string,
 }
 
 // Scan triggers vulnerability scan for the specified artifact
-func (s *ArtifactService) Scan(project, repository, reference string, scanType string) error {
+func (s *ArtifactService) Scan(ctx context.Context, project, repository, reference string, scanType string) error {
 	projectEsc := url.PathEscape(project)
 	repoEsc := url.PathEscape(repository)
 	refEsc := url.PathEscape(reference)
@@ -118,7 +130,7 @@ func (s *ArtifactService) Scan(project, repository, reference string, scanType s
 		body = map[string]string{"scan_type": scanType}
 	}
 
-	resp, err := s.client.Post(path, body)
+	resp, err := s.client.Post(ctx, path, body)
 	if err != nil {
 		return err
 	}
@@ -131,54 +143,169 @@ func (s *ArtifactService) Scan(project, repository, reference string, scanType s
 	return nil
 }
 
-// Vulnerabilities retrieves the vulnerability report for the specified artifact
-func (s *ArtifactService) Vulnerabilities(project, repository, reference string) (*api.VulnerabilityReport, error) {
+// Vulnerabilities retrieves the vulnerability report for the specified
+// artifact. It returns nil when the artifact has not been scanned.
+func (s *ArtifactService) Vulnerabilities(ctx context.Context, project, repository, reference string) (*api.VulnerabilityReport, error) {
 	projectEsc := url.PathEscape(project)
 	repoEsc := url.PathEscape(repository)
 	refEsc := url.PathEscape(reference)
 	path := fmt.Sprintf("/projects/%s/repositories/%s/artifacts/%s/additions/vulnerabilities", projectEsc, repoEsc, refEsc)
 
-	resp, err := s.client.Get(path, nil)
+	resp, err := s.client.Get(ctx, path, nil)
 	if err != nil {
 		return nil, err
 	}
 
+	var raw map[string]json.RawMessage
+	if err := s.client.DecodeResponse(resp, &raw); err != nil {
+		return nil, fmt.Errorf("failed to decode vulnerability report: %w", err)
+	}
+	// An artifact that was never scanned has no report
+	if len(raw) == 0 {
+		return nil, nil
+	}
+
+	var data []byte
+	if _, ok := raw["vulnerabilities"]; ok {
+		var err error
+		if data, err = json.Marshal(raw); err != nil {
+			return nil, fmt.Errorf("failed to encode vulnerability report: %w", err)
+		}
+	} else if mimeType := vulnerabilityReportKey(raw); mimeType != "" {
+		data = raw[mimeType]
+	} else {
+		return nil, fmt.Errorf("no vulnerability report found in response")
+	}
+
 	var report api.VulnerabilityReport
-	if err := s.client.DecodeResponse(resp, &report); err != nil {
+	if err := json.Unmarshal(data, &report); err != nil {
 		return nil, fmt.Errorf("failed to decode vulnerability report: %w", err)
 	}
 
 	return &report, nil
 }
 
-// SBOM retrieves the SBOM report for the specified artifact
-func (s *ArtifactService) SBOM(project, repository, reference string) (map[string]interface{}, error) {
+// vulnerabilityReportKey picks the MIME type key of a vulnerability
+// report, e.g. "application/vnd.security.vulnerability.report; version=1.1"
+// or the older "application/vnd.scanner.adapter.vuln.report.harbor+json;
+// version=1.0". The current family wins, then the highest version.
+func vulnerabilityReportKey(raw map[string]json.RawMessage) string {
+	rank := func(mimeType string) int {
+		switch {
+		case strings.Contains(mimeType, "vulnerability.report"):
+			return 2
+		case strings.Contains(mimeType, "vuln.report"):
+			return 1
+		}
+		return 0
+	}
+
+	best := ""
+	for mimeType := range raw {
+		r := rank(mimeType)
+		if r == 0 {
+			continue
+		}
+		if best == "" || r > rank(best) || (r == rank(best) && mimeType > best) {
+			best = mimeType
+		}
+	}
+	return best
+}
+
+// SBOM retrieves the SBOM report for the specified artifact. It returns
+// nil when the artifact has no SBOM.
+func (s *ArtifactService) SBOM(ctx context.Context, project, repository, reference string) (*sbom.SBOM, error) {
 	projectEsc := url.PathEscape(project)
 	repoEsc := url.PathEscape(repository)
 	refEsc := url.PathEscape(reference)
 	path := fmt.Sprintf("/projects/%s/repositories/%s/artifacts/%s/additions/sbom", projectEsc, repoEsc, refEsc)
 
-	resp, err := s.client.Get(path, nil)
+	resp, err := s.client.Get(ctx, path, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	var report map[string]interface{}
-	if err := s.client.DecodeResponse(resp, &report); err != nil {
+	var raw map[string]json.RawMessage
+	if err := s.client.DecodeResponse(resp, &raw); err != nil {
 		return nil, fmt.Errorf("failed to decode SBOM: %w", err)
 	}
+	if len(raw) == 0 {
+		return nil, nil
+	}
 
-	return report, nil
+	data, _ := json.Marshal(raw)
+	return sbom.Parse(data)
 }
 
 // Delete removes the specified artifact identified by tag or digest.
-func (s *ArtifactService) Delete(project, repository, reference string) error {
+func (s *ArtifactService) Delete(ctx context.Context, project, repository, reference string) error {
 	projectEsc := url.PathEscape(project)
 	repoEsc := url.PathEscape(repository)
 	refEsc := url.PathEscape(reference)
 	path := fmt.Sprintf("/projects/%s/repositories/%s/artifacts/%s", projectEsc, repoEsc, refEsc)
 
-	resp, err := s.client.Delete(path)
+	resp, err := s.client.Delete(ctx, path)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Copy copies an artifact into a repository on the server side. from
+// names the source as <project>/<repository>:<tag> or
+// <project>/<repository>@<digest>. The digest of the copy is returned.
+func (s *ArtifactService) Copy(ctx context.Context, project, repository, from string) (string, error) {
+	path := artifactsPath(project, repository) + "?" + url.Values{"from": {from}}.Encode()
+
+	resp, err := s.client.Post(ctx, path, nil)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		return "", fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	// The Location header points at the copy by digest
+	location := resp.Header.Get("Location")
+	if location == "" {
+		return "", nil
+	}
+	digest, err := url.PathUnescape(location[strings.LastIndex(location, "/")+1:])
+	if err != nil {
+		return "", fmt.Errorf("invalid location header: %s", location)
+	}
+	return digest, nil
+}
+
+// CreateTag adds a tag to the artifact identified by tag or digest
+func (s *ArtifactService) CreateTag(ctx context.Context, project, repository, reference, tag string) error {
+	path := fmt.Sprintf("%s/%s/tags", artifactsPath(project, repository), url.PathEscape(reference))
+
+	resp, err := s.client.Post(ctx, path, map[string]string{"name": tag})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// DeleteTag removes a tag from the artifact identified by tag or digest
+func (s *ArtifactService) DeleteTag(ctx context.Context, project, repository, reference, tag string) error {
+	path := fmt.Sprintf("%s/%s/tags/%s", artifactsPath(project, repository), url.PathEscape(reference), url.PathEscape(tag))
+
+	resp, err := s.client.Delete(ctx, path)
 	if err != nil {
 		return err
 	}
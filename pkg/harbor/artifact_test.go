@@ -0,0 +1,124 @@
+package harbor
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/pascal71/hrbcli/pkg/api"
+)
+
+func TestArtifactServiceCopyAndTags(t *testing.T) {
+	var requests []string
+	var tagBody string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.EscapedPath()+"?"+r.URL.RawQuery)
+		switch r.Method {
+		case http.MethodPost:
+			if r.URL.Query().Get("from") != "" {
+				w.Header().Set("Location", "/api/v2.0/projects/prod/repositories/app/artifacts/sha256:abc")
+			} else {
+				data, _ := io.ReadAll(r.Body)
+				tagBody = string(data)
+			}
+			w.WriteHeader(http.StatusCreated)
+		default:
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+	defer server.Close()
+
+	client := &api.Client{BaseURL: server.URL, APIVersion: "v2.0", HTTPClient: server.Client()}
+	svc := NewArtifactService(client)
+	ctx := context.Background()
+
+	digest, err := svc.Copy(ctx, "prod", "app", "dev/app:1.2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if digest != "sha256:abc" {
+		t.Fatalf("unexpected digest %q", digest)
+	}
+	if err := svc.CreateTag(ctx, "prod", "app", digest, "stable"); err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.DeleteTag(ctx, "prod", "app", digest, "1.2"); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		"POST /api/v2.0/projects/prod/repositories/app/artifacts?from=dev%2Fapp%3A1.2",
+		"POST /api/v2.0/projects/prod/repositories/app/artifacts/sha256:abc/tags?",
+		"DELETE /api/v2.0/projects/prod/repositories/app/artifacts/sha256:abc/tags/1.2?",
+	}
+	for i, w := range want {
+		if i >= len(requests) || requests[i] != w {
+			t.Fatalf("unexpected requests %v", requests)
+		}
+	}
+	if tagBody != `{"name":"stable"}` {
+		t.Fatalf("unexpected tag body %s", tagBody)
+	}
+}
+
+func TestArtifactServiceVulnerabilitiesUnwrapsMimeType(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"application/vnd.security.vulnerability.report; version=1.1":{"severity":"High","vulnerabilities":[{"id":"CVE-1","package":"openssl","severity":"High"}]}}`))
+	}))
+	defer server.Close()
+
+	client := &api.Client{BaseURL: server.URL, APIVersion: "v2.0", HTTPClient: server.Client()}
+	report, err := NewArtifactService(client).Vulnerabilities(context.Background(), "library", "app", "1.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Severity != "High" || len(report.Vulnerabilities) != 1 || report.Vulnerabilities[0].CVEID != "CVE-1" {
+		t.Fatalf("unexpected report %+v", report)
+	}
+}
+
+func TestArtifactServiceVulnerabilitiesReportShapes(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		severity string
+		wantErr  bool
+	}{
+		{"not scanned", `{}`, "", false},
+		{"unwrapped", `{"severity":"Low","vulnerabilities":[]}`, "Low", false},
+		{"legacy mime type", `{"application/vnd.scanner.adapter.vuln.report.harbor+json; version=1.0":{"severity":"Critical","vulnerabilities":[{"id":"CVE-2"}]}}`, "Critical", false},
+		{"prefers current mime type", `{"application/vnd.scanner.adapter.vuln.report.harbor+json; version=1.0":{"severity":"Low"},"application/vnd.security.vulnerability.report; version=1.1":{"severity":"High"}}`, "High", false},
+		{"unknown shape", `{"application/vnd.example.report":{"severity":"High"}}`, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			client := &api.Client{BaseURL: server.URL, APIVersion: "v2.0", HTTPClient: server.Client()}
+			report, err := NewArtifactService(client).Vulnerabilities(context.Background(), "library", "app", "1.0")
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %+v", report)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.body == `{}` {
+				if report != nil {
+					t.Fatalf("expected no report, got %+v", report)
+				}
+				return
+			}
+			if report.Severity != tt.severity {
+				t.Fatalf("expected severity %q, got %q", tt.severity, report.Severity)
+			}
+		})
+	}
+}
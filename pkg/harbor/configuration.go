@@ -1,6 +1,7 @@
 package harbor
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 
@@ -18,24 +19,35 @@ func NewConfigService(client *api.Client) *ConfigService {
 	return &ConfigService{client: client}
 }
 
-// Get retrieves the Harbor system configuration as a key/value map
-func (s *ConfigService) Get() (map[string]interface{}, error) {
-	resp, err := s.client.Get("/configurations", nil)
+// configItem is a setting as returned by /configurations
+type configItem struct {
+	Value    interface{} `json:"value"`
+	Editable bool        `json:"editable"`
+}
+
+func (s *ConfigService) get(ctx context.Context) (map[string]configItem, error) {
+	resp, err := s.client.Get(ctx, "/configurations", nil)
 	if err != nil {
 		return nil, err
 	}
 
 	// Harbor API returns configuration in the form
 	// {"setting": {"value": <any>, "editable": <bool>}}
-	// Convert it to a simple key/value map for easier consumption.
-	raw := make(map[string]struct {
-		Value    interface{} `json:"value"`
-		Editable bool        `json:"editable"`
-	})
+	raw := make(map[string]configItem)
 	if err := s.client.DecodeResponse(resp, &raw); err != nil {
 		return nil, fmt.Errorf("failed to decode configuration: %w", err)
 	}
+	return raw, nil
+}
 
+// Get retrieves the Harbor system configuration as a key/value map
+func (s *ConfigService) Get(ctx context.Context) (map[string]interface{}, error) {
+	raw, err := s.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Convert it to a simple key/value map for easier consumption.
 	cfg := make(map[string]interface{}, len(raw))
 	for k, v := range raw {
 		cfg[k] = v.Value
@@ -43,10 +55,27 @@ func (s *ConfigService) Get() (map[string]interface{}, error) {
 	return cfg, nil
 }
 
+// GetEditable retrieves only the settings that can be changed through
+// Update
+func (s *ConfigService) GetEditable(ctx context.Context) (map[string]interface{}, error) {
+	raw, err := s.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := make(map[string]interface{}, len(raw))
+	for k, v := range raw {
+		if v.Editable {
+			cfg[k] = v.Value
+		}
+	}
+	return cfg, nil
+}
+
 // Update updates Harbor system configuration.
 // The cfg map may contain a subset of settings to modify.
-func (s *ConfigService) Update(cfg map[string]interface{}) error {
-	resp, err := s.client.Put("/configurations", cfg)
+func (s *ConfigService) Update(ctx context.Context, cfg map[string]interface{}) error {
+	resp, err := s.client.Put(ctx, "/configurations", cfg)
 	if err != nil {
 		return err
 	}
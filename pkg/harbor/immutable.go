@@ -0,0 +1,192 @@
+package harbor
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/bmatcuk/doublestar/v4"
+
+	"github.com/pascal71/hrbcli/pkg/api"
+)
+
+// ImmutableRuleService handles project immutable tag rules
+type ImmutableRuleService struct {
+	client *api.Client
+}
+
+// NewImmutableRuleService creates a new ImmutableRuleService
+func NewImmutableRuleService(client *api.Client) *ImmutableRuleService {
+	return &ImmutableRuleService{client: client}
+}
+
+func immutableRulesPath(project string) string {
+	return fmt.Sprintf("/projects/%s/immutabletagrules", url.PathEscape(project))
+}
+
+// NewImmutableRule builds an immutable rule from doublestar repository
+// and tag patterns. With excludeRepos or excludeTags set the rule applies
+// to everything not matching the pattern.
+func NewImmutableRule(repos string, excludeRepos bool, tags string, excludeTags bool) (*api.ImmutableRule, error) {
+	if repos == "" {
+		repos = "**"
+	}
+	if tags == "" {
+		tags = "**"
+	}
+	if !doublestar.ValidatePattern(repos) {
+		return nil, fmt.Errorf("invalid repository pattern: %s", repos)
+	}
+	if !doublestar.ValidatePattern(tags) {
+		return nil, fmt.Errorf("invalid tag pattern: %s", tags)
+	}
+
+	repoDecoration := "repoMatches"
+	if excludeRepos {
+		repoDecoration = "repoExcludes"
+	}
+	tagDecoration := "matches"
+	if excludeTags {
+		tagDecoration = "excludes"
+	}
+
+	return &api.ImmutableRule{
+		Action:   api.ImmutableRuleAction,
+		Template: api.ImmutableRuleTemplate,
+		TagSelectors: []*api.ImmutableSelector{{
+			Kind:       "doublestar",
+			Decoration: tagDecoration,
+			Pattern:    tags,
+		}},
+		ScopeSelectors: map[string][]*api.ImmutableSelector{
+			"repository": {{
+				Kind:       "doublestar",
+				Decoration: repoDecoration,
+				Pattern:    repos,
+			}},
+		},
+	}, nil
+}
+
+// List lists immutable tag rules of a project
+func (s *ImmutableRuleService) List(ctx context.Context, project string, opts *api.ListOptions) ([]*api.ImmutableRule, error) {
+	params := listParams(opts)
+
+	resp, err := s.client.Get(ctx, immutableRulesPath(project), params)
+	if err != nil {
+		return nil, err
+	}
+
+	var rules []*api.ImmutableRule
+	if err := s.client.DecodeResponse(resp, &rules); err != nil {
+		return nil, fmt.Errorf("failed to decode immutable rules: %w", err)
+	}
+	return rules, nil
+}
+
+// ListAll lists immutable tag rules of a project across all pages
+func (s *ImmutableRuleService) ListAll(ctx context.Context, project string, opts *api.ListOptions, limit int) ([]*api.ImmutableRule, error) {
+	return api.ListAll[*api.ImmutableRule](ctx, s.client, immutableRulesPath(project), listParams(opts), limit)
+}
+
+// Get retrieves an immutable rule by ID. Harbor has no endpoint for a
+// single rule, so the project's rules are listed and searched.
+func (s *ImmutableRuleService) Get(ctx context.Context, project string, id int64) (*api.ImmutableRule, error) {
+	rules, err := s.ListAll(ctx, project, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range rules {
+		if r.ID == id {
+			return r, nil
+		}
+	}
+	return nil, fmt.Errorf("immutable rule %d not found in project '%s'", id, project)
+}
+
+// Create creates an immutable rule and returns its ID
+func (s *ImmutableRuleService) Create(ctx context.Context, project string, rule *api.ImmutableRule) (int64, error) {
+	resp, err := s.client.Post(ctx, immutableRulesPath(project), rule)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		return 0, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	return parseLocationID(resp.Header.Get("Location"))
+}
+
+// Update replaces an immutable rule
+func (s *ImmutableRuleService) Update(ctx context.Context, project string, id int64, rule *api.ImmutableRule) error {
+	resp, err := s.client.Put(ctx, fmt.Sprintf("%s/%d", immutableRulesPath(project), id), rule)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Enable enables an immutable rule
+func (s *ImmutableRuleService) Enable(ctx context.Context, project string, id int64) error {
+	return s.setDisabled(ctx, project, id, false)
+}
+
+// Disable disables an immutable rule without deleting it
+func (s *ImmutableRuleService) Disable(ctx context.Context, project string, id int64) error {
+	return s.setDisabled(ctx, project, id, true)
+}
+
+func (s *ImmutableRuleService) setDisabled(ctx context.Context, project string, id int64, disabled bool) error {
+	rule, err := s.Get(ctx, project, id)
+	if err != nil {
+		return err
+	}
+	rule.Disabled = disabled
+	return s.Update(ctx, project, id, rule)
+}
+
+// Delete deletes an immutable rule
+func (s *ImmutableRuleService) Delete(ctx context.Context, project string, id int64) error {
+	resp, err := s.client.Delete(ctx, fmt.Sprintf("%s/%d", immutableRulesPath(project), id))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// DescribeImmutableSelectors renders the repository and tag selectors of
+// a rule, e.g. "matches **" and "excludes dev-*"
+func DescribeImmutableSelectors(rule *api.ImmutableRule) (string, string) {
+	describe := func(sel *api.ImmutableSelector) string {
+		decoration := sel.Decoration
+		switch decoration {
+		case "repoMatches":
+			decoration = "matches"
+		case "repoExcludes":
+			decoration = "excludes"
+		}
+		return decoration + " " + sel.Pattern
+	}
+
+	repos, tags := "", ""
+	if sel := rule.ScopeSelectors["repository"]; len(sel) > 0 {
+		repos = describe(sel[0])
+	}
+	if len(rule.TagSelectors) > 0 {
+		tags = describe(rule.TagSelectors[0])
+	}
+	return repos, tags
+}
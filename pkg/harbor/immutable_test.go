@@ -0,0 +1,66 @@
+package harbor
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/pascal71/hrbcli/pkg/api"
+)
+
+func TestImmutableRuleServiceDisable(t *testing.T) {
+	var put api.ImmutableRule
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v2.0/projects/demo/immutabletagrules":
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`[{"id":3,"priority":0,"disabled":false,"action":"immutable","template":"immutable_template",
+				"tag_selectors":[{"kind":"doublestar","decoration":"matches","pattern":"release-*"}],
+				"scope_selectors":{"repository":[{"kind":"doublestar","decoration":"repoMatches","pattern":"**"}]}}]`))
+		case r.Method == http.MethodPut && r.URL.Path == "/api/v2.0/projects/demo/immutabletagrules/3":
+			json.NewDecoder(r.Body).Decode(&put)
+			w.WriteHeader(http.StatusOK)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := &api.Client{
+		BaseURL:    server.URL,
+		APIVersion: "v2.0",
+		HTTPClient: server.Client(),
+	}
+
+	svc := NewImmutableRuleService(client)
+	if err := svc.Disable(context.Background(), "demo", 3); err != nil {
+		t.Fatalf("disable: %v", err)
+	}
+	if !put.Disabled || put.ID != 3 || len(put.TagSelectors) != 1 || put.TagSelectors[0].Pattern != "release-*" {
+		t.Fatalf("unexpected rule sent: %+v", put)
+	}
+
+	if err := svc.Enable(context.Background(), "demo", 4); err == nil {
+		t.Fatalf("expected error for unknown rule")
+	}
+}
+
+func TestNewImmutableRule(t *testing.T) {
+	rule, err := NewImmutableRule("team-a/**", false, "dev-*", true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rule.Action != api.ImmutableRuleAction || rule.Template != api.ImmutableRuleTemplate {
+		t.Fatalf("unexpected action/template: %s/%s", rule.Action, rule.Template)
+	}
+	repos, tags := DescribeImmutableSelectors(rule)
+	if repos != "matches team-a/**" || tags != "excludes dev-*" {
+		t.Fatalf("unexpected selectors: %q %q", repos, tags)
+	}
+
+	if _, err := NewImmutableRule("[", false, "**", false); err == nil {
+		t.Fatalf("expected error for invalid pattern")
+	}
+}
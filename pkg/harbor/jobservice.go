@@ -1,6 +1,7 @@
 package harbor
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/pascal71/hrbcli/pkg/api"
@@ -18,8 +19,8 @@ func NewJobService(client *api.Client) *JobService {
 }
 
 // GetWorkerPools retrieves worker pools
-func (s *JobService) GetWorkerPools() ([]*api.WorkerPool, error) {
-	resp, err := s.client.Get("/jobservice/pools", nil)
+func (s *JobService) GetWorkerPools(ctx context.Context) ([]*api.WorkerPool, error) {
+	resp, err := s.client.Get(ctx, "/jobservice/pools", nil)
 	if err != nil {
 		return nil, err
 	}
@@ -31,8 +32,8 @@ func (s *JobService) GetWorkerPools() ([]*api.WorkerPool, error) {
 }
 
 // GetWorkers retrieves workers in a pool. Use poolID="all" to get all workers.
-func (s *JobService) GetWorkers(poolID string) ([]*api.Worker, error) {
-	resp, err := s.client.Get(fmt.Sprintf("/jobservice/pools/%s/workers", poolID), nil)
+func (s *JobService) GetWorkers(ctx context.Context, poolID string) ([]*api.Worker, error) {
+	resp, err := s.client.Get(ctx, fmt.Sprintf("/jobservice/pools/%s/workers", poolID), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -44,8 +45,8 @@ func (s *JobService) GetWorkers(poolID string) ([]*api.Worker, error) {
 }
 
 // ListJobQueues lists job queues
-func (s *JobService) ListJobQueues() ([]*api.JobQueue, error) {
-	resp, err := s.client.Get("/jobservice/queues", nil)
+func (s *JobService) ListJobQueues(ctx context.Context) ([]*api.JobQueue, error) {
+	resp, err := s.client.Get(ctx, "/jobservice/queues", nil)
 	if err != nil {
 		return nil, err
 	}
@@ -1,6 +1,7 @@
 package harbor
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"net/url"
@@ -20,7 +21,28 @@ func NewLabelService(client *api.Client) *LabelService {
 }
 
 // List lists labels with optional filters
-func (s *LabelService) List(opts *api.LabelListOptions) ([]*api.Label, error) {
+func (s *LabelService) List(ctx context.Context, opts *api.LabelListOptions) ([]*api.Label, error) {
+	params := labelListParams(opts)
+
+	resp, err := s.client.Get(ctx, "/labels", params)
+	if err != nil {
+		return nil, err
+	}
+
+	var labels []*api.Label
+	if err := s.client.DecodeResponse(resp, &labels); err != nil {
+		return nil, fmt.Errorf("failed to decode labels: %w", err)
+	}
+
+	return labels, nil
+}
+
+// ListAll lists labels across all pages
+func (s *LabelService) ListAll(ctx context.Context, opts *api.LabelListOptions, limit int) ([]*api.Label, error) {
+	return api.ListAll[*api.Label](ctx, s.client, "/labels", labelListParams(opts), limit)
+}
+
+func labelListParams(opts *api.LabelListOptions) map[string]string {
 	params := make(map[string]string)
 	if opts != nil {
 		if opts.Page > 0 {
@@ -39,23 +61,12 @@ func (s *LabelService) List(opts *api.LabelListOptions) ([]*api.Label, error) {
 			params["project_id"] = strconv.FormatInt(opts.ProjectID, 10)
 		}
 	}
-
-	resp, err := s.client.Get("/labels", params)
-	if err != nil {
-		return nil, err
-	}
-
-	var labels []*api.Label
-	if err := s.client.DecodeResponse(resp, &labels); err != nil {
-		return nil, fmt.Errorf("failed to decode labels: %w", err)
-	}
-
-	return labels, nil
+	return params
 }
 
 // Get retrieves a label by ID
-func (s *LabelService) Get(id int64) (*api.Label, error) {
-	resp, err := s.client.Get(fmt.Sprintf("/labels/%d", id), nil)
+func (s *LabelService) Get(ctx context.Context, id int64) (*api.Label, error) {
+	resp, err := s.client.Get(ctx, fmt.Sprintf("/labels/%d", id), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -67,9 +78,31 @@ func (s *LabelService) Get(id int64) (*api.Label, error) {
 	return &label, nil
 }
 
+// FindByName returns the label with the given name, preferring a label of
+// the project over a global one. projectID 0 searches global labels only.
+func (s *LabelService) FindByName(ctx context.Context, name string, projectID int64) (*api.Label, error) {
+	scopes := []*api.LabelListOptions{{Name: name, Scope: "g"}}
+	if projectID > 0 {
+		scopes = append([]*api.LabelListOptions{{Name: name, Scope: "p", ProjectID: projectID}}, scopes...)
+	}
+	for _, opts := range scopes {
+		labels, err := s.ListAll(ctx, opts, 0)
+		if err != nil {
+			return nil, err
+		}
+		// The name filter also matches partially
+		for _, l := range labels {
+			if l.Name == name {
+				return l, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("label %q not found", name)
+}
+
 // Create creates a new label
-func (s *LabelService) Create(label *api.Label) (*api.Label, error) {
-	resp, err := s.client.Post("/labels", label)
+func (s *LabelService) Create(ctx context.Context, label *api.Label) (*api.Label, error) {
+	resp, err := s.client.Post(ctx, "/labels", label)
 	if err != nil {
 		return nil, err
 	}
@@ -89,12 +122,12 @@ func (s *LabelService) Create(label *api.Label) (*api.Label, error) {
 		return nil, fmt.Errorf("failed to parse label ID from location: %s", location)
 	}
 
-	return s.Get(id)
+	return s.Get(ctx, id)
 }
 
 // Update updates an existing label
-func (s *LabelService) Update(id int64, label *api.Label) error {
-	resp, err := s.client.Put(fmt.Sprintf("/labels/%d", id), label)
+func (s *LabelService) Update(ctx context.Context, id int64, label *api.Label) error {
+	resp, err := s.client.Put(ctx, fmt.Sprintf("/labels/%d", id), label)
 	if err != nil {
 		return err
 	}
@@ -108,8 +141,8 @@ func (s *LabelService) Update(id int64, label *api.Label) error {
 }
 
 // Delete deletes a label by ID
-func (s *LabelService) Delete(id int64) error {
-	resp, err := s.client.Delete(fmt.Sprintf("/labels/%d", id))
+func (s *LabelService) Delete(ctx context.Context, id int64) error {
+	resp, err := s.client.Delete(ctx, fmt.Sprintf("/labels/%d", id))
 	if err != nil {
 		return err
 	}
@@ -122,14 +155,14 @@ func (s *LabelService) Delete(id int64) error {
 }
 
 // AddToArtifact attaches a label to an artifact by reference
-func (s *LabelService) AddToArtifact(project, repository, reference string, labelID int64) error {
+func (s *LabelService) AddToArtifact(ctx context.Context, project, repository, reference string, labelID int64) error {
 	projectEsc := url.PathEscape(project)
 	repoEsc := url.PathEscape(repository)
 	refEsc := url.PathEscape(reference)
 	path := fmt.Sprintf("/projects/%s/repositories/%s/artifacts/%s/labels", projectEsc, repoEsc, refEsc)
 
 	body := &api.Label{ID: labelID}
-	resp, err := s.client.Post(path, body)
+	resp, err := s.client.Post(ctx, path, body)
 	if err != nil {
 		return err
 	}
@@ -142,13 +175,13 @@ func (s *LabelService) AddToArtifact(project, repository, reference string, labe
 }
 
 // RemoveFromArtifact removes a label from an artifact
-func (s *LabelService) RemoveFromArtifact(project, repository, reference string, labelID int64) error {
+func (s *LabelService) RemoveFromArtifact(ctx context.Context, project, repository, reference string, labelID int64) error {
 	projectEsc := url.PathEscape(project)
 	repoEsc := url.PathEscape(repository)
 	refEsc := url.PathEscape(reference)
 	path := fmt.Sprintf("/projects/%s/repositories/%s/artifacts/%s/labels/%d", projectEsc, repoEsc, refEsc, labelID)
 
-	resp, err := s.client.Delete(path)
+	resp, err := s.client.Delete(ctx, path)
 	if err != nil {
 		return err
 	}
@@ -1,6 +1,7 @@
 package harbor
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
@@ -28,7 +29,7 @@ func TestLabelServiceAddToArtifactEscapesPath(t *testing.T) {
 	project := "my project"
 	repo := "my/repo"
 	ref := "sha256:abc"
-	if err := svc.AddToArtifact(project, repo, ref, 1); err != nil {
+	if err := svc.AddToArtifact(context.Background(), project, repo, ref, 1); err != nil {
 		t.Fatalf("AddToArtifact error: %v", err)
 	}
 
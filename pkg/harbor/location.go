@@ -0,0 +1,42 @@
+package harbor
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// parseLocationID extracts the trailing numeric ID from a Location header
+// such as "/api/v2.0/projects/foo/members/12" or an absolute URL.
+func parseLocationID(location string) (int64, error) {
+	last, err := parseLocationName(location)
+	if err != nil {
+		return 0, err
+	}
+	id, err := strconv.ParseInt(last, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid ID in location: %s", location)
+	}
+	return id, nil
+}
+
+// parseLocationName extracts the last path segment from a Location header,
+// e.g. the UUID in "/api/v2.0/scanners/<uuid>".
+func parseLocationName(location string) (string, error) {
+	if location == "" {
+		return "", fmt.Errorf("no location header in response")
+	}
+
+	u, err := url.Parse(location)
+	if err != nil {
+		return "", fmt.Errorf("invalid location header: %s", location)
+	}
+
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	last, err := url.PathUnescape(parts[len(parts)-1])
+	if err != nil || last == "" {
+		return "", fmt.Errorf("invalid location header: %s", location)
+	}
+	return last, nil
+}
@@ -0,0 +1,181 @@
+package harbor
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/pascal71/hrbcli/pkg/api"
+)
+
+// memberRoles maps role names accepted by the CLI to Harbor role IDs
+var memberRoles = map[string]int64{
+	"projectadmin": api.RoleProjectAdmin,
+	"maintainer":   api.RoleMaintainer,
+	"developer":    api.RoleDeveloper,
+	"guest":        api.RoleGuest,
+	"limitedguest": api.RoleLimitedGuest,
+}
+
+// MemberRoleNames lists the role names accepted by ParseMemberRole
+var MemberRoleNames = []string{"projectAdmin", "maintainer", "developer", "guest", "limitedGuest"}
+
+// ParseMemberRole converts a role name such as "developer" or
+// "projectAdmin" to its Harbor role ID. Names are case-insensitive and
+// may contain dashes or underscores (e.g. "limited-guest").
+func ParseMemberRole(name string) (int64, error) {
+	key := strings.ToLower(strings.NewReplacer("-", "", "_", "").Replace(name))
+	if id, ok := memberRoles[key]; ok {
+		return id, nil
+	}
+	return 0, fmt.Errorf("invalid role: %s (valid: %s)", name, strings.Join(MemberRoleNames, ", "))
+}
+
+// MemberRoleName returns the CLI role name for a Harbor role ID
+func MemberRoleName(id int64) string {
+	switch id {
+	case api.RoleProjectAdmin:
+		return "projectAdmin"
+	case api.RoleMaintainer:
+		return "maintainer"
+	case api.RoleDeveloper:
+		return "developer"
+	case api.RoleGuest:
+		return "guest"
+	case api.RoleLimitedGuest:
+		return "limitedGuest"
+	default:
+		return strconv.FormatInt(id, 10)
+	}
+}
+
+// MemberService handles project membership operations
+type MemberService struct {
+	client *api.Client
+}
+
+// NewMemberService creates a new member service
+func NewMemberService(client *api.Client) *MemberService {
+	return &MemberService{client: client}
+}
+
+func membersPath(project string) string {
+	return fmt.Sprintf("/projects/%s/members", url.PathEscape(project))
+}
+
+// List lists members of a project
+func (s *MemberService) List(ctx context.Context, project string, opts *api.MemberListOptions) ([]*api.ProjectMemberEntity, error) {
+	resp, err := s.client.Get(ctx, membersPath(project), memberListParams(opts))
+	if err != nil {
+		return nil, err
+	}
+
+	var members []*api.ProjectMemberEntity
+	if err := s.client.DecodeResponse(resp, &members); err != nil {
+		return nil, fmt.Errorf("failed to decode members: %w", err)
+	}
+	return members, nil
+}
+
+// ListAll lists members of a project across all pages
+func (s *MemberService) ListAll(ctx context.Context, project string, opts *api.MemberListOptions, limit int) ([]*api.ProjectMemberEntity, error) {
+	return api.ListAll[*api.ProjectMemberEntity](ctx, s.client, membersPath(project), memberListParams(opts), limit)
+}
+
+func memberListParams(opts *api.MemberListOptions) map[string]string {
+	params := make(map[string]string)
+	if opts != nil {
+		if opts.Page > 0 {
+			params["page"] = strconv.Itoa(opts.Page)
+		}
+		if opts.PageSize > 0 {
+			params["page_size"] = strconv.Itoa(opts.PageSize)
+		}
+		if opts.EntityName != "" {
+			params["entityname"] = opts.EntityName
+		}
+	}
+	return params
+}
+
+// Get retrieves a project member by ID
+func (s *MemberService) Get(ctx context.Context, project string, id int64) (*api.ProjectMemberEntity, error) {
+	path := fmt.Sprintf("%s/%d", membersPath(project), id)
+	resp, err := s.client.Get(ctx, path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var member api.ProjectMemberEntity
+	if err := s.client.DecodeResponse(resp, &member); err != nil {
+		return nil, fmt.Errorf("failed to decode member: %w", err)
+	}
+	return &member, nil
+}
+
+// FindByName returns the project member with the given user or group name
+func (s *MemberService) FindByName(ctx context.Context, project, name string) (*api.ProjectMemberEntity, error) {
+	members, err := s.ListAll(ctx, project, &api.MemberListOptions{EntityName: name}, 0)
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range members {
+		if m.EntityName == name {
+			return m, nil
+		}
+	}
+	return nil, fmt.Errorf("member '%s' not found in project '%s'", name, project)
+}
+
+// Add adds a user or group to a project
+func (s *MemberService) Add(ctx context.Context, project string, req *api.ProjectMember) (*api.ProjectMemberEntity, error) {
+	path := membersPath(project)
+	resp, err := s.client.Post(ctx, path, req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	id, err := parseLocationID(resp.Header.Get("Location"))
+	if err != nil {
+		return nil, err
+	}
+	return s.Get(ctx, project, id)
+}
+
+// UpdateRole changes the role of a project member
+func (s *MemberService) UpdateRole(ctx context.Context, project string, id, roleID int64) error {
+	path := fmt.Sprintf("%s/%d", membersPath(project), id)
+	resp, err := s.client.Put(ctx, path, &api.RoleRequest{RoleID: roleID})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Remove removes a member from a project
+func (s *MemberService) Remove(ctx context.Context, project string, id int64) error {
+	path := fmt.Sprintf("%s/%d", membersPath(project), id)
+	resp, err := s.client.Delete(ctx, path)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+	return nil
+}
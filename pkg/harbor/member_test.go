@@ -0,0 +1,50 @@
+package harbor
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/pascal71/hrbcli/pkg/api"
+)
+
+func TestMemberServiceAddParsesLocation(t *testing.T) {
+	var got api.ProjectMember
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v2.0/projects/demo/members":
+			json.NewDecoder(r.Body).Decode(&got)
+			w.Header().Set("Location", "/api/v2.0/projects/demo/members/9")
+			w.WriteHeader(http.StatusCreated)
+		case "/api/v2.0/projects/demo/members/9":
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"id":9,"entity_name":"john","role_id":2,"entity_type":"u"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := &api.Client{
+		BaseURL:    server.URL,
+		APIVersion: "v2.0",
+		HTTPClient: server.Client(),
+	}
+
+	svc := NewMemberService(client)
+	member, err := svc.Add(context.Background(), "demo", &api.ProjectMember{
+		RoleID:     api.RoleDeveloper,
+		MemberUser: &api.UserEntity{Username: "john"},
+	})
+	if err != nil {
+		t.Fatalf("Add error: %v", err)
+	}
+	if member.ID != 9 || member.EntityName != "john" {
+		t.Fatalf("unexpected member: %+v", member)
+	}
+	if got.RoleID != api.RoleDeveloper || got.MemberUser == nil || got.MemberUser.Username != "john" {
+		t.Fatalf("unexpected request: %+v", got)
+	}
+}
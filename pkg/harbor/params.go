@@ -0,0 +1,49 @@
+package harbor
+
+import (
+	"strconv"
+
+	"github.com/pascal71/hrbcli/pkg/api"
+)
+
+// listParams converts list options to the query parameters understood by
+// most Harbor list endpoints
+func listParams(opts *api.ListOptions) map[string]string {
+	params := make(map[string]string)
+	if opts != nil {
+		if opts.Page > 0 {
+			params["page"] = strconv.Itoa(opts.Page)
+		}
+		if opts.PageSize > 0 {
+			params["page_size"] = strconv.Itoa(opts.PageSize)
+		}
+		if opts.Query != "" {
+			params["q"] = opts.Query
+		}
+		if opts.Sort != "" {
+			params["sort"] = opts.Sort
+		}
+	}
+	return params
+}
+
+// policyListParams builds list parameters for endpoints that take the
+// filter as "query" rather than "q"
+func policyListParams(opts *api.ListOptions) map[string]string {
+	params := make(map[string]string)
+	if opts != nil {
+		if opts.Query != "" {
+			params["query"] = opts.Query
+		}
+		if opts.Sort != "" {
+			params["sort"] = opts.Sort
+		}
+		if opts.Page > 0 {
+			params["page"] = strconv.Itoa(opts.Page)
+		}
+		if opts.PageSize > 0 {
+			params["page_size"] = strconv.Itoa(opts.PageSize)
+		}
+	}
+	return params
+}
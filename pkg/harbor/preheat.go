@@ -1,7 +1,9 @@
 package harbor
 
 import (
+	"context"
 	"fmt"
+	"net/http"
 
 	"github.com/pascal71/hrbcli/pkg/api"
 )
@@ -18,9 +20,9 @@ func NewPreheatService(client *api.Client) *PreheatService {
 }
 
 // ListProviders lists distribution providers under a project
-func (s *PreheatService) ListProviders(project string) ([]*api.PreheatProvider, error) {
+func (s *PreheatService) ListProviders(ctx context.Context, project string) ([]*api.PreheatProvider, error) {
 	path := fmt.Sprintf("/projects/%s/preheat/providers", project)
-	resp, err := s.client.Get(path, nil)
+	resp, err := s.client.Get(ctx, path, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -32,24 +34,10 @@ func (s *PreheatService) ListProviders(project string) ([]*api.PreheatProvider,
 }
 
 // ListPolicies lists preheat policies under a project
-func (s *PreheatService) ListPolicies(project string, opts *api.ListOptions) ([]*api.PreheatPolicy, error) {
-	params := make(map[string]string)
-	if opts != nil {
-		if opts.Query != "" {
-			params["query"] = opts.Query
-		}
-		if opts.Sort != "" {
-			params["sort"] = opts.Sort
-		}
-		if opts.Page > 0 {
-			params["page"] = fmt.Sprintf("%d", opts.Page)
-		}
-		if opts.PageSize > 0 {
-			params["page_size"] = fmt.Sprintf("%d", opts.PageSize)
-		}
-	}
+func (s *PreheatService) ListPolicies(ctx context.Context, project string, opts *api.ListOptions) ([]*api.PreheatPolicy, error) {
+	params := policyListParams(opts)
 	path := fmt.Sprintf("/projects/%s/preheat/policies", project)
-	resp, err := s.client.Get(path, params)
+	resp, err := s.client.Get(ctx, path, params)
 	if err != nil {
 		return nil, err
 	}
@@ -60,10 +48,16 @@ func (s *PreheatService) ListPolicies(project string, opts *api.ListOptions) ([]
 	return policies, nil
 }
 
+// ListAllPolicies lists preheat policies under a project across all pages
+func (s *PreheatService) ListAllPolicies(ctx context.Context, project string, opts *api.ListOptions, limit int) ([]*api.PreheatPolicy, error) {
+	path := fmt.Sprintf("/projects/%s/preheat/policies", project)
+	return api.ListAll[*api.PreheatPolicy](ctx, s.client, path, policyListParams(opts), limit)
+}
+
 // GetPolicy retrieves a preheat policy
-func (s *PreheatService) GetPolicy(project, name string) (*api.PreheatPolicy, error) {
+func (s *PreheatService) GetPolicy(ctx context.Context, project, name string) (*api.PreheatPolicy, error) {
 	path := fmt.Sprintf("/projects/%s/preheat/policies/%s", project, name)
-	resp, err := s.client.Get(path, nil)
+	resp, err := s.client.Get(ctx, path, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -73,3 +67,18 @@ func (s *PreheatService) GetPolicy(project, name string) (*api.PreheatPolicy, er
 	}
 	return &policy, nil
 }
+
+// CreatePolicy creates a preheat policy under a project
+func (s *PreheatService) CreatePolicy(ctx context.Context, project string, policy *api.PreheatPolicy) error {
+	path := fmt.Sprintf("/projects/%s/preheat/policies", project)
+	resp, err := s.client.Post(ctx, path, policy)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -1,6 +1,7 @@
 package harbor
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"strconv"
@@ -20,25 +21,10 @@ func NewProjectService(client *api.Client) *ProjectService {
 }
 
 // List lists projects
-func (s *ProjectService) List(opts *api.ListOptions) ([]*api.Project, error) {
-	params := make(map[string]string)
+func (s *ProjectService) List(ctx context.Context, opts *api.ListOptions) ([]*api.Project, error) {
+	params := listParams(opts)
 
-	if opts != nil {
-		if opts.Page > 0 {
-			params["page"] = strconv.Itoa(opts.Page)
-		}
-		if opts.PageSize > 0 {
-			params["page_size"] = strconv.Itoa(opts.PageSize)
-		}
-		if opts.Query != "" {
-			params["q"] = opts.Query
-		}
-		if opts.Sort != "" {
-			params["sort"] = opts.Sort
-		}
-	}
-
-	resp, err := s.client.Get("/projects", params)
+	resp, err := s.client.Get(ctx, "/projects", params)
 	if err != nil {
 		return nil, err
 	}
@@ -51,9 +37,15 @@ func (s *ProjectService) List(opts *api.ListOptions) ([]*api.Project, error) {
 	return projects, nil
 }
 
+// ListAll lists projects across all pages. A positive limit caps the
+// number of projects returned.
+func (s *ProjectService) ListAll(ctx context.Context, opts *api.ListOptions, limit int) ([]*api.Project, error) {
+	return api.ListAll[*api.Project](ctx, s.client, "/projects", listParams(opts), limit)
+}
+
 // Get gets a project by name or ID
-func (s *ProjectService) Get(nameOrID string) (*api.Project, error) {
-	resp, err := s.client.Get(fmt.Sprintf("/projects/%s", nameOrID), nil)
+func (s *ProjectService) Get(ctx context.Context, nameOrID string) (*api.Project, error) {
+	resp, err := s.client.Get(ctx, fmt.Sprintf("/projects/%s", nameOrID), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -67,8 +59,8 @@ func (s *ProjectService) Get(nameOrID string) (*api.Project, error) {
 }
 
 // Create creates a new project
-func (s *ProjectService) Create(req *api.ProjectReq) error {
-	resp, err := s.client.Post("/projects", req)
+func (s *ProjectService) Create(ctx context.Context, req *api.ProjectReq) error {
+	resp, err := s.client.Post(ctx, "/projects", req)
 	if err != nil {
 		return err
 	}
@@ -82,8 +74,8 @@ func (s *ProjectService) Create(req *api.ProjectReq) error {
 }
 
 // Update updates a project
-func (s *ProjectService) Update(nameOrID string, req *api.ProjectReq) error {
-	resp, err := s.client.Put(fmt.Sprintf("/projects/%s", nameOrID), req)
+func (s *ProjectService) Update(ctx context.Context, nameOrID string, req *api.ProjectReq) error {
+	resp, err := s.client.Put(ctx, fmt.Sprintf("/projects/%s", nameOrID), req)
 	if err != nil {
 		return err
 	}
@@ -97,8 +89,8 @@ func (s *ProjectService) Update(nameOrID string, req *api.ProjectReq) error {
 }
 
 // Delete deletes a project
-func (s *ProjectService) Delete(nameOrID string) error {
-	resp, err := s.client.Delete(fmt.Sprintf("/projects/%s", nameOrID))
+func (s *ProjectService) Delete(ctx context.Context, nameOrID string) error {
+	resp, err := s.client.Delete(ctx, fmt.Sprintf("/projects/%s", nameOrID))
 	if err != nil {
 		return err
 	}
@@ -112,8 +104,8 @@ func (s *ProjectService) Delete(nameOrID string) error {
 }
 
 // GetSummary gets project summary
-func (s *ProjectService) GetSummary(nameOrID string) (*api.ProjectSummary, error) {
-	resp, err := s.client.Get(fmt.Sprintf("/projects/%s/summary", nameOrID), nil)
+func (s *ProjectService) GetSummary(ctx context.Context, nameOrID string) (*api.ProjectSummary, error) {
+	resp, err := s.client.Get(ctx, fmt.Sprintf("/projects/%s/summary", nameOrID), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -127,8 +119,8 @@ func (s *ProjectService) GetSummary(nameOrID string) (*api.ProjectSummary, error
 }
 
 // Exists checks if a project exists
-func (s *ProjectService) Exists(projectName string) (bool, error) {
-	resp, err := s.client.Head(fmt.Sprintf("/projects?project_name=%s", projectName))
+func (s *ProjectService) Exists(ctx context.Context, projectName string) (bool, error) {
+	resp, err := s.client.Head(ctx, fmt.Sprintf("/projects?project_name=%s", projectName))
 	if err != nil {
 		if apiErr, ok := err.(*api.APIError); ok && apiErr.IsNotFound() {
 			return false, nil
@@ -141,11 +133,11 @@ func (s *ProjectService) Exists(projectName string) (bool, error) {
 }
 
 // SearchByName searches projects by name
-func (s *ProjectService) SearchByName(name string) ([]*api.Project, error) {
+func (s *ProjectService) SearchByName(ctx context.Context, name string) ([]*api.Project, error) {
 	opts := &api.ListOptions{
 		Query: fmt.Sprintf("name=~%s", name),
 	}
-	return s.List(opts)
+	return s.List(ctx, opts)
 }
 
 // ParseStorageLimit parses storage limit string (e.g., "10G", "500M") to bytes
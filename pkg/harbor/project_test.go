@@ -34,3 +34,40 @@ func TestParseStorageLimit(t *testing.T) {
 		}
 	}
 }
+
+func TestParseMemberRole(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+		wantErr  bool
+	}{
+		{"projectAdmin", 1, false},
+		{"developer", 2, false},
+		{"guest", 3, false},
+		{"maintainer", 4, false},
+		{"limitedGuest", 5, false},
+		{"limited-guest", 5, false},
+		{"PROJECTADMIN", 1, false},
+		{"owner", 0, true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseMemberRole(tt.input)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("expected error for %q", tt.input)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("unexpected error for %q: %v", tt.input, err)
+			continue
+		}
+		if got != tt.expected {
+			t.Errorf("ParseMemberRole(%q) = %d, want %d", tt.input, got, tt.expected)
+		}
+		if MemberRoleName(got) == "" {
+			t.Errorf("MemberRoleName(%d) is empty", got)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package harbor
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 
@@ -18,22 +19,10 @@ func NewRegistryService(client *api.Client) *RegistryService {
 }
 
 // List lists all registry endpoints
-func (s *RegistryService) List(opts *api.ListOptions) ([]*api.Registry, error) {
-	params := make(map[string]string)
+func (s *RegistryService) List(ctx context.Context, opts *api.ListOptions) ([]*api.Registry, error) {
+	params := listParams(opts)
 
-	if opts != nil {
-		if opts.Query != "" {
-			params["q"] = opts.Query
-		}
-		if opts.Page > 0 {
-			params["page"] = fmt.Sprintf("%d", opts.Page)
-		}
-		if opts.PageSize > 0 {
-			params["page_size"] = fmt.Sprintf("%d", opts.PageSize)
-		}
-	}
-
-	resp, err := s.client.Get("/registries", params)
+	resp, err := s.client.Get(ctx, "/registries", params)
 	if err != nil {
 		return nil, err
 	}
@@ -46,9 +35,14 @@ func (s *RegistryService) List(opts *api.ListOptions) ([]*api.Registry, error) {
 	return registries, nil
 }
 
+// ListAll lists registry endpoints across all pages
+func (s *RegistryService) ListAll(ctx context.Context, opts *api.ListOptions, limit int) ([]*api.Registry, error) {
+	return api.ListAll[*api.Registry](ctx, s.client, "/registries", listParams(opts), limit)
+}
+
 // Get gets a registry by ID
-func (s *RegistryService) Get(id int64) (*api.Registry, error) {
-	resp, err := s.client.Get(fmt.Sprintf("/registries/%d", id), nil)
+func (s *RegistryService) Get(ctx context.Context, id int64) (*api.Registry, error) {
+	resp, err := s.client.Get(ctx, fmt.Sprintf("/registries/%d", id), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -62,8 +56,8 @@ func (s *RegistryService) Get(id int64) (*api.Registry, error) {
 }
 
 // Create creates a new registry endpoint
-func (s *RegistryService) Create(req *api.RegistryReq) (*api.Registry, error) {
-	resp, err := s.client.Post("/registries", req)
+func (s *RegistryService) Create(ctx context.Context, req *api.RegistryReq) (*api.Registry, error) {
+	resp, err := s.client.Post(ctx, "/registries", req)
 	if err != nil {
 		return nil, err
 	}
@@ -86,12 +80,12 @@ func (s *RegistryService) Create(req *api.RegistryReq) (*api.Registry, error) {
 	}
 
 	// Get the created registry
-	return s.Get(id)
+	return s.Get(ctx, id)
 }
 
 // Update updates a registry endpoint
-func (s *RegistryService) Update(id int64, req *api.RegistryReq) error {
-	resp, err := s.client.Put(fmt.Sprintf("/registries/%d", id), req)
+func (s *RegistryService) Update(ctx context.Context, id int64, req *api.RegistryReq) error {
+	resp, err := s.client.Put(ctx, fmt.Sprintf("/registries/%d", id), req)
 	if err != nil {
 		return err
 	}
@@ -105,8 +99,8 @@ func (s *RegistryService) Update(id int64, req *api.RegistryReq) error {
 }
 
 // Delete deletes a registry endpoint
-func (s *RegistryService) Delete(id int64) error {
-	resp, err := s.client.Delete(fmt.Sprintf("/registries/%d", id))
+func (s *RegistryService) Delete(ctx context.Context, id int64) error {
+	resp, err := s.client.Delete(ctx, fmt.Sprintf("/registries/%d", id))
 	if err != nil {
 		return err
 	}
@@ -120,8 +114,8 @@ func (s *RegistryService) Delete(id int64) error {
 }
 
 // Ping tests connectivity to a registry
-func (s *RegistryService) Ping(req *api.RegistryReq) error {
-	resp, err := s.client.Post("/registries/ping", req)
+func (s *RegistryService) Ping(ctx context.Context, req *api.RegistryReq) error {
+	resp, err := s.client.Post(ctx, "/registries/ping", req)
 	if err != nil {
 		return err
 	}
@@ -140,8 +134,8 @@ func (s *RegistryService) Ping(req *api.RegistryReq) error {
 
 // GetInfo returns adapter information for the specified registry type.
 // Harbor 2.6+ exposes adapter details via `/replication/adapterinfos`.
-func (s *RegistryService) GetInfo(registryType string) (*api.RegistryInfo, error) {
-	resp, err := s.client.Get("/replication/adapterinfos", nil)
+func (s *RegistryService) GetInfo(ctx context.Context, registryType string) (*api.RegistryInfo, error) {
+	resp, err := s.client.Get(ctx, "/replication/adapterinfos", nil)
 	if err != nil {
 		return nil, err
 	}
@@ -161,8 +155,8 @@ func (s *RegistryService) GetInfo(registryType string) (*api.RegistryInfo, error
 
 // ListAdapters lists available registry adapters
 
-func (s *RegistryService) ListAdapters() (map[string]*api.RegistryInfo, error) {
-	resp, err := s.client.Get("/replication/adapterinfos", nil)
+func (s *RegistryService) ListAdapters(ctx context.Context) (map[string]*api.RegistryInfo, error) {
+	resp, err := s.client.Get(ctx, "/replication/adapterinfos", nil)
 	if err != nil {
 		return nil, err
 	}
@@ -1,6 +1,7 @@
 package harbor
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"net/http"
@@ -20,9 +21,9 @@ func NewReplicationService(client *api.Client) *ReplicationService {
 
 // ResolvePolicyID resolves a replication policy ID from its name.
 // Returns an error if the policy cannot be found.
-func (s *ReplicationService) ResolvePolicyID(name string) (int64, error) {
+func (s *ReplicationService) ResolvePolicyID(ctx context.Context, name string) (int64, error) {
 	opts := &api.ListOptions{Query: fmt.Sprintf("name=~%s", name)}
-	policies, err := s.ListPolicies(opts)
+	policies, err := s.ListAllPolicies(ctx, opts, 0)
 	if err != nil {
 		return 0, err
 	}
@@ -35,24 +36,10 @@ func (s *ReplicationService) ResolvePolicyID(name string) (int64, error) {
 }
 
 // ListPolicies lists replication policies
-func (s *ReplicationService) ListPolicies(opts *api.ListOptions) ([]*api.ReplicationPolicy, error) {
-	params := make(map[string]string)
-	if opts != nil {
-		if opts.Query != "" {
-			params["query"] = opts.Query
-		}
-		if opts.Sort != "" {
-			params["sort"] = opts.Sort
-		}
-		if opts.Page > 0 {
-			params["page"] = fmt.Sprintf("%d", opts.Page)
-		}
-		if opts.PageSize > 0 {
-			params["page_size"] = fmt.Sprintf("%d", opts.PageSize)
-		}
-	}
+func (s *ReplicationService) ListPolicies(ctx context.Context, opts *api.ListOptions) ([]*api.ReplicationPolicy, error) {
+	params := policyListParams(opts)
 
-	resp, err := s.client.Get("/replication/policies", params)
+	resp, err := s.client.Get(ctx, "/replication/policies", params)
 	if err != nil {
 		return nil, err
 	}
@@ -65,9 +52,14 @@ func (s *ReplicationService) ListPolicies(opts *api.ListOptions) ([]*api.Replica
 	return policies, nil
 }
 
+// ListAllPolicies lists replication policies across all pages
+func (s *ReplicationService) ListAllPolicies(ctx context.Context, opts *api.ListOptions, limit int) ([]*api.ReplicationPolicy, error) {
+	return api.ListAll[*api.ReplicationPolicy](ctx, s.client, "/replication/policies", policyListParams(opts), limit)
+}
+
 // GetPolicy retrieves a policy by ID
-func (s *ReplicationService) GetPolicy(id int64) (*api.ReplicationPolicy, error) {
-	resp, err := s.client.Get(fmt.Sprintf("/replication/policies/%d", id), nil)
+func (s *ReplicationService) GetPolicy(ctx context.Context, id int64) (*api.ReplicationPolicy, error) {
+	resp, err := s.client.Get(ctx, fmt.Sprintf("/replication/policies/%d", id), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -80,8 +72,8 @@ func (s *ReplicationService) GetPolicy(id int64) (*api.ReplicationPolicy, error)
 }
 
 // CreatePolicy creates a new replication policy
-func (s *ReplicationService) CreatePolicy(req *api.ReplicationPolicy) (*api.ReplicationPolicy, error) {
-	resp, err := s.client.Post("/replication/policies", req)
+func (s *ReplicationService) CreatePolicy(ctx context.Context, req *api.ReplicationPolicy) (*api.ReplicationPolicy, error) {
+	resp, err := s.client.Post(ctx, "/replication/policies", req)
 	if err != nil {
 		return nil, err
 	}
@@ -101,12 +93,26 @@ func (s *ReplicationService) CreatePolicy(req *api.ReplicationPolicy) (*api.Repl
 		return nil, fmt.Errorf("failed to parse policy ID from location: %s", location)
 	}
 
-	return s.GetPolicy(id)
+	return s.GetPolicy(ctx, id)
+}
+
+// UpdatePolicy replaces a replication policy
+func (s *ReplicationService) UpdatePolicy(ctx context.Context, id int64, req *api.ReplicationPolicy) error {
+	resp, err := s.client.Put(ctx, fmt.Sprintf("/replication/policies/%d", id), req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+	return nil
 }
 
 // DeletePolicy deletes a replication policy
-func (s *ReplicationService) DeletePolicy(id int64) error {
-	resp, err := s.client.Delete(fmt.Sprintf("/replication/policies/%d", id))
+func (s *ReplicationService) DeletePolicy(ctx context.Context, id int64) error {
+	resp, err := s.client.Delete(ctx, fmt.Sprintf("/replication/policies/%d", id))
 	if err != nil {
 		return err
 	}
@@ -119,9 +125,9 @@ func (s *ReplicationService) DeletePolicy(id int64) error {
 }
 
 // StartExecution manually triggers a replication execution
-func (s *ReplicationService) StartExecution(policyID int64) (*api.ReplicationExecution, error) {
+func (s *ReplicationService) StartExecution(ctx context.Context, policyID int64) (*api.ReplicationExecution, error) {
 	req := &api.StartReplicationExecution{PolicyID: policyID}
-	resp, err := s.client.Post("/replication/executions", req)
+	resp, err := s.client.Post(ctx, "/replication/executions", req)
 	if err != nil {
 		return nil, err
 	}
@@ -141,17 +147,20 @@ func (s *ReplicationService) StartExecution(policyID int64) (*api.ReplicationExe
 		return nil, fmt.Errorf("failed to parse execution ID from location: %s", location)
 	}
 
-	return s.GetExecution(id)
+	return s.GetExecution(ctx, id)
 }
 
-// ListExecutions lists executions
-func (s *ReplicationService) ListExecutions(policyID int64) ([]*api.ReplicationExecution, error) {
-	params := make(map[string]string)
+func executionParams(policyID int64, opts *api.ListOptions) map[string]string {
+	params := listParams(opts)
 	if policyID > 0 {
 		params["policy_id"] = fmt.Sprintf("%d", policyID)
 	}
+	return params
+}
 
-	resp, err := s.client.Get("/replication/executions", params)
+// ListExecutions lists executions, optionally of a single policy
+func (s *ReplicationService) ListExecutions(ctx context.Context, policyID int64, opts *api.ListOptions) ([]*api.ReplicationExecution, error) {
+	resp, err := s.client.Get(ctx, "/replication/executions", executionParams(policyID, opts))
 	if err != nil {
 		return nil, err
 	}
@@ -163,9 +172,14 @@ func (s *ReplicationService) ListExecutions(policyID int64) ([]*api.ReplicationE
 	return execs, nil
 }
 
+// ListAllExecutions lists executions across all pages
+func (s *ReplicationService) ListAllExecutions(ctx context.Context, policyID int64, opts *api.ListOptions, limit int) ([]*api.ReplicationExecution, error) {
+	return api.ListAll[*api.ReplicationExecution](ctx, s.client, "/replication/executions", executionParams(policyID, opts), limit)
+}
+
 // GetExecution retrieves a replication execution
-func (s *ReplicationService) GetExecution(id int64) (*api.ReplicationExecution, error) {
-	resp, err := s.client.Get(fmt.Sprintf("/replication/executions/%d", id), nil)
+func (s *ReplicationService) GetExecution(ctx context.Context, id int64) (*api.ReplicationExecution, error) {
+	resp, err := s.client.Get(ctx, fmt.Sprintf("/replication/executions/%d", id), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -178,8 +192,8 @@ func (s *ReplicationService) GetExecution(id int64) (*api.ReplicationExecution,
 }
 
 // ListTasks lists tasks for an execution
-func (s *ReplicationService) ListTasks(executionID int64) ([]*api.ReplicationTask, error) {
-	resp, err := s.client.Get(fmt.Sprintf("/replication/executions/%d/tasks", executionID), nil)
+func (s *ReplicationService) ListTasks(ctx context.Context, executionID int64, opts *api.ListOptions) ([]*api.ReplicationTask, error) {
+	resp, err := s.client.Get(ctx, fmt.Sprintf("/replication/executions/%d/tasks", executionID), listParams(opts))
 	if err != nil {
 		return nil, err
 	}
@@ -191,9 +205,14 @@ func (s *ReplicationService) ListTasks(executionID int64) ([]*api.ReplicationTas
 	return tasks, nil
 }
 
+// ListAllTasks lists tasks for an execution across all pages
+func (s *ReplicationService) ListAllTasks(ctx context.Context, executionID int64, opts *api.ListOptions, limit int) ([]*api.ReplicationTask, error) {
+	return api.ListAll[*api.ReplicationTask](ctx, s.client, fmt.Sprintf("/replication/executions/%d/tasks", executionID), listParams(opts), limit)
+}
+
 // GetTaskLog retrieves the log of a task
-func (s *ReplicationService) GetTaskLog(executionID, taskID int64) (string, error) {
-	resp, err := s.client.Get(fmt.Sprintf("/replication/executions/%d/tasks/%d/log", executionID, taskID), nil)
+func (s *ReplicationService) GetTaskLog(ctx context.Context, executionID, taskID int64) (string, error) {
+	resp, err := s.client.Get(ctx, fmt.Sprintf("/replication/executions/%d/tasks/%d/log", executionID, taskID), nil)
 	if err != nil {
 		return "", err
 	}
@@ -0,0 +1,50 @@
+package harbor
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/pascal71/hrbcli/pkg/api"
+)
+
+func TestReplicationServiceListAllExecutionsKeepsPolicyFilter(t *testing.T) {
+	var queries []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v2.0/replication/executions" {
+			t.Errorf("unexpected request %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		queries = append(queries, r.URL.RawQuery)
+		w.Header().Set("X-Total-Count", "3")
+		switch r.URL.Query().Get("page") {
+		case "1":
+			w.Write([]byte(`[{"id":1,"policy_id":7},{"id":2,"policy_id":7}]`))
+		case "2":
+			w.Write([]byte(`[{"id":3,"policy_id":7}]`))
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+
+	client := &api.Client{BaseURL: server.URL, APIVersion: "v2.0", HTTPClient: server.Client()}
+	execs, err := NewReplicationService(client).ListAllExecutions(context.Background(), 7, &api.ListOptions{PageSize: 2}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(execs) != 3 || execs[2].ID != 3 {
+		t.Fatalf("unexpected executions %+v", execs)
+	}
+	want := []string{"page=1&page_size=2&policy_id=7", "page=2&page_size=2&policy_id=7"}
+	if len(queries) != len(want) {
+		t.Fatalf("unexpected queries %v", queries)
+	}
+	for i := range want {
+		if queries[i] != want[i] {
+			t.Errorf("query %d: expected %s, got %s", i, want[i], queries[i])
+		}
+	}
+}
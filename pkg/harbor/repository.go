@@ -1,10 +1,10 @@
 package harbor
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"net/url"
-	"strconv"
 
 	"github.com/pascal71/hrbcli/pkg/api"
 )
@@ -19,27 +19,13 @@ func NewRepositoryService(client *api.Client) *RepositoryService {
 	return &RepositoryService{client: client}
 }
 
-// List lists repositories within a project
-func (s *RepositoryService) List(projectName string, opts *api.ListOptions) ([]*api.Repository, error) {
-	params := make(map[string]string)
-	if opts != nil {
-		if opts.Query != "" {
-			params["q"] = opts.Query
-		}
-		if opts.Sort != "" {
-			params["sort"] = opts.Sort
-		}
-		if opts.Page > 0 {
-			params["page"] = strconv.Itoa(opts.Page)
-		}
-		if opts.PageSize > 0 {
-			params["page_size"] = strconv.Itoa(opts.PageSize)
-		}
-	}
+func repositoriesPath(projectName string) string {
+	return fmt.Sprintf("/projects/%s/repositories", url.PathEscape(projectName))
+}
 
-	projectEsc := url.PathEscape(projectName)
-	path := fmt.Sprintf("/projects/%s/repositories", projectEsc)
-	resp, err := s.client.Get(path, params)
+// List lists repositories within a project
+func (s *RepositoryService) List(ctx context.Context, projectName string, opts *api.ListOptions) ([]*api.Repository, error) {
+	resp, err := s.client.Get(ctx, repositoriesPath(projectName), listParams(opts))
 	if err != nil {
 		return nil, err
 	}
@@ -52,13 +38,18 @@ func (s *RepositoryService) List(projectName string, opts *api.ListOptions) ([]*
 	return repos, nil
 }
 
+// ListAll lists repositories within a project across all pages
+func (s *RepositoryService) ListAll(ctx context.Context, projectName string, opts *api.ListOptions, limit int) ([]*api.Repository, error) {
+	return api.ListAll[*api.Repository](ctx, s.client, repositoriesPath(projectName), listParams(opts), limit)
+}
+
 // Get retrieves a repository by name within a project
-func (s *RepositoryService) Get(projectName, repositoryName string) (*api.Repository, error) {
+func (s *RepositoryService) Get(ctx context.Context, projectName, repositoryName string) (*api.Repository, error) {
 	projectEsc := url.PathEscape(projectName)
 	repoEsc := url.PathEscape(repositoryName)
 	path := fmt.Sprintf("/projects/%s/repositories/%s", projectEsc, repoEsc)
 
-	resp, err := s.client.Get(path, nil)
+	resp, err := s.client.Get(ctx, path, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -72,12 +63,12 @@ func (s *RepositoryService) Get(projectName, repositoryName string) (*api.Reposi
 }
 
 // Delete removes a repository from a project
-func (s *RepositoryService) Delete(projectName, repositoryName string) error {
+func (s *RepositoryService) Delete(ctx context.Context, projectName, repositoryName string) error {
 	projectEsc := url.PathEscape(projectName)
 	repoEsc := url.PathEscape(repositoryName)
 	path := fmt.Sprintf("/projects/%s/repositories/%s", projectEsc, repoEsc)
 
-	resp, err := s.client.Delete(path)
+	resp, err := s.client.Delete(ctx, path)
 	if err != nil {
 		return err
 	}
@@ -90,28 +81,9 @@ func (s *RepositoryService) Delete(projectName, repositoryName string) error {
 }
 
 // ListTags lists all tags for a repository
-func (s *RepositoryService) ListTags(projectName, repositoryName string, opts *api.ListOptions) ([]*api.ArtifactTag, error) {
-	projectEsc := url.PathEscape(projectName)
-	repoEsc := url.PathEscape(repositoryName)
-	path := fmt.Sprintf("/projects/%s/repositories/%s/artifacts", projectEsc, repoEsc)
-
-	params := map[string]string{"with_tag": "true"}
-	if opts != nil {
-		if opts.Query != "" {
-			params["q"] = opts.Query
-		}
-		if opts.Sort != "" {
-			params["sort"] = opts.Sort
-		}
-		if opts.Page > 0 {
-			params["page"] = strconv.Itoa(opts.Page)
-		}
-		if opts.PageSize > 0 {
-			params["page_size"] = strconv.Itoa(opts.PageSize)
-		}
-	}
-
-	resp, err := s.client.Get(path, params)
+func (s *RepositoryService) ListTags(ctx context.Context, projectName, repositoryName string, opts *api.ListOptions) ([]*api.ArtifactTag, error) {
+	path, params := tagListRequest(projectName, repositoryName, opts)
+	resp, err := s.client.Get(ctx, path, params)
 	if err != nil {
 		return nil, err
 	}
@@ -121,6 +93,31 @@ func (s *RepositoryService) ListTags(projectName, repositoryName string, opts *a
 		return nil, fmt.Errorf("failed to decode artifacts: %w", err)
 	}
 
+	return artifactTags(artifacts), nil
+}
+
+// ListAllTags lists the tags of all artifacts in a repository across all
+// pages. The limit applies to the number of artifacts read.
+func (s *RepositoryService) ListAllTags(ctx context.Context, projectName, repositoryName string, opts *api.ListOptions, limit int) ([]*api.ArtifactTag, error) {
+	path, params := tagListRequest(projectName, repositoryName, opts)
+	artifacts, err := api.ListAll[*api.Artifact](ctx, s.client, path, params, limit)
+	if err != nil {
+		return nil, err
+	}
+	return artifactTags(artifacts), nil
+}
+
+func tagListRequest(projectName, repositoryName string, opts *api.ListOptions) (string, map[string]string) {
+	projectEsc := url.PathEscape(projectName)
+	repoEsc := url.PathEscape(repositoryName)
+	path := fmt.Sprintf("/projects/%s/repositories/%s/artifacts", projectEsc, repoEsc)
+
+	params := listParams(opts)
+	params["with_tag"] = "true"
+	return path, params
+}
+
+func artifactTags(artifacts []*api.Artifact) []*api.ArtifactTag {
 	var tags []*api.ArtifactTag
 	for _, a := range artifacts {
 		for _, t := range a.Tags {
@@ -128,5 +125,5 @@ func (s *RepositoryService) ListTags(projectName, repositoryName string, opts *a
 			tags = append(tags, &api.ArtifactTag{Name: tag.Name, Immutable: tag.Immutable})
 		}
 	}
-	return tags, nil
+	return tags
 }
@@ -1,10 +1,12 @@
 package harbor
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"strconv"
 	"testing"
 
 	"github.com/pascal71/hrbcli/pkg/api"
@@ -28,7 +30,7 @@ func TestRepositoryServiceListEscapesProjectName(t *testing.T) {
 	svc := NewRepositoryService(client)
 
 	project := "my project/with/slash"
-	if _, err := svc.List(project, nil); err != nil {
+	if _, err := svc.List(context.Background(), project, nil); err != nil {
 		t.Fatalf("List error: %v", err)
 	}
 
@@ -37,3 +39,84 @@ func TestRepositoryServiceListEscapesProjectName(t *testing.T) {
 		t.Fatalf("unexpected path: got %s want %s", gotPath, expected)
 	}
 }
+
+func TestRepositoryServiceListAllFollowsLinkHeader(t *testing.T) {
+	var pages []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page := r.URL.Query().Get("page")
+		pages = append(pages, page)
+		w.Header().Set("X-Total-Count", "3")
+		switch page {
+		case "1":
+			w.Header().Set("Link", `</api/v2.0/projects/demo/repositories?page=2&page_size=2>; rel="next"`)
+			w.Write([]byte(`[{"name":"demo/a"},{"name":"demo/b"}]`))
+		case "2":
+			w.Header().Set("Link", `</api/v2.0/projects/demo/repositories?page=1&page_size=2>; rel="prev"`)
+			w.Write([]byte(`[{"name":"demo/c"}]`))
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+
+	client := &api.Client{
+		BaseURL:    server.URL,
+		APIVersion: "v2.0",
+		HTTPClient: server.Client(),
+	}
+
+	svc := NewRepositoryService(client)
+	repos, err := svc.ListAll(context.Background(), "demo", &api.ListOptions{PageSize: 2}, 0)
+	if err != nil {
+		t.Fatalf("ListAll error: %v", err)
+	}
+	if len(repos) != 3 || repos[2].Name != "demo/c" {
+		t.Fatalf("unexpected repositories: %+v", repos)
+	}
+	if len(pages) != 2 {
+		t.Fatalf("expected 2 requests, got %v", pages)
+	}
+}
+
+func TestRepositoryServiceListAllWithoutLinkHeader(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		size, _ := strconv.Atoi(r.URL.Query().Get("page_size"))
+		w.Header().Set("X-Total-Count", "250")
+		w.Write([]byte("["))
+		for i := 0; i < size && (page-1)*size+i < 250; i++ {
+			if i > 0 {
+				w.Write([]byte(","))
+			}
+			fmt.Fprintf(w, `{"name":"demo/r%d"}`, (page-1)*size+i)
+		}
+		w.Write([]byte("]"))
+	}))
+	defer server.Close()
+
+	client := &api.Client{
+		BaseURL:    server.URL,
+		APIVersion: "v2.0",
+		HTTPClient: server.Client(),
+	}
+
+	svc := NewRepositoryService(client)
+	repos, err := svc.ListAll(context.Background(), "demo", nil, 0)
+	if err != nil {
+		t.Fatalf("ListAll error: %v", err)
+	}
+	if len(repos) != 250 || requests != 3 {
+		t.Fatalf("got %d repositories in %d requests", len(repos), requests)
+	}
+
+	requests = 0
+	repos, err = svc.ListAll(context.Background(), "demo", nil, 120)
+	if err != nil {
+		t.Fatalf("ListAll error: %v", err)
+	}
+	if len(repos) != 120 || requests != 2 {
+		t.Fatalf("limit: got %d repositories in %d requests", len(repos), requests)
+	}
+}
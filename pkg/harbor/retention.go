@@ -0,0 +1,149 @@
+package harbor
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/pascal71/hrbcli/pkg/api"
+)
+
+// RetentionService handles tag retention policy operations
+type RetentionService struct {
+	client *api.Client
+}
+
+// NewRetentionService creates a new RetentionService
+func NewRetentionService(client *api.Client) *RetentionService {
+	return &RetentionService{client: client}
+}
+
+// GetPolicy retrieves a retention policy by ID
+func (s *RetentionService) GetPolicy(ctx context.Context, id int64) (*api.RetentionPolicy, error) {
+	resp, err := s.client.Get(ctx, fmt.Sprintf("/retentions/%d", id), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var policy api.RetentionPolicy
+	if err := s.client.DecodeResponse(resp, &policy); err != nil {
+		return nil, fmt.Errorf("failed to decode retention policy: %w", err)
+	}
+	return &policy, nil
+}
+
+// CreatePolicy creates a retention policy and returns its ID
+func (s *RetentionService) CreatePolicy(ctx context.Context, policy *api.RetentionPolicy) (int64, error) {
+	resp, err := s.client.Post(ctx, "/retentions", policy)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		return 0, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	return parseLocationID(resp.Header.Get("Location"))
+}
+
+// UpdatePolicy updates a retention policy
+func (s *RetentionService) UpdatePolicy(ctx context.Context, id int64, policy *api.RetentionPolicy) error {
+	resp, err := s.client.Put(ctx, fmt.Sprintf("/retentions/%d", id), policy)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// DeletePolicy deletes a retention policy
+func (s *RetentionService) DeletePolicy(ctx context.Context, id int64) error {
+	resp, err := s.client.Delete(ctx, fmt.Sprintf("/retentions/%d", id))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Run triggers a retention execution. With dryRun set Harbor only
+// records what would be deleted.
+func (s *RetentionService) Run(ctx context.Context, id int64, dryRun bool) error {
+	body := map[string]bool{"dry_run": dryRun}
+	resp, err := s.client.Post(ctx, fmt.Sprintf("/retentions/%d/executions", id), body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// ListExecutions lists executions of a retention policy
+func (s *RetentionService) ListExecutions(ctx context.Context, id int64, opts *api.ListOptions) ([]*api.RetentionExecution, error) {
+	resp, err := s.client.Get(ctx, fmt.Sprintf("/retentions/%d/executions", id), listParams(opts))
+	if err != nil {
+		return nil, err
+	}
+
+	var execs []*api.RetentionExecution
+	if err := s.client.DecodeResponse(resp, &execs); err != nil {
+		return nil, fmt.Errorf("failed to decode executions: %w", err)
+	}
+	return execs, nil
+}
+
+// ListAllExecutions lists executions of a retention policy across all pages
+func (s *RetentionService) ListAllExecutions(ctx context.Context, id int64, opts *api.ListOptions, limit int) ([]*api.RetentionExecution, error) {
+	return api.ListAll[*api.RetentionExecution](ctx, s.client, fmt.Sprintf("/retentions/%d/executions", id), listParams(opts), limit)
+}
+
+// ListTasks lists tasks of a retention execution
+func (s *RetentionService) ListTasks(ctx context.Context, id, executionID int64, opts *api.ListOptions) ([]*api.RetentionExecutionTask, error) {
+	resp, err := s.client.Get(ctx, fmt.Sprintf("/retentions/%d/executions/%d/tasks", id, executionID), listParams(opts))
+	if err != nil {
+		return nil, err
+	}
+
+	var tasks []*api.RetentionExecutionTask
+	if err := s.client.DecodeResponse(resp, &tasks); err != nil {
+		return nil, fmt.Errorf("failed to decode tasks: %w", err)
+	}
+	return tasks, nil
+}
+
+// ListAllTasks lists tasks of a retention execution across all pages
+func (s *RetentionService) ListAllTasks(ctx context.Context, id, executionID int64, opts *api.ListOptions, limit int) ([]*api.RetentionExecutionTask, error) {
+	return api.ListAll[*api.RetentionExecutionTask](ctx, s.client, fmt.Sprintf("/retentions/%d/executions/%d/tasks", id, executionID), listParams(opts), limit)
+}
+
+// GetTaskLog retrieves the log of a retention task
+func (s *RetentionService) GetTaskLog(ctx context.Context, id, executionID, taskID int64) (string, error) {
+	resp, err := s.client.Get(ctx, fmt.Sprintf("/retentions/%d/executions/%d/tasks/%d", id, executionID, taskID), nil)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+	buf, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read log: %w", err)
+	}
+	return string(buf), nil
+}
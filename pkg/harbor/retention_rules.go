@@ -0,0 +1,353 @@
+package harbor
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/bmatcuk/doublestar/v4"
+
+	"github.com/pascal71/hrbcli/pkg/api"
+)
+
+// RetentionSpec is the rule file format used by the CLI. It is a
+// flattened form of api.RetentionPolicy that is easier to write by hand:
+//
+//	schedule: "0 0 0 * * *"
+//	rules:
+//	  - template: latestPushedK
+//	    count: 10
+//	    repositories: "**"
+//	    tags: "release-*"
+type RetentionSpec struct {
+	Algorithm string               `yaml:"algorithm,omitempty" json:"algorithm,omitempty"`
+	Schedule  string               `yaml:"schedule,omitempty" json:"schedule,omitempty"`
+	Rules     []*RetentionRuleSpec `yaml:"rules" json:"rules"`
+}
+
+// RetentionRuleSpec describes a single retention rule
+type RetentionRuleSpec struct {
+	Template            string `yaml:"template" json:"template"`
+	Count               int    `yaml:"count,omitempty" json:"count,omitempty"`
+	Repositories        string `yaml:"repositories,omitempty" json:"repositories,omitempty"`
+	ExcludeRepositories bool   `yaml:"exclude_repositories,omitempty" json:"exclude_repositories,omitempty"`
+	Tags                string `yaml:"tags,omitempty" json:"tags,omitempty"`
+	ExcludeTags         bool   `yaml:"exclude_tags,omitempty" json:"exclude_tags,omitempty"`
+	Untagged            bool   `yaml:"untagged,omitempty" json:"untagged,omitempty"`
+	Disabled            bool   `yaml:"disabled,omitempty" json:"disabled,omitempty"`
+}
+
+// Policy converts the spec to a Harbor retention policy for the project
+func (s *RetentionSpec) Policy(projectID int64) (*api.RetentionPolicy, error) {
+	if len(s.Rules) == 0 {
+		return nil, fmt.Errorf("at least one retention rule is required")
+	}
+	if len(s.Rules) > 15 {
+		return nil, fmt.Errorf("at most 15 retention rules are allowed")
+	}
+
+	algorithm := s.Algorithm
+	if algorithm == "" {
+		algorithm = "or"
+	}
+	if algorithm != "or" {
+		return nil, fmt.Errorf("unsupported algorithm: %s (valid: or)", algorithm)
+	}
+
+	policy := &api.RetentionPolicy{
+		Algorithm: algorithm,
+		Trigger: &api.RetentionTrigger{
+			Kind:     "Schedule",
+			Settings: map[string]interface{}{"cron": s.Schedule},
+		},
+		Scope: &api.RetentionScope{Level: "project", Ref: projectID},
+	}
+
+	for i, r := range s.Rules {
+		rule, err := r.rule()
+		if err != nil {
+			return nil, fmt.Errorf("rule %d: %w", i+1, err)
+		}
+		rule.Priority = i
+		policy.Rules = append(policy.Rules, rule)
+	}
+	return policy, nil
+}
+
+func (r *RetentionRuleSpec) rule() (*api.RetentionRule, error) {
+	params := map[string]interface{}{}
+	switch r.Template {
+	case api.RetentionTemplateLatestPushedK, api.RetentionTemplateLatestPulledN,
+		api.RetentionTemplateNDaysSinceLastPush, api.RetentionTemplateNDaysSinceLastPull:
+		if r.Count <= 0 {
+			return nil, fmt.Errorf("template %s requires a positive count", r.Template)
+		}
+		params[r.Template] = r.Count
+	case api.RetentionTemplateAlways:
+	default:
+		return nil, fmt.Errorf("invalid template: %s", r.Template)
+	}
+
+	repos := r.Repositories
+	if repos == "" {
+		repos = "**"
+	}
+	repoDecoration := "repoMatches"
+	if r.ExcludeRepositories {
+		repoDecoration = "repoExcludes"
+	}
+
+	tags := r.Tags
+	if tags == "" {
+		tags = "**"
+	}
+	tagDecoration := "matches"
+	if r.ExcludeTags {
+		tagDecoration = "excludes"
+	}
+
+	return &api.RetentionRule{
+		Disabled: r.Disabled,
+		Action:   "retain",
+		Template: r.Template,
+		Params:   params,
+		TagSelectors: []*api.RetentionSelector{{
+			Kind:       "doublestar",
+			Decoration: tagDecoration,
+			Pattern:    tags,
+			Extras:     fmt.Sprintf(`{"untagged":%t}`, r.Untagged),
+		}},
+		ScopeSelectors: map[string][]*api.RetentionSelector{
+			"repository": {{
+				Kind:       "doublestar",
+				Decoration: repoDecoration,
+				Pattern:    repos,
+			}},
+		},
+	}, nil
+}
+
+// RetentionDecision is the outcome of simulating a retention policy
+// against a single artifact
+type RetentionDecision struct {
+	Repository string    `json:"repository"`
+	Digest     string    `json:"digest"`
+	Tags       []string  `json:"tags"`
+	PushTime   time.Time `json:"push_time"`
+	PullTime   time.Time `json:"pull_time"`
+	Retained   bool      `json:"retained"`
+	// Rule is the 1-based index of the first rule retaining the
+	// artifact, or 0 when the artifact would be removed.
+	Rule int `json:"rule,omitempty"`
+}
+
+// SimulateRetention evaluates a retention policy locally against the
+// artifacts of one repository, the same way Harbor does: an artifact is
+// retained when any enabled rule retains it and removed otherwise.
+// repository is the repository name without the project prefix.
+func SimulateRetention(policy *api.RetentionPolicy, repository string, artifacts []*api.Artifact, now time.Time) ([]*RetentionDecision, error) {
+	decisions := make([]*RetentionDecision, len(artifacts))
+	for i, a := range artifacts {
+		tags := make([]string, len(a.Tags))
+		for j, t := range a.Tags {
+			tags[j] = t.Name
+		}
+		decisions[i] = &RetentionDecision{
+			Repository: repository,
+			Digest:     a.Digest,
+			Tags:       tags,
+			PushTime:   a.PushTime,
+			PullTime:   a.PullTime,
+		}
+	}
+
+	for ruleIdx, rule := range policy.Rules {
+		if rule.Disabled {
+			continue
+		}
+
+		inScope, err := matchRetentionScope(rule, repository)
+		if err != nil {
+			return nil, fmt.Errorf("rule %d: %w", ruleIdx+1, err)
+		}
+		if !inScope {
+			continue
+		}
+
+		var candidates []int
+		for i, a := range artifacts {
+			ok, err := matchRetentionTags(rule, a)
+			if err != nil {
+				return nil, fmt.Errorf("rule %d: %w", ruleIdx+1, err)
+			}
+			if ok {
+				candidates = append(candidates, i)
+			}
+		}
+
+		kept, err := applyRetentionTemplate(rule, artifacts, candidates, now)
+		if err != nil {
+			return nil, fmt.Errorf("rule %d: %w", ruleIdx+1, err)
+		}
+		for _, i := range kept {
+			if !decisions[i].Retained {
+				decisions[i].Retained = true
+				decisions[i].Rule = ruleIdx + 1
+			}
+		}
+	}
+
+	return decisions, nil
+}
+
+func matchRetentionScope(rule *api.RetentionRule, repository string) (bool, error) {
+	for _, sel := range rule.ScopeSelectors["repository"] {
+		matched, err := doublestar.Match(sel.Pattern, repository)
+		if err != nil {
+			return false, fmt.Errorf("invalid repository pattern %q: %w", sel.Pattern, err)
+		}
+		switch sel.Decoration {
+		case "repoMatches":
+			if !matched {
+				return false, nil
+			}
+		case "repoExcludes":
+			if matched {
+				return false, nil
+			}
+		default:
+			return false, fmt.Errorf("unsupported repository decoration: %s", sel.Decoration)
+		}
+	}
+	return true, nil
+}
+
+func matchRetentionTags(rule *api.RetentionRule, a *api.Artifact) (bool, error) {
+	for _, sel := range rule.TagSelectors {
+		var extras struct {
+			Untagged bool `json:"untagged"`
+		}
+		if sel.Extras != "" {
+			if err := json.Unmarshal([]byte(sel.Extras), &extras); err != nil {
+				return false, fmt.Errorf("invalid tag selector extras %q: %w", sel.Extras, err)
+			}
+		}
+
+		if len(a.Tags) == 0 {
+			if !extras.Untagged {
+				return false, nil
+			}
+			continue
+		}
+
+		selected := false
+		for _, t := range a.Tags {
+			matched, err := doublestar.Match(sel.Pattern, t.Name)
+			if err != nil {
+				return false, fmt.Errorf("invalid tag pattern %q: %w", sel.Pattern, err)
+			}
+			switch sel.Decoration {
+			case "matches":
+				selected = selected || matched
+			case "excludes":
+				selected = selected || !matched
+			default:
+				return false, fmt.Errorf("unsupported tag decoration: %s", sel.Decoration)
+			}
+		}
+		if !selected {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func applyRetentionTemplate(rule *api.RetentionRule, artifacts []*api.Artifact, candidates []int, now time.Time) ([]int, error) {
+	if rule.Template == api.RetentionTemplateAlways {
+		return candidates, nil
+	}
+
+	n, err := retentionParam(rule)
+	if err != nil {
+		return nil, err
+	}
+
+	byTime := func(pick func(*api.Artifact) time.Time) []int {
+		sorted := append([]int(nil), candidates...)
+		sort.SliceStable(sorted, func(i, j int) bool {
+			return pick(artifacts[sorted[i]]).After(pick(artifacts[sorted[j]]))
+		})
+		if len(sorted) > n {
+			sorted = sorted[:n]
+		}
+		return sorted
+	}
+	since := func(pick func(*api.Artifact) time.Time) []int {
+		cutoff := now.Add(-time.Duration(n) * 24 * time.Hour)
+		var kept []int
+		for _, i := range candidates {
+			if pick(artifacts[i]).After(cutoff) {
+				kept = append(kept, i)
+			}
+		}
+		return kept
+	}
+	pushed := func(a *api.Artifact) time.Time { return a.PushTime }
+	pulled := func(a *api.Artifact) time.Time { return a.PullTime }
+
+	switch rule.Template {
+	case api.RetentionTemplateLatestPushedK:
+		return byTime(pushed), nil
+	case api.RetentionTemplateLatestPulledN:
+		return byTime(pulled), nil
+	case api.RetentionTemplateNDaysSinceLastPush:
+		return since(pushed), nil
+	case api.RetentionTemplateNDaysSinceLastPull:
+		return since(pulled), nil
+	default:
+		return nil, fmt.Errorf("unsupported template: %s", rule.Template)
+	}
+}
+
+// retentionParam returns the numeric parameter of a rule. Harbor stores
+// it under the template name; JSON decoding yields a float64.
+func retentionParam(rule *api.RetentionRule) (int, error) {
+	v, ok := rule.Params[rule.Template]
+	if !ok {
+		return 0, fmt.Errorf("missing parameter %s", rule.Template)
+	}
+	switch n := v.(type) {
+	case int:
+		return n, nil
+	case int64:
+		return int(n), nil
+	case float64:
+		return int(n), nil
+	case string:
+		return strconv.Atoi(n)
+	default:
+		return 0, fmt.Errorf("invalid parameter %s: %v", rule.Template, v)
+	}
+}
+
+// DescribeRetentionRule renders a rule in a short human readable form
+func DescribeRetentionRule(rule *api.RetentionRule) string {
+	desc := rule.Template
+	if rule.Template != api.RetentionTemplateAlways {
+		if n, err := retentionParam(rule); err == nil {
+			desc = fmt.Sprintf("%s=%d", rule.Template, n)
+		}
+	}
+	for _, sel := range rule.ScopeSelectors["repository"] {
+		desc += fmt.Sprintf(" repos %s %s", sel.Decoration, sel.Pattern)
+	}
+	for _, sel := range rule.TagSelectors {
+		desc += fmt.Sprintf(" tags %s %s", sel.Decoration, sel.Pattern)
+		if sel.Extras != "" && sel.Extras != `{"untagged":false}` {
+			desc += " " + sel.Extras
+		}
+	}
+	return desc
+}
@@ -0,0 +1,104 @@
+package harbor
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/pascal71/hrbcli/pkg/api"
+)
+
+func retentionArtifact(digest string, pushedDaysAgo int, now time.Time, tags ...string) *api.Artifact {
+	a := &api.Artifact{
+		Digest:   digest,
+		PushTime: now.Add(-time.Duration(pushedDaysAgo) * 24 * time.Hour),
+	}
+	for _, t := range tags {
+		a.Tags = append(a.Tags, api.ArtifactTag{Name: t})
+	}
+	return a
+}
+
+func TestSimulateRetention(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	artifacts := []*api.Artifact{
+		retentionArtifact("sha256:a", 1, now, "release-3"),
+		retentionArtifact("sha256:b", 5, now, "release-2"),
+		retentionArtifact("sha256:c", 10, now, "release-1"),
+		retentionArtifact("sha256:d", 2, now, "dev"),
+		retentionArtifact("sha256:e", 40, now),
+	}
+
+	spec := &RetentionSpec{Rules: []*RetentionRuleSpec{
+		{Template: api.RetentionTemplateLatestPushedK, Count: 2, Tags: "release-*"},
+		{Template: api.RetentionTemplateNDaysSinceLastPush, Count: 7, Repositories: "other/**"},
+	}}
+	policy, err := spec.Policy(1)
+	if err != nil {
+		t.Fatalf("policy: %v", err)
+	}
+
+	// Round-trip through JSON so params are float64 as when read from the server
+	data, _ := json.Marshal(policy)
+	var decoded api.RetentionPolicy
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+
+	decisions, err := SimulateRetention(&decoded, "app", artifacts, now)
+	if err != nil {
+		t.Fatalf("simulate: %v", err)
+	}
+
+	want := map[string]bool{"sha256:a": true, "sha256:b": true, "sha256:c": false, "sha256:d": false, "sha256:e": false}
+	for _, d := range decisions {
+		if d.Retained != want[d.Digest] {
+			t.Errorf("%s: retained=%v, want %v", d.Digest, d.Retained, want[d.Digest])
+		}
+		if d.Retained && d.Rule != 1 {
+			t.Errorf("%s: rule=%d, want 1", d.Digest, d.Rule)
+		}
+	}
+}
+
+func TestSimulateRetentionUntagged(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	artifacts := []*api.Artifact{
+		retentionArtifact("sha256:a", 1, now),
+		retentionArtifact("sha256:b", 1, now, "v1"),
+	}
+
+	for _, untagged := range []bool{false, true} {
+		spec := &RetentionSpec{Rules: []*RetentionRuleSpec{
+			{Template: api.RetentionTemplateAlways, Tags: "nothing", Untagged: untagged},
+		}}
+		policy, err := spec.Policy(1)
+		if err != nil {
+			t.Fatalf("policy: %v", err)
+		}
+		decisions, err := SimulateRetention(policy, "app", artifacts, now)
+		if err != nil {
+			t.Fatalf("simulate: %v", err)
+		}
+		if decisions[0].Retained != untagged {
+			t.Errorf("untagged=%v: untagged artifact retained=%v", untagged, decisions[0].Retained)
+		}
+		if decisions[1].Retained {
+			t.Errorf("untagged=%v: non-matching tag retained", untagged)
+		}
+	}
+}
+
+func TestRetentionSpecPolicyValidation(t *testing.T) {
+	cases := []*RetentionSpec{
+		{},
+		{Rules: []*RetentionRuleSpec{{Template: "bogus"}}},
+		{Rules: []*RetentionRuleSpec{{Template: api.RetentionTemplateLatestPushedK}}},
+		{Algorithm: "and", Rules: []*RetentionRuleSpec{{Template: api.RetentionTemplateAlways}}},
+	}
+	for i, spec := range cases {
+		if _, err := spec.Policy(1); err == nil {
+			t.Errorf("case %d: expected error", i)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package harbor
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/pascal71/hrbcli/pkg/api"
+)
+
+// RobotService handles robot account operations
+type RobotService struct {
+	client *api.Client
+}
+
+// NewRobotService creates a new robot service
+func NewRobotService(client *api.Client) *RobotService {
+	return &RobotService{client: client}
+}
+
+// List lists robot accounts. Use opts.Query to filter, for example
+// "Level=project,ProjectID=1".
+func (s *RobotService) List(ctx context.Context, opts *api.ListOptions) ([]*api.Robot, error) {
+	params := listParams(opts)
+
+	resp, err := s.client.Get(ctx, "/robots", params)
+	if err != nil {
+		return nil, err
+	}
+
+	var robots []*api.Robot
+	if err := s.client.DecodeResponse(resp, &robots); err != nil {
+		return nil, fmt.Errorf("failed to decode robots: %w", err)
+	}
+	return robots, nil
+}
+
+// ListAll lists robot accounts across all pages
+func (s *RobotService) ListAll(ctx context.Context, opts *api.ListOptions, limit int) ([]*api.Robot, error) {
+	return api.ListAll[*api.Robot](ctx, s.client, "/robots", listParams(opts), limit)
+}
+
+// Get retrieves a robot account by ID
+func (s *RobotService) Get(ctx context.Context, id int64) (*api.Robot, error) {
+	resp, err := s.client.Get(ctx, fmt.Sprintf("/robots/%d", id), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var robot api.Robot
+	if err := s.client.DecodeResponse(resp, &robot); err != nil {
+		return nil, fmt.Errorf("failed to decode robot: %w", err)
+	}
+	return &robot, nil
+}
+
+// Create creates a robot account. The returned value contains the
+// generated secret, which Harbor does not return again.
+func (s *RobotService) Create(ctx context.Context, req *api.RobotCreate) (*api.RobotCreated, error) {
+	resp, err := s.client.Post(ctx, "/robots", req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusCreated {
+		resp.Body.Close()
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	var created api.RobotCreated
+	if err := s.client.DecodeResponse(resp, &created); err != nil {
+		return nil, fmt.Errorf("failed to decode robot: %w", err)
+	}
+	return &created, nil
+}
+
+// Update updates a robot account
+func (s *RobotService) Update(ctx context.Context, id int64, robot *api.Robot) error {
+	resp, err := s.client.Put(ctx, fmt.Sprintf("/robots/%d", id), robot)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Delete deletes a robot account by ID
+func (s *RobotService) Delete(ctx context.Context, id int64) error {
+	resp, err := s.client.Delete(ctx, fmt.Sprintf("/robots/%d", id))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// RefreshSecret refreshes the secret of a robot account. When secret is
+// empty Harbor generates a new one, which is returned.
+func (s *RobotService) RefreshSecret(ctx context.Context, id int64, secret string) (*api.RobotSec, error) {
+	resp, err := s.client.Patch(ctx, fmt.Sprintf("/robots/%d", id), &api.RobotSec{Secret: secret})
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	var sec api.RobotSec
+	if err := s.client.DecodeResponse(resp, &sec); err != nil {
+		return nil, fmt.Errorf("failed to decode robot secret: %w", err)
+	}
+	return &sec, nil
+}
@@ -0,0 +1,48 @@
+package harbor
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/pascal71/hrbcli/pkg/api"
+)
+
+func TestRobotServiceRefreshSecret(t *testing.T) {
+	var gotMethod string
+	var gotBody api.RobotSec
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotMethod = r.Method
+		json.NewDecoder(r.Body).Decode(&gotBody)
+		if r.URL.Path != "/api/v2.0/robots/3" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"secret":"generated"}`))
+	}))
+	defer server.Close()
+
+	client := &api.Client{
+		BaseURL:    server.URL,
+		APIVersion: "v2.0",
+		HTTPClient: server.Client(),
+	}
+
+	svc := NewRobotService(client)
+	sec, err := svc.RefreshSecret(context.Background(), 3, "")
+	if err != nil {
+		t.Fatalf("RefreshSecret error: %v", err)
+	}
+	if gotMethod != http.MethodPatch {
+		t.Fatalf("unexpected method: %s", gotMethod)
+	}
+	if gotBody.Secret != "" {
+		t.Fatalf("unexpected secret in request: %q", gotBody.Secret)
+	}
+	if sec.Secret != "generated" {
+		t.Fatalf("unexpected secret: %q", sec.Secret)
+	}
+}
@@ -0,0 +1,110 @@
+package harbor
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/pascal71/hrbcli/pkg/api"
+)
+
+// Schedule types understood by Harbor
+const (
+	ScheduleManual = "Manual"
+	ScheduleHourly = "Hourly"
+	ScheduleDaily  = "Daily"
+	ScheduleWeekly = "Weekly"
+	ScheduleCustom = "Custom"
+	ScheduleNone   = "None"
+)
+
+// ScanAllService handles the system-wide scan of all artifacts
+type ScanAllService struct {
+	client *api.Client
+}
+
+// NewScanAllService creates a new ScanAllService
+func NewScanAllService(client *api.Client) *ScanAllService {
+	return &ScanAllService{client: client}
+}
+
+// Run starts a scan of all artifacts
+func (s *ScanAllService) Run(ctx context.Context) error {
+	body := map[string]interface{}{
+		"schedule": &api.Schedule{Type: ScheduleManual},
+	}
+	resp, err := s.client.Post(ctx, "/system/scanAll/schedule", body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// GetSchedule retrieves the scan all schedule. The schedule is nil when
+// none is configured.
+func (s *ScanAllService) GetSchedule(ctx context.Context) (*api.ScanAllSchedule, error) {
+	resp, err := s.client.Get(ctx, "/system/scanAll/schedule", nil)
+	if err != nil {
+		return nil, err
+	}
+	var schedule api.ScanAllSchedule
+	if err := s.client.DecodeResponse(resp, &schedule); err != nil {
+		return nil, fmt.Errorf("failed to decode scan all schedule: %w", err)
+	}
+	return &schedule, nil
+}
+
+// SetSchedule creates or replaces the periodic scan all schedule. A
+// schedule of type None disables it.
+func (s *ScanAllService) SetSchedule(ctx context.Context, schedule *api.Schedule) error {
+	current, err := s.GetSchedule(ctx)
+	if err != nil {
+		return err
+	}
+
+	body := map[string]interface{}{"schedule": schedule}
+	var resp *http.Response
+	if current.Schedule == nil || current.Schedule.Type == "" {
+		resp, err = s.client.Post(ctx, "/system/scanAll/schedule", body)
+	} else {
+		resp, err = s.client.Put(ctx, "/system/scanAll/schedule", body)
+	}
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// GetMetrics retrieves the progress of the latest scan all run
+func (s *ScanAllService) GetMetrics(ctx context.Context) (*api.ScanAllMetrics, error) {
+	resp, err := s.client.Get(ctx, "/system/scanAll/metrics", nil)
+	if err != nil {
+		return nil, err
+	}
+	var metrics api.ScanAllMetrics
+	if err := s.client.DecodeResponse(resp, &metrics); err != nil {
+		return nil, fmt.Errorf("failed to decode scan all metrics: %w", err)
+	}
+	return &metrics, nil
+}
+
+// Stop stops the running scan all job
+func (s *ScanAllService) Stop(ctx context.Context) error {
+	resp, err := s.client.Post(ctx, "/system/scanAll/stop", nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusAccepted && resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+	return nil
+}
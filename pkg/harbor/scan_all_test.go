@@ -0,0 +1,89 @@
+package harbor
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/pascal71/hrbcli/pkg/api"
+)
+
+func TestScanAllServiceSchedule(t *testing.T) {
+	current := `{}`
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.URL.Path {
+		case "GET /api/v2.0/system/scanAll/schedule":
+			w.Write([]byte(current))
+			return
+		case "GET /api/v2.0/system/scanAll/metrics":
+			w.Write([]byte(`{"total":10,"completed":4,"metrics":{"Success":3,"Error":1,"Running":6},"ongoing":true,"trigger":"Manual"}`))
+			return
+		case "POST /api/v2.0/system/scanAll/stop":
+			w.WriteHeader(http.StatusAccepted)
+		case "POST /api/v2.0/system/scanAll/schedule":
+			w.WriteHeader(http.StatusCreated)
+		case "PUT /api/v2.0/system/scanAll/schedule":
+			w.WriteHeader(http.StatusOK)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		requests = append(requests, r.Method+" "+string(body))
+	}))
+	defer server.Close()
+
+	client := &api.Client{BaseURL: server.URL, APIVersion: "v2.0", HTTPClient: server.Client()}
+	svc := NewScanAllService(client)
+	ctx := context.Background()
+
+	if err := svc.Run(ctx); err != nil {
+		t.Fatal(err)
+	}
+	schedule := &api.Schedule{Type: ScheduleCustom, Cron: "0 0 2 * * *"}
+	if err := svc.SetSchedule(ctx, schedule); err != nil {
+		t.Fatal(err)
+	}
+	current = `{"schedule":{"type":"Custom","cron":"0 0 2 * * *","next_scheduled_time":"2026-10-17T02:00:00Z"}}`
+	if err := svc.SetSchedule(ctx, &api.Schedule{Type: ScheduleNone}); err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.Stop(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		`POST {"schedule":{"type":"Manual"}}`,
+		`POST {"schedule":{"type":"Custom","cron":"0 0 2 * * *"}}`,
+		`PUT {"schedule":{"type":"None"}}`,
+		`POST `,
+	}
+	if len(requests) != len(expected) {
+		t.Fatalf("unexpected requests %q", requests)
+	}
+	for i := range expected {
+		if requests[i] != expected[i] {
+			t.Errorf("request %d: expected %q, got %q", i, expected[i], requests[i])
+		}
+	}
+
+	got, err := svc.GetSchedule(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Schedule.Cron != "0 0 2 * * *" || got.Schedule.NextScheduledTime == nil {
+		t.Fatalf("unexpected schedule %+v", got.Schedule)
+	}
+
+	metrics, err := svc.GetMetrics(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if metrics.Total != 10 || metrics.Completed != 4 || !metrics.Ongoing || metrics.Metrics["Error"] != 1 {
+		t.Fatalf("unexpected metrics %+v", metrics)
+	}
+}
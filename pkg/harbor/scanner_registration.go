@@ -0,0 +1,220 @@
+package harbor
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/pascal71/hrbcli/pkg/api"
+)
+
+// Authentication schemes Harbor uses to call a scanner adapter
+const (
+	ScannerAuthNone   = ""
+	ScannerAuthBasic  = "Basic"
+	ScannerAuthBearer = "Bearer"
+	ScannerAuthAPIKey = "X-ScannerAdapter-API-Key"
+)
+
+// ParseScannerAuth maps none, basic, bearer or api-key to the
+// authentication scheme expected by Harbor
+func ParseScannerAuth(auth string) (string, error) {
+	switch strings.ToLower(auth) {
+	case "", "none":
+		return ScannerAuthNone, nil
+	case "basic":
+		return ScannerAuthBasic, nil
+	case "bearer":
+		return ScannerAuthBearer, nil
+	case "api-key", strings.ToLower(ScannerAuthAPIKey):
+		return ScannerAuthAPIKey, nil
+	}
+	return "", fmt.Errorf("invalid auth %q: must be none, basic, bearer or api-key", auth)
+}
+
+// ScannerRegistrationService handles scanner registrations and the
+// scanner assigned to projects
+type ScannerRegistrationService struct {
+	client *api.Client
+}
+
+// NewScannerRegistrationService creates a new ScannerRegistrationService
+func NewScannerRegistrationService(client *api.Client) *ScannerRegistrationService {
+	return &ScannerRegistrationService{client: client}
+}
+
+func scannerPath(uuid string) string {
+	return fmt.Sprintf("/scanners/%s", url.PathEscape(uuid))
+}
+
+func projectScannerPath(project string) string {
+	return fmt.Sprintf("/projects/%s/scanner", url.PathEscape(project))
+}
+
+// List lists scanner registrations
+func (s *ScannerRegistrationService) List(ctx context.Context, opts *api.ListOptions) ([]*api.ScannerRegistration, error) {
+	resp, err := s.client.Get(ctx, "/scanners", listParams(opts))
+	if err != nil {
+		return nil, err
+	}
+
+	var scanners []*api.ScannerRegistration
+	if err := s.client.DecodeResponse(resp, &scanners); err != nil {
+		return nil, fmt.Errorf("failed to decode scanners: %w", err)
+	}
+	return scanners, nil
+}
+
+// ListAll lists scanner registrations across all pages
+func (s *ScannerRegistrationService) ListAll(ctx context.Context, opts *api.ListOptions, limit int) ([]*api.ScannerRegistration, error) {
+	return api.ListAll[*api.ScannerRegistration](ctx, s.client, "/scanners", listParams(opts), limit)
+}
+
+// Get gets a scanner registration by UUID
+func (s *ScannerRegistrationService) Get(ctx context.Context, uuid string) (*api.ScannerRegistration, error) {
+	resp, err := s.client.Get(ctx, scannerPath(uuid), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var scanner api.ScannerRegistration
+	if err := s.client.DecodeResponse(resp, &scanner); err != nil {
+		return nil, fmt.Errorf("failed to decode scanner: %w", err)
+	}
+	return &scanner, nil
+}
+
+// Find gets a scanner registration by name or UUID
+func (s *ScannerRegistrationService) Find(ctx context.Context, nameOrUUID string) (*api.ScannerRegistration, error) {
+	scanners, err := s.ListAll(ctx, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+	for _, sc := range scanners {
+		if sc.UUID == nameOrUUID || sc.Name == nameOrUUID {
+			return sc, nil
+		}
+	}
+	return nil, fmt.Errorf("scanner %s not found", nameOrUUID)
+}
+
+// Create registers a scanner adapter and returns the new registration
+func (s *ScannerRegistrationService) Create(ctx context.Context, req *api.ScannerRegistrationReq) (*api.ScannerRegistration, error) {
+	resp, err := s.client.Post(ctx, "/scanners", req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	uuid, err := parseLocationName(resp.Header.Get("Location"))
+	if err != nil {
+		return nil, err
+	}
+	return s.Get(ctx, uuid)
+}
+
+// Update replaces the settings of a scanner registration
+func (s *ScannerRegistrationService) Update(ctx context.Context, uuid string, req *api.ScannerRegistrationReq) error {
+	resp, err := s.client.Put(ctx, scannerPath(uuid), req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Delete removes a scanner registration
+func (s *ScannerRegistrationService) Delete(ctx context.Context, uuid string) error {
+	resp, err := s.client.Delete(ctx, scannerPath(uuid))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// SetDefault makes a scanner the system default, used by projects without
+// a scanner of their own
+func (s *ScannerRegistrationService) SetDefault(ctx context.Context, uuid string) error {
+	resp, err := s.client.Patch(ctx, scannerPath(uuid), map[string]bool{"is_default": true})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Ping tests the connection to a scanner adapter
+func (s *ScannerRegistrationService) Ping(ctx context.Context, req *api.ScannerRegistrationReq) error {
+	resp, err := s.client.Post(ctx, "/scanners/ping", req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("ping failed with status: %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// GetMetadata retrieves the scanner details and capabilities reported by
+// the adapter of a registration
+func (s *ScannerRegistrationService) GetMetadata(ctx context.Context, uuid string) (*api.ScannerAdapterMetadata, error) {
+	resp, err := s.client.Get(ctx, scannerPath(uuid)+"/metadata", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var metadata api.ScannerAdapterMetadata
+	if err := s.client.DecodeResponse(resp, &metadata); err != nil {
+		return nil, fmt.Errorf("failed to decode scanner metadata: %w", err)
+	}
+	return &metadata, nil
+}
+
+// GetProjectScanner gets the scanner used by a project
+func (s *ScannerRegistrationService) GetProjectScanner(ctx context.Context, project string) (*api.ScannerRegistration, error) {
+	resp, err := s.client.Get(ctx, projectScannerPath(project), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var scanner api.ScannerRegistration
+	if err := s.client.DecodeResponse(resp, &scanner); err != nil {
+		return nil, fmt.Errorf("failed to decode project scanner: %w", err)
+	}
+	return &scanner, nil
+}
+
+// SetProjectScanner sets the scanner used by a project
+func (s *ScannerRegistrationService) SetProjectScanner(ctx context.Context, project, uuid string) error {
+	resp, err := s.client.Put(ctx, projectScannerPath(project), map[string]string{"uuid": uuid})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+	return nil
+}
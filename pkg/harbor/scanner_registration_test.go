@@ -0,0 +1,126 @@
+package harbor
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/pascal71/hrbcli/pkg/api"
+)
+
+func TestScannerRegistrationService(t *testing.T) {
+	bodies := map[string]string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies[r.Method+" "+r.URL.Path] = string(body)
+		switch r.Method + " " + r.URL.Path {
+		case "GET /api/v2.0/scanners":
+			w.Write([]byte(`[{"uuid":"u-1","name":"trivy","is_default":true},{"uuid":"u-2","name":"acme"}]`))
+		case "POST /api/v2.0/scanners":
+			w.Header().Set("Location", "/api/v2.0/scanners/u-2")
+			w.WriteHeader(http.StatusCreated)
+		case "GET /api/v2.0/scanners/u-2":
+			w.Write([]byte(`{"uuid":"u-2","name":"acme","auth":"X-ScannerAdapter-API-Key","capabilities":{"support_vulnerability":true,"support_sbom":false}}`))
+		case "GET /api/v2.0/scanners/u-2/metadata":
+			w.Write([]byte(`{"scanner":{"name":"Acme","vendor":"Acme Inc","version":"3.1"},"capabilities":[{"type":"vulnerability","consumes_mime_types":["application/vnd.oci.image.manifest.v1+json"],"produces_mime_types":["application/vnd.security.vulnerability.report; version=1.1"]}],"properties":{"harbor.scanner-adapter/registry-authorization-type":"Bearer"}}`))
+		case "PATCH /api/v2.0/scanners/u-2", "PUT /api/v2.0/scanners/u-2", "DELETE /api/v2.0/scanners/u-2",
+			"POST /api/v2.0/scanners/ping", "PUT /api/v2.0/projects/library/scanner":
+			w.WriteHeader(http.StatusOK)
+		case "GET /api/v2.0/projects/library/scanner":
+			w.Write([]byte(`{"uuid":"u-1","name":"trivy"}`))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := &api.Client{BaseURL: server.URL, APIVersion: "v2.0", HTTPClient: server.Client()}
+	svc := NewScannerRegistrationService(client)
+	ctx := context.Background()
+
+	req := &api.ScannerRegistrationReq{Name: "acme", URL: "https://scanner.example.com", Auth: ScannerAuthAPIKey, AccessCredential: "key"}
+	if err := svc.Ping(ctx, req); err != nil {
+		t.Fatal(err)
+	}
+	created, err := svc.Create(ctx, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created.UUID != "u-2" || created.Capabilities["support_vulnerability"] != true {
+		t.Fatalf("unexpected scanner %+v", created)
+	}
+
+	found, err := svc.Find(ctx, "acme")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if found.UUID != "u-2" {
+		t.Fatalf("expected u-2, got %s", found.UUID)
+	}
+	if _, err := svc.Find(ctx, "missing"); err == nil {
+		t.Fatal("expected error for unknown scanner")
+	}
+
+	if err := svc.SetDefault(ctx, "u-2"); err != nil {
+		t.Fatal(err)
+	}
+	req.Disabled = true
+	if err := svc.Update(ctx, "u-2", req); err != nil {
+		t.Fatal(err)
+	}
+
+	metadata, err := svc.GetMetadata(ctx, "u-2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if metadata.Scanner.Vendor != "Acme Inc" || len(metadata.Capabilities) != 1 || metadata.Capabilities[0].Type != "vulnerability" {
+		t.Fatalf("unexpected metadata %+v", metadata)
+	}
+
+	if err := svc.SetProjectScanner(ctx, "library", "u-2"); err != nil {
+		t.Fatal(err)
+	}
+	projectScanner, err := svc.GetProjectScanner(ctx, "library")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if projectScanner.Name != "trivy" {
+		t.Fatalf("unexpected project scanner %+v", projectScanner)
+	}
+	if err := svc.Delete(ctx, "u-2"); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]string{
+		"PATCH /api/v2.0/scanners/u-2":           `{"is_default":true}`,
+		"PUT /api/v2.0/projects/library/scanner": `{"uuid":"u-2"}`,
+		"PUT /api/v2.0/scanners/u-2":             `{"name":"acme","url":"https://scanner.example.com","auth":"X-ScannerAdapter-API-Key","access_credential":"key","skip_certVerify":false,"use_internal_addr":false,"disabled":true}`,
+	}
+	for request, body := range expected {
+		if bodies[request] != body {
+			t.Errorf("%s: expected body %s, got %s", request, body, bodies[request])
+		}
+	}
+}
+
+func TestParseScannerAuth(t *testing.T) {
+	tests := map[string]string{
+		"":        ScannerAuthNone,
+		"none":    ScannerAuthNone,
+		"Basic":   ScannerAuthBasic,
+		"bearer":  ScannerAuthBearer,
+		"api-key": ScannerAuthAPIKey,
+	}
+	for in, want := range tests {
+		got, err := ParseScannerAuth(in)
+		if err != nil || got != want {
+			t.Errorf("ParseScannerAuth(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	if _, err := ParseScannerAuth("digest"); err == nil {
+		t.Error("expected error for unknown auth")
+	}
+}
@@ -1,7 +1,9 @@
 package harbor
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"net/http"
 
 	"github.com/pascal71/hrbcli/pkg/api"
@@ -18,8 +20,8 @@ func NewSystemService(client *api.Client) *SystemService {
 }
 
 // GetStatistics retrieves Harbor statistics
-func (s *SystemService) GetStatistics() (*api.Statistic, error) {
-	resp, err := s.client.Get("/statistics", nil)
+func (s *SystemService) GetStatistics(ctx context.Context) (*api.Statistic, error) {
+	resp, err := s.client.Get(ctx, "/statistics", nil)
 	if err != nil {
 		return nil, err
 	}
@@ -33,13 +35,13 @@ func (s *SystemService) GetStatistics() (*api.Statistic, error) {
 }
 
 // GetInfo retrieves Harbor system information.
-func (s *SystemService) GetInfo(withStorage bool) (*api.SystemInfo, error) {
+func (s *SystemService) GetInfo(ctx context.Context, withStorage bool) (*api.SystemInfo, error) {
 	params := make(map[string]string)
 	if withStorage {
 		params["with_storage"] = "true"
 	}
 
-	resp, err := s.client.Get("/systeminfo", params)
+	resp, err := s.client.Get(ctx, "/systeminfo", params)
 	if err != nil {
 		return nil, err
 	}
@@ -52,9 +54,25 @@ func (s *SystemService) GetInfo(withStorage bool) (*api.SystemInfo, error) {
 	return &info, nil
 }
 
+// GetCert downloads the root certificate Harbor was configured with. It
+// is returned as PEM.
+func (s *SystemService) GetCert(ctx context.Context) ([]byte, error) {
+	resp, err := s.client.Get(ctx, "/systeminfo/getcert", nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read certificate: %w", err)
+	}
+	return data, nil
+}
+
 // GetConfig retrieves Harbor configuration settings.
-func (s *SystemService) GetConfig() (map[string]interface{}, error) {
-	resp, err := s.client.Get("/configurations", nil)
+func (s *SystemService) GetConfig(ctx context.Context) (map[string]interface{}, error) {
+	resp, err := s.client.Get(ctx, "/configurations", nil)
 	if err != nil {
 		return nil, err
 	}
@@ -76,8 +94,8 @@ func (s *SystemService) GetConfig() (map[string]interface{}, error) {
 }
 
 // UpdateConfig updates Harbor configuration settings.
-func (s *SystemService) UpdateConfig(cfg map[string]interface{}) error {
-	resp, err := s.client.Put("/configurations", cfg)
+func (s *SystemService) UpdateConfig(ctx context.Context, cfg map[string]interface{}) error {
+	resp, err := s.client.Put(ctx, "/configurations", cfg)
 	if err != nil {
 		return err
 	}
@@ -91,11 +109,11 @@ func (s *SystemService) UpdateConfig(cfg map[string]interface{}) error {
 }
 
 // ScheduleGC triggers a manual garbage collection job.
-func (s *SystemService) ScheduleGC() error {
+func (s *SystemService) ScheduleGC(ctx context.Context) error {
 	body := map[string]interface{}{
 		"schedule": map[string]string{"type": "Manual"},
 	}
-	resp, err := s.client.Post("/system/gc/schedule", body)
+	resp, err := s.client.Post(ctx, "/system/gc/schedule", body)
 	if err != nil {
 		return err
 	}
@@ -107,8 +125,8 @@ func (s *SystemService) ScheduleGC() error {
 }
 
 // GetGCHistory retrieves history of garbage collection executions.
-func (s *SystemService) GetGCHistory() ([]*api.GCHistory, error) {
-	resp, err := s.client.Get("/system/gc", nil)
+func (s *SystemService) GetGCHistory(ctx context.Context) ([]*api.GCHistory, error) {
+	resp, err := s.client.Get(ctx, "/system/gc", nil)
 	if err != nil {
 		return nil, err
 	}
@@ -120,8 +138,8 @@ func (s *SystemService) GetGCHistory() ([]*api.GCHistory, error) {
 }
 
 // GetGC retrieves a garbage collection execution by id.
-func (s *SystemService) GetGC(id int64) (*api.GCHistory, error) {
-	resp, err := s.client.Get(fmt.Sprintf("/system/gc/%d", id), nil)
+func (s *SystemService) GetGC(ctx context.Context, id int64) (*api.GCHistory, error) {
+	resp, err := s.client.Get(ctx, fmt.Sprintf("/system/gc/%d", id), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -1,6 +1,7 @@
 package harbor
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"net/url"
@@ -21,23 +22,9 @@ func NewUserService(client *api.Client) *UserService {
 }
 
 // List lists users
-func (s *UserService) List(opts *api.ListOptions) ([]*api.User, error) {
-	params := make(map[string]string)
-	if opts != nil {
-		if opts.Page > 0 {
-			params["page"] = strconv.Itoa(opts.Page)
-		}
-		if opts.PageSize > 0 {
-			params["page_size"] = strconv.Itoa(opts.PageSize)
-		}
-		if opts.Query != "" {
-			params["q"] = opts.Query
-		}
-		if opts.Sort != "" {
-			params["sort"] = opts.Sort
-		}
-	}
-	resp, err := s.client.Get("/users", params)
+func (s *UserService) List(ctx context.Context, opts *api.ListOptions) ([]*api.User, error) {
+	params := listParams(opts)
+	resp, err := s.client.Get(ctx, "/users", params)
 	if err != nil {
 		return nil, err
 	}
@@ -48,18 +35,16 @@ func (s *UserService) List(opts *api.ListOptions) ([]*api.User, error) {
 	return users, nil
 }
 
+// ListAll lists users across all pages
+func (s *UserService) ListAll(ctx context.Context, opts *api.ListOptions, limit int) ([]*api.User, error) {
+	return api.ListAll[*api.User](ctx, s.client, "/users", listParams(opts), limit)
+}
+
 // Search searches users by username
-func (s *UserService) Search(username string, opts *api.ListOptions) ([]*api.User, error) {
-	params := map[string]string{"username": username}
-	if opts != nil {
-		if opts.Page > 0 {
-			params["page"] = strconv.Itoa(opts.Page)
-		}
-		if opts.PageSize > 0 {
-			params["page_size"] = strconv.Itoa(opts.PageSize)
-		}
-	}
-	resp, err := s.client.Get("/users/search", params)
+func (s *UserService) Search(ctx context.Context, username string, opts *api.ListOptions) ([]*api.User, error) {
+	params := listParams(opts)
+	params["username"] = username
+	resp, err := s.client.Get(ctx, "/users/search", params)
 	if err != nil {
 		return nil, err
 	}
@@ -70,9 +55,29 @@ func (s *UserService) Search(username string, opts *api.ListOptions) ([]*api.Use
 	return users, nil
 }
 
+// SearchAll searches users by username across all pages
+func (s *UserService) SearchAll(ctx context.Context, username string, opts *api.ListOptions, limit int) ([]*api.User, error) {
+	params := listParams(opts)
+	params["username"] = username
+	return api.ListAll[*api.User](ctx, s.client, "/users/search", params, limit)
+}
+
 // Get retrieves a user by ID
-func (s *UserService) Get(id int64) (*api.User, error) {
-	resp, err := s.client.Get(fmt.Sprintf("/users/%d", id), nil)
+func (s *UserService) Get(ctx context.Context, id int64) (*api.User, error) {
+	resp, err := s.client.Get(ctx, fmt.Sprintf("/users/%d", id), nil)
+	if err != nil {
+		return nil, err
+	}
+	var user api.User
+	if err := s.client.DecodeResponse(resp, &user); err != nil {
+		return nil, fmt.Errorf("failed to decode user: %w", err)
+	}
+	return &user, nil
+}
+
+// Current retrieves the user the client is authenticated as
+func (s *UserService) Current(ctx context.Context) (*api.User, error) {
+	resp, err := s.client.Get(ctx, "/users/current", nil)
 	if err != nil {
 		return nil, err
 	}
@@ -84,22 +89,22 @@ func (s *UserService) Get(id int64) (*api.User, error) {
 }
 
 // GetByUsername fetches a user by username
-func (s *UserService) GetByUsername(username string) (*api.User, error) {
-	users, err := s.Search(username, nil)
+func (s *UserService) GetByUsername(ctx context.Context, username string) (*api.User, error) {
+	users, err := s.Search(ctx, username, nil)
 	if err != nil {
 		return nil, err
 	}
 	for _, u := range users {
 		if u.Username == username {
-			return s.Get(int64(u.UserID))
+			return s.Get(ctx, int64(u.UserID))
 		}
 	}
 	return nil, fmt.Errorf("user '%s' not found", username)
 }
 
 // Create creates a new user
-func (s *UserService) Create(req *api.UserReq) (*api.User, error) {
-	resp, err := s.client.Post("/users", req)
+func (s *UserService) Create(ctx context.Context, req *api.UserReq) (*api.User, error) {
+	resp, err := s.client.Post(ctx, "/users", req)
 	if err != nil {
 		return nil, err
 	}
@@ -127,12 +132,12 @@ func (s *UserService) Create(req *api.UserReq) (*api.User, error) {
 		return nil, fmt.Errorf("invalid user ID in location: %s", location)
 	}
 
-	return s.Get(id)
+	return s.Get(ctx, id)
 }
 
 // Delete deletes a user by ID
-func (s *UserService) Delete(id int64) error {
-	resp, err := s.client.Delete(fmt.Sprintf("/users/%d", id))
+func (s *UserService) Delete(ctx context.Context, id int64) error {
+	resp, err := s.client.Delete(ctx, fmt.Sprintf("/users/%d", id))
 	if err != nil {
 		return err
 	}
@@ -144,9 +149,9 @@ func (s *UserService) Delete(id int64) error {
 }
 
 // SetAdmin toggles admin role for a user
-func (s *UserService) SetAdmin(id int64, admin bool) error {
+func (s *UserService) SetAdmin(ctx context.Context, id int64, admin bool) error {
 	flag := &api.SysAdminFlag{SysadminFlag: admin}
-	resp, err := s.client.Put(fmt.Sprintf("/users/%d/sysadmin", id), flag)
+	resp, err := s.client.Put(ctx, fmt.Sprintf("/users/%d/sysadmin", id), flag)
 	if err != nil {
 		return err
 	}
@@ -158,8 +163,8 @@ func (s *UserService) SetAdmin(id int64, admin bool) error {
 }
 
 // UpdateProfile updates user's profile fields
-func (s *UserService) UpdateProfile(id int64, profile *api.UserProfile) error {
-	resp, err := s.client.Put(fmt.Sprintf("/users/%d", id), profile)
+func (s *UserService) UpdateProfile(ctx context.Context, id int64, profile *api.UserProfile) error {
+	resp, err := s.client.Put(ctx, fmt.Sprintf("/users/%d", id), profile)
 	if err != nil {
 		return err
 	}
@@ -1,6 +1,7 @@
 package harbor
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -31,7 +32,7 @@ func TestUserServiceCreateParsesLocation(t *testing.T) {
 	}
 
 	svc := NewUserService(client)
-	user, err := svc.Create(&api.UserReq{Username: "foo", Email: "bar@example.com", Password: "x"})
+	user, err := svc.Create(context.Background(), &api.UserReq{Username: "foo", Email: "bar@example.com", Password: "x"})
 	if err != nil {
 		t.Fatalf("Create error: %v", err)
 	}
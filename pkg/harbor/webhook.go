@@ -0,0 +1,223 @@
+package harbor
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/pascal71/hrbcli/pkg/api"
+)
+
+// WebhookService handles project webhook policies and their executions
+type WebhookService struct {
+	client *api.Client
+}
+
+// NewWebhookService creates a new WebhookService
+func NewWebhookService(client *api.Client) *WebhookService {
+	return &WebhookService{client: client}
+}
+
+// NormalizeEventTypes upper-cases, de-duplicates and sorts webhook event
+// types so that "push_artifact" and "PUSH_ARTIFACT" are equivalent. A nil
+// slice stays nil.
+func NormalizeEventTypes(events []string) []string {
+	if events == nil {
+		return nil
+	}
+	seen := map[string]bool{}
+	out := make([]string, 0, len(events))
+	for _, e := range events {
+		e = strings.ToUpper(strings.TrimSpace(e))
+		if e != "" && !seen[e] {
+			seen[e] = true
+			out = append(out, e)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+func webhookPath(project string) string {
+	return fmt.Sprintf("/projects/%s/webhook", url.PathEscape(project))
+}
+
+// ListPolicies lists webhook policies of a project
+func (s *WebhookService) ListPolicies(ctx context.Context, project string, opts *api.ListOptions) ([]*api.WebhookPolicy, error) {
+	params := listParams(opts)
+
+	resp, err := s.client.Get(ctx, webhookPath(project)+"/policies", params)
+	if err != nil {
+		return nil, err
+	}
+
+	var policies []*api.WebhookPolicy
+	if err := s.client.DecodeResponse(resp, &policies); err != nil {
+		return nil, fmt.Errorf("failed to decode webhook policies: %w", err)
+	}
+	return policies, nil
+}
+
+// ListAllPolicies lists webhook policies of a project across all pages
+func (s *WebhookService) ListAllPolicies(ctx context.Context, project string, opts *api.ListOptions, limit int) ([]*api.WebhookPolicy, error) {
+	return api.ListAll[*api.WebhookPolicy](ctx, s.client, webhookPath(project)+"/policies", listParams(opts), limit)
+}
+
+// GetPolicy retrieves a webhook policy by ID
+func (s *WebhookService) GetPolicy(ctx context.Context, project string, id int64) (*api.WebhookPolicy, error) {
+	resp, err := s.client.Get(ctx, fmt.Sprintf("%s/policies/%d", webhookPath(project), id), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var policy api.WebhookPolicy
+	if err := s.client.DecodeResponse(resp, &policy); err != nil {
+		return nil, fmt.Errorf("failed to decode webhook policy: %w", err)
+	}
+	return &policy, nil
+}
+
+// ResolvePolicyID resolves a webhook policy ID from its name
+func (s *WebhookService) ResolvePolicyID(ctx context.Context, project, name string) (int64, error) {
+	policies, err := s.ListAllPolicies(ctx, project, &api.ListOptions{Query: "name=~" + name}, 0)
+	if err != nil {
+		return 0, err
+	}
+	for _, p := range policies {
+		if p.Name == name {
+			return p.ID, nil
+		}
+	}
+	return 0, fmt.Errorf("webhook policy '%s' not found in project '%s'", name, project)
+}
+
+// CreatePolicy creates a webhook policy in a project
+func (s *WebhookService) CreatePolicy(ctx context.Context, project string, policy *api.WebhookPolicy) (*api.WebhookPolicy, error) {
+	resp, err := s.client.Post(ctx, webhookPath(project)+"/policies", policy)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	id, err := parseLocationID(resp.Header.Get("Location"))
+	if err != nil {
+		return nil, err
+	}
+	return s.GetPolicy(ctx, project, id)
+}
+
+// UpdatePolicy updates a webhook policy
+func (s *WebhookService) UpdatePolicy(ctx context.Context, project string, id int64, policy *api.WebhookPolicy) error {
+	resp, err := s.client.Put(ctx, fmt.Sprintf("%s/policies/%d", webhookPath(project), id), policy)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// DeletePolicy deletes a webhook policy
+func (s *WebhookService) DeletePolicy(ctx context.Context, project string, id int64) error {
+	resp, err := s.client.Delete(ctx, fmt.Sprintf("%s/policies/%d", webhookPath(project), id))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// ListEventTypes lists the event and notify types supported for webhooks
+func (s *WebhookService) ListEventTypes(ctx context.Context, project string) (*api.SupportedWebhookEventTypes, error) {
+	resp, err := s.client.Get(ctx, webhookPath(project)+"/events", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var events api.SupportedWebhookEventTypes
+	if err := s.client.DecodeResponse(resp, &events); err != nil {
+		return nil, fmt.Errorf("failed to decode webhook event types: %w", err)
+	}
+	return &events, nil
+}
+
+func webhookExecutionsPath(project string, policyID int64) string {
+	return fmt.Sprintf("%s/policies/%d/executions", webhookPath(project), policyID)
+}
+
+func webhookTasksPath(project string, policyID, executionID int64) string {
+	return fmt.Sprintf("%s/%d/tasks", webhookExecutionsPath(project, policyID), executionID)
+}
+
+// ListExecutions lists delivery executions of a webhook policy
+func (s *WebhookService) ListExecutions(ctx context.Context, project string, policyID int64, opts *api.ListOptions) ([]*api.WebhookExecution, error) {
+	resp, err := s.client.Get(ctx, webhookExecutionsPath(project, policyID), listParams(opts))
+	if err != nil {
+		return nil, err
+	}
+
+	var execs []*api.WebhookExecution
+	if err := s.client.DecodeResponse(resp, &execs); err != nil {
+		return nil, fmt.Errorf("failed to decode executions: %w", err)
+	}
+	return execs, nil
+}
+
+// ListAllExecutions lists delivery executions of a webhook policy across
+// all pages
+func (s *WebhookService) ListAllExecutions(ctx context.Context, project string, policyID int64, opts *api.ListOptions, limit int) ([]*api.WebhookExecution, error) {
+	return api.ListAll[*api.WebhookExecution](ctx, s.client, webhookExecutionsPath(project, policyID), listParams(opts), limit)
+}
+
+// ListTasks lists the tasks of a webhook execution
+func (s *WebhookService) ListTasks(ctx context.Context, project string, policyID, executionID int64, opts *api.ListOptions) ([]*api.WebhookTask, error) {
+	resp, err := s.client.Get(ctx, webhookTasksPath(project, policyID, executionID), listParams(opts))
+	if err != nil {
+		return nil, err
+	}
+
+	var tasks []*api.WebhookTask
+	if err := s.client.DecodeResponse(resp, &tasks); err != nil {
+		return nil, fmt.Errorf("failed to decode tasks: %w", err)
+	}
+	return tasks, nil
+}
+
+// ListAllTasks lists the tasks of a webhook execution across all pages
+func (s *WebhookService) ListAllTasks(ctx context.Context, project string, policyID, executionID int64, opts *api.ListOptions, limit int) ([]*api.WebhookTask, error) {
+	return api.ListAll[*api.WebhookTask](ctx, s.client, webhookTasksPath(project, policyID, executionID), listParams(opts), limit)
+}
+
+// GetTaskLog retrieves the log of a webhook task
+func (s *WebhookService) GetTaskLog(ctx context.Context, project string, policyID, executionID, taskID int64) (string, error) {
+	path := fmt.Sprintf("%s/policies/%d/executions/%d/tasks/%d/log", webhookPath(project), policyID, executionID, taskID)
+	resp, err := s.client.Get(ctx, path, nil)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+	buf, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read log: %w", err)
+	}
+	return string(buf), nil
+}
@@ -0,0 +1,17 @@
+package harbor
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNormalizeEventTypes(t *testing.T) {
+	got := NormalizeEventTypes([]string{" push_artifact", "PULL_ARTIFACT", "", "Push_Artifact"})
+	want := []string{"PULL_ARTIFACT", "PUSH_ARTIFACT"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	if NormalizeEventTypes(nil) != nil {
+		t.Fatal("expected nil to stay nil")
+	}
+}
@@ -1,6 +1,7 @@
 package output
 
 import (
+	"encoding/csv"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -54,6 +55,16 @@ func YAML(data interface{}) error {
 	return encoder.Encode(data)
 }
 
+// CSV outputs rows as comma separated values. The first row is the
+// header.
+func CSV(rows [][]string) error {
+	w := csv.NewWriter(os.Stdout)
+	if err := w.WriteAll(rows); err != nil {
+		return err
+	}
+	return w.Error()
+}
+
 // WriteFile writes data to the specified path in the given format.
 // Format can be "json" or "yaml". Any other value defaults to JSON.
 func WriteFile(path string, format string, data interface{}) error {
@@ -0,0 +1,79 @@
+package output
+
+import (
+	"encoding/xml"
+	"io"
+	"strings"
+)
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	ClassName string        `xml:"classname,attr"`
+	Name      string        `xml:"name,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// JUnit writes scan results as JUnit XML with one test suite per
+// artifact and one failed test case per finding. Artifacts without
+// findings get a single passing test case.
+func JUnit(w io.Writer, results []ScanResult) error {
+	doc := junitTestSuites{Name: "hrbcli vulnerabilities"}
+	for _, r := range results {
+		suite := junitTestSuite{Name: r.Artifact}
+		for _, f := range r.Findings {
+			text := []string{}
+			if f.Description != "" {
+				text = append(text, f.Description)
+			}
+			text = append(text, f.Links...)
+			suite.TestCases = append(suite.TestCases, junitTestCase{
+				ClassName: r.Artifact,
+				Name:      f.ID + " in " + f.Package,
+				Failure: &junitFailure{
+					Message: f.summary(),
+					Type:    f.Severity,
+					Text:    strings.Join(text, "\n"),
+				},
+			})
+			suite.Failures++
+		}
+		if len(suite.TestCases) == 0 {
+			suite.TestCases = []junitTestCase{{ClassName: r.Artifact, Name: "no vulnerabilities"}}
+		}
+		suite.Tests = len(suite.TestCases)
+		doc.Tests += suite.Tests
+		doc.Failures += suite.Failures
+		doc.Suites = append(doc.Suites, suite)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
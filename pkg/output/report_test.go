@@ -0,0 +1,66 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"testing"
+)
+
+var testResults = []ScanResult{
+	{Artifact: "library/app:1.0", Findings: []Finding{
+		{ID: "CVE-1", Package: "openssl", Version: "3.0.0", FixedVersion: "3.0.1", Severity: "Critical", Links: []string{"https://avd.aquasec.com/nvd/cve-1"}},
+		{ID: "CVE-2", Package: "zlib", Version: "1.2", Severity: "Low"},
+	}},
+	{Artifact: "library/app:1.1", Findings: []Finding{
+		{ID: "CVE-1", Package: "openssl", Version: "3.0.0", FixedVersion: "3.0.1", Severity: "Critical"},
+	}},
+	{Artifact: "library/base:1.0"},
+}
+
+func TestSARIF(t *testing.T) {
+	var buf bytes.Buffer
+	if err := SARIF(&buf, testResults); err != nil {
+		t.Fatal(err)
+	}
+	var log sarifLog
+	if err := json.Unmarshal(buf.Bytes(), &log); err != nil {
+		t.Fatal(err)
+	}
+	run := log.Runs[0]
+	if log.Version != "2.1.0" || len(run.Tool.Driver.Rules) != 2 || len(run.Results) != 3 {
+		t.Fatalf("unexpected log %s", buf.String())
+	}
+	rule := run.Tool.Driver.Rules[0]
+	if rule.HelpURI != "https://avd.aquasec.com/nvd/cve-1" || rule.Properties["security-severity"] != "9.5" {
+		t.Fatalf("unexpected rule %+v", rule)
+	}
+	res := run.Results[2]
+	if res.RuleIndex != 0 || res.Level != "error" || res.Locations[0].PhysicalLocation.ArtifactLocation.URI != "library/app:1.1" {
+		t.Fatalf("unexpected result %+v", res)
+	}
+	if run.Results[1].Level != "note" || run.Results[1].Properties["fixedVersion"] != "" {
+		t.Fatalf("unexpected result %+v", run.Results[1])
+	}
+}
+
+func TestJUnit(t *testing.T) {
+	var buf bytes.Buffer
+	if err := JUnit(&buf, testResults); err != nil {
+		t.Fatal(err)
+	}
+	var doc junitTestSuites
+	if err := xml.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc.Tests != 4 || doc.Failures != 3 || len(doc.Suites) != 3 {
+		t.Fatalf("unexpected document %s", buf.String())
+	}
+	failure := doc.Suites[0].TestCases[0].Failure
+	if failure == nil || failure.Type != "Critical" || failure.Message != "Critical: CVE-1 in openssl 3.0.0 (fixed in 3.0.1)" {
+		t.Fatalf("unexpected failure %+v", failure)
+	}
+	if tc := doc.Suites[2].TestCases[0]; tc.Failure != nil || tc.Name != "no vulnerabilities" {
+		t.Fatalf("expected a passing test case, got %+v", tc)
+	}
+}
@@ -0,0 +1,206 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Finding is a vulnerability as written to SARIF and JUnit reports
+type Finding struct {
+	ID           string
+	Package      string
+	Version      string
+	FixedVersion string
+	Severity     string
+	Description  string
+	Links        []string
+}
+
+// ScanResult holds the findings for one artifact, e.g.
+// "library/nginx:1.25"
+type ScanResult struct {
+	Artifact string
+	Findings []Finding
+}
+
+// summary describes a finding in one line
+func (f Finding) summary() string {
+	msg := fmt.Sprintf("%s: %s in %s %s", f.Severity, f.ID, f.Package, f.Version)
+	if f.FixedVersion != "" {
+		msg += fmt.Sprintf(" (fixed in %s)", f.FixedVersion)
+	}
+	return msg
+}
+
+// link returns the first reference URL of a finding
+func (f Finding) link() string {
+	if len(f.Links) > 0 {
+		return f.Links[0]
+	}
+	return ""
+}
+
+// SARIF log structure, limited to what code scanning tools read
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifText struct {
+	Text     string `json:"text"`
+	Markdown string `json:"markdown,omitempty"`
+}
+
+type sarifRule struct {
+	ID                   string                 `json:"id"`
+	Name                 string                 `json:"name"`
+	ShortDescription     sarifText              `json:"shortDescription"`
+	FullDescription      sarifText              `json:"fullDescription"`
+	HelpURI              string                 `json:"helpUri,omitempty"`
+	Help                 sarifText              `json:"help"`
+	DefaultConfiguration map[string]string      `json:"defaultConfiguration"`
+	Properties           map[string]interface{} `json:"properties"`
+}
+
+type sarifResult struct {
+	RuleID     string                 `json:"ruleId"`
+	RuleIndex  int                    `json:"ruleIndex"`
+	Level      string                 `json:"level"`
+	Message    sarifText              `json:"message"`
+	Locations  []sarifLocation        `json:"locations"`
+	Properties map[string]interface{} `json:"properties,omitempty"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation struct {
+		ArtifactLocation struct {
+			URI string `json:"uri"`
+		} `json:"artifactLocation"`
+		Region struct {
+			StartLine int `json:"startLine"`
+		} `json:"region"`
+	} `json:"physicalLocation"`
+}
+
+// sarifLevel maps a severity to a SARIF result level
+func sarifLevel(severity string) string {
+	switch strings.ToLower(severity) {
+	case "critical", "high":
+		return "error"
+	case "medium":
+		return "warning"
+	default:
+		return "note"
+	}
+}
+
+// securitySeverity maps a severity to the CVSS-like score code scanning
+// uses to rank security results
+func securitySeverity(severity string) string {
+	switch strings.ToLower(severity) {
+	case "critical":
+		return "9.5"
+	case "high":
+		return "8.0"
+	case "medium":
+		return "5.5"
+	case "low":
+		return "2.0"
+	default:
+		return "0.0"
+	}
+}
+
+// SARIF writes scan results as a SARIF 2.1.0 log. Each CVE becomes a
+// rule and each affected package of an artifact a result.
+func SARIF(w io.Writer, results []ScanResult) error {
+	run := sarifRun{
+		Tool: sarifTool{Driver: sarifDriver{
+			Name:           "hrbcli",
+			InformationURI: "https://github.com/pascal71/hrbcli",
+			Rules:          []sarifRule{},
+		}},
+		Results: []sarifResult{},
+	}
+
+	rules := map[string]int{}
+	for _, r := range results {
+		for _, f := range r.Findings {
+			idx, ok := rules[f.ID]
+			if !ok {
+				idx = len(run.Tool.Driver.Rules)
+				rules[f.ID] = idx
+				description := f.Description
+				if description == "" {
+					description = f.ID
+				}
+				help := description
+				markdown := fmt.Sprintf("**%s** (%s)\n\n%s", f.ID, f.Severity, description)
+				if len(f.Links) > 0 {
+					markdown += "\n"
+				}
+				for _, l := range f.Links {
+					help += "\n" + l
+					markdown += fmt.Sprintf("\n- [%s](%s)", l, l)
+				}
+				run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifRule{
+					ID:                   f.ID,
+					Name:                 "Vulnerability",
+					ShortDescription:     sarifText{Text: fmt.Sprintf("%s in %s", f.ID, f.Package)},
+					FullDescription:      sarifText{Text: description},
+					HelpURI:              f.link(),
+					Help:                 sarifText{Text: help, Markdown: markdown},
+					DefaultConfiguration: map[string]string{"level": sarifLevel(f.Severity)},
+					Properties: map[string]interface{}{
+						"security-severity": securitySeverity(f.Severity),
+						"tags":              []string{"security", "vulnerability", strings.ToLower(f.Severity)},
+					},
+				})
+			}
+
+			result := sarifResult{
+				RuleID:    f.ID,
+				RuleIndex: idx,
+				Level:     sarifLevel(f.Severity),
+				Message:   sarifText{Text: fmt.Sprintf("%s\nArtifact: %s", f.summary(), r.Artifact)},
+				Properties: map[string]interface{}{
+					"package":          f.Package,
+					"installedVersion": f.Version,
+					"fixedVersion":     f.FixedVersion,
+					"severity":         f.Severity,
+				},
+			}
+			var loc sarifLocation
+			loc.PhysicalLocation.ArtifactLocation.URI = r.Artifact
+			loc.PhysicalLocation.Region.StartLine = 1
+			result.Locations = []sarifLocation{loc}
+			run.Results = append(run.Results, result)
+		}
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(sarifLog{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs:    []sarifRun{run},
+	})
+}
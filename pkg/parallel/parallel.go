@@ -0,0 +1,93 @@
+package parallel
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// DefaultConcurrency is the number of tasks run at once unless
+// configured otherwise
+const DefaultConcurrency = 8
+
+// Result is the outcome of one task
+type Result[T any] struct {
+	Value T
+	Err   error
+}
+
+// Map calls fn for every item with at most concurrency calls at once.
+// Results are returned in the order of items. A failing task does not
+// stop the others; once ctx is canceled, tasks that have not started
+// fail with the context error. progress may be nil.
+func Map[I, T any](ctx context.Context, items []I, concurrency int, progress *Progress, fn func(ctx context.Context, item I) (T, error)) []Result[T] {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	results := make([]Result[T], len(items))
+	progress.Start(len(items))
+	defer progress.Finish()
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, concurrency)
+	for i, item := range items {
+		sem <- struct{}{}
+		if err := ctx.Err(); err != nil {
+			<-sem
+			results[i].Err = err
+			progress.Step(true)
+			continue
+		}
+		wg.Add(1)
+		go func(i int, item I) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			value, err := fn(ctx, item)
+			results[i] = Result[T]{Value: value, Err: err}
+			progress.Step(err != nil)
+		}(i, item)
+	}
+	wg.Wait()
+	return results
+}
+
+// Failures collects the errors of results in order
+func Failures[T any](results []Result[T]) Errors {
+	var errs Errors
+	for _, r := range results {
+		if r.Err != nil {
+			errs = append(errs, r.Err)
+		}
+	}
+	return errs
+}
+
+// Errors aggregates the failures of a run
+type Errors []error
+
+func (e Errors) Error() string {
+	if len(e) == 1 {
+		return e[0].Error()
+	}
+	lines := []string{fmt.Sprintf("%d operations failed:", len(e))}
+	for _, err := range e {
+		lines = append(lines, "  - "+err.Error())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Unwrap returns the aggregated errors
+func (e Errors) Unwrap() []error {
+	return e
+}
+
+// Err returns the errors, or nil when there are none
+func (e Errors) Err() error {
+	if len(e) == 0 {
+		return nil
+	}
+	return e
+}
@@ -0,0 +1,79 @@
+package parallel
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestMapOrderAndConcurrency(t *testing.T) {
+	items := make([]int, 50)
+	for i := range items {
+		items[i] = i
+	}
+
+	var running, peak int32
+	var buf bytes.Buffer
+	results := Map(context.Background(), items, 4, NewProgress(&buf, "Working"), func(ctx context.Context, i int) (string, error) {
+		n := atomic.AddInt32(&running, 1)
+		for {
+			p := atomic.LoadInt32(&peak)
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+		time.Sleep(time.Millisecond * time.Duration(50-i) / 10)
+		atomic.AddInt32(&running, -1)
+		if i%10 == 3 {
+			return "", fmt.Errorf("item %d failed", i)
+		}
+		return fmt.Sprintf("item %d", i), nil
+	})
+
+	if peak > 4 {
+		t.Fatalf("expected at most 4 concurrent tasks, got %d", peak)
+	}
+	for i, r := range results {
+		if i%10 == 3 {
+			if r.Err == nil {
+				t.Errorf("expected item %d to fail", i)
+			}
+			continue
+		}
+		if r.Err != nil || r.Value != fmt.Sprintf("item %d", i) {
+			t.Errorf("unexpected result %d: %+v", i, r)
+		}
+	}
+
+	errs := Failures(results)
+	if len(errs) != 5 || errs[0].Error() != "item 3 failed" || errs[4].Error() != "item 43 failed" {
+		t.Fatalf("unexpected failures %v", errs)
+	}
+	if !strings.HasPrefix(errs.Error(), "5 operations failed:\n  - item 3 failed") {
+		t.Fatalf("unexpected message %q", errs.Error())
+	}
+	if !strings.Contains(buf.String(), "Working 50/50 (5 failed)") {
+		t.Fatalf("unexpected progress %q", buf.String())
+	}
+}
+
+func TestMapCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	results := Map(ctx, []int{1, 2, 3}, 1, nil, func(ctx context.Context, i int) (int, error) {
+		if i == 1 {
+			cancel()
+		}
+		return i, nil
+	})
+	if results[0].Err != nil || !errors.Is(results[1].Err, context.Canceled) || !errors.Is(results[2].Err, context.Canceled) {
+		t.Fatalf("unexpected results %+v", results)
+	}
+	if err := Failures(results[:1]).Err(); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+}
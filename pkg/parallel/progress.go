@@ -0,0 +1,68 @@
+package parallel
+
+import (
+	"fmt"
+	"io"
+	"sync"
+)
+
+// Progress shows how many tasks of a run are done on a single,
+// continuously rewritten line. A nil Progress shows nothing.
+type Progress struct {
+	w     io.Writer
+	label string
+
+	mu     sync.Mutex
+	total  int
+	done   int
+	failed int
+}
+
+// NewProgress creates a progress indicator writing to w, usually a
+// terminal on stderr
+func NewProgress(w io.Writer, label string) *Progress {
+	return &Progress{w: w, label: label}
+}
+
+// Start resets the progress for a run of total tasks
+func (p *Progress) Start(total int) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.total, p.done, p.failed = total, 0, 0
+	p.render()
+}
+
+// Step records a finished task
+func (p *Progress) Step(failed bool) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.done++
+	if failed {
+		p.failed++
+	}
+	p.render()
+}
+
+// Finish clears the line
+func (p *Progress) Finish() {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	fmt.Fprint(p.w, "\r\033[K")
+}
+
+func (p *Progress) render() {
+	line := fmt.Sprintf("\r\033[K%s %d/%d", p.label, p.done, p.total)
+	if p.failed > 0 {
+		line += fmt.Sprintf(" (%d failed)", p.failed)
+	}
+	fmt.Fprint(p.w, line)
+}
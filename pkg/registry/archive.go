@@ -0,0 +1,179 @@
+package registry
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// IsArchive reports whether path names a tarball (.tar, .tar.gz or
+// .tgz) rather than a directory
+func IsArchive(path string) bool {
+	return strings.HasSuffix(path, ".tar") || isGzipArchive(path)
+}
+
+func isGzipArchive(path string) bool {
+	return strings.HasSuffix(path, ".tar.gz") || strings.HasSuffix(path, ".tgz")
+}
+
+// OpenSource opens an OCI image layout or docker-save archive, either a
+// directory or a tarball, optionally gzipped. Tarballs are extracted to a
+// temporary directory that cleanup removes.
+func OpenSource(path string) (src Source, cleanup func(), err error) {
+	cleanup = func() {}
+	dir := path
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, cleanup, err
+	}
+	if !info.IsDir() {
+		if dir, err = os.MkdirTemp("", "hrbcli-image-"); err != nil {
+			return nil, cleanup, err
+		}
+		cleanup = func() { os.RemoveAll(dir) }
+		if err := extract(path, dir); err != nil {
+			cleanup()
+			return nil, func() {}, err
+		}
+	}
+
+	// Docker 25 and later save an OCI layout next to manifest.json
+	switch {
+	case IsLayout(dir):
+		src, err = OpenLayout(dir)
+	case IsDockerArchive(dir):
+		src, err = OpenDockerArchive(dir)
+	default:
+		err = fmt.Errorf("%s is neither an OCI image layout nor a docker archive", path)
+	}
+	if err != nil {
+		cleanup()
+		return nil, func() {}, err
+	}
+	return src, cleanup, nil
+}
+
+// extract unpacks the regular files and directories of a tarball into dir
+func extract(path, dir string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var r io.Reader = bufio.NewReader(f)
+	if magic, _ := r.(*bufio.Reader).Peek(2); bytes.Equal(magic, []byte{0x1f, 0x8b}) {
+		gz, err := gzip.NewReader(r)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	root := filepath.Clean(dir) + string(filepath.Separator)
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		target := filepath.Join(dir, filepath.FromSlash(hdr.Name))
+		if !strings.HasPrefix(target+string(filepath.Separator), root) {
+			return fmt.Errorf("invalid path %q in %s", hdr.Name, path)
+		}
+
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return err
+			}
+			out, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+			if err != nil {
+				return err
+			}
+			if _, err := io.Copy(out, tr); err != nil {
+				out.Close()
+				return err
+			}
+			if err := out.Close(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// WriteArchive packs the files below dir into a tarball at path, gzipped
+// when path ends in .tar.gz or .tgz
+func WriteArchive(path, dir string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var w io.Writer = f
+	var gz *gzip.Writer
+	if isGzipArchive(path) {
+		gz = gzip.NewWriter(f)
+		w = gz
+	}
+	tw := tar.NewWriter(w)
+
+	err = filepath.Walk(dir, func(file string, info os.FileInfo, err error) error {
+		if err != nil || file == dir {
+			return err
+		}
+		name, err := filepath.Rel(dir, file)
+		if err != nil {
+			return err
+		}
+		hdr, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		hdr.Name = filepath.ToSlash(name)
+		if info.IsDir() {
+			hdr.Name += "/"
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		in, err := os.Open(file)
+		if err != nil {
+			return err
+		}
+		defer in.Close()
+		_, err = io.Copy(tw, in)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	if gz != nil {
+		if err := gz.Close(); err != nil {
+			return err
+		}
+	}
+	return f.Close()
+}
@@ -0,0 +1,272 @@
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/pascal71/hrbcli/pkg/api"
+)
+
+// Client talks the OCI Distribution v2 protocol to Harbor's /v2/
+// endpoint. Bearer tokens are obtained from the realm Harbor announces,
+// normally /service/token, and cached per scope.
+type Client struct {
+	BaseURL    string
+	Username   string
+	Password   string
+	HTTPClient *http.Client
+
+	mu        sync.Mutex
+	challenge *challenge
+	tokens    map[string]string
+}
+
+// challenge is the parsed WWW-Authenticate header of /v2/
+type challenge struct {
+	scheme string
+	params map[string]string
+}
+
+// NewClient creates a registry client. An empty username accesses
+// public repositories anonymously.
+func NewClient(baseURL, username, password string, httpClient *http.Client) *Client {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return &Client{
+		BaseURL:    strings.TrimRight(baseURL, "/"),
+		Username:   username,
+		Password:   password,
+		HTTPClient: httpClient,
+		tokens:     map[string]string{},
+	}
+}
+
+// NewClientFromAPI creates a registry client for the Harbor instance and
+// credentials of an API client. Transfers are bounded by the request
+// context instead of the API client's timeout.
+func NewClientFromAPI(c *api.Client) *Client {
+	httpClient := *c.HTTPClient
+	httpClient.Timeout = 0
+	return NewClient(c.BaseURL, c.Username, c.Password, &httpClient)
+}
+
+// Host returns the registry host, as used in image references
+func (c *Client) Host() string {
+	u, err := url.Parse(c.BaseURL)
+	if err != nil {
+		return c.BaseURL
+	}
+	return u.Host
+}
+
+// Repository returns a handle on the named repository, e.g.
+// "library/nginx". push requests write access.
+func (c *Client) Repository(name string, push bool) *Repository {
+	actions := "pull"
+	if push {
+		actions = "pull,push"
+	}
+	return &Repository{
+		client:    c,
+		name:      name,
+		scope:     fmt.Sprintf("repository:%s:%s", name, actions),
+		manifests: map[string][]byte{},
+	}
+}
+
+// do sends a request authorized for scope. Requests with a body must be
+// replayable through req.GetBody when the token has to be renewed.
+func (c *Client) do(ctx context.Context, req *http.Request, scope string) (*http.Response, error) {
+	req = req.WithContext(ctx)
+	auth, err := c.authorization(ctx, scope)
+	if err != nil {
+		return nil, err
+	}
+	if auth != "" {
+		req.Header.Set("Authorization", auth)
+	}
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	if resp.StatusCode != http.StatusUnauthorized || auth == "" || (req.Body != nil && req.GetBody == nil) {
+		return resp, nil
+	}
+
+	// The cached token expired; fetch a new one and try once more
+	resp.Body.Close()
+	c.mu.Lock()
+	delete(c.tokens, scope)
+	c.mu.Unlock()
+	if auth, err = c.authorization(ctx, scope); err != nil {
+		return nil, err
+	}
+	retry := req.Clone(ctx)
+	if req.GetBody != nil {
+		if retry.Body, err = req.GetBody(); err != nil {
+			return nil, err
+		}
+	}
+	retry.Header.Set("Authorization", auth)
+	resp, err = c.HTTPClient.Do(retry)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	return resp, nil
+}
+
+// authorization returns the Authorization header value for scope
+func (c *Client) authorization(ctx context.Context, scope string) (string, error) {
+	ch, err := c.ping(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	switch ch.scheme {
+	case "basic":
+		if c.Username == "" {
+			return "", nil
+		}
+		req, _ := http.NewRequest(http.MethodGet, c.BaseURL, nil)
+		req.SetBasicAuth(c.Username, c.Password)
+		return req.Header.Get("Authorization"), nil
+	case "bearer":
+		c.mu.Lock()
+		token, ok := c.tokens[scope]
+		c.mu.Unlock()
+		if !ok {
+			if token, err = c.fetchToken(ctx, ch, scope); err != nil {
+				return "", err
+			}
+			c.mu.Lock()
+			c.tokens[scope] = token
+			c.mu.Unlock()
+		}
+		return "Bearer " + token, nil
+	default:
+		return "", nil
+	}
+}
+
+// ping queries /v2/ once to learn how the registry authenticates
+func (c *Client) ping(ctx context.Context) (*challenge, error) {
+	c.mu.Lock()
+	ch := c.challenge
+	c.mu.Unlock()
+	if ch != nil {
+		return ch, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.BaseURL+"/v2/", nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		ch = &challenge{}
+	case http.StatusUnauthorized:
+		ch = parseChallenge(resp.Header.Get("WWW-Authenticate"))
+		if ch.scheme != "basic" && ch.scheme != "bearer" {
+			return nil, fmt.Errorf("unsupported registry authentication %q", resp.Header.Get("WWW-Authenticate"))
+		}
+	default:
+		return nil, fmt.Errorf("%s/v2/ is not an OCI registry: unexpected status code: %d", c.BaseURL, resp.StatusCode)
+	}
+
+	c.mu.Lock()
+	c.challenge = ch
+	c.mu.Unlock()
+	return ch, nil
+}
+
+var challengeParam = regexp.MustCompile(`(\w+)="([^"]*)"`)
+
+// parseChallenge parses a header such as
+// Bearer realm="https://harbor/service/token",service="harbor-registry"
+func parseChallenge(header string) *challenge {
+	scheme, rest, _ := strings.Cut(strings.TrimSpace(header), " ")
+	ch := &challenge{scheme: strings.ToLower(scheme), params: map[string]string{}}
+	for _, m := range challengeParam.FindAllStringSubmatch(rest, -1) {
+		ch.params[strings.ToLower(m[1])] = m[2]
+	}
+	return ch
+}
+
+// fetchToken requests a bearer token for scope from the token realm
+func (c *Client) fetchToken(ctx context.Context, ch *challenge, scope string) (string, error) {
+	realm := ch.params["realm"]
+	if realm == "" {
+		return "", fmt.Errorf("registry did not announce a token realm")
+	}
+	u, err := url.Parse(realm)
+	if err != nil {
+		return "", fmt.Errorf("invalid token realm %q: %w", realm, err)
+	}
+	q := u.Query()
+	if service := ch.params["service"]; service != "" {
+		q.Set("service", service)
+	}
+	q.Set("scope", scope)
+	u.RawQuery = q.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	if c.Username != "" {
+		req.SetBasicAuth(c.Username, c.Password)
+	}
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", responseError(resp)
+	}
+
+	var token struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return "", fmt.Errorf("failed to decode token: %w", err)
+	}
+	if token.Token == "" {
+		token.Token = token.AccessToken
+	}
+	if token.Token == "" {
+		return "", fmt.Errorf("token response did not contain a token")
+	}
+	return token.Token, nil
+}
+
+// responseError turns an unexpected response into an *api.APIError,
+// keeping the registry's error message
+func responseError(resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	apiErr := &api.APIError{Code: resp.StatusCode, Message: strings.TrimSpace(string(body))}
+	if msg := apiErr.FriendlyMessage(); msg != "" {
+		apiErr.Message = msg
+	}
+	if apiErr.Message == "" {
+		apiErr.Message = http.StatusText(resp.StatusCode)
+	}
+	return apiErr
+}
@@ -0,0 +1,171 @@
+package registry
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+)
+
+// maxManifestSize bounds the manifests read into memory
+const maxManifestSize = 4 << 20
+
+// Source is where content is copied from: a remote repository, an OCI
+// image layout or a docker-save archive
+type Source interface {
+	// Resolve returns the descriptor of the manifest named by ref, a tag
+	// or digest. An empty ref selects the only manifest of a layout.
+	Resolve(ctx context.Context, ref string) (Descriptor, error)
+	// Fetch returns the content of a manifest or blob
+	Fetch(ctx context.Context, desc Descriptor) (io.ReadCloser, error)
+}
+
+// Target is where content is copied to
+type Target interface {
+	// Exists reports whether the blob or manifest is already present
+	Exists(ctx context.Context, desc Descriptor) (bool, error)
+	// PushBlob stores a config or layer blob
+	PushBlob(ctx context.Context, desc Descriptor, r io.Reader) error
+	// PushManifest stores a manifest or index and, when ref is not
+	// empty, tags it
+	PushManifest(ctx context.Context, desc Descriptor, data []byte, ref string) error
+}
+
+// CopyOptions tunes Copy
+type CopyOptions struct {
+	// Progress is called for every blob, with skipped set when the
+	// target already had it
+	Progress func(desc Descriptor, skipped bool)
+}
+
+// Copy copies the manifest named srcRef, everything it references and,
+// for an index, all platform manifests, from src to dst and tags the
+// result dstRef. Blobs present in dst are not transferred again.
+func Copy(ctx context.Context, src Source, srcRef string, dst Target, dstRef string, opts CopyOptions) (Descriptor, error) {
+	root, err := src.Resolve(ctx, srcRef)
+	if err != nil {
+		return Descriptor{}, err
+	}
+	c := &copier{src: src, dst: dst, opts: opts, done: map[string]bool{}}
+	if err := c.copyManifest(ctx, root, dstRef); err != nil {
+		return Descriptor{}, err
+	}
+	return root, nil
+}
+
+type copier struct {
+	src  Source
+	dst  Target
+	opts CopyOptions
+	done map[string]bool
+}
+
+func (c *copier) copyManifest(ctx context.Context, desc Descriptor, ref string) error {
+	data, err := FetchManifest(ctx, c.src, desc)
+	if err != nil {
+		return err
+	}
+	refs, err := children(desc, data)
+	if err != nil {
+		return err
+	}
+
+	for _, child := range refs {
+		if IsIndex(desc.MediaType) {
+			if !IsManifest(child.MediaType) {
+				return fmt.Errorf("unsupported media type %q in index %s", child.MediaType, desc.Digest)
+			}
+			if err := c.copyManifest(ctx, child, ""); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := c.copyBlob(ctx, child); err != nil {
+			return err
+		}
+	}
+
+	if err := c.dst.PushManifest(ctx, desc, data, ref); err != nil {
+		return fmt.Errorf("failed to push manifest %s: %w", desc.Digest, err)
+	}
+	return nil
+}
+
+func (c *copier) copyBlob(ctx context.Context, desc Descriptor) error {
+	if c.done[desc.Digest] {
+		return nil
+	}
+	c.done[desc.Digest] = true
+
+	exists, err := c.dst.Exists(ctx, desc)
+	if err != nil {
+		return err
+	}
+	if c.opts.Progress != nil {
+		c.opts.Progress(desc, exists)
+	}
+	if exists {
+		return nil
+	}
+
+	r, err := c.src.Fetch(ctx, desc)
+	if err != nil {
+		return fmt.Errorf("failed to fetch blob %s: %w", desc.Digest, err)
+	}
+	defer r.Close()
+	if err := c.dst.PushBlob(ctx, desc, newVerifier(r, desc)); err != nil {
+		return fmt.Errorf("failed to push blob %s: %w", desc.Digest, err)
+	}
+	return nil
+}
+
+// FetchManifest reads a manifest from src and verifies its digest
+func FetchManifest(ctx context.Context, src Source, desc Descriptor) ([]byte, error) {
+	r, err := src.Fetch(ctx, desc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch manifest %s: %w", desc.Digest, err)
+	}
+	defer r.Close()
+	data, err := io.ReadAll(io.LimitReader(r, maxManifestSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest %s: %w", desc.Digest, err)
+	}
+	if len(data) > maxManifestSize {
+		return nil, fmt.Errorf("manifest %s exceeds %d bytes", desc.Digest, maxManifestSize)
+	}
+	if got := Digest(data); got != desc.Digest {
+		return nil, fmt.Errorf("manifest digest mismatch: expected %s, got %s", desc.Digest, got)
+	}
+	return data, nil
+}
+
+// verifier fails a read once the content read exceeds the size of the
+// descriptor or, at the end, does not match its digest, so a corrupt or
+// tampered blob is never stored
+type verifier struct {
+	r    io.Reader
+	desc Descriptor
+	h    hash.Hash
+	n    int64
+}
+
+func newVerifier(r io.Reader, desc Descriptor) *verifier {
+	return &verifier{r: r, desc: desc, h: sha256.New()}
+}
+
+func (v *verifier) Read(p []byte) (int, error) {
+	n, err := v.r.Read(p)
+	v.h.Write(p[:n])
+	v.n += int64(n)
+	if v.n > v.desc.Size {
+		return n, fmt.Errorf("blob %s exceeds %d bytes", v.desc.Digest, v.desc.Size)
+	}
+	if err == io.EOF {
+		if got := "sha256:" + hex.EncodeToString(v.h.Sum(nil)); got != v.desc.Digest || v.n != v.desc.Size {
+			return n, fmt.Errorf("content mismatch: expected %s (%d bytes), got %s (%d bytes)", v.desc.Digest, v.desc.Size, got, v.n)
+		}
+	}
+	return n, err
+}
@@ -0,0 +1,149 @@
+package registry
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const dockerManifestFile = "manifest.json"
+
+// dockerImage is an entry of a docker-save manifest.json
+type dockerImage struct {
+	Config   string   `json:"Config"`
+	RepoTags []string `json:"RepoTags"`
+	Layers   []string `json:"Layers"`
+}
+
+// DockerArchive is an extracted `docker save` archive. Its images are
+// presented as OCI manifests with the layers as saved.
+type DockerArchive struct {
+	root   string
+	images []dockerImage
+	// blobs maps digests to files of the archive, manifests to the
+	// synthesized manifests
+	blobs     map[string]string
+	manifests map[string][]byte
+}
+
+// IsDockerArchive reports whether dir holds an extracted docker-save
+// archive
+func IsDockerArchive(dir string) bool {
+	_, err := os.Stat(filepath.Join(dir, dockerManifestFile))
+	return err == nil
+}
+
+// OpenDockerArchive opens an extracted docker-save archive
+func OpenDockerArchive(dir string) (*DockerArchive, error) {
+	data, err := os.ReadFile(filepath.Join(dir, dockerManifestFile))
+	if err != nil {
+		return nil, fmt.Errorf("%s is not a docker archive: %w", dir, err)
+	}
+	a := &DockerArchive{root: dir, blobs: map[string]string{}, manifests: map[string][]byte{}}
+	if err := json.Unmarshal(data, &a.images); err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", dockerManifestFile, err)
+	}
+	if len(a.images) == 0 {
+		return nil, fmt.Errorf("docker archive %s holds no images", dir)
+	}
+	return a, nil
+}
+
+// Resolve finds an image by one of its repository tags, either in full
+// ("app:1.0") or by tag alone ("1.0"). An empty ref selects the only
+// image in the archive.
+func (a *DockerArchive) Resolve(ctx context.Context, ref string) (Descriptor, error) {
+	image, err := a.find(ref)
+	if err != nil {
+		return Descriptor{}, err
+	}
+
+	config, err := a.describe(image.Config, MediaTypeOCIConfig)
+	if err != nil {
+		return Descriptor{}, err
+	}
+	manifest := Manifest{SchemaVersion: 2, MediaType: MediaTypeOCIManifest, Config: config, Layers: []Descriptor{}}
+	for _, layer := range image.Layers {
+		desc, err := a.describe(layer, "")
+		if err != nil {
+			return Descriptor{}, err
+		}
+		manifest.Layers = append(manifest.Layers, desc)
+	}
+
+	data, err := json.Marshal(manifest)
+	if err != nil {
+		return Descriptor{}, err
+	}
+	desc := Descriptor{MediaType: MediaTypeOCIManifest, Digest: Digest(data), Size: int64(len(data))}
+	a.manifests[desc.Digest] = data
+	return desc, nil
+}
+
+func (a *DockerArchive) find(ref string) (dockerImage, error) {
+	if ref == "" {
+		if len(a.images) != 1 {
+			return dockerImage{}, fmt.Errorf("docker archive %s holds %d images; name one of them", a.root, len(a.images))
+		}
+		return a.images[0], nil
+	}
+	for _, image := range a.images {
+		for _, tag := range image.RepoTags {
+			if tag == ref || strings.HasSuffix(tag, ":"+ref) {
+				return image, nil
+			}
+		}
+	}
+	return dockerImage{}, fmt.Errorf("docker archive %s has no image %q", a.root, ref)
+}
+
+// describe hashes a file of the archive. Layers get the gzip or plain
+// tar media type depending on their content.
+func (a *DockerArchive) describe(name, mediaType string) (Descriptor, error) {
+	path := filepath.Join(a.root, filepath.FromSlash(name))
+	if !strings.HasPrefix(path, filepath.Clean(a.root)+string(filepath.Separator)) {
+		return Descriptor{}, fmt.Errorf("invalid path %q in %s", name, dockerManifestFile)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return Descriptor{}, err
+	}
+	defer f.Close()
+
+	br := bufio.NewReader(f)
+	if mediaType == "" {
+		mediaType = MediaTypeOCILayer
+		if magic, _ := br.Peek(2); bytes.Equal(magic, []byte{0x1f, 0x8b}) {
+			mediaType = MediaTypeOCILayerGzip
+		}
+	}
+	h := sha256.New()
+	n, err := io.Copy(h, br)
+	if err != nil {
+		return Descriptor{}, fmt.Errorf("failed to read %s: %w", name, err)
+	}
+
+	desc := Descriptor{MediaType: mediaType, Digest: "sha256:" + hex.EncodeToString(h.Sum(nil)), Size: n}
+	a.blobs[desc.Digest] = path
+	return desc, nil
+}
+
+// Fetch returns a synthesized manifest or a file of the archive
+func (a *DockerArchive) Fetch(ctx context.Context, desc Descriptor) (io.ReadCloser, error) {
+	if data, ok := a.manifests[desc.Digest]; ok {
+		return io.NopCloser(bytes.NewReader(data)), nil
+	}
+	path, ok := a.blobs[desc.Digest]
+	if !ok {
+		return nil, fmt.Errorf("docker archive %s has no blob %s", a.root, desc.Digest)
+	}
+	return os.Open(path)
+}
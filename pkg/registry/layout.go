@@ -0,0 +1,208 @@
+package registry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+const (
+	layoutFile    = "oci-layout"
+	indexFile     = "index.json"
+	layoutVersion = "1.0.0"
+)
+
+// Layout is an OCI image layout directory. It is both a Source and a
+// Target.
+type Layout struct {
+	root string
+
+	mu    sync.Mutex
+	index Index
+}
+
+// IsLayout reports whether dir holds an OCI image layout
+func IsLayout(dir string) bool {
+	_, err := os.Stat(filepath.Join(dir, layoutFile))
+	return err == nil
+}
+
+// OpenLayout opens an existing OCI image layout
+func OpenLayout(dir string) (*Layout, error) {
+	data, err := os.ReadFile(filepath.Join(dir, layoutFile))
+	if err != nil {
+		return nil, fmt.Errorf("%s is not an OCI image layout: %w", dir, err)
+	}
+	var marker struct {
+		ImageLayoutVersion string `json:"imageLayoutVersion"`
+	}
+	if err := json.Unmarshal(data, &marker); err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", layoutFile, err)
+	}
+	if marker.ImageLayoutVersion != layoutVersion {
+		return nil, fmt.Errorf("unsupported image layout version %q", marker.ImageLayoutVersion)
+	}
+
+	l := &Layout{root: dir}
+	data, err = os.ReadFile(filepath.Join(dir, indexFile))
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &l.index); err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", indexFile, err)
+	}
+	return l, nil
+}
+
+// NewLayout opens the OCI image layout in dir, creating it when dir does
+// not exist or is empty
+func NewLayout(dir string) (*Layout, error) {
+	if IsLayout(dir) {
+		return OpenLayout(dir)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	if len(entries) > 0 {
+		return nil, fmt.Errorf("%s exists and is not an OCI image layout", dir)
+	}
+
+	if err := os.MkdirAll(filepath.Join(dir, "blobs", "sha256"), 0755); err != nil {
+		return nil, err
+	}
+	marker := fmt.Sprintf(`{"imageLayoutVersion":%q}`, layoutVersion)
+	if err := os.WriteFile(filepath.Join(dir, layoutFile), []byte(marker), 0644); err != nil {
+		return nil, err
+	}
+	l := &Layout{root: dir, index: Index{SchemaVersion: 2, MediaType: MediaTypeOCIIndex, Manifests: []Descriptor{}}}
+	if err := l.writeIndex(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// Manifests returns the entries of index.json
+func (l *Layout) Manifests() []Descriptor {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]Descriptor(nil), l.index.Manifests...)
+}
+
+func (l *Layout) blobPath(digest string) (string, error) {
+	encoded, err := splitDigest(digest)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(l.root, "blobs", "sha256", encoded), nil
+}
+
+// Resolve finds a manifest by its ref name annotation or digest. An
+// empty ref selects the only manifest in the layout.
+func (l *Layout) Resolve(ctx context.Context, ref string) (Descriptor, error) {
+	manifests := l.Manifests()
+	if ref == "" {
+		if len(manifests) != 1 {
+			return Descriptor{}, fmt.Errorf("image layout %s holds %d manifests; name one of them", l.root, len(manifests))
+		}
+		return manifests[0], nil
+	}
+	for _, m := range manifests {
+		if m.Digest == ref || m.Annotations[AnnotationRefName] == ref {
+			return m, nil
+		}
+	}
+	return Descriptor{}, fmt.Errorf("image layout %s has no manifest %q", l.root, ref)
+}
+
+// Fetch opens a blob of the layout
+func (l *Layout) Fetch(ctx context.Context, desc Descriptor) (io.ReadCloser, error) {
+	path, err := l.blobPath(desc.Digest)
+	if err != nil {
+		return nil, err
+	}
+	return os.Open(path)
+}
+
+// Exists reports whether the layout has the blob
+func (l *Layout) Exists(ctx context.Context, desc Descriptor) (bool, error) {
+	path, err := l.blobPath(desc.Digest)
+	if err != nil {
+		return false, err
+	}
+	info, err := os.Stat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return info.Size() == desc.Size, nil
+}
+
+// PushBlob writes a blob, verifying its digest and size
+func (l *Layout) PushBlob(ctx context.Context, desc Descriptor, r io.Reader) error {
+	path, err := l.blobPath(desc.Digest)
+	if err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(filepath.Dir(path), ".upload-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+
+	if _, err := io.Copy(f, newVerifier(r, desc)); err != nil {
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
+
+// PushManifest writes a manifest blob. When ref is not empty the
+// manifest is also added to index.json, named ref unless ref is a
+// digest, replacing any manifest of the same name.
+func (l *Layout) PushManifest(ctx context.Context, desc Descriptor, data []byte, ref string) error {
+	if err := l.PushBlob(ctx, desc, bytes.NewReader(data)); err != nil {
+		return err
+	}
+	if ref == "" {
+		return nil
+	}
+
+	entry := Descriptor{MediaType: desc.MediaType, Digest: desc.Digest, Size: desc.Size, Platform: desc.Platform}
+	if !strings.HasPrefix(ref, "sha256:") {
+		entry.Annotations = map[string]string{AnnotationRefName: ref}
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	manifests := l.index.Manifests[:0]
+	for _, m := range l.index.Manifests {
+		name := m.Annotations[AnnotationRefName]
+		if (entry.Annotations != nil && name == ref) || (entry.Annotations == nil && name == "" && m.Digest == desc.Digest) {
+			continue
+		}
+		manifests = append(manifests, m)
+	}
+	l.index.Manifests = append(manifests, entry)
+	return l.writeIndex()
+}
+
+func (l *Layout) writeIndex() error {
+	data, err := json.MarshalIndent(l.index, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(l.root, indexFile), data, 0644)
+}
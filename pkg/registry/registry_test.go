@@ -0,0 +1,310 @@
+package registry
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeRegistry is an in-process stand-in for Harbor's registry with
+// bearer token authentication
+type fakeRegistry struct {
+	mu        sync.Mutex
+	blobs     map[string][]byte
+	manifests map[string]fakeManifest // by repo@ref
+	uploads   int
+}
+
+type fakeManifest struct {
+	mediaType string
+	data      []byte
+}
+
+func newFakeRegistry(t *testing.T) (*fakeRegistry, *Client) {
+	t.Helper()
+	reg := &fakeRegistry{blobs: map[string][]byte{}, manifests: map[string]fakeManifest{}}
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/service/token" {
+			user, pass, _ := r.BasicAuth()
+			if user != "admin" || pass != "secret" || r.URL.Query().Get("service") != "harbor-registry" {
+				http.Error(w, `{"errors":[{"code":"UNAUTHORIZED","message":"invalid credentials"}]}`, http.StatusUnauthorized)
+				return
+			}
+			fmt.Fprintf(w, `{"token":"t-%s"}`, r.URL.Query().Get("scope"))
+			return
+		}
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "Bearer t-repository:") {
+			w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%s/service/token",service="harbor-registry"`, server.URL))
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		reg.serve(t, w, r)
+	}))
+	t.Cleanup(server.Close)
+	return reg, NewClient(server.URL, "admin", "secret", server.Client())
+}
+
+func (reg *fakeRegistry) serve(t *testing.T, w http.ResponseWriter, r *http.Request) {
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+
+	path := strings.TrimPrefix(r.URL.Path, "/v2/")
+	switch {
+	case strings.Contains(path, "/manifests/"):
+		repo, ref, _ := strings.Cut(path, "/manifests/")
+		switch r.Method {
+		case http.MethodPut:
+			data, _ := io.ReadAll(r.Body)
+			m := fakeManifest{mediaType: r.Header.Get("Content-Type"), data: data}
+			reg.manifests[repo+"@"+ref] = m
+			reg.manifests[repo+"@"+Digest(data)] = m
+			w.WriteHeader(http.StatusCreated)
+		case http.MethodGet, http.MethodHead:
+			m, ok := reg.manifests[repo+"@"+ref]
+			if !ok {
+				http.Error(w, `{"errors":[{"code":"MANIFEST_UNKNOWN","message":"manifest unknown"}]}`, http.StatusNotFound)
+				return
+			}
+			w.Header().Set("Content-Type", m.mediaType)
+			w.Header().Set("Docker-Content-Digest", Digest(m.data))
+			w.Write(m.data)
+		}
+	case strings.HasSuffix(path, "/blobs/uploads/") && r.Method == http.MethodPost:
+		w.Header().Set("Location", "/v2/"+path+"upload-1?state=abc")
+		w.WriteHeader(http.StatusAccepted)
+	case strings.Contains(path, "/blobs/uploads/") && r.Method == http.MethodPut:
+		data, _ := io.ReadAll(r.Body)
+		digest := r.URL.Query().Get("digest")
+		if Digest(data) != digest || r.URL.Query().Get("state") != "abc" {
+			http.Error(w, "digest invalid", http.StatusBadRequest)
+			return
+		}
+		reg.blobs[digest] = data
+		reg.uploads++
+		w.WriteHeader(http.StatusCreated)
+	case strings.Contains(path, "/blobs/"):
+		_, digest, _ := strings.Cut(path, "/blobs/")
+		data, ok := reg.blobs[digest]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write(data)
+	default:
+		t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+// testImage writes a single-layer image to a new layout in dir
+func testImage(t *testing.T, dir, ref string) (*Layout, Descriptor) {
+	t.Helper()
+	ctx := context.Background()
+	layout, err := NewLayout(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	config := []byte(`{"architecture":"amd64","os":"linux"}`)
+	layer := []byte("layer content")
+	manifest := Manifest{
+		SchemaVersion: 2,
+		MediaType:     MediaTypeOCIManifest,
+		Config:        Descriptor{MediaType: MediaTypeOCIConfig, Digest: Digest(config), Size: int64(len(config))},
+		Layers:        []Descriptor{{MediaType: MediaTypeOCILayer, Digest: Digest(layer), Size: int64(len(layer))}},
+	}
+	for _, blob := range [][]byte{config, layer} {
+		if err := layout.PushBlob(ctx, Descriptor{Digest: Digest(blob), Size: int64(len(blob))}, bytes.NewReader(blob)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	data, _ := json.Marshal(manifest)
+	desc := Descriptor{MediaType: MediaTypeOCIManifest, Digest: Digest(data), Size: int64(len(data))}
+	if err := layout.PushManifest(ctx, desc, data, ref); err != nil {
+		t.Fatal(err)
+	}
+	return layout, desc
+}
+
+func TestPushAndPull(t *testing.T) {
+	ctx := context.Background()
+	reg, client := newFakeRegistry(t)
+	layout, desc := testImage(t, t.TempDir(), "1.0")
+
+	repo := client.Repository("library/app", true)
+	pushed, err := Copy(ctx, layout, "", repo, "1.0", CopyOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pushed.Digest != desc.Digest || reg.uploads != 2 {
+		t.Fatalf("unexpected push %+v with %d uploads", pushed, reg.uploads)
+	}
+
+	// Pushing again transfers nothing
+	var skipped int
+	_, err = Copy(ctx, layout, "1.0", repo, "1.1", CopyOptions{Progress: func(d Descriptor, exists bool) {
+		if exists {
+			skipped++
+		}
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reg.uploads != 2 || skipped != 2 {
+		t.Fatalf("expected blobs to be skipped, got %d uploads and %d skipped", reg.uploads, skipped)
+	}
+
+	// Pull into a tarball and read it back
+	dir := t.TempDir()
+	pulled, err := NewLayout(filepath.Join(dir, "layout"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Copy(ctx, client.Repository("library/app", false), "1.1", pulled, "1.1", CopyOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	archive := filepath.Join(dir, "app.tar.gz")
+	if err := WriteArchive(archive, filepath.Join(dir, "layout")); err != nil {
+		t.Fatal(err)
+	}
+	src, cleanup, err := OpenSource(archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+	got, err := src.Resolve(ctx, "1.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Digest != desc.Digest {
+		t.Fatalf("expected %s, got %s", desc.Digest, got.Digest)
+	}
+	if _, err := FetchManifest(ctx, src, got); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCopyIndex(t *testing.T) {
+	ctx := context.Background()
+	reg, client := newFakeRegistry(t)
+	layout, desc := testImage(t, t.TempDir(), "")
+	desc.Platform = &Platform{Architecture: "amd64", OS: "linux"}
+
+	data, _ := json.Marshal(Index{SchemaVersion: 2, MediaType: MediaTypeOCIIndex, Manifests: []Descriptor{desc}})
+	index := Descriptor{MediaType: MediaTypeOCIIndex, Digest: Digest(data), Size: int64(len(data))}
+	if err := layout.PushManifest(ctx, index, data, "multi"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Copy(ctx, layout, "multi", client.Repository("dev/app", true), "1.0", CopyOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Copy between repositories of the registry
+	src := client.Repository("dev/app", false)
+	if _, err := Copy(ctx, src, "1.0", client.Repository("prod/app", true), "1.0", CopyOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := reg.manifests["prod/app@"+desc.Digest]; !ok {
+		t.Fatalf("platform manifest was not copied")
+	}
+	if m := reg.manifests["prod/app@1.0"]; m.mediaType != MediaTypeOCIIndex || Digest(m.data) != index.Digest {
+		t.Fatalf("unexpected index %+v", m)
+	}
+}
+
+// discardTarget accepts everything without checking it
+type discardTarget struct{}
+
+func (discardTarget) Exists(ctx context.Context, desc Descriptor) (bool, error) { return false, nil }
+
+func (discardTarget) PushBlob(ctx context.Context, desc Descriptor, r io.Reader) error {
+	_, err := io.Copy(io.Discard, r)
+	return err
+}
+
+func (discardTarget) PushManifest(ctx context.Context, desc Descriptor, data []byte, ref string) error {
+	return nil
+}
+
+func TestCopyRejectsTamperedBlob(t *testing.T) {
+	ctx := context.Background()
+	reg, client := newFakeRegistry(t)
+	layout, _ := testImage(t, t.TempDir(), "1.0")
+	if _, err := Copy(ctx, layout, "", client.Repository("library/app", true), "1.0", CopyOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	layer := Digest([]byte("layer content"))
+	for tampered, want := range map[string]string{
+		"layer contenT":          "content mismatch",
+		"layer content and more": "exceeds 13 bytes",
+	} {
+		reg.blobs[layer] = []byte(tampered)
+		_, err := Copy(ctx, client.Repository("library/app", false), "1.0", discardTarget{}, "", CopyOptions{})
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Fatalf("expected %q for %q, got %v", want, tampered, err)
+		}
+	}
+}
+
+func TestPushDockerArchive(t *testing.T) {
+	ctx := context.Background()
+	reg, client := newFakeRegistry(t)
+
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	files := []struct{ name, body string }{
+		{"manifest.json", `[{"Config":"abc.json","RepoTags":["app:1.0"],"Layers":["layer1/layer.tar"]}]`},
+		{"abc.json", `{"architecture":"amd64","os":"linux"}`},
+		{"layer1/layer.tar", "plain layer"},
+	}
+	for _, f := range files {
+		tw.WriteHeader(&tar.Header{Name: f.name, Mode: 0644, Size: int64(len(f.body)), Typeflag: tar.TypeReg})
+		tw.Write([]byte(f.body))
+	}
+	tw.Close()
+	path := filepath.Join(t.TempDir(), "app.tar")
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	src, cleanup, err := OpenSource(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+	if _, err := src.Resolve(ctx, "2.0"); err == nil {
+		t.Fatalf("expected an error for an unknown tag")
+	}
+	if _, err := Copy(ctx, src, "1.0", client.Repository("library/app", true), "1.0", CopyOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	var manifest Manifest
+	if err := json.Unmarshal(reg.manifests["library/app@1.0"].data, &manifest); err != nil {
+		t.Fatal(err)
+	}
+	if len(manifest.Layers) != 1 || manifest.Layers[0].MediaType != MediaTypeOCILayer || manifest.Layers[0].Digest != Digest([]byte("plain layer")) {
+		t.Fatalf("unexpected manifest %+v", manifest)
+	}
+}
+
+func TestTokenAuthFailure(t *testing.T) {
+	_, client := newFakeRegistry(t)
+	client.Password = "wrong"
+	_, err := client.Repository("library/app", false).Resolve(context.Background(), "1.0")
+	if err == nil || !strings.Contains(err.Error(), "invalid credentials") {
+		t.Fatalf("expected an authentication error, got %v", err)
+	}
+}
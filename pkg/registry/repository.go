@@ -0,0 +1,200 @@
+package registry
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// Repository is a repository on the registry. It is both a Source and a
+// Target.
+type Repository struct {
+	client *Client
+	name   string
+	scope  string
+
+	mu        sync.Mutex
+	manifests map[string][]byte
+}
+
+// Name returns the repository name, e.g. "library/nginx"
+func (r *Repository) Name() string {
+	return r.name
+}
+
+func (r *Repository) url(kind, ref string) string {
+	return fmt.Sprintf("%s/v2/%s/%s/%s", r.client.BaseURL, r.name, kind, ref)
+}
+
+// Resolve fetches the manifest named by a tag or digest
+func (r *Repository) Resolve(ctx context.Context, ref string) (Descriptor, error) {
+	if ref == "" {
+		ref = "latest"
+	}
+	req, err := http.NewRequest(http.MethodGet, r.url("manifests", ref), nil)
+	if err != nil {
+		return Descriptor{}, err
+	}
+	req.Header.Set("Accept", strings.Join(manifestMediaTypes, ", "))
+	resp, err := r.client.do(ctx, req, r.scope)
+	if err != nil {
+		return Descriptor{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return Descriptor{}, fmt.Errorf("failed to get manifest %s:%s: %w", r.name, ref, responseError(resp))
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxManifestSize+1))
+	if err != nil {
+		return Descriptor{}, fmt.Errorf("failed to read manifest: %w", err)
+	}
+	if len(data) > maxManifestSize {
+		return Descriptor{}, fmt.Errorf("manifest %s:%s exceeds %d bytes", r.name, ref, maxManifestSize)
+	}
+
+	contentType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	desc := Descriptor{
+		MediaType: manifestMediaType(data, contentType),
+		Digest:    Digest(data),
+		Size:      int64(len(data)),
+	}
+	if !IsManifest(desc.MediaType) {
+		return Descriptor{}, fmt.Errorf("unsupported manifest media type %q", desc.MediaType)
+	}
+	if strings.HasPrefix(ref, "sha256:") && ref != desc.Digest {
+		return Descriptor{}, fmt.Errorf("manifest digest mismatch: expected %s, got %s", ref, desc.Digest)
+	}
+
+	r.mu.Lock()
+	r.manifests[desc.Digest] = data
+	r.mu.Unlock()
+	return desc, nil
+}
+
+// Fetch returns the content of a manifest or blob
+func (r *Repository) Fetch(ctx context.Context, desc Descriptor) (io.ReadCloser, error) {
+	kind := "blobs"
+	if IsManifest(desc.MediaType) {
+		r.mu.Lock()
+		data, ok := r.manifests[desc.Digest]
+		r.mu.Unlock()
+		if ok {
+			return io.NopCloser(bytes.NewReader(data)), nil
+		}
+		kind = "manifests"
+	}
+
+	req, err := http.NewRequest(http.MethodGet, r.url(kind, desc.Digest), nil)
+	if err != nil {
+		return nil, err
+	}
+	if kind == "manifests" {
+		req.Header.Set("Accept", desc.MediaType)
+	}
+	resp, err := r.client.do(ctx, req, r.scope)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		return nil, responseError(resp)
+	}
+	return resp.Body, nil
+}
+
+// Exists reports whether the repository has the blob or manifest
+func (r *Repository) Exists(ctx context.Context, desc Descriptor) (bool, error) {
+	kind := "blobs"
+	if IsManifest(desc.MediaType) {
+		kind = "manifests"
+	}
+	req, err := http.NewRequest(http.MethodHead, r.url(kind, desc.Digest), nil)
+	if err != nil {
+		return false, err
+	}
+	if kind == "manifests" {
+		req.Header.Set("Accept", desc.MediaType)
+	}
+	resp, err := r.client.do(ctx, req, r.scope)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return true, nil
+	case http.StatusNotFound:
+		return false, nil
+	default:
+		return false, responseError(resp)
+	}
+}
+
+// PushBlob uploads a blob in a single request
+func (r *Repository) PushBlob(ctx context.Context, desc Descriptor, content io.Reader) error {
+	req, err := http.NewRequest(http.MethodPost, r.url("blobs", "uploads/"), nil)
+	if err != nil {
+		return err
+	}
+	resp, err := r.client.do(ctx, req, r.scope)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusAccepted {
+		return fmt.Errorf("failed to start upload: %w", responseError(resp))
+	}
+
+	location, err := resp.Request.URL.Parse(resp.Header.Get("Location"))
+	if err != nil || resp.Header.Get("Location") == "" {
+		return fmt.Errorf("registry returned an invalid upload location %q", resp.Header.Get("Location"))
+	}
+	q := location.Query()
+	q.Set("digest", desc.Digest)
+	location.RawQuery = q.Encode()
+
+	req, err = http.NewRequest(http.MethodPut, location.String(), content)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = desc.Size
+	req.Header.Set("Content-Type", "application/octet-stream")
+	resp, err = r.client.do(ctx, req, r.scope)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		return fmt.Errorf("failed to complete upload: %w", responseError(resp))
+	}
+	return nil
+}
+
+// PushManifest uploads a manifest, under ref when given and by digest
+// otherwise
+func (r *Repository) PushManifest(ctx context.Context, desc Descriptor, data []byte, ref string) error {
+	if ref == "" {
+		ref = desc.Digest
+	}
+	req, err := http.NewRequest(http.MethodPut, r.url("manifests", ref), bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", desc.MediaType)
+	resp, err := r.client.do(ctx, req, r.scope)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
+		return responseError(resp)
+	}
+	return nil
+}
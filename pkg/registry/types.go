@@ -0,0 +1,140 @@
+package registry
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Media types understood by the client
+const (
+	MediaTypeOCIManifest    = "application/vnd.oci.image.manifest.v1+json"
+	MediaTypeOCIIndex       = "application/vnd.oci.image.index.v1+json"
+	MediaTypeOCIConfig      = "application/vnd.oci.image.config.v1+json"
+	MediaTypeOCILayer       = "application/vnd.oci.image.layer.v1.tar"
+	MediaTypeOCILayerGzip   = "application/vnd.oci.image.layer.v1.tar+gzip"
+	MediaTypeDockerManifest = "application/vnd.docker.distribution.manifest.v2+json"
+	MediaTypeDockerList     = "application/vnd.docker.distribution.manifest.list.v2+json"
+)
+
+// manifestMediaTypes is sent as Accept header when fetching manifests
+var manifestMediaTypes = []string{
+	MediaTypeOCIManifest,
+	MediaTypeOCIIndex,
+	MediaTypeDockerManifest,
+	MediaTypeDockerList,
+}
+
+// AnnotationRefName names a manifest in an OCI image layout index
+const AnnotationRefName = "org.opencontainers.image.ref.name"
+
+// Platform describes the platform of an index entry
+type Platform struct {
+	Architecture string `json:"architecture"`
+	OS           string `json:"os"`
+	Variant      string `json:"variant,omitempty"`
+}
+
+// Descriptor references content by digest
+type Descriptor struct {
+	MediaType   string            `json:"mediaType"`
+	Digest      string            `json:"digest"`
+	Size        int64             `json:"size"`
+	Platform    *Platform         `json:"platform,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// Manifest is an OCI image manifest or Docker schema 2 manifest
+type Manifest struct {
+	SchemaVersion int               `json:"schemaVersion"`
+	MediaType     string            `json:"mediaType,omitempty"`
+	ArtifactType  string            `json:"artifactType,omitempty"`
+	Config        Descriptor        `json:"config"`
+	Layers        []Descriptor      `json:"layers"`
+	Annotations   map[string]string `json:"annotations,omitempty"`
+}
+
+// Index is an OCI image index or Docker manifest list
+type Index struct {
+	SchemaVersion int               `json:"schemaVersion"`
+	MediaType     string            `json:"mediaType,omitempty"`
+	Manifests     []Descriptor      `json:"manifests"`
+	Annotations   map[string]string `json:"annotations,omitempty"`
+}
+
+// IsIndex reports whether mediaType is a multi-platform index
+func IsIndex(mediaType string) bool {
+	return mediaType == MediaTypeOCIIndex || mediaType == MediaTypeDockerList
+}
+
+// IsManifest reports whether mediaType is a supported manifest or index
+func IsManifest(mediaType string) bool {
+	for _, t := range manifestMediaTypes {
+		if t == mediaType {
+			return true
+		}
+	}
+	return false
+}
+
+// Digest returns the sha256 digest of data
+func Digest(data []byte) string {
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// splitDigest validates a sha256 digest and returns its hex part
+func splitDigest(digest string) (string, error) {
+	algorithm, encoded, ok := strings.Cut(digest, ":")
+	if !ok || algorithm != "sha256" || len(encoded) != 64 {
+		return "", fmt.Errorf("unsupported digest %q", digest)
+	}
+	if _, err := hex.DecodeString(encoded); err != nil {
+		return "", fmt.Errorf("unsupported digest %q", digest)
+	}
+	return encoded, nil
+}
+
+// manifestMediaType returns the media type declared in a manifest body,
+// falling back to the given type
+func manifestMediaType(data []byte, fallback string) string {
+	var m struct {
+		SchemaVersion int             `json:"schemaVersion"`
+		MediaType     string          `json:"mediaType"`
+		Manifests     json.RawMessage `json:"manifests"`
+	}
+	if err := json.Unmarshal(data, &m); err != nil {
+		return fallback
+	}
+	switch {
+	case m.MediaType != "":
+		return m.MediaType
+	case IsManifest(fallback):
+		return fallback
+	case m.Manifests != nil:
+		return MediaTypeOCIIndex
+	default:
+		return MediaTypeOCIManifest
+	}
+}
+
+// children returns the descriptors a manifest or index references
+func children(desc Descriptor, data []byte) ([]Descriptor, error) {
+	if IsIndex(desc.MediaType) {
+		var index Index
+		if err := json.Unmarshal(data, &index); err != nil {
+			return nil, fmt.Errorf("failed to decode index: %w", err)
+		}
+		return index.Manifests, nil
+	}
+	var manifest Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to decode manifest: %w", err)
+	}
+	if manifest.SchemaVersion != 2 {
+		return nil, fmt.Errorf("unsupported manifest schema version %d", manifest.SchemaVersion)
+	}
+	return append([]Descriptor{manifest.Config}, manifest.Layers...), nil
+}
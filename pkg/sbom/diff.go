@@ -0,0 +1,115 @@
+package sbom
+
+import (
+	"sort"
+	"strings"
+)
+
+// Change statuses
+const (
+	Added     = "added"
+	Removed   = "removed"
+	Changed   = "changed"
+	Unchanged = "unchanged"
+)
+
+// statusOrder lists statuses in report order
+var statusOrder = map[string]int{Added: 0, Changed: 1, Removed: 2, Unchanged: 3}
+
+// Change describes how a component differs between two SBOMs. Versions
+// of a component listed more than once are joined with ", ".
+type Change struct {
+	Status string `json:"status" yaml:"status"`
+	Name   string `json:"name" yaml:"name"`
+	// Key is the package URL without version, or the name
+	Key         string `json:"key" yaml:"key"`
+	FromVersion string `json:"from_version,omitempty" yaml:"from_version,omitempty"`
+	ToVersion   string `json:"to_version,omitempty" yaml:"to_version,omitempty"`
+}
+
+// Diff compares the component sets of two SBOMs. Changes are sorted by
+// status, then by name.
+func Diff(from, to *SBOM) []*Change {
+	before := versions(from)
+	after := versions(to)
+
+	var changes []*Change
+	for key, a := range after {
+		c := &Change{Key: key, Name: a.name, ToVersion: a.joined()}
+		b, ok := before[key]
+		switch {
+		case !ok:
+			c.Status = Added
+		case b.joined() != a.joined():
+			c.Status = Changed
+		default:
+			c.Status = Unchanged
+		}
+		if ok {
+			c.FromVersion = b.joined()
+		}
+		changes = append(changes, c)
+	}
+	for key, b := range before {
+		if _, ok := after[key]; !ok {
+			changes = append(changes, &Change{Status: Removed, Key: key, Name: b.name, FromVersion: b.joined()})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		a, b := changes[i], changes[j]
+		if a.Status != b.Status {
+			return statusOrder[a.Status] < statusOrder[b.Status]
+		}
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return a.Key < b.Key
+	})
+	return changes
+}
+
+// Counts tallies changes per status
+func Counts(changes []*Change) map[string]int {
+	counts := map[string]int{}
+	for _, c := range changes {
+		counts[c.Status]++
+	}
+	return counts
+}
+
+type componentVersions struct {
+	name     string
+	versions []string
+}
+
+func (v *componentVersions) joined() string {
+	return strings.Join(v.versions, ", ")
+}
+
+// versions groups the distinct versions of each component by key
+func versions(s *SBOM) map[string]*componentVersions {
+	out := map[string]*componentVersions{}
+	for _, c := range s.Components {
+		key := componentKey(c)
+		v, ok := out[key]
+		if !ok {
+			v = &componentVersions{name: c.Name}
+			out[key] = v
+		}
+		found := false
+		for _, existing := range v.versions {
+			if existing == c.Version {
+				found = true
+				break
+			}
+		}
+		if !found {
+			v.versions = append(v.versions, c.Version)
+		}
+	}
+	for _, v := range out {
+		sort.Strings(v.versions)
+	}
+	return out
+}
@@ -0,0 +1,269 @@
+package sbom
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// cycloneDX is the subset of a CycloneDX JSON BOM that is normalized
+type cycloneDX struct {
+	BOMFormat   string `json:"bomFormat"`
+	SpecVersion string `json:"specVersion"`
+	Version     int    `json:"version"`
+	Metadata    *struct {
+		Component *cdxComponent `json:"component,omitempty"`
+	} `json:"metadata,omitempty"`
+	Components []cdxComponent `json:"components"`
+}
+
+type cdxComponent struct {
+	Type       string         `json:"type"`
+	BOMRef     string         `json:"bom-ref,omitempty"`
+	Name       string         `json:"name"`
+	Version    string         `json:"version,omitempty"`
+	PURL       string         `json:"purl,omitempty"`
+	Licenses   []cdxLicense   `json:"licenses,omitempty"`
+	Components []cdxComponent `json:"components,omitempty"`
+}
+
+type cdxLicense struct {
+	License *struct {
+		ID   string `json:"id,omitempty"`
+		Name string `json:"name,omitempty"`
+	} `json:"license,omitempty"`
+	Expression string `json:"expression,omitempty"`
+}
+
+// spdx is the subset of an SPDX JSON document that is normalized
+type spdx struct {
+	SPDXVersion       string `json:"spdxVersion"`
+	DataLicense       string `json:"dataLicense"`
+	SPDXID            string `json:"SPDXID"`
+	Name              string `json:"name"`
+	DocumentNamespace string `json:"documentNamespace"`
+	CreationInfo      struct {
+		Created  string   `json:"created"`
+		Creators []string `json:"creators"`
+	} `json:"creationInfo"`
+	Packages                   []spdxPackage          `json:"packages"`
+	HasExtractedLicensingInfos []spdxExtractedLicense `json:"hasExtractedLicensingInfos,omitempty"`
+}
+
+type spdxPackage struct {
+	SPDXID           string       `json:"SPDXID"`
+	Name             string       `json:"name"`
+	VersionInfo      string       `json:"versionInfo,omitempty"`
+	DownloadLocation string       `json:"downloadLocation"`
+	LicenseConcluded string       `json:"licenseConcluded,omitempty"`
+	LicenseDeclared  string       `json:"licenseDeclared,omitempty"`
+	PrimaryPurpose   string       `json:"primaryPackagePurpose,omitempty"`
+	ExternalRefs     []spdxExtRef `json:"externalRefs,omitempty"`
+}
+
+// spdxExtractedLicense defines a LicenseRef used by the packages
+type spdxExtractedLicense struct {
+	LicenseID     string `json:"licenseId"`
+	ExtractedText string `json:"extractedText"`
+	Name          string `json:"name"`
+}
+
+type spdxExtRef struct {
+	ReferenceCategory string `json:"referenceCategory"`
+	ReferenceType     string `json:"referenceType"`
+	ReferenceLocator  string `json:"referenceLocator"`
+}
+
+// noAssertion marks SPDX fields without a value
+const noAssertion = "NOASSERTION"
+
+// spdxIDString matches a license or exception ID in an SPDX expression
+var spdxIDString = regexp.MustCompile(`^[A-Za-z0-9.+-]+(:[A-Za-z0-9.+-]+)?$`)
+
+// licenseRefInvalid matches characters not allowed in a LicenseRef
+var licenseRefInvalid = regexp.MustCompile(`[^A-Za-z0-9.-]+`)
+
+// isLicenseExpression reports whether license is a valid SPDX license
+// expression rather than a free-text license name
+func isLicenseExpression(license string) bool {
+	if _, err := parseExpression(license); err != nil {
+		return false
+	}
+	for _, tok := range strings.Fields(strings.NewReplacer("(", " ", ")", " ").Replace(license)) {
+		switch strings.ToUpper(tok) {
+		case "AND", "OR", "WITH":
+			continue
+		}
+		if !spdxIDString.MatchString(tok) {
+			return false
+		}
+	}
+	return true
+}
+
+// licenseRef turns a free-text license name into an SPDX LicenseRef
+func licenseRef(name string) string {
+	ref := strings.Trim(licenseRefInvalid.ReplaceAllString(name, "-"), "-")
+	if ref == "" {
+		ref = "unknown"
+	}
+	return "LicenseRef-" + ref
+}
+
+// newUUID returns a random version 4 UUID
+func newUUID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return fmt.Sprintf("%x", time.Now().UnixNano())
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+func parseCycloneDX(data []byte) (*SBOM, error) {
+	var bom cycloneDX
+	if err := json.Unmarshal(data, &bom); err != nil {
+		return nil, fmt.Errorf("failed to decode CycloneDX SBOM: %w", err)
+	}
+	s := &SBOM{Format: FormatCycloneDX, Components: []*Component{}}
+	if bom.Metadata != nil && bom.Metadata.Component != nil {
+		s.Name = bom.Metadata.Component.Name
+	}
+
+	var walk func([]cdxComponent)
+	walk = func(components []cdxComponent) {
+		for _, c := range components {
+			comp := &Component{Name: c.Name, Version: c.Version, Type: c.Type, PURL: c.PURL}
+			for _, l := range c.Licenses {
+				switch {
+				case l.Expression != "":
+					comp.Licenses = append(comp.Licenses, l.Expression)
+				case l.License != nil && l.License.ID != "":
+					comp.Licenses = append(comp.Licenses, l.License.ID)
+				case l.License != nil && l.License.Name != "":
+					comp.Licenses = append(comp.Licenses, l.License.Name)
+				}
+			}
+			s.Components = append(s.Components, comp)
+			walk(c.Components)
+		}
+	}
+	walk(bom.Components)
+	return s, nil
+}
+
+func parseSPDX(data []byte) (*SBOM, error) {
+	var doc spdx
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to decode SPDX SBOM: %w", err)
+	}
+	s := &SBOM{Format: FormatSPDX, Name: doc.Name, Components: []*Component{}}
+	for _, p := range doc.Packages {
+		comp := &Component{Name: p.Name, Version: p.VersionInfo, Type: strings.ToLower(p.PrimaryPurpose)}
+		for _, ref := range p.ExternalRefs {
+			if ref.ReferenceType == "purl" {
+				comp.PURL = ref.ReferenceLocator
+				break
+			}
+		}
+		// The concluded license wins over the declared one
+		for _, l := range []string{p.LicenseConcluded, p.LicenseDeclared} {
+			if l != "" && l != noAssertion && l != "NONE" {
+				comp.Licenses = []string{l}
+				break
+			}
+		}
+		s.Components = append(s.Components, comp)
+	}
+	return s, nil
+}
+
+func (s *SBOM) toCycloneDX() *cycloneDX {
+	bom := &cycloneDX{BOMFormat: "CycloneDX", SpecVersion: "1.5", Version: 1, Components: []cdxComponent{}}
+	if s.Name != "" {
+		bom.Metadata = &struct {
+			Component *cdxComponent `json:"component,omitempty"`
+		}{Component: &cdxComponent{Type: "container", Name: s.Name}}
+	}
+	for i, c := range s.Components {
+		typ := c.Type
+		if typ == "" {
+			typ = "library"
+		}
+		comp := cdxComponent{Type: typ, BOMRef: fmt.Sprintf("component-%d", i+1), Name: c.Name, Version: c.Version, PURL: c.PURL}
+		if c.PURL != "" {
+			comp.BOMRef = c.PURL
+		}
+		for _, l := range c.Licenses {
+			if isLicenseExpression(l) {
+				comp.Licenses = append(comp.Licenses, cdxLicense{Expression: l})
+				continue
+			}
+			license := cdxLicense{License: &struct {
+				ID   string `json:"id,omitempty"`
+				Name string `json:"name,omitempty"`
+			}{Name: l}}
+			comp.Licenses = append(comp.Licenses, license)
+		}
+		bom.Components = append(bom.Components, comp)
+	}
+	return bom
+}
+
+func (s *SBOM) toSPDX() *spdx {
+	name := s.Name
+	if name == "" {
+		name = "hrbcli-sbom"
+	}
+	doc := &spdx{
+		SPDXVersion:       "SPDX-2.3",
+		DataLicense:       "CC0-1.0",
+		SPDXID:            "SPDXRef-DOCUMENT",
+		Name:              name,
+		DocumentNamespace: "https://github.com/pascal71/hrbcli/spdx/" + strings.NewReplacer(" ", "-", ":", "-", "@", "-").Replace(name) + "-" + newUUID(),
+		Packages:          []spdxPackage{},
+	}
+	doc.CreationInfo.Created = time.Now().UTC().Format(time.RFC3339)
+	doc.CreationInfo.Creators = []string{"Tool: hrbcli"}
+	extracted := map[string]bool{}
+	for i, c := range s.Components {
+		license := noAssertion
+		if len(c.Licenses) > 0 {
+			parts := make([]string, len(c.Licenses))
+			for j, l := range c.Licenses {
+				if isLicenseExpression(l) {
+					parts[j] = l
+					if len(c.Licenses) > 1 && strings.ContainsAny(l, " ") {
+						parts[j] = "(" + l + ")"
+					}
+					continue
+				}
+				ref := licenseRef(l)
+				if !extracted[ref] {
+					extracted[ref] = true
+					doc.HasExtractedLicensingInfos = append(doc.HasExtractedLicensingInfos,
+						spdxExtractedLicense{LicenseID: ref, ExtractedText: l, Name: l})
+				}
+				parts[j] = ref
+			}
+			license = strings.Join(parts, " AND ")
+		}
+		pkg := spdxPackage{
+			SPDXID:           fmt.Sprintf("SPDXRef-Package-%d", i+1),
+			Name:             c.Name,
+			VersionInfo:      c.Version,
+			DownloadLocation: noAssertion,
+			LicenseConcluded: license,
+			LicenseDeclared:  license,
+		}
+		if c.PURL != "" {
+			pkg.ExternalRefs = []spdxExtRef{{ReferenceCategory: "PACKAGE-MANAGER", ReferenceType: "purl", ReferenceLocator: c.PURL}}
+		}
+		doc.Packages = append(doc.Packages, pkg)
+	}
+	return doc
+}
@@ -0,0 +1,249 @@
+package sbom
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// License statuses
+const (
+	LicenseAllowed = "allowed"
+	LicenseDenied  = "denied"
+	LicenseUnknown = "unknown"
+)
+
+// LicensePolicy decides which licenses are acceptable. Entries are SPDX
+// license IDs or glob patterns such as "GPL-*", matched case-insensitively.
+type LicensePolicy struct {
+	// Allow lists the only acceptable licenses. An empty list allows
+	// every license that is not denied.
+	Allow []string `json:"allow,omitempty" yaml:"allow,omitempty"`
+	// Deny lists licenses that are never acceptable
+	Deny []string `json:"deny,omitempty" yaml:"deny,omitempty"`
+}
+
+// LicenseCheck is the outcome of checking a license expression
+type LicenseCheck struct {
+	Status string `json:"status" yaml:"status"`
+	// Denied lists the licenses that made the expression unacceptable
+	Denied []string `json:"denied,omitempty" yaml:"denied,omitempty"`
+}
+
+// LoadLicensePolicy reads and validates a license policy file
+func LoadLicensePolicy(file string) (*LicensePolicy, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var p LicensePolicy
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&p); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", file, err)
+	}
+	if err := p.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid license policy %s: %w", file, err)
+	}
+	return &p, nil
+}
+
+// Validate checks the patterns of the policy
+func (p *LicensePolicy) Validate() error {
+	for _, pattern := range append(append([]string{}, p.Allow...), p.Deny...) {
+		if _, err := path.Match(strings.ToLower(pattern), ""); err != nil {
+			return fmt.Errorf("bad pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// Check evaluates an SPDX license expression. Of "A OR B" one license
+// must be acceptable, of "A AND B" both. Exceptions ("A WITH X") are
+// judged by their license. Components without a license are unknown.
+func (p *LicensePolicy) Check(expression string) *LicenseCheck {
+	expression = strings.TrimSpace(expression)
+	if expression == "" || expression == noAssertion || expression == "NONE" {
+		return &LicenseCheck{Status: LicenseUnknown}
+	}
+
+	node, err := parseExpression(expression)
+	if err != nil {
+		// Judge malformed expressions as a single license
+		node = &licenseNode{id: expression}
+	}
+	ok, denied := p.eval(node)
+	if ok {
+		return &LicenseCheck{Status: LicenseAllowed}
+	}
+	sort.Strings(denied)
+	return &LicenseCheck{Status: LicenseDenied, Denied: dedup(denied)}
+}
+
+// CheckComponent evaluates all licenses of a component, which must all
+// be acceptable
+func (p *LicensePolicy) CheckComponent(c *Component) *LicenseCheck {
+	switch len(c.Licenses) {
+	case 0:
+		return &LicenseCheck{Status: LicenseUnknown}
+	case 1:
+		return p.Check(c.Licenses[0])
+	}
+	parts := make([]string, len(c.Licenses))
+	for i, l := range c.Licenses {
+		parts[i] = "(" + l + ")"
+	}
+	return p.Check(strings.Join(parts, " AND "))
+}
+
+// allowed judges a single license ID
+func (p *LicensePolicy) allowed(id string) bool {
+	if matchAny(p.Deny, id) {
+		return false
+	}
+	return len(p.Allow) == 0 || matchAny(p.Allow, id)
+}
+
+func (p *LicensePolicy) eval(n *licenseNode) (bool, []string) {
+	switch n.op {
+	case "":
+		if p.allowed(n.id) {
+			return true, nil
+		}
+		return false, []string{n.id}
+	case "AND":
+		lok, ldenied := p.eval(n.left)
+		rok, rdenied := p.eval(n.right)
+		return lok && rok, append(ldenied, rdenied...)
+	default:
+		lok, ldenied := p.eval(n.left)
+		rok, rdenied := p.eval(n.right)
+		if lok || rok {
+			return true, nil
+		}
+		return false, append(ldenied, rdenied...)
+	}
+}
+
+func matchAny(patterns []string, id string) bool {
+	id = strings.ToLower(id)
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(strings.ToLower(pattern), id); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func dedup(values []string) []string {
+	var out []string
+	for i, v := range values {
+		if i == 0 || v != values[i-1] {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// licenseNode is a parsed SPDX license expression: a license ID, or an
+// AND/OR of two expressions
+type licenseNode struct {
+	op          string
+	id          string
+	left, right *licenseNode
+}
+
+// parseExpression parses an SPDX license expression. AND binds tighter
+// than OR.
+func parseExpression(expression string) (*licenseNode, error) {
+	expression = strings.NewReplacer("(", " ( ", ")", " ) ").Replace(expression)
+	p := &exprParser{tokens: strings.Fields(expression)}
+	n, err := p.or()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos != len(p.tokens) {
+		return nil, fmt.Errorf("unexpected %q", p.tokens[p.pos])
+	}
+	return n, nil
+}
+
+type exprParser struct {
+	tokens []string
+	pos    int
+}
+
+func (p *exprParser) peek() string {
+	if p.pos < len(p.tokens) {
+		return strings.ToUpper(p.tokens[p.pos])
+	}
+	return ""
+}
+
+func (p *exprParser) or() (*licenseNode, error) {
+	left, err := p.and()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek() == "OR" {
+		p.pos++
+		right, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+		left = &licenseNode{op: "OR", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *exprParser) and() (*licenseNode, error) {
+	left, err := p.term()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek() == "AND" {
+		p.pos++
+		right, err := p.term()
+		if err != nil {
+			return nil, err
+		}
+		left = &licenseNode{op: "AND", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *exprParser) term() (*licenseNode, error) {
+	switch tok := p.peek(); tok {
+	case "":
+		return nil, fmt.Errorf("unexpected end of expression")
+	case "(":
+		p.pos++
+		n, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek() != ")" {
+			return nil, fmt.Errorf("missing )")
+		}
+		p.pos++
+		return n, nil
+	case ")", "AND", "OR", "WITH":
+		return nil, fmt.Errorf("unexpected %q", p.tokens[p.pos])
+	}
+
+	n := &licenseNode{id: p.tokens[p.pos]}
+	p.pos++
+	if p.peek() == "WITH" {
+		// Skip the exception, the license decides
+		p.pos += 2
+		if p.pos > len(p.tokens) {
+			return nil, fmt.Errorf("missing exception after WITH")
+		}
+	}
+	return n, nil
+}
@@ -0,0 +1,119 @@
+package sbom
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Document formats
+const (
+	FormatCycloneDX = "cyclonedx"
+	FormatSPDX      = "spdx"
+)
+
+// Component is a package listed in an SBOM
+type Component struct {
+	Name     string   `json:"name" yaml:"name"`
+	Version  string   `json:"version,omitempty" yaml:"version,omitempty"`
+	Type     string   `json:"type,omitempty" yaml:"type,omitempty"`
+	PURL     string   `json:"purl,omitempty" yaml:"purl,omitempty"`
+	Licenses []string `json:"licenses,omitempty" yaml:"licenses,omitempty"`
+}
+
+// SBOM is a software bill of materials normalized from CycloneDX or SPDX
+type SBOM struct {
+	// Format is the format of the source document
+	Format     string       `json:"format" yaml:"format"`
+	Name       string       `json:"name,omitempty" yaml:"name,omitempty"`
+	Components []*Component `json:"components" yaml:"components"`
+
+	raw json.RawMessage
+}
+
+// Parse normalizes an SBOM addition as returned by Harbor. The document
+// may be wrapped in a report keyed by MIME type and an "sbom" field.
+func Parse(data []byte) (*SBOM, error) {
+	var report map[string]interface{}
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, fmt.Errorf("failed to decode SBOM: %w", err)
+	}
+	doc := unwrap(report)
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	var s *SBOM
+	switch {
+	case doc["bomFormat"] == "CycloneDX":
+		s, err = parseCycloneDX(data)
+	case doc["spdxVersion"] != nil:
+		s, err = parseSPDX(data)
+	default:
+		return nil, fmt.Errorf("unrecognized SBOM format: expected CycloneDX or SPDX JSON")
+	}
+	if err != nil {
+		return nil, err
+	}
+	s.raw = data
+
+	sort.Slice(s.Components, func(i, j int) bool {
+		if s.Components[i].Name != s.Components[j].Name {
+			return s.Components[i].Name < s.Components[j].Name
+		}
+		return s.Components[i].Version < s.Components[j].Version
+	})
+	return s, nil
+}
+
+// unwrap finds the SBOM document inside a Harbor report
+func unwrap(report map[string]interface{}) map[string]interface{} {
+	if inner, ok := report["sbom"].(map[string]interface{}); ok {
+		return unwrap(inner)
+	}
+	for key, v := range report {
+		if inner, ok := v.(map[string]interface{}); ok && strings.Contains(key, "/") {
+			return unwrap(inner)
+		}
+	}
+	return report
+}
+
+// Document returns the SBOM as a CycloneDX or SPDX JSON document. The
+// source document is returned unchanged when it already has that format.
+func (s *SBOM) Document(format string) (interface{}, error) {
+	if format == s.Format && s.raw != nil {
+		var doc interface{}
+		err := json.Unmarshal(s.raw, &doc)
+		return doc, err
+	}
+	switch format {
+	case FormatCycloneDX:
+		return s.toCycloneDX(), nil
+	case FormatSPDX:
+		return s.toSPDX(), nil
+	default:
+		return nil, fmt.Errorf("unsupported SBOM format %q", format)
+	}
+}
+
+// componentKey identifies a component independent of its version: the
+// package URL without version and qualifiers, or the name
+func componentKey(c *Component) string {
+	if c.PURL == "" {
+		return c.Name
+	}
+	key := c.PURL
+	if i := strings.IndexAny(key, "?#"); i != -1 {
+		key = key[:i]
+	}
+	// The version follows the last @ after the last /
+	if slash := strings.LastIndex(key, "/"); slash != -1 {
+		if at := strings.LastIndex(key[slash:], "@"); at != -1 {
+			key = key[:slash+at]
+		}
+	}
+	return key
+}
@@ -0,0 +1,215 @@
+package sbom
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+const spdxReport = `{
+  "application/vnd.security.sbom.report+json; version=1.0": {
+    "media_type": "application/spdx+json",
+    "sbom": {
+      "spdxVersion": "SPDX-2.3",
+      "name": "library/app:1.0",
+      "packages": [
+        {"SPDXID": "SPDXRef-1", "name": "zlib", "versionInfo": "1.2.13", "licenseConcluded": "NOASSERTION", "licenseDeclared": "Zlib",
+         "externalRefs": [{"referenceCategory": "PACKAGE-MANAGER", "referenceType": "purl", "referenceLocator": "pkg:apk/alpine/zlib@1.2.13?arch=x86_64"}]},
+        {"SPDXID": "SPDXRef-2", "name": "busybox", "versionInfo": "1.36.1", "licenseConcluded": "GPL-2.0-only",
+         "externalRefs": [{"referenceCategory": "PACKAGE-MANAGER", "referenceType": "purl", "referenceLocator": "pkg:apk/alpine/busybox@1.36.1"}]}
+      ]
+    }
+  }
+}`
+
+const cycloneDXReport = `{
+  "bomFormat": "CycloneDX",
+  "specVersion": "1.5",
+  "metadata": {"component": {"type": "container", "name": "library/app:1.1"}},
+  "components": [
+    {"type": "library", "name": "zlib", "version": "1.3", "purl": "pkg:apk/alpine/zlib@1.3?arch=x86_64",
+     "licenses": [{"license": {"id": "Zlib"}}]},
+    {"type": "library", "name": "openssl", "version": "3.1.4", "purl": "pkg:apk/alpine/openssl@3.1.4",
+     "licenses": [{"expression": "Apache-2.0"}],
+     "components": [{"type": "library", "name": "libcrypto", "version": "3.1.4"}]}
+  ]
+}`
+
+func TestParseSPDX(t *testing.T) {
+	s, err := Parse([]byte(spdxReport))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Format != FormatSPDX || s.Name != "library/app:1.0" || len(s.Components) != 2 {
+		t.Fatalf("unexpected SBOM %+v", s)
+	}
+	zlib := s.Components[1]
+	if zlib.Name != "zlib" || zlib.PURL != "pkg:apk/alpine/zlib@1.2.13?arch=x86_64" || len(zlib.Licenses) != 1 || zlib.Licenses[0] != "Zlib" {
+		t.Fatalf("unexpected component %+v", zlib)
+	}
+}
+
+func TestParseCycloneDX(t *testing.T) {
+	s, err := Parse([]byte(cycloneDXReport))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Format != FormatCycloneDX || s.Name != "library/app:1.1" || len(s.Components) != 3 {
+		t.Fatalf("unexpected SBOM %+v", s)
+	}
+	if c := s.Components[0]; c.Name != "libcrypto" || c.Licenses != nil {
+		t.Fatalf("unexpected nested component %+v", c)
+	}
+	if c := s.Components[1]; c.Name != "openssl" || c.Licenses[0] != "Apache-2.0" {
+		t.Fatalf("unexpected component %+v", c)
+	}
+}
+
+func TestParseUnknownFormat(t *testing.T) {
+	if _, err := Parse([]byte(`{"foo": "bar"}`)); err == nil {
+		t.Fatal("expected an error")
+	}
+}
+
+func TestDocumentConversion(t *testing.T) {
+	s, err := Parse([]byte(spdxReport))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	doc, err := s.Document(FormatCycloneDX)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := json.Marshal(doc)
+	converted, err := Parse(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if converted.Format != FormatCycloneDX || len(converted.Components) != 2 {
+		t.Fatalf("unexpected conversion %s", data)
+	}
+	if c := converted.Components[1]; c.PURL != s.Components[1].PURL || c.Licenses[0] != "Zlib" {
+		t.Fatalf("unexpected converted component %+v", c)
+	}
+
+	doc, err = s.Document(FormatSPDX)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ = json.Marshal(doc)
+	var raw map[string]interface{}
+	_ = json.Unmarshal(data, &raw)
+	if raw["spdxVersion"] != "SPDX-2.3" || raw["media_type"] != nil {
+		t.Fatalf("expected the unwrapped source document, got %s", data)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	from, err := Parse([]byte(spdxReport))
+	if err != nil {
+		t.Fatal(err)
+	}
+	to, err := Parse([]byte(cycloneDXReport))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	changes := Diff(from, to)
+	if len(changes) != 4 {
+		t.Fatalf("expected 4 changes, got %d", len(changes))
+	}
+	expected := []Change{
+		{Status: Added, Name: "libcrypto", Key: "libcrypto", ToVersion: "3.1.4"},
+		{Status: Added, Name: "openssl", Key: "pkg:apk/alpine/openssl", ToVersion: "3.1.4"},
+		{Status: Changed, Name: "zlib", Key: "pkg:apk/alpine/zlib", FromVersion: "1.2.13", ToVersion: "1.3"},
+		{Status: Removed, Name: "busybox", Key: "pkg:apk/alpine/busybox", FromVersion: "1.36.1"},
+	}
+	for i, c := range changes {
+		if *c != expected[i] {
+			t.Errorf("change %d: expected %+v, got %+v", i, expected[i], *c)
+		}
+	}
+	if counts := Counts(changes); counts[Added] != 2 || counts[Unchanged] != 0 {
+		t.Fatalf("unexpected counts %v", counts)
+	}
+}
+
+func TestLicensePolicyCheck(t *testing.T) {
+	policy := &LicensePolicy{Allow: []string{"MIT", "Apache-2.0", "BSD-*"}, Deny: []string{"BSD-4-Clause"}}
+	tests := []struct {
+		expression string
+		status     string
+		denied     []string
+	}{
+		{"MIT", LicenseAllowed, nil},
+		{"mit", LicenseAllowed, nil},
+		{"BSD-3-Clause", LicenseAllowed, nil},
+		{"BSD-4-Clause", LicenseDenied, []string{"BSD-4-Clause"}},
+		{"GPL-2.0-only OR MIT", LicenseAllowed, nil},
+		{"GPL-2.0-only AND MIT", LicenseDenied, []string{"GPL-2.0-only"}},
+		{"(MIT OR GPL-3.0) AND (LGPL-2.1 OR GPL-2.0)", LicenseDenied, []string{"GPL-2.0", "LGPL-2.1"}},
+		{"Apache-2.0 WITH LLVM-exception", LicenseAllowed, nil},
+		{"NOASSERTION", LicenseUnknown, nil},
+		{"", LicenseUnknown, nil},
+		{"MIT AND (", LicenseDenied, []string{"MIT AND ("}},
+	}
+	for _, tt := range tests {
+		check := policy.Check(tt.expression)
+		if check.Status != tt.status || len(check.Denied) != len(tt.denied) {
+			t.Errorf("%q: expected %s %v, got %s %v", tt.expression, tt.status, tt.denied, check.Status, check.Denied)
+			continue
+		}
+		for i := range tt.denied {
+			if check.Denied[i] != tt.denied[i] {
+				t.Errorf("%q: expected denied %v, got %v", tt.expression, tt.denied, check.Denied)
+			}
+		}
+	}
+
+	denyOnly := &LicensePolicy{Deny: []string{"GPL-*"}}
+	if check := denyOnly.CheckComponent(&Component{Licenses: []string{"MIT", "GPL-3.0-only"}}); check.Status != LicenseDenied {
+		t.Fatalf("expected all component licenses to apply, got %+v", check)
+	}
+	if check := denyOnly.Check("Unlicense"); check.Status != LicenseAllowed {
+		t.Fatalf("expected licenses that are not denied to be allowed, got %+v", check)
+	}
+}
+
+func TestDocumentLicenseNamesAndCreationInfo(t *testing.T) {
+	s := &SBOM{Format: FormatSPDX, Name: "app", Components: []*Component{
+		{Name: "a", Licenses: []string{"Apache License 2.0"}},
+		{Name: "b", Licenses: []string{"MIT OR Apache-2.0", "BSD-3-Clause"}},
+	}}
+
+	doc, err := s.Document(FormatSPDX)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first := doc.(*spdx)
+	if _, err := time.Parse(time.RFC3339, first.CreationInfo.Created); err != nil {
+		t.Fatalf("invalid created %q: %v", first.CreationInfo.Created, err)
+	}
+	if got := first.Packages[0].LicenseDeclared; got != "LicenseRef-Apache-License-2.0" {
+		t.Fatalf("unexpected license %q", got)
+	}
+	if got := first.Packages[1].LicenseDeclared; got != "(MIT OR Apache-2.0) AND BSD-3-Clause" {
+		t.Fatalf("unexpected license %q", got)
+	}
+	if len(first.HasExtractedLicensingInfos) != 1 || first.HasExtractedLicensingInfos[0].ExtractedText != "Apache License 2.0" {
+		t.Fatalf("unexpected extracted licenses %+v", first.HasExtractedLicensingInfos)
+	}
+	doc, _ = s.Document(FormatSPDX)
+	if doc.(*spdx).DocumentNamespace == first.DocumentNamespace {
+		t.Fatalf("namespace %s reused", first.DocumentNamespace)
+	}
+
+	doc, err = s.Document(FormatCycloneDX)
+	if err != nil {
+		t.Fatal(err)
+	}
+	licenses := doc.(*cycloneDX).Components[0].Licenses
+	if len(licenses) != 1 || licenses[0].Expression != "" || licenses[0].License.Name != "Apache License 2.0" {
+		t.Fatalf("unexpected CycloneDX licenses %+v", licenses)
+	}
+}
@@ -0,0 +1,459 @@
+package snapshot
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/pascal71/hrbcli/pkg/api"
+	"github.com/pascal71/hrbcli/pkg/harbor"
+)
+
+// Sections lists the parts of a snapshot in the order they are imported
+var Sections = []string{"configuration", "registries", "projects", "labels", "users", "replication", "preheat"}
+
+// Import statuses
+const (
+	StatusCreated = "created"
+	StatusUpdated = "updated"
+	StatusSkipped = "skipped"
+	StatusFailed  = "failed"
+)
+
+// ImportOptions control Import
+type ImportOptions struct {
+	// Sections limits the import to these sections; empty means all
+	Sections []string
+	// UserPassword is the initial password of imported users. Users are
+	// skipped when it is empty because passwords are never exported.
+	UserPassword string
+}
+
+// Result is the outcome of importing one object
+type Result struct {
+	Section string `json:"section"`
+	Name    string `json:"name"`
+	Status  string `json:"status"`
+	Message string `json:"message,omitempty"`
+}
+
+// importer recreates snapshot objects and tracks the IDs they get on the
+// target instance
+type importer struct {
+	client  *api.Client
+	snap    *Snapshot
+	opts    ImportOptions
+	results []*Result
+
+	// registryIDs maps source registry IDs to target registry IDs
+	registryIDs map[int64]int64
+	// projectIDs maps project names to target project IDs
+	projectIDs map[string]int64
+}
+
+// Import creates the objects of snap that do not exist on the instance
+// behind client yet. Existing objects, matched by name, are left
+// untouched. Failures of single objects are recorded in the results and
+// do not stop the import; the error is only set when the target cannot
+// be read.
+func Import(ctx context.Context, client *api.Client, snap *Snapshot, opts ImportOptions) ([]*Result, error) {
+	im := &importer{
+		client:      client,
+		snap:        snap,
+		opts:        opts,
+		registryIDs: map[int64]int64{},
+		projectIDs:  map[string]int64{},
+	}
+
+	steps := map[string]func(context.Context) error{
+		"configuration": im.configuration,
+		"registries":    im.registries,
+		"projects":      im.projects,
+		"labels":        im.labels,
+		"users":         im.users,
+		"replication":   im.replication,
+		"preheat":       im.preheat,
+	}
+	for _, name := range Sections {
+		if !im.included(name) {
+			continue
+		}
+		if err := steps[name](ctx); err != nil {
+			return im.results, fmt.Errorf("failed to import %s: %w", name, err)
+		}
+	}
+	return im.results, nil
+}
+
+func (im *importer) included(section string) bool {
+	if len(im.opts.Sections) == 0 {
+		return true
+	}
+	for _, s := range im.opts.Sections {
+		if s == section {
+			return true
+		}
+	}
+	return false
+}
+
+// record adds a result with status, or a failure when err is set
+func (im *importer) record(section, name string, err error, status, message string) {
+	r := &Result{Section: section, Name: name, Status: status, Message: message}
+	if err != nil {
+		r.Status, r.Message = StatusFailed, err.Error()
+	}
+	im.results = append(im.results, r)
+}
+
+// configuration updates the editable settings that differ
+func (im *importer) configuration(ctx context.Context) error {
+	if len(im.snap.Configuration) == 0 {
+		return nil
+	}
+	svc := harbor.NewConfigService(im.client)
+	current, err := svc.GetEditable(ctx)
+	if err != nil {
+		return err
+	}
+
+	changes := map[string]interface{}{}
+	var keys []string
+	for k, v := range im.snap.Configuration {
+		cur, ok := current[k]
+		if ok && !reflect.DeepEqual(cur, v) {
+			changes[k] = v
+			keys = append(keys, k)
+		}
+	}
+	if len(changes) == 0 {
+		im.record("configuration", "settings", nil, StatusSkipped, "already up to date")
+		return nil
+	}
+	sort.Strings(keys)
+	err = svc.Update(ctx, changes)
+	im.record("configuration", "settings", err, StatusUpdated, fmt.Sprintf("%d settings changed: %v", len(keys), keys))
+	return nil
+}
+
+func (im *importer) registries(ctx context.Context) error {
+	svc := harbor.NewRegistryService(im.client)
+	existing, err := svc.ListAll(ctx, nil, 0)
+	if err != nil {
+		return err
+	}
+	byName := map[string]int64{}
+	for _, r := range existing {
+		byName[r.Name] = r.ID
+	}
+
+	for _, r := range im.snap.Registries {
+		if id, ok := byName[r.Name]; ok {
+			im.registryIDs[r.ID] = id
+			im.record("registries", r.Name, nil, StatusSkipped, "already exists")
+			continue
+		}
+
+		req := &api.RegistryReq{
+			Name:        r.Name,
+			URL:         r.URL,
+			Description: r.Description,
+			Type:        r.Type,
+			Insecure:    r.Insecure,
+			Credential:  r.Credential,
+		}
+		created, err := svc.Create(ctx, req)
+		message := ""
+		if err == nil {
+			im.registryIDs[r.ID] = created.ID
+			if r.Credential != nil && r.Credential.AccessKey != "" {
+				message = "set the credential secret again with 'hrbcli registry update'"
+			}
+		}
+		im.record("registries", r.Name, err, StatusCreated, message)
+	}
+	return nil
+}
+
+// registryID maps a source registry ID to the target registry with the
+// same name; 0 is the local Harbor
+func (im *importer) registryID(ctx context.Context, id int64) (int64, error) {
+	if id == 0 {
+		return 0, nil
+	}
+	if mapped, ok := im.registryIDs[id]; ok {
+		return mapped, nil
+	}
+
+	var name string
+	for _, r := range im.snap.Registries {
+		if r.ID == id {
+			name = r.Name
+		}
+	}
+	if name == "" {
+		return 0, fmt.Errorf("registry %d is not part of the snapshot", id)
+	}
+	// The registries section was not imported; match existing ones
+	existing, err := harbor.NewRegistryService(im.client).ListAll(ctx, nil, 0)
+	if err != nil {
+		return 0, err
+	}
+	for _, r := range existing {
+		if r.Name == name {
+			im.registryIDs[id] = r.ID
+			return r.ID, nil
+		}
+	}
+	return 0, fmt.Errorf("registry %s not found", name)
+}
+
+func (im *importer) projects(ctx context.Context) error {
+	svc := harbor.NewProjectService(im.client)
+	existing, err := svc.ListAll(ctx, nil, 0)
+	if err != nil {
+		return err
+	}
+	byName := map[string]int64{}
+	for _, p := range existing {
+		byName[p.Name] = p.ProjectID
+	}
+
+	for _, p := range im.snap.Projects {
+		if id, ok := byName[p.Name]; ok {
+			im.projectIDs[p.Name] = id
+			im.record("projects", p.Name, nil, StatusSkipped, "already exists")
+			continue
+		}
+		err := im.createProject(ctx, svc, p)
+		im.record("projects", p.Name, err, StatusCreated, "")
+	}
+	return nil
+}
+
+func (im *importer) createProject(ctx context.Context, svc *harbor.ProjectService, p *Project) error {
+	req := &api.ProjectReq{ProjectName: p.Name, Public: &p.Public}
+	if p.Metadata != nil {
+		md := *p.Metadata
+		// Retention policies are not part of the snapshot
+		md.RetentionID = ""
+		req.Metadata = &md
+	}
+	if p.CVEAllowlist != nil && len(p.CVEAllowlist.Items) > 0 {
+		req.CVEAllowlist = &api.CVEAllowlist{Items: p.CVEAllowlist.Items}
+	}
+	if p.Quota != nil {
+		req.StorageLimit = &p.Quota.Storage
+	}
+	if p.RegistryID != 0 {
+		id, err := im.registryID(ctx, p.RegistryID)
+		if err != nil {
+			return err
+		}
+		req.RegistryID = &id
+	}
+
+	if err := svc.Create(ctx, req); err != nil {
+		return err
+	}
+	created, err := svc.Get(ctx, p.Name)
+	if err != nil {
+		return err
+	}
+	im.projectIDs[p.Name] = created.ProjectID
+	return nil
+}
+
+// projectID returns the target ID of a project, looking it up when the
+// projects section was not imported
+func (im *importer) projectID(ctx context.Context, name string) (int64, error) {
+	if id, ok := im.projectIDs[name]; ok {
+		return id, nil
+	}
+	p, err := harbor.NewProjectService(im.client).Get(ctx, name)
+	if err != nil {
+		return 0, fmt.Errorf("project %s: %w", name, err)
+	}
+	im.projectIDs[name] = p.ProjectID
+	return p.ProjectID, nil
+}
+
+func (im *importer) labels(ctx context.Context) error {
+	svc := harbor.NewLabelService(im.client)
+	// existing label names by project, "" for global labels
+	existing := map[string]map[string]bool{}
+
+	for _, l := range im.snap.Labels {
+		name := l.Name
+		opts := &api.LabelListOptions{Scope: "g"}
+		label := &api.Label{Name: l.Name, Description: l.Description, Color: l.Color, Scope: "g"}
+		if l.Project != "" {
+			name = l.Project + "/" + l.Name
+			id, err := im.projectID(ctx, l.Project)
+			if err != nil {
+				im.record("labels", name, err, "", "")
+				continue
+			}
+			opts = &api.LabelListOptions{Scope: "p", ProjectID: id}
+			label.Scope, label.ProjectID = "p", id
+		}
+
+		names, ok := existing[l.Project]
+		if !ok {
+			list, err := svc.ListAll(ctx, opts, 0)
+			if err != nil {
+				return err
+			}
+			names = map[string]bool{}
+			for _, e := range list {
+				names[e.Name] = true
+			}
+			existing[l.Project] = names
+		}
+		if names[l.Name] {
+			im.record("labels", name, nil, StatusSkipped, "already exists")
+			continue
+		}
+
+		_, err := svc.Create(ctx, label)
+		im.record("labels", name, err, StatusCreated, "")
+	}
+	return nil
+}
+
+func (im *importer) users(ctx context.Context) error {
+	if len(im.snap.Users) == 0 {
+		return nil
+	}
+	if im.opts.UserPassword == "" {
+		im.record("users", fmt.Sprintf("%d users", len(im.snap.Users)), nil, StatusSkipped, "no initial password given")
+		return nil
+	}
+
+	svc := harbor.NewUserService(im.client)
+	existing, err := svc.ListAll(ctx, nil, 0)
+	if err != nil {
+		return err
+	}
+	byName := map[string]bool{}
+	for _, u := range existing {
+		byName[u.Username] = true
+	}
+
+	for _, u := range im.snap.Users {
+		if byName[u.Username] {
+			im.record("users", u.Username, nil, StatusSkipped, "already exists")
+			continue
+		}
+		created, err := svc.Create(ctx, &api.UserReq{
+			Username: u.Username,
+			Email:    u.Email,
+			Password: im.opts.UserPassword,
+			Realname: u.Realname,
+			Comment:  u.Comment,
+		})
+		if err == nil && u.SysadminFlag {
+			if created == nil {
+				created, err = svc.GetByUsername(ctx, u.Username)
+			}
+			if err == nil {
+				err = svc.SetAdmin(ctx, int64(created.UserID), true)
+			}
+		}
+		im.record("users", u.Username, err, StatusCreated, "")
+	}
+	return nil
+}
+
+func (im *importer) replication(ctx context.Context) error {
+	svc := harbor.NewReplicationService(im.client)
+	existing, err := svc.ListAllPolicies(ctx, nil, 0)
+	if err != nil {
+		return err
+	}
+	byName := map[string]bool{}
+	for _, p := range existing {
+		byName[p.Name] = true
+	}
+
+	for _, p := range im.snap.ReplicationPolicies {
+		if byName[p.Name] {
+			im.record("replication", p.Name, nil, StatusSkipped, "already exists")
+			continue
+		}
+		policy, err := im.replicationPolicy(ctx, p)
+		if err == nil {
+			_, err = svc.CreatePolicy(ctx, policy)
+		}
+		im.record("replication", p.Name, err, StatusCreated, "")
+	}
+	return nil
+}
+
+// replicationPolicy copies p with registry references remapped to the
+// target instance
+func (im *importer) replicationPolicy(ctx context.Context, p *api.ReplicationPolicy) (*api.ReplicationPolicy, error) {
+	policy := *p
+	policy.ID = 0
+	for _, ref := range []**api.Registry{&policy.SrcRegistry, &policy.DestRegistry} {
+		if *ref == nil {
+			continue
+		}
+		id, err := im.registryID(ctx, (*ref).ID)
+		if err != nil {
+			return nil, err
+		}
+		if id == 0 {
+			*ref = nil
+			continue
+		}
+		*ref = &api.Registry{ID: id}
+	}
+	return &policy, nil
+}
+
+func (im *importer) preheat(ctx context.Context) error {
+	svc := harbor.NewPreheatService(im.client)
+	for _, p := range im.snap.PreheatPolicies {
+		name := p.Project + "/" + p.Name
+		_, err := svc.GetPolicy(ctx, p.Project, p.Name)
+		if err == nil {
+			im.record("preheat", name, nil, StatusSkipped, "already exists")
+			continue
+		}
+		if apiErr, ok := err.(*api.APIError); ok && apiErr.IsNotFound() {
+			err = im.createPreheatPolicy(ctx, svc, p)
+		}
+		im.record("preheat", name, err, StatusCreated, "")
+	}
+	return nil
+}
+
+// createPreheatPolicy creates p with its project and provider resolved
+// on the target instance. Providers are matched by name.
+func (im *importer) createPreheatPolicy(ctx context.Context, svc *harbor.PreheatService, p *PreheatPolicy) error {
+	projectID, err := im.projectID(ctx, p.Project)
+	if err != nil {
+		return err
+	}
+	providers, err := svc.ListProviders(ctx, p.Project)
+	if err != nil {
+		return err
+	}
+	var providerID int64
+	for _, provider := range providers {
+		if provider.Provider == p.ProviderName {
+			providerID = provider.ID
+		}
+	}
+	if providerID == 0 {
+		return fmt.Errorf("preheat provider %q not found", p.ProviderName)
+	}
+
+	policy := *p.PreheatPolicy
+	policy.ID = 0
+	policy.ProjectID = projectID
+	policy.ProviderID = providerID
+	return svc.CreatePolicy(ctx, p.Project, &policy)
+}
@@ -0,0 +1,346 @@
+package snapshot
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pascal71/hrbcli/pkg/api"
+	"github.com/pascal71/hrbcli/pkg/harbor"
+	"github.com/pascal71/hrbcli/pkg/output"
+)
+
+// FormatVersion is the snapshot layout written by this version of
+// hrbcli. Snapshots with a newer version are rejected on import.
+const FormatVersion = 1
+
+// Manifest describes a snapshot
+type Manifest struct {
+	Version       int       `json:"version"`
+	CreatedAt     time.Time `json:"created_at"`
+	Source        string    `json:"source"`
+	HarborVersion string    `json:"harbor_version,omitempty"`
+}
+
+// Project is a project with its quota
+type Project struct {
+	*api.Project
+	Quota *api.QuotaHard `json:"quota,omitempty"`
+}
+
+// Label is a label with the name of its project, empty for global
+// labels
+type Label struct {
+	*api.Label
+	Project string `json:"project,omitempty"`
+}
+
+// PreheatPolicy is a preheat policy with the name of its project
+type PreheatPolicy struct {
+	*api.PreheatPolicy
+	Project string `json:"project"`
+}
+
+// Snapshot is the exported configuration of a Harbor instance. IDs are
+// those of the source instance; references between objects are resolved
+// by name on import.
+type Snapshot struct {
+	Manifest            Manifest
+	Registries          []*api.Registry
+	Projects            []*Project
+	Labels              []*Label
+	Users               []*api.User
+	ReplicationPolicies []*api.ReplicationPolicy
+	PreheatPolicies     []*PreheatPolicy
+	Configuration       map[string]interface{}
+}
+
+// Files of a snapshot, one per section
+const (
+	manifestFile      = "manifest.json"
+	registriesFile    = "registries.json"
+	projectsFile      = "projects.json"
+	labelsFile        = "labels.json"
+	usersFile         = "users.json"
+	replicationFile   = "replication_policies.json"
+	preheatFile       = "preheat_policies.json"
+	configurationFile = "configuration.json"
+)
+
+// sections maps each file to the snapshot field it holds
+func (s *Snapshot) sections() map[string]interface{} {
+	return map[string]interface{}{
+		manifestFile:      &s.Manifest,
+		registriesFile:    &s.Registries,
+		projectsFile:      &s.Projects,
+		labelsFile:        &s.Labels,
+		usersFile:         &s.Users,
+		replicationFile:   &s.ReplicationPolicies,
+		preheatFile:       &s.PreheatPolicies,
+		configurationFile: &s.Configuration,
+	}
+}
+
+// Export reads the configuration of the Harbor instance behind client.
+// Registry secrets and user passwords are never included.
+func Export(ctx context.Context, client *api.Client) (*Snapshot, error) {
+	snap := &Snapshot{Manifest: Manifest{Version: FormatVersion, CreatedAt: time.Now().UTC(), Source: client.BaseURL}}
+
+	info, err := harbor.NewSystemService(client).GetInfo(ctx, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get system info: %w", err)
+	}
+	snap.Manifest.HarborVersion = info.HarborVersion
+
+	if snap.Registries, err = harbor.NewRegistryService(client).ListAll(ctx, nil, 0); err != nil {
+		return nil, fmt.Errorf("failed to list registries: %w", err)
+	}
+	for _, r := range snap.Registries {
+		if r.Credential != nil {
+			r.Credential.AccessSecret = ""
+		}
+	}
+
+	projectSvc := harbor.NewProjectService(client)
+	projects, err := projectSvc.ListAll(ctx, nil, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list projects: %w", err)
+	}
+	labelSvc := harbor.NewLabelService(client)
+	preheatSvc := harbor.NewPreheatService(client)
+	for _, p := range projects {
+		project := &Project{Project: p}
+		summary, err := projectSvc.GetSummary(ctx, p.Name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get summary of project %s: %w", p.Name, err)
+		}
+		if summary.Quota != nil {
+			project.Quota = &summary.Quota.Hard
+		}
+		snap.Projects = append(snap.Projects, project)
+
+		labels, err := labelSvc.ListAll(ctx, &api.LabelListOptions{Scope: "p", ProjectID: p.ProjectID}, 0)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list labels of project %s: %w", p.Name, err)
+		}
+		for _, l := range labels {
+			snap.Labels = append(snap.Labels, &Label{Label: l, Project: p.Name})
+		}
+
+		policies, err := preheatSvc.ListAllPolicies(ctx, p.Name, nil, 0)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list preheat policies of project %s: %w", p.Name, err)
+		}
+		for _, policy := range policies {
+			snap.PreheatPolicies = append(snap.PreheatPolicies, &PreheatPolicy{PreheatPolicy: policy, Project: p.Name})
+		}
+	}
+
+	labels, err := labelSvc.ListAll(ctx, &api.LabelListOptions{Scope: "g"}, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list labels: %w", err)
+	}
+	for _, l := range labels {
+		snap.Labels = append(snap.Labels, &Label{Label: l})
+	}
+
+	if snap.Users, err = harbor.NewUserService(client).ListAll(ctx, nil, 0); err != nil {
+		return nil, fmt.Errorf("failed to list users: %w", err)
+	}
+	if snap.ReplicationPolicies, err = harbor.NewReplicationService(client).ListAllPolicies(ctx, nil, 0); err != nil {
+		return nil, fmt.Errorf("failed to list replication policies: %w", err)
+	}
+	if snap.Configuration, err = harbor.NewConfigService(client).GetEditable(ctx); err != nil {
+		return nil, fmt.Errorf("failed to get configuration: %w", err)
+	}
+
+	return snap, nil
+}
+
+// IsArchive reports whether path names a gzipped tarball rather than a
+// directory
+func IsArchive(path string) bool {
+	return strings.HasSuffix(path, ".tar.gz") || strings.HasSuffix(path, ".tgz")
+}
+
+// Write saves the snapshot as one JSON file per section in dir, or as a
+// gzipped tarball of those files when path ends in .tar.gz or .tgz
+func (s *Snapshot) Write(path string) error {
+	if !IsArchive(path) {
+		return s.writeDir(path)
+	}
+
+	dir, err := os.MkdirTemp("", "hrbcli-export-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+	if err := s.writeDir(dir); err != nil {
+		return err
+	}
+	return writeArchive(path, dir)
+}
+
+func (s *Snapshot) writeDir(dir string) error {
+	if err := clearDir(dir); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	for name, data := range s.sections() {
+		if err := output.WriteFile(filepath.Join(dir, name), "json", data); err != nil {
+			return fmt.Errorf("failed to write %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// clearDir removes the files of an earlier snapshot in dir, so none of
+// them are left behind to be imported with the new one. Any other
+// non-empty directory is refused.
+func clearDir(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) || len(entries) == 0 {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(filepath.Join(dir, manifestFile)); err != nil {
+		return fmt.Errorf("%s is not empty and does not hold a snapshot", dir)
+	}
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		if err := os.Remove(filepath.Join(dir, e.Name())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeArchive(path, dir string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		data, err := os.ReadFile(filepath.Join(dir, e.Name()))
+		if err != nil {
+			return err
+		}
+		hdr := &tar.Header{Name: e.Name(), Mode: 0600, Size: int64(len(data)), ModTime: time.Now()}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if _, err := tw.Write(data); err != nil {
+			return err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// Read loads a snapshot written by Write from a directory or tarball
+func Read(path string) (*Snapshot, error) {
+	files, err := readFiles(path)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Snapshot{}
+	data, ok := files[manifestFile]
+	if !ok {
+		return nil, fmt.Errorf("%s is not a snapshot: %s is missing", path, manifestFile)
+	}
+	if err := json.Unmarshal(data, &s.Manifest); err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", manifestFile, err)
+	}
+	if s.Manifest.Version > FormatVersion {
+		return nil, fmt.Errorf("snapshot version %d is newer than supported version %d", s.Manifest.Version, FormatVersion)
+	}
+
+	for name, v := range s.sections() {
+		data, ok := files[name]
+		if !ok || name == manifestFile {
+			continue
+		}
+		if err := json.Unmarshal(data, v); err != nil {
+			return nil, fmt.Errorf("failed to decode %s: %w", name, err)
+		}
+	}
+	return s, nil
+}
+
+// readFiles returns the contents of the snapshot files by name
+func readFiles(path string) (map[string][]byte, error) {
+	files := map[string][]byte{}
+	if !IsArchive(path) {
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range entries {
+			if e.IsDir() {
+				continue
+			}
+			data, err := os.ReadFile(filepath.Join(path, e.Name()))
+			if err != nil {
+				return nil, err
+			}
+			files[e.Name()] = data
+		}
+		return files, nil
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, err
+		}
+		files[filepath.Base(hdr.Name)] = data
+	}
+	return files, nil
+}
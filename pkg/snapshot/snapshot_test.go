@@ -0,0 +1,146 @@
+package snapshot
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pascal71/hrbcli/pkg/api"
+)
+
+func testSnapshot() *Snapshot {
+	return &Snapshot{
+		Manifest: Manifest{Version: FormatVersion, Source: "https://harbor.example.com"},
+		Registries: []*api.Registry{
+			{ID: 7, Name: "hub", Type: "docker-hub", URL: "https://hub.docker.com"},
+		},
+		Projects: []*Project{
+			{Project: &api.Project{ProjectID: 3, Name: "cache", RegistryID: 7}, Quota: &api.QuotaHard{Storage: 1024}},
+		},
+		ReplicationPolicies: []*api.ReplicationPolicy{
+			{ID: 9, Name: "pull-hub", SrcRegistry: &api.Registry{ID: 7, Name: "hub"}, DestRegistry: &api.Registry{ID: 0}},
+		},
+		Labels:        []*Label{{Label: &api.Label{Name: "approved", Color: "#00FF00"}, Project: "cache"}},
+		Configuration: map[string]interface{}{"robot_token_duration": float64(30)},
+	}
+}
+
+func TestWriteAndRead(t *testing.T) {
+	for _, name := range []string{"snapshot", "snapshot.tar.gz"} {
+		path := filepath.Join(t.TempDir(), name)
+		if err := testSnapshot().Write(path); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		snap, err := Read(path)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if len(snap.Projects) != 1 || snap.Projects[0].RegistryID != 7 || snap.Projects[0].Quota.Storage != 1024 {
+			t.Fatalf("%s: unexpected projects %+v", name, snap.Projects[0])
+		}
+		if len(snap.Labels) != 1 || snap.Labels[0].Project != "cache" || snap.Labels[0].Name != "approved" {
+			t.Fatalf("%s: unexpected labels %+v", name, snap.Labels)
+		}
+	}
+}
+
+func TestWriteReplacesEarlierSnapshot(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "snapshot")
+	if err := testSnapshot().Write(dir); err != nil {
+		t.Fatal(err)
+	}
+	stale := filepath.Join(dir, "stale.json")
+	if err := os.WriteFile(stale, []byte("{}"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := testSnapshot().Write(dir); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(stale); !os.IsNotExist(err) {
+		t.Fatalf("expected %s to be removed, got %v", stale, err)
+	}
+
+	other := t.TempDir()
+	if err := os.WriteFile(filepath.Join(other, "notes.txt"), []byte("keep"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := testSnapshot().Write(other); err == nil {
+		t.Fatal("expected a non-empty directory without a snapshot to be refused")
+	}
+}
+
+func TestReadRejectsNewerVersion(t *testing.T) {
+	dir := t.TempDir()
+	snap := testSnapshot()
+	snap.Manifest.Version = FormatVersion + 1
+	if err := snap.Write(dir); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Read(dir); err == nil {
+		t.Fatalf("expected an error for a newer snapshot version")
+	}
+	if _, err := Read(t.TempDir()); err == nil {
+		t.Fatalf("expected an error for a directory without manifest")
+	}
+}
+
+func TestImportRemapsRegistryIDs(t *testing.T) {
+	var project, policy map[string]interface{}
+	decode := func(r *http.Request, v *map[string]interface{}) {
+		data, _ := io.ReadAll(r.Body)
+		json.Unmarshal(data, v)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.URL.Path {
+		case "GET /api/v2.0/registries", "GET /api/v2.0/projects", "GET /api/v2.0/replication/policies":
+			w.Write([]byte(`[]`))
+		case "POST /api/v2.0/registries":
+			w.Header().Set("Location", "/api/v2.0/registries/42")
+			w.WriteHeader(http.StatusCreated)
+		case "GET /api/v2.0/registries/42":
+			w.Write([]byte(`{"id":42,"name":"hub"}`))
+		case "POST /api/v2.0/projects":
+			decode(r, &project)
+			w.WriteHeader(http.StatusCreated)
+		case "GET /api/v2.0/projects/cache":
+			w.Write([]byte(`{"project_id":5,"name":"cache"}`))
+		case "POST /api/v2.0/replication/policies":
+			decode(r, &policy)
+			w.Header().Set("Location", "/api/v2.0/replication/policies/11")
+			w.WriteHeader(http.StatusCreated)
+		case "GET /api/v2.0/replication/policies/11":
+			w.Write([]byte(`{"id":11,"name":"pull-hub"}`))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := &api.Client{BaseURL: server.URL, APIVersion: "v2.0", HTTPClient: server.Client()}
+	results, err := Import(context.Background(), client, testSnapshot(), ImportOptions{
+		Sections: []string{"registries", "projects", "replication"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range results {
+		if r.Status != StatusCreated {
+			t.Fatalf("unexpected result %+v", r)
+		}
+	}
+
+	if project["registry_id"] != float64(42) || project["storage_limit"] != float64(1024) {
+		t.Fatalf("unexpected project request %v", project)
+	}
+	src, _ := policy["src_registry"].(map[string]interface{})
+	if src["id"] != float64(42) || policy["dest_registry"] != nil || policy["id"] != float64(0) {
+		t.Fatalf("unexpected policy request %v", policy)
+	}
+}
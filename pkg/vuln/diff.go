@@ -0,0 +1,138 @@
+package vuln
+
+import (
+	"sort"
+
+	"github.com/pascal71/hrbcli/pkg/api"
+)
+
+// Change statuses
+const (
+	Added           = "added"
+	Removed         = "removed"
+	SeverityChanged = "severity-changed"
+	Unchanged       = "unchanged"
+)
+
+// statusOrder lists statuses in report order
+var statusOrder = map[string]int{Added: 0, SeverityChanged: 1, Removed: 2, Unchanged: 3}
+
+// Change describes how a CVE affecting a package differs between two
+// reports
+type Change struct {
+	Status  string `json:"status" yaml:"status"`
+	CVEID   string `json:"id" yaml:"id"`
+	Package string `json:"package" yaml:"package"`
+	// From fields are empty for added CVEs, To fields for removed ones
+	FromSeverity string `json:"from_severity,omitempty" yaml:"from_severity,omitempty"`
+	ToSeverity   string `json:"to_severity,omitempty" yaml:"to_severity,omitempty"`
+	FromVersion  string `json:"from_version,omitempty" yaml:"from_version,omitempty"`
+	ToVersion    string `json:"to_version,omitempty" yaml:"to_version,omitempty"`
+	FixedVersion string `json:"fix_version,omitempty" yaml:"fix_version,omitempty"`
+}
+
+// Severity returns the current severity of the change: the new one,
+// or the old one for removed CVEs
+func (c *Change) Severity() string {
+	if c.Status == Removed {
+		return c.FromSeverity
+	}
+	return c.ToSeverity
+}
+
+// IsNew reports whether the change introduces a finding at or above
+// threshold that was not there before
+func (c *Change) IsNew(threshold string) bool {
+	switch c.Status {
+	case Added:
+		return AtLeast(c.ToSeverity, threshold)
+	case SeverityChanged:
+		return AtLeast(c.ToSeverity, threshold) && !AtLeast(c.FromSeverity, threshold)
+	default:
+		return false
+	}
+}
+
+// Diff classifies each CVE and package pair of two reports. Changes are
+// sorted by status, then by descending severity, CVE and package.
+func Diff(from, to *api.VulnerabilityReport) []*Change {
+	before := index(from)
+	after := index(to)
+
+	var changes []*Change
+	for key, a := range after {
+		c := &Change{CVEID: a.CVEID, Package: a.Package, ToSeverity: a.Severity, ToVersion: a.Version, FixedVersion: a.FixedVersion}
+		b, ok := before[key]
+		switch {
+		case !ok:
+			c.Status = Added
+		case SeverityRank(b.Severity) != SeverityRank(a.Severity):
+			c.Status = SeverityChanged
+		default:
+			c.Status = Unchanged
+		}
+		if ok {
+			c.FromSeverity = b.Severity
+			c.FromVersion = b.Version
+		}
+		changes = append(changes, c)
+	}
+	for key, b := range before {
+		if _, ok := after[key]; ok {
+			continue
+		}
+		changes = append(changes, &Change{
+			Status:       Removed,
+			CVEID:        b.CVEID,
+			Package:      b.Package,
+			FromSeverity: b.Severity,
+			FromVersion:  b.Version,
+			FixedVersion: b.FixedVersion,
+		})
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		a, b := changes[i], changes[j]
+		if statusOrder[a.Status] != statusOrder[b.Status] {
+			return statusOrder[a.Status] < statusOrder[b.Status]
+		}
+		if SeverityRank(a.Severity()) != SeverityRank(b.Severity()) {
+			return SeverityRank(a.Severity()) > SeverityRank(b.Severity())
+		}
+		if a.CVEID != b.CVEID {
+			return a.CVEID < b.CVEID
+		}
+		return a.Package < b.Package
+	})
+	return changes
+}
+
+type findingKey struct {
+	cve, pkg string
+}
+
+// index maps CVE and package pairs to findings. When a package is
+// affected by a CVE in several versions the most severe finding wins.
+func index(report *api.VulnerabilityReport) map[findingKey]api.VulnerabilityItem {
+	findings := map[findingKey]api.VulnerabilityItem{}
+	if report == nil {
+		return findings
+	}
+	for _, v := range report.Vulnerabilities {
+		key := findingKey{v.CVEID, v.Package}
+		if existing, ok := findings[key]; ok && SeverityRank(existing.Severity) >= SeverityRank(v.Severity) {
+			continue
+		}
+		findings[key] = v
+	}
+	return findings
+}
+
+// Counts returns the number of changes per status
+func Counts(changes []*Change) map[string]int {
+	counts := map[string]int{}
+	for _, c := range changes {
+		counts[c.Status]++
+	}
+	return counts
+}
@@ -0,0 +1,50 @@
+package vuln
+
+import (
+	"testing"
+
+	"github.com/pascal71/hrbcli/pkg/api"
+)
+
+func TestDiff(t *testing.T) {
+	from := &api.VulnerabilityReport{Vulnerabilities: []api.VulnerabilityItem{
+		{CVEID: "CVE-1", Package: "openssl", Version: "1.0", Severity: "High"},
+		{CVEID: "CVE-2", Package: "zlib", Version: "1.2", Severity: "Low"},
+		{CVEID: "CVE-3", Package: "curl", Version: "7.0", Severity: "Medium"},
+	}}
+	to := &api.VulnerabilityReport{Vulnerabilities: []api.VulnerabilityItem{
+		{CVEID: "CVE-2", Package: "zlib", Version: "1.3", Severity: "Critical"},
+		{CVEID: "CVE-3", Package: "curl", Version: "7.0", Severity: "Medium"},
+		{CVEID: "CVE-4", Package: "bash", Version: "5.0", Severity: "Low", FixedVersion: "5.1"},
+		{CVEID: "CVE-4", Package: "bash", Version: "5.0", Severity: "High"},
+	}}
+
+	changes := Diff(from, to)
+	want := []Change{
+		{Status: Added, CVEID: "CVE-4", Package: "bash", ToSeverity: "High", ToVersion: "5.0"},
+		{Status: SeverityChanged, CVEID: "CVE-2", Package: "zlib", FromSeverity: "Low", ToSeverity: "Critical", FromVersion: "1.2", ToVersion: "1.3"},
+		{Status: Removed, CVEID: "CVE-1", Package: "openssl", FromSeverity: "High", FromVersion: "1.0"},
+		{Status: Unchanged, CVEID: "CVE-3", Package: "curl", FromSeverity: "Medium", ToSeverity: "Medium", FromVersion: "7.0", ToVersion: "7.0"},
+	}
+	if len(changes) != len(want) {
+		t.Fatalf("expected %d changes, got %d", len(want), len(changes))
+	}
+	for i, w := range want {
+		if *changes[i] != w {
+			t.Errorf("change %d: got %+v, want %+v", i, *changes[i], w)
+		}
+	}
+
+	var gated []string
+	for _, c := range changes {
+		if c.IsNew("high") {
+			gated = append(gated, c.CVEID)
+		}
+	}
+	if len(gated) != 2 || gated[0] != "CVE-4" || gated[1] != "CVE-2" {
+		t.Fatalf("unexpected gated changes %v", gated)
+	}
+	if changes[1].IsNew("low") {
+		t.Fatalf("a severity increase above an already exceeded threshold is not new")
+	}
+}
@@ -0,0 +1,204 @@
+package vuln
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/pascal71/hrbcli/pkg/api"
+)
+
+// dateLayout is the format of allowlist expiry dates
+const dateLayout = "2006-01-02"
+
+// Policy is a vulnerability gate loaded from a policy file
+type Policy struct {
+	// MaxCounts limits the number of findings per severity, e.g.
+	// critical: 0. Severities without a limit are not gated.
+	MaxCounts map[string]int `json:"max_counts,omitempty" yaml:"max_counts,omitempty"`
+	// Allowlist accepts known CVEs, optionally until an expiry date
+	Allowlist []AllowlistEntry `json:"allowlist,omitempty" yaml:"allowlist,omitempty"`
+	// IgnoreUnfixed drops findings without a fixed version
+	IgnoreUnfixed bool `json:"ignore_unfixed,omitempty" yaml:"ignore_unfixed,omitempty"`
+	// FixableOnly counts unfixed findings but only gates fixable ones
+	FixableOnly bool `json:"fixable_only,omitempty" yaml:"fixable_only,omitempty"`
+	// ExcludePackages are package names or glob patterns to skip
+	ExcludePackages []string `json:"exclude_packages,omitempty" yaml:"exclude_packages,omitempty"`
+}
+
+// AllowlistEntry accepts a CVE, for one package or all of them
+type AllowlistEntry struct {
+	ID            string `json:"id" yaml:"id"`
+	Package       string `json:"package,omitempty" yaml:"package,omitempty"`
+	Expires       string `json:"expires,omitempty" yaml:"expires,omitempty"`
+	Justification string `json:"justification" yaml:"justification"`
+}
+
+// LoadPolicy reads and validates a policy file
+func LoadPolicy(file string) (*Policy, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var p Policy
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&p); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", file, err)
+	}
+	if err := p.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid policy %s: %w", file, err)
+	}
+	return &p, nil
+}
+
+// Validate checks severities, dates and patterns and normalizes
+// severity names to lower case
+func (p *Policy) Validate() error {
+	counts := map[string]int{}
+	for severity, max := range p.MaxCounts {
+		if !ValidSeverity(severity) {
+			return fmt.Errorf("max_counts: unknown severity %q", severity)
+		}
+		if max < 0 {
+			return fmt.Errorf("max_counts: %s must not be negative", severity)
+		}
+		counts[strings.ToLower(severity)] = max
+	}
+	p.MaxCounts = counts
+
+	for i, e := range p.Allowlist {
+		if e.ID == "" {
+			return fmt.Errorf("allowlist entry %d: id is required", i+1)
+		}
+		if strings.TrimSpace(e.Justification) == "" {
+			return fmt.Errorf("allowlist entry %s: justification is required", e.ID)
+		}
+		if e.Expires != "" {
+			if _, err := time.Parse(dateLayout, e.Expires); err != nil {
+				return fmt.Errorf("allowlist entry %s: expires must be a date like 2006-01-02", e.ID)
+			}
+		}
+	}
+
+	for _, pattern := range p.ExcludePackages {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("exclude_packages: invalid pattern %q", pattern)
+		}
+	}
+	return nil
+}
+
+// Finding statuses
+const (
+	StatusGated       = "gated"
+	StatusNotGated    = "not-gated"
+	StatusAllowlisted = "allowlisted"
+	StatusIgnored     = "ignored"
+	StatusExcluded    = "excluded"
+)
+
+// Finding is a vulnerability with the policy's verdict on it
+type Finding struct {
+	api.VulnerabilityItem `yaml:",inline"`
+	Status                string `json:"status" yaml:"status"`
+	Reason                string `json:"reason,omitempty" yaml:"reason,omitempty"`
+}
+
+// Result is the outcome of evaluating a report against a policy
+type Result struct {
+	Passed bool `json:"passed" yaml:"passed"`
+	// Reasons explain why the check failed
+	Reasons []string `json:"reasons,omitempty" yaml:"reasons,omitempty"`
+	// Warnings flag policy entries that need attention, such as expired
+	// allowlist entries
+	Warnings []string `json:"warnings,omitempty" yaml:"warnings,omitempty"`
+	// Counts are the gated findings per severity
+	Counts   map[string]int `json:"counts" yaml:"counts"`
+	Findings []*Finding     `json:"findings" yaml:"findings"`
+}
+
+// Evaluate checks a report against the policy as of now. Expired
+// allowlist entries no longer apply.
+func (p *Policy) Evaluate(report *api.VulnerabilityReport, now time.Time) *Result {
+	res := &Result{Passed: true, Counts: map[string]int{}, Findings: []*Finding{}}
+	today := now.Format(dateLayout)
+
+	expired := map[int]bool{}
+	for i, e := range p.Allowlist {
+		if e.Expires != "" && e.Expires < today {
+			expired[i] = true
+			res.Warnings = append(res.Warnings, fmt.Sprintf("allowlist entry %s expired on %s", e.ID, e.Expires))
+		}
+	}
+
+	var items []api.VulnerabilityItem
+	if report != nil {
+		items = report.Vulnerabilities
+	}
+	for _, v := range items {
+		f := &Finding{VulnerabilityItem: v, Status: StatusGated}
+		switch {
+		case p.excluded(v.Package):
+			f.Status, f.Reason = StatusExcluded, "package excluded by policy"
+		case p.allowlisted(v, expired, f):
+			f.Status = StatusAllowlisted
+		case v.FixedVersion == "" && p.IgnoreUnfixed:
+			f.Status, f.Reason = StatusIgnored, "no fix available"
+		case v.FixedVersion == "" && p.FixableOnly:
+			f.Status, f.Reason = StatusNotGated, "no fix available; only fixable findings are gated"
+		}
+		if f.Status == StatusGated {
+			res.Counts[strings.ToLower(v.Severity)]++
+		}
+		res.Findings = append(res.Findings, f)
+	}
+
+	severities := make([]string, 0, len(p.MaxCounts))
+	for s := range p.MaxCounts {
+		severities = append(severities, s)
+	}
+	sort.Slice(severities, func(i, j int) bool { return SeverityRank(severities[i]) > SeverityRank(severities[j]) })
+	for _, s := range severities {
+		if n := res.Counts[s]; n > p.MaxCounts[s] {
+			res.Passed = false
+			res.Reasons = append(res.Reasons, fmt.Sprintf("%d %s vulnerabilities exceed the maximum of %d", n, s, p.MaxCounts[s]))
+		}
+	}
+
+	sort.SliceStable(res.Findings, func(i, j int) bool {
+		return SeverityRank(res.Findings[i].Severity) > SeverityRank(res.Findings[j].Severity)
+	})
+	return res
+}
+
+func (p *Policy) excluded(pkg string) bool {
+	for _, pattern := range p.ExcludePackages {
+		if ok, _ := path.Match(pattern, pkg); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// allowlisted reports whether an unexpired allowlist entry covers v and
+// records its justification on f
+func (p *Policy) allowlisted(v api.VulnerabilityItem, expired map[int]bool, f *Finding) bool {
+	for i, e := range p.Allowlist {
+		if expired[i] || !strings.EqualFold(e.ID, v.CVEID) || (e.Package != "" && e.Package != v.Package) {
+			continue
+		}
+		f.Reason = e.Justification
+		if e.Expires != "" {
+			f.Reason += " (until " + e.Expires + ")"
+		}
+		return true
+	}
+	return false
+}
@@ -0,0 +1,94 @@
+package vuln
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/pascal71/hrbcli/pkg/api"
+)
+
+func writePolicy(t *testing.T, content string) string {
+	t.Helper()
+	file := filepath.Join(t.TempDir(), "policy.yaml")
+	if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return file
+}
+
+func TestPolicyEvaluate(t *testing.T) {
+	policy, err := LoadPolicy(writePolicy(t, `
+max_counts:
+  Critical: 0
+  high: 1
+allowlist:
+  - id: CVE-1
+    expires: 2026-12-31
+    justification: not reachable
+  - id: CVE-2
+    expires: 2026-01-01
+    justification: waiting for upstream
+ignore_unfixed: true
+exclude_packages: ["linux-*"]
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report := &api.VulnerabilityReport{Vulnerabilities: []api.VulnerabilityItem{
+		{CVEID: "CVE-1", Package: "openssl", Severity: "Critical", FixedVersion: "3.0.1"},
+		{CVEID: "CVE-2", Package: "zlib", Severity: "High", FixedVersion: "1.3"},
+		{CVEID: "CVE-3", Package: "curl", Severity: "High", FixedVersion: "8.0"},
+		{CVEID: "CVE-4", Package: "bash", Severity: "Critical"},
+		{CVEID: "CVE-5", Package: "linux-libc-dev", Severity: "Critical", FixedVersion: "6.1"},
+	}}
+	res := policy.Evaluate(report, time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC))
+
+	if res.Passed {
+		t.Fatalf("expected the check to fail")
+	}
+	if len(res.Reasons) != 1 || res.Reasons[0] != "2 high vulnerabilities exceed the maximum of 1" {
+		t.Fatalf("unexpected reasons %v", res.Reasons)
+	}
+	if len(res.Warnings) != 1 {
+		t.Fatalf("expected a warning for the expired allowlist entry, got %v", res.Warnings)
+	}
+	statuses := map[string]string{}
+	for _, f := range res.Findings {
+		statuses[f.CVEID] = f.Status
+	}
+	want := map[string]string{
+		"CVE-1": StatusAllowlisted,
+		"CVE-2": StatusGated,
+		"CVE-3": StatusGated,
+		"CVE-4": StatusIgnored,
+		"CVE-5": StatusExcluded,
+	}
+	for id, s := range want {
+		if statuses[id] != s {
+			t.Errorf("%s: got status %q, want %q", id, statuses[id], s)
+		}
+	}
+
+	// Only fixable findings count with fixable_only
+	policy = &Policy{MaxCounts: map[string]int{"critical": 0}, FixableOnly: true}
+	report.Vulnerabilities = report.Vulnerabilities[3:4]
+	if res := policy.Evaluate(report, time.Now()); !res.Passed || res.Findings[0].Status != StatusNotGated {
+		t.Fatalf("unexpected result %+v", res)
+	}
+}
+
+func TestLoadPolicyValidates(t *testing.T) {
+	for _, content := range []string{
+		"max_counts: {severe: 1}",
+		"allowlist: [{id: CVE-1}]",
+		"allowlist: [{id: CVE-1, justification: ok, expires: tomorrow}]",
+		"unknown_rule: true",
+	} {
+		if _, err := LoadPolicy(writePolicy(t, content)); err == nil {
+			t.Errorf("expected an error for %q", content)
+		}
+	}
+}
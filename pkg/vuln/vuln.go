@@ -0,0 +1,30 @@
+package vuln
+
+import "strings"
+
+var severityRanks = map[string]int{
+	"none":       0,
+	"unknown":    0,
+	"negligible": 1,
+	"low":        2,
+	"medium":     3,
+	"high":       4,
+	"critical":   5,
+}
+
+// SeverityRank orders severities from none (0) to critical (5). The
+// comparison is case-insensitive; unknown severities rank as none.
+func SeverityRank(severity string) int {
+	return severityRanks[strings.ToLower(severity)]
+}
+
+// ValidSeverity reports whether severity is a known severity name
+func ValidSeverity(severity string) bool {
+	_, ok := severityRanks[strings.ToLower(severity)]
+	return ok
+}
+
+// AtLeast reports whether severity is at or above threshold
+func AtLeast(severity, threshold string) bool {
+	return SeverityRank(severity) >= SeverityRank(threshold)
+}